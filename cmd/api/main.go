@@ -8,8 +8,12 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	// Recurring tasks are evaluated in IANA time zones; embed the database
+	// so they resolve even in minimal container images.
+	_ "time/tzdata"
 
 	"TaskFlow/internal/app"
+	"TaskFlow/internal/jobs"
 )
 
 func main() {
@@ -24,6 +28,9 @@ func main() {
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	waitJobs := jobs.Start(jobsCtx, a.Jobs...)
+
 	go func() {
 		log.Printf("listening on %s", srv.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -38,5 +45,7 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	_ = srv.Shutdown(ctx)
+	stopJobs()
+	waitJobs()
 	log.Println("shutdown complete")
 }
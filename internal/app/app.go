@@ -1,10 +1,16 @@
 package app
 
 import (
+	"context"
+	"fmt"
 	"net/http"
+	"time"
 
+	"TaskFlow/internal/blob"
 	"TaskFlow/internal/config"
 	httpx "TaskFlow/internal/http"
+	"TaskFlow/internal/jobs"
+	"TaskFlow/internal/mail"
 	"TaskFlow/internal/repo/postgres"
 	"TaskFlow/internal/service"
 )
@@ -12,6 +18,8 @@ import (
 type App struct {
 	Config config.Config
 	Router http.Handler
+	// Jobs are background tasks to run for as long as the server is up.
+	Jobs []jobs.Job
 }
 
 func New() (*App, error) {
@@ -25,20 +33,171 @@ func New() (*App, error) {
 	userRepo := postgres.NewUserRepo(db)
 	projectRepo := postgres.NewProjectRepo(db)
 	taskRepo := postgres.NewTaskRepo(db)
+	dependencyRepo := postgres.NewDependencyRepo(db)
+	labelRepo := postgres.NewLabelRepo(db)
+	commentRepo := postgres.NewCommentRepo(db)
+	attachmentRepo := postgres.NewAttachmentRepo(db)
+	timeEntryRepo := postgres.NewTimeEntryRepo(db)
+	checklistRepo := postgres.NewChecklistRepo(db)
+	customFieldRepo := postgres.NewCustomFieldRepo(db)
+	templateRepo := postgres.NewTemplateRepo(db)
+	duplicationRepo := postgres.NewDuplicationRepo(db)
+	trashRepo := postgres.NewTrashRepo(db)
+	sprintRepo := postgres.NewSprintRepo(db)
+	watcherRepo := postgres.NewWatcherRepo(db)
+	notificationRepo := postgres.NewNotificationRepo(db)
+	digestRepo := postgres.NewDigestRepo(db)
 
+	store, err := newBlobStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := newMailTransport(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	notificationSvc := service.NewNotificationService(notificationRepo,
+		service.WithDueSoonWindow(time.Duration(cfg.DueSoonHours)*time.Hour),
+	)
 	authSvc := service.NewAuthService(userRepo, cfg.JWTSecret)
-	projectSvc := service.NewProjectService(projectRepo)
-	tasksSvc := service.NewTaskService(taskRepo)
+	projectSvc := service.NewProjectService(projectRepo,
+		service.WithDuplications(duplicationRepo, cfg.DuplicateAsyncTasks),
+	)
+	tasksSvc := service.NewTaskService(taskRepo,
+		service.WithMaxDepth(cfg.TaskMaxDepth),
+		service.WithDependencies(dependencyRepo),
+		service.WithChecklists(checklistRepo),
+		service.WithCustomFields(customFieldRepo),
+		service.WithSprints(sprintRepo),
+		service.WithWatchers(watcherRepo),
+		service.WithNotifier(notificationSvc),
+		service.WithBlockerEnforcement(cfg.EnforceBlockers),
+	)
+	labelSvc := service.NewLabelService(labelRepo)
+	commentSvc := service.NewCommentService(commentRepo,
+		service.WithCommentWatchers(watcherRepo),
+		service.WithCommentNotifier(notificationSvc),
+	)
+	attachmentSvc := service.NewAttachmentService(attachmentRepo, store,
+		service.WithMaxAttachmentSize(cfg.AttachmentMaxBytes),
+		service.WithAllowedTypes(cfg.AttachmentTypes),
+	)
+	timeSvc := service.NewTimeService(timeEntryRepo)
+	customFieldSvc := service.NewCustomFieldService(customFieldRepo)
+	templateSvc := service.NewTemplateService(templateRepo, projectRepo, labelRepo, taskRepo,
+		service.WithTemplateMaxDepth(cfg.TaskMaxDepth),
+	)
+	trashSvc := service.NewTrashService(trashRepo,
+		service.WithTrashRetention(time.Duration(cfg.TrashRetentionDays)*24*time.Hour),
+	)
+	sprintSvc := service.NewSprintService(sprintRepo)
+	watcherSvc := service.NewWatcherService(watcherRepo)
+	digestSvc := service.NewDigestService(digestRepo, transport,
+		service.WithDigestBaseURL(cfg.PublicURL),
+	)
 
 	router := httpx.NewRouter(httpx.Deps{
 		Config:     cfg,
 		AuthSvc:    authSvc,
 		ProjectSvc: projectSvc,
 		TaskSvc:    tasksSvc,
+		LabelSvc:   labelSvc,
+		CommentSvc: commentSvc,
+		AttachSvc:  attachmentSvc,
+		TimeSvc:    timeSvc,
+		FieldSvc:   customFieldSvc,
+		TemplSvc:   templateSvc,
+		TrashSvc:   trashSvc,
+		SprintSvc:  sprintSvc,
+		WatchSvc:   watcherSvc,
+		NotifySvc:  notificationSvc,
+		DigestSvc:  digestSvc,
 	})
 
 	return &App{
 		Config: cfg,
 		Router: router,
+		Jobs: []jobs.Job{
+			{
+				Name:     "sweep-attachment-blobs",
+				Interval: time.Minute,
+				Run: func(ctx context.Context) error {
+					_, err := attachmentSvc.SweepDeletedBlobs(ctx)
+					return err
+				},
+			},
+			{
+				Name:     "rebalance-task-positions",
+				Interval: 10 * time.Minute,
+				Run: func(ctx context.Context) error {
+					_, err := tasksSvc.RebalancePositions(ctx)
+					return err
+				},
+			},
+			{
+				Name:     "duplicate-projects",
+				Interval: 5 * time.Second,
+				Run: func(ctx context.Context) error {
+					_, err := projectSvc.RunDuplications(ctx)
+					return err
+				},
+			},
+			{
+				Name:     "notify-due-soon",
+				Interval: 10 * time.Minute,
+				Run: func(ctx context.Context) error {
+					_, err := notificationSvc.NotifyDueSoon(ctx)
+					return err
+				},
+			},
+			{
+				Name:     "send-digests",
+				Interval: 5 * time.Minute,
+				Run: func(ctx context.Context) error {
+					_, err := digestSvc.Send(ctx)
+					return err
+				},
+			},
+			{
+				Name:     "purge-trash",
+				Interval: time.Hour,
+				Run: func(ctx context.Context) error {
+					_, err := trashSvc.Purge(ctx)
+					return err
+				},
+			},
+		},
 	}, nil
 }
+
+func newBlobStore(cfg config.Config) (service.BlobStore, error) {
+	switch cfg.BlobStore {
+	case "fs":
+		return blob.NewFSStore(cfg.BlobDir)
+	case "s3":
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Region:    cfg.S3Region,
+			Bucket:    cfg.S3Bucket,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		}, nil)
+	}
+	return nil, fmt.Errorf("unknown BLOB_STORE %q (want fs or s3)", cfg.BlobStore)
+}
+
+func newMailTransport(cfg config.Config) (service.MailTransport, error) {
+	switch cfg.MailTransport {
+	case "file":
+		return mail.NewFileTransport(cfg.MailDir, cfg.MailFrom)
+	case "smtp":
+		return mail.NewSMTPTransport(mail.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+	}
+	return nil, fmt.Errorf("unknown MAIL_TRANSPORT %q (want file or smtp)", cfg.MailTransport)
+}
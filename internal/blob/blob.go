@@ -0,0 +1,26 @@
+// Package blob provides storage backends for attachment contents.
+package blob
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrNotFound is returned by Get when no object is stored under a key.
+var ErrNotFound = errors.New("blob not found")
+
+var errInvalidKey = errors.New("invalid blob key")
+
+// validKey rejects keys that could escape a store's namespace. Keys are
+// slash-separated paths of non-empty segments other than "." and "..".
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return false
+	}
+	for _, seg := range strings.Split(key, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			return false
+		}
+	}
+	return true
+}
@@ -1,18 +1,82 @@
 package config
 
-import "os"
+import (
+	"os"
+	"strconv"
+	"strings"
+)
 
 type Config struct {
-	HTTPAddr    string
-	DatabaseURL string
-	JWTSecret   string
+	HTTPAddr     string
+	DatabaseURL  string
+	JWTSecret    string
+	TaskMaxDepth int
+	// EnforceBlockers rejects completing a task while it has open blockers.
+	EnforceBlockers bool
+
+	// BlobStore selects where attachment contents are kept: "fs" or "s3".
+	BlobStore   string
+	BlobDir     string
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// AttachmentMaxBytes caps a single upload.
+	AttachmentMaxBytes int64
+	// AttachmentTypes lists accepted MIME types; "image/*" matches a family.
+	AttachmentTypes []string
+	// DuplicateAsyncTasks is the task count above which a project is
+	// copied by a background job rather than during the request.
+	DuplicateAsyncTasks int
+	// TrashRetentionDays is how long deleted projects and tasks can be
+	// restored before a background job purges them.
+	TrashRetentionDays int
+	// DueSoonHours is how long before its due date a task's watchers are
+	// notified that it is due soon.
+	DueSoonHours int
+
+	// PublicURL is where clients reach the API; links in emails point here.
+	PublicURL string
+	// MailTransport selects how email is delivered: "file" writes each
+	// message to MailDir, "smtp" sends it through SMTPAddr.
+	MailTransport string
+	MailDir       string
+	MailFrom      string
+	SMTPAddr      string
+	SMTPUsername  string
+	SMTPPassword  string
 }
 
 func FromEnv() Config {
 	return Config{
-		HTTPAddr:    getenv("HTTP_ADDR", ":8080"),
-		DatabaseURL: must("DATABASE_URL"),
-		JWTSecret:   must("JWT_SECRET"),
+		HTTPAddr:        getenv("HTTP_ADDR", ":8080"),
+		DatabaseURL:     must("DATABASE_URL"),
+		JWTSecret:       must("JWT_SECRET"),
+		TaskMaxDepth:    getenvInt("TASK_MAX_DEPTH", 5),
+		EnforceBlockers: getenvBool("ENFORCE_BLOCKERS", false),
+
+		BlobStore:          getenv("BLOB_STORE", "fs"),
+		BlobDir:            getenv("BLOB_DIR", "./data/blobs"),
+		S3Endpoint:         os.Getenv("S3_ENDPOINT"),
+		S3Region:           getenv("S3_REGION", "us-east-1"),
+		S3Bucket:           os.Getenv("S3_BUCKET"),
+		S3AccessKey:        os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:        os.Getenv("S3_SECRET_KEY"),
+		AttachmentMaxBytes: int64(getenvInt("ATTACHMENT_MAX_BYTES", 25<<20)),
+		AttachmentTypes:    getenvList("ATTACHMENT_TYPES", "image/*,application/pdf,text/plain,application/zip"),
+
+		DuplicateAsyncTasks: getenvInt("DUPLICATE_ASYNC_TASKS", 1000),
+		TrashRetentionDays:  getenvInt("TRASH_RETENTION_DAYS", 30),
+		DueSoonHours:        getenvInt("DUE_SOON_HOURS", 24),
+
+		PublicURL:     getenv("PUBLIC_URL", "http://localhost:8080"),
+		MailTransport: getenv("MAIL_TRANSPORT", "file"),
+		MailDir:       getenv("MAIL_DIR", "./data/mail"),
+		MailFrom:      getenv("MAIL_FROM", "TaskFlow <no-reply@localhost>"),
+		SMTPAddr:      os.Getenv("SMTP_ADDR"),
+		SMTPUsername:  os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:  os.Getenv("SMTP_PASSWORD"),
 	}
 }
 
@@ -23,6 +87,40 @@ func getenv(k, def string) string {
 	return def
 }
 
+func getenvInt(k string, def int) int {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		panic("invalid integer env var: " + k)
+	}
+	return n
+}
+
+func getenvBool(k string, def bool) bool {
+	v := os.Getenv(k)
+	if v == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		panic("invalid boolean env var: " + k)
+	}
+	return b
+}
+
+func getenvList(k, def string) []string {
+	var out []string
+	for _, v := range strings.Split(getenv(k, def), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 func must(k string) string {
 	v := os.Getenv(k)
 	if v == "" {
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// Attachment is a file uploaded to a task. Its contents live in a blob
+// store under StorageKey; SHA256 is the hex digest of those contents.
+type Attachment struct {
+	ID          string    `json:"id"`
+	TaskID      string    `json:"taskId"`
+	UploaderID  string    `json:"uploaderId"`
+	Filename    string    `json:"filename"`
+	ContentType string    `json:"contentType"`
+	Size        int64     `json:"size"`
+	SHA256      string    `json:"sha256"`
+	StorageKey  string    `json:"-"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
@@ -0,0 +1,24 @@
+package domain
+
+// BoardSort is the Cursor.Sort of board column cursors, whose Values hold
+// the column's status and the last task's position.
+const BoardSort = "status,position"
+
+// BoardQuery selects the columns of a project board and where each starts.
+type BoardQuery struct {
+	// Statuses are the columns to return, in board order when empty.
+	Statuses []TaskStatus
+	// Limit caps the tasks returned per column.
+	Limit int
+	// Cursors continue individual columns from a previous response.
+	Cursors map[TaskStatus]Cursor
+}
+
+// BoardColumn is one status column of a project board. Count is the number
+// of tasks in the column, regardless of paging.
+type BoardColumn struct {
+	Status     TaskStatus
+	Count      int
+	Tasks      []Task
+	NextCursor *Cursor
+}
@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// BurndownDay is the state of a project at the end of one day. Estimate
+// sums use each task's current estimate; tasks without one count as zero.
+type BurndownDay struct {
+	Date              string  `json:"date"`
+	RemainingTasks    int     `json:"remainingTasks"`
+	CompletedTasks    int     `json:"completedTasks"`
+	RemainingEstimate float64 `json:"remainingEstimate"`
+	CompletedEstimate float64 `json:"completedEstimate"`
+}
+
+// Burndown is a project's daily remaining-versus-completed series.
+type Burndown struct {
+	ProjectID    string        `json:"projectId"`
+	EstimateUnit EstimateUnit  `json:"estimateUnit"`
+	Timezone     string        `json:"timezone"`
+	Days         []BurndownDay `json:"days"`
+}
+
+// BurndownQuery selects the days of a burndown, From and To inclusive, as
+// calendar dates in Location.
+type BurndownQuery struct {
+	From, To time.Time
+	Location *time.Location
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// ChecklistItem is one line of a task's checklist.
+type ChecklistItem struct {
+	ID      string `json:"id"`
+	TaskID  string `json:"taskId"`
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+	// Position orders items within the task; compare keys byte-wise.
+	Position  string    `json:"position"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// ChecklistItemPatch describes a partial checklist item update. Nil fields
+// are left unchanged.
+type ChecklistItemPatch struct {
+	Text    *string
+	Checked *bool
+}
@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// Comment is a Markdown note on a task. Top-level comments may carry a
+// single level of replies; a reply's ParentID names its top-level comment.
+// Deleted comments are kept as tombstones so threads stay intact, with
+// their body withheld.
+type Comment struct {
+	ID        string     `json:"id"`
+	TaskID    string     `json:"taskId"`
+	ParentID  *string    `json:"parentId"`
+	AuthorID  string     `json:"authorId"`
+	Body      string     `json:"body"`
+	BodyHTML  string     `json:"bodyHtml,omitempty"`
+	Edited    bool       `json:"edited"`
+	EditedAt  *time.Time `json:"editedAt"`
+	Deleted   bool       `json:"deleted"`
+	Replies   []Comment  `json:"replies,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+	UpdatedAt time.Time  `json:"updatedAt"`
+}
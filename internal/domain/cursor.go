@@ -1,8 +1,40 @@
 package domain
 
-import "time"
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
 
+// Cursor is a keyset pagination position. CreatedAt and ID identify the last
+// row of a page. When a listing is ordered by other keys, Sort records the
+// ordering the cursor was issued for and Values holds the last row's value
+// for each of those keys, in order.
 type Cursor struct {
 	CreatedAt time.Time `json:"createdAt"`
 	ID        string    `json:"id"`
+	Sort      string    `json:"sort,omitempty"`
+	Values    []string  `json:"values,omitempty"`
+}
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Token encodes the cursor as an opaque URL-safe string.
+func (c Cursor) Token() string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// ParseCursorToken decodes a token produced by Cursor.Token.
+func ParseCursorToken(s string) (Cursor, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	var c Cursor
+	if err := json.Unmarshal(b, &c); err != nil || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
 }
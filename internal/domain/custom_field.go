@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"strings"
+	"time"
+)
+
+// CustomFieldType is the kind of value a custom field holds.
+type CustomFieldType string
+
+const (
+	FieldText         CustomFieldType = "text"
+	FieldNumber       CustomFieldType = "number"
+	FieldDate         CustomFieldType = "date"
+	FieldSingleSelect CustomFieldType = "single_select"
+	FieldMultiSelect  CustomFieldType = "multi_select"
+	FieldUser         CustomFieldType = "user"
+	FieldURL          CustomFieldType = "url"
+)
+
+// CustomFieldTypes lists every field type.
+var CustomFieldTypes = []CustomFieldType{
+	FieldText, FieldNumber, FieldDate, FieldSingleSelect, FieldMultiSelect, FieldUser, FieldURL,
+}
+
+func (t CustomFieldType) Valid() bool {
+	switch t {
+	case FieldText, FieldNumber, FieldDate, FieldSingleSelect, FieldMultiSelect, FieldUser, FieldURL:
+		return true
+	}
+	return false
+}
+
+// HasOptions reports whether values are picked from the field's options.
+func (t CustomFieldType) HasOptions() bool {
+	return t == FieldSingleSelect || t == FieldMultiSelect
+}
+
+// Ordered reports whether values support range filters (gt, lt, ...).
+func (t CustomFieldType) Ordered() bool {
+	return t == FieldNumber || t == FieldDate
+}
+
+// CustomField is a project-level field definition. Values are stored per
+// task and keyed by the field's ID in Task.CustomFields.
+type CustomField struct {
+	ID        string          `json:"id"`
+	ProjectID string          `json:"projectId"`
+	Name      string          `json:"name"`
+	Type      CustomFieldType `json:"type"`
+	// Options are the choices of a select field, in display order.
+	Options   []string  `json:"options"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// CustomFieldPatch describes a partial field update. The type cannot
+// change. Nil fields are left unchanged.
+type CustomFieldPatch struct {
+	Name    *string
+	Options []string
+}
+
+// CustomFieldOp is the comparison a custom field filter applies.
+type CustomFieldOp string
+
+const (
+	OpEq  CustomFieldOp = "eq"
+	OpGt  CustomFieldOp = "gt"
+	OpGte CustomFieldOp = "gte"
+	OpLt  CustomFieldOp = "lt"
+	OpLte CustomFieldOp = "lte"
+	// OpEmpty matches tasks without (Value "true") or with (Value "false")
+	// a value for the field.
+	OpEmpty CustomFieldOp = "empty"
+)
+
+// CustomFieldFilter narrows a task listing by one custom field value. For
+// a multi-select field, eq matches tasks whose selection includes Value.
+type CustomFieldFilter struct {
+	FieldID string
+	// Type is filled in from the field definition before querying.
+	Type  CustomFieldType
+	Op    CustomFieldOp
+	Value string
+}
+
+// CustomFieldSortPrefix marks a sort field naming a custom field, as in
+// "cf.<fieldId>" or "-cf.<fieldId>".
+const CustomFieldSortPrefix = "cf."
+
+// CustomFieldSortID returns the field ID of a custom field sort key, or ""
+// for a built-in field.
+func CustomFieldSortID(field string) string {
+	if id, ok := strings.CutPrefix(field, CustomFieldSortPrefix); ok {
+		return id
+	}
+	return ""
+}
@@ -0,0 +1,7 @@
+package domain
+
+// TaskDependencies lists the tasks on either side of a task's dependency links.
+type TaskDependencies struct {
+	BlockedBy []Task `json:"blockedBy"`
+	Blocks    []Task `json:"blocks"`
+}
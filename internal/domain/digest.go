@@ -0,0 +1,61 @@
+package domain
+
+import "time"
+
+// DigestFrequency is how often a user is emailed a digest of unread
+// notifications.
+type DigestFrequency string
+
+const (
+	DigestOff    DigestFrequency = "off"
+	DigestDaily  DigestFrequency = "daily"
+	DigestWeekly DigestFrequency = "weekly"
+)
+
+// DigestSettings says when a user's digest is sent: at Hour o'clock in
+// Timezone, every day or, for weekly digests, on Weekday (0 is Sunday).
+// Users who never changed them get a daily digest at 08:00 UTC.
+type DigestSettings struct {
+	Frequency  DigestFrequency `json:"frequency"`
+	Timezone   string          `json:"timezone"`
+	Hour       int             `json:"hour"`
+	Weekday    int             `json:"weekday"`
+	LastSentAt *time.Time      `json:"lastSentAt"`
+}
+
+// DefaultDigestSettings are the settings of users without their own.
+func DefaultDigestSettings() DigestSettings {
+	return DigestSettings{Frequency: DigestDaily, Timezone: "UTC", Hour: 8, Weekday: 1}
+}
+
+// DigestSettingsPatch describes a partial settings update. Nil fields are
+// left unchanged.
+type DigestSettingsPatch struct {
+	Frequency *DigestFrequency
+	Timezone  *string
+	Hour      *int
+	Weekday   *int
+}
+
+// DigestRecipient is a user with unread notifications not yet covered by a
+// digest. Since is when the oldest of them was created. Token is the
+// user's unsubscribe token, empty until their settings are first stored.
+type DigestRecipient struct {
+	UserID   string
+	Email    string
+	Settings DigestSettings
+	Token    string
+	Since    time.Time
+}
+
+// DigestItem is one unread notification as listed in a digest.
+type DigestItem struct {
+	Type        NotificationType
+	TaskID      string
+	TaskTitle   string
+	ProjectName string
+	// Actor is the email of the user who made the change, if any.
+	Actor     string
+	DueDate   *time.Time
+	CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// DuplicateOptions controls how a project is copied.
+type DuplicateOptions struct {
+	// Name names the copy; empty means "<source name> (copy)".
+	Name string `json:"name"`
+	// ResetCompletion reopens every copied task and unticks its checklist.
+	ResetCompletion bool `json:"resetCompletion"`
+	// ShiftDays moves every due date by this many days.
+	ShiftDays int `json:"shiftDays"`
+}
+
+// DuplicationStatus is the state of a background project copy.
+type DuplicationStatus string
+
+const (
+	DuplicationPending DuplicationStatus = "pending"
+	DuplicationDone    DuplicationStatus = "done"
+	DuplicationFailed  DuplicationStatus = "failed"
+)
+
+// Duplication is a project copy run in the background because the source
+// project is large.
+type Duplication struct {
+	ID     string `json:"id"`
+	UserID string `json:"-"`
+	// SourceProjectID is nil once the source project has been deleted.
+	SourceProjectID *string           `json:"sourceProjectId"`
+	Options         DuplicateOptions  `json:"options"`
+	Status          DuplicationStatus `json:"status"`
+	// ProjectID is the copy, set once Status is done.
+	ProjectID *string `json:"projectId"`
+	// Error says why a failed copy failed.
+	Error     string    `json:"error,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
@@ -0,0 +1,44 @@
+package domain
+
+import "errors"
+
+// ErrDuplicate is returned by repositories when a write would violate a
+// uniqueness rule, such as two labels with the same name in a project.
+var ErrDuplicate = errors.New("duplicate")
+
+// ErrInvalidNeighbor is returned when a task is moved next to a task that
+// does not exist, belongs to another project, or when the requested
+// neighbors are not next to each other in that order. Checklist items follow the same rule within
+// their task.
+var ErrInvalidNeighbor = errors.New("invalid neighbor")
+
+// ErrDependencyCycle is returned when a blocker link would close a cycle
+// in the dependency graph.
+var ErrDependencyCycle = errors.New("dependency cycle")
+
+// ErrInvalidParent is returned when a task is moved under a parent that
+// does not exist or belongs to another project.
+var ErrInvalidParent = errors.New("invalid parent")
+
+// ErrParentCycle is returned when a task is moved under itself or one of
+// its own subtasks.
+var ErrParentCycle = errors.New("parent cycle")
+
+// ErrTooDeep is returned when moving a task under a new parent would nest
+// its subtasks deeper than allowed.
+var ErrTooDeep = errors.New("nested too deep")
+
+// ErrArchived is returned by repositories that check inside their own
+// transaction whether the project they write to is archived.
+var ErrArchived = errors.New("archived")
+
+// ErrInvalidProject is returned when a task is moved to a project that
+// does not exist or is not owned by the caller.
+var ErrInvalidProject = errors.New("invalid project")
+
+// ErrInvalidSprint is returned when unfinished tasks are carried over to a
+// sprint that does not exist, belongs to another project or is completed.
+var ErrInvalidSprint = errors.New("invalid sprint")
+
+// ErrSprintCompleted is returned when a sprint is completed a second time.
+var ErrSprintCompleted = errors.New("sprint completed")
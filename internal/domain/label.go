@@ -0,0 +1,28 @@
+package domain
+
+import "time"
+
+// Label is a project-scoped tag that can be attached to any task in the project.
+type Label struct {
+	ID        string    `json:"id"`
+	ProjectID string    `json:"projectId"`
+	Name      string    `json:"name"`
+	Color     string    `json:"color"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// TaskLabel is the compact form of a label embedded in task responses.
+type TaskLabel struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// LabelMatch selects whether a label filter requires any or all labels.
+type LabelMatch string
+
+const (
+	LabelMatchAny LabelMatch = "any"
+	LabelMatchAll LabelMatch = "all"
+)
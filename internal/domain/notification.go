@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// NotificationType names the event a notification reports.
+type NotificationType string
+
+const (
+	NotifyAssigned  NotificationType = "assigned"
+	NotifyCommented NotificationType = "commented"
+	NotifyCompleted NotificationType = "completed"
+	NotifyDueSoon   NotificationType = "due_soon"
+)
+
+// Notification tells a user about a change to a task they follow.
+type Notification struct {
+	ID        string           `json:"id"`
+	Type      NotificationType `json:"type"`
+	TaskID    string           `json:"taskId"`
+	ProjectID string           `json:"projectId"`
+	TaskTitle string           `json:"taskTitle"`
+	// ActorID is the user who made the change; nil for due_soon and once
+	// the actor's account is gone.
+	ActorID   *string `json:"actorId"`
+	CommentID *string `json:"commentId"`
+	// DueDate is the due date a due_soon notification was sent for.
+	DueDate   *time.Time `json:"dueDate"`
+	ReadAt    *time.Time `json:"readAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+// TaskEvent is a change to a task that produces a notification for each of
+// Recipients who has not turned its type off.
+type TaskEvent struct {
+	Type       NotificationType
+	TaskID     string
+	ActorID    string
+	CommentID  *string
+	Recipients []string
+}
+
+// NotificationPrefs records which events notify a user. Every type is on
+// until turned off.
+type NotificationPrefs struct {
+	Assigned  bool `json:"assigned"`
+	Commented bool `json:"commented"`
+	Completed bool `json:"completed"`
+	DueSoon   bool `json:"dueSoon"`
+}
+
+// NotificationPrefsPatch describes a partial preferences update. Nil fields
+// are left unchanged.
+type NotificationPrefsPatch struct {
+	Assigned  *bool
+	Commented *bool
+	Completed *bool
+	DueSoon   *bool
+}
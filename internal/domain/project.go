@@ -1,11 +1,49 @@
 package domain
 
-import "time"
+import (
+	"regexp"
+	"strings"
+	"time"
+)
 
 type Project struct {
-	ID        string    `json:"id"`
-	UserID    string    `json:"-"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID     string `json:"id"`
+	UserID string `json:"-"`
+	Name   string `json:"name"`
+	// Key prefixes the keys of the project's tasks, as in WEB-123.
+	Key string `json:"key"`
+	// EstimateUnit is what the estimates of the project's tasks count.
+	EstimateUnit EstimateUnit `json:"estimateUnit"`
+	// ArchivedAt is set while the project is archived. The tasks of an
+	// archived project are read-only.
+	ArchivedAt *time.Time `json:"archivedAt"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	UpdatedAt  time.Time  `json:"updatedAt"`
 }
+
+// ProjectPatch describes a partial project update. Nil fields are left
+// unchanged.
+type ProjectPatch struct {
+	Name         *string
+	Key          *string
+	EstimateUnit *EstimateUnit
+}
+
+var projectKeyPattern = regexp.MustCompile(`^[A-Z][A-Z0-9]{1,9}$`)
+
+// NormalizeProjectKey upper-cases a project key and reports whether it is
+// valid: two to ten letters and digits, starting with a letter.
+func NormalizeProjectKey(key string) (string, bool) {
+	key = strings.ToUpper(strings.TrimSpace(key))
+	return key, projectKeyPattern.MatchString(key)
+}
+
+// EstimateUnit is the unit task estimates are given in.
+type EstimateUnit string
+
+const (
+	EstimatePoints EstimateUnit = "points"
+	EstimateHours  EstimateUnit = "hours"
+)
+
+func (u EstimateUnit) Valid() bool { return u == EstimatePoints || u == EstimateHours }
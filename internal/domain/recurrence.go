@@ -0,0 +1,26 @@
+package domain
+
+// RegenerateFrom decides which date the next occurrence of a recurring
+// task is computed from.
+type RegenerateFrom string
+
+const (
+	// FromScheduled keeps a fixed schedule: the next occurrence follows the
+	// completed one's due date, however late it was finished.
+	FromScheduled RegenerateFrom = "scheduled"
+	// FromCompletion restarts the schedule from the moment the task was
+	// completed, e.g. "water the plants 3 days after last time".
+	FromCompletion RegenerateFrom = "completion"
+)
+
+// Recurrence makes a task repeat. Rule is an RFC 5545 RRULE value evaluated
+// in Timezone (an IANA name). Occurrence numbers the task within its series,
+// starting at 1, so COUNT can be honoured. NextTaskID is set once the next
+// occurrence has been generated.
+type Recurrence struct {
+	Rule           string         `json:"rule"`
+	Timezone       string         `json:"timezone"`
+	RegenerateFrom RegenerateFrom `json:"regenerateFrom"`
+	Occurrence     int            `json:"occurrence"`
+	NextTaskID     *string        `json:"nextTaskId"`
+}
@@ -0,0 +1,65 @@
+package domain
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortKey is one term of a sort expression such as "-priority".
+type SortKey struct {
+	Field string
+	Desc  bool
+	// FieldType is the type of a custom field key ("cf.<fieldId>"), filled
+	// in from the field definition before querying.
+	FieldType CustomFieldType
+}
+
+// TaskSortFields are the task fields a listing may be ordered by.
+var TaskSortFields = []string{"position", "priority", "dueDate", "title", "createdAt", "updatedAt"}
+
+// ParseTaskSort parses a comma-separated list of task sort fields, each
+// optionally prefixed with "-" for descending order. Custom fields are
+// named "cf.<fieldId>"; whether the field exists is checked later.
+func ParseTaskSort(s string) ([]SortKey, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, nil
+	}
+
+	var keys []SortKey
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		k := SortKey{Field: part}
+		if strings.HasPrefix(part, "-") {
+			k = SortKey{Field: part[1:], Desc: true}
+		}
+		if strings.HasPrefix(k.Field, CustomFieldSortPrefix) {
+			if CustomFieldSortID(k.Field) == "" {
+				return nil, fmt.Errorf("sort field %q is missing a custom field ID", k.Field)
+			}
+		} else if !slices.Contains(TaskSortFields, k.Field) {
+			return nil, fmt.Errorf("unknown sort field %q (allowed: %s)", k.Field, strings.Join(TaskSortFields, ", "))
+		}
+		if seen[k.Field] {
+			return nil, fmt.Errorf("duplicate sort field %q", k.Field)
+		}
+		seen[k.Field] = true
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+// FormatSort renders keys back into their canonical query form.
+func FormatSort(keys []SortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		if k.Desc {
+			parts[i] = "-" + k.Field
+		} else {
+			parts[i] = k.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
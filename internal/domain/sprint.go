@@ -0,0 +1,54 @@
+package domain
+
+import "time"
+
+// Sprint is a time-boxed iteration of a project. A task belongs to at most
+// one sprint; tasks without one are in the project's backlog.
+type Sprint struct {
+	ID        string `json:"id"`
+	ProjectID string `json:"projectId"`
+	Name      string `json:"name"`
+	Goal      string `json:"goal"`
+	// StartDate and EndDate are inclusive YYYY-MM-DD calendar dates.
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+	// CompletedAt is nil while the sprint is open.
+	CompletedAt *time.Time `json:"completedAt"`
+	CreatedAt   time.Time  `json:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt"`
+}
+
+// SprintPatch describes a partial sprint update. Nil fields are left
+// unchanged.
+type SprintPatch struct {
+	Name      *string
+	Goal      *string
+	StartDate *string
+	EndDate   *string
+}
+
+// SprintCompletion is the outcome of completing a sprint.
+type SprintCompletion struct {
+	Sprint Sprint `json:"sprint"`
+	// NextSprintID is the sprint the unfinished tasks were carried to; nil
+	// when they went back to the backlog.
+	NextSprintID *string `json:"nextSprintId"`
+	CarriedOver  int     `json:"carriedOver"`
+}
+
+// SprintStats summarizes a sprint's progress. Tasks carried over when the
+// sprint was completed still count toward its totals, as unfinished work.
+// Estimate sums are in the project's estimate unit; tasks without an
+// estimate count as zero.
+type SprintStats struct {
+	SprintID          string       `json:"sprintId"`
+	EstimateUnit      EstimateUnit `json:"estimateUnit"`
+	TotalTasks        int          `json:"totalTasks"`
+	CompletedTasks    int          `json:"completedTasks"`
+	CarriedOverTasks  int          `json:"carriedOverTasks"`
+	TotalEstimate     float64      `json:"totalEstimate"`
+	CompletedEstimate float64      `json:"completedEstimate"`
+	// CompletionRate is CompletedTasks / TotalTasks, or 0 for an empty
+	// sprint.
+	CompletionRate float64 `json:"completionRate"`
+}
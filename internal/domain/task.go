@@ -1,12 +1,236 @@
 package domain
 
-import "time"
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
 
 type Task struct {
-	ID        string    `json:"id"`
-	ProjectID string    `json:"projectId"`
-	Title     string    `json:"title"`
-	Completed bool      `json:"completed"`
-	CreatedAt time.Time `json:"createdAt"`
-	UpdatedAt time.Time `json:"updatedAt"`
+	ID        string `json:"id"`
+	ProjectID string `json:"projectId"`
+	// Number counts up within the project; Key joins it to the project
+	// key, as in WEB-123.
+	Number       int     `json:"number"`
+	Key          string  `json:"key"`
+	ParentTaskID *string `json:"parentTaskId"`
+	Title        string  `json:"title"`
+	// Description is Markdown source. DescriptionHTML is only populated
+	// when a client asks for server-rendered output.
+	Description     string `json:"description"`
+	DescriptionHTML string `json:"descriptionHtml,omitempty"`
+	Completed       bool   `json:"completed"`
+	// CompletedAt and CompletedBy record when and by whom the task was
+	// last completed; both are nil while it is open.
+	CompletedAt *time.Time `json:"completedAt"`
+	CompletedBy *string    `json:"completedBy"`
+	// Status is the task's board column; it is done exactly when the
+	// task is completed.
+	Status   TaskStatus `json:"status"`
+	Priority Priority   `json:"priority"`
+	DueDate  *time.Time `json:"dueDate"`
+	// Estimate is in the project's estimate unit; nil when not estimated.
+	Estimate *float64 `json:"estimate"`
+	// SprintID is nil for tasks in the project's backlog.
+	SprintID *string `json:"sprintId"`
+	// Roll-up counts over the task's direct subtasks.
+	SubtaskCount          int `json:"subtaskCount"`
+	CompletedSubtaskCount int `json:"completedSubtaskCount"`
+	// Checklist progress: items ticked off out of all items, and the same
+	// rendered as "3/5" for display.
+	ChecklistTotal    int    `json:"checklistTotal"`
+	ChecklistChecked  int    `json:"checklistChecked"`
+	ChecklistProgress string `json:"checklistProgress"`
+	// IsBlocked is true while any task blocking this one is still open.
+	IsBlocked bool        `json:"isBlocked"`
+	Labels    []TaskLabel `json:"labels"`
+	// Position is the task's fractional-index key for manual ordering
+	// within its project; compare keys byte-wise.
+	Position string `json:"position"`
+	// Recurrence is nil for one-off tasks.
+	Recurrence *Recurrence `json:"recurrence"`
+	// CustomFields maps custom field IDs to the task's values; fields
+	// without a value are omitted.
+	CustomFields map[string]json.RawMessage `json:"customFields"`
+	CreatedAt    time.Time                  `json:"createdAt"`
+	UpdatedAt    time.Time                  `json:"updatedAt"`
+}
+
+// TaskNode is a task together with its nested subtasks.
+type TaskNode struct {
+	Task
+	Subtasks []TaskNode `json:"subtasks"`
+}
+
+// TaskFilter narrows a task listing. ProjectID is required.
+type TaskFilter struct {
+	ProjectID string
+	Completed *bool
+	Status    *TaskStatus
+	// Labels are label names, matched case-insensitively.
+	Labels       []string
+	LabelMatch   LabelMatch
+	CustomFields []CustomFieldFilter
+	// SprintID limits the listing to one sprint's tasks, and Backlog to
+	// tasks in no sprint.
+	SprintID string
+	Backlog  bool
+	// CompletedAfter and CompletedBefore limit the listing to tasks
+	// completed at or after, and before, the given times.
+	CompletedAfter  *time.Time
+	CompletedBefore *time.Time
+}
+
+// TaskInput carries the caller-supplied fields for a new task.
+type TaskInput struct {
+	Title       string
+	Description string
+	// Status defaults to todo.
+	Status       TaskStatus
+	Priority     Priority
+	DueDate      *time.Time
+	Estimate     *float64
+	ParentTaskID *string
+	SprintID     *string
+	Recurrence   *Recurrence
+	// CustomFields maps field IDs to values.
+	CustomFields map[string]json.RawMessage
+}
+
+// TaskPatch describes a partial task update. Nil fields are left unchanged.
+type TaskPatch struct {
+	Title        *string
+	Description  *string
+	Completed    *bool
+	Status       *TaskStatus
+	Priority     *Priority
+	DueDate      *time.Time
+	ClearDueDate bool
+	// Estimate replaces the estimate; ClearEstimate removes it.
+	Estimate      *float64
+	ClearEstimate bool
+	ParentTaskID  *string
+	ClearParent   bool
+	// MaxDepth is how many levels of subtasks may lie below a top-level
+	// task, checked against a new ParentTaskID; zero means no limit.
+	MaxDepth int
+	// ProjectID moves the task, with its subtasks, to another project,
+	// where it becomes top-level unless ParentTaskID names a new parent.
+	// The moved tasks leave their sprints; SprintID may name one of the
+	// target project.
+	ProjectID *string
+	// SprintID puts the task in a sprint; ClearSprint moves it back to
+	// the backlog.
+	SprintID    *string
+	ClearSprint bool
+	// Recurrence replaces the task's recurrence; ClearRecurrence removes it.
+	Recurrence      *Recurrence
+	ClearRecurrence bool
+	// CompleteSubtasks also marks every descendant completed when the
+	// patch sets Completed to true.
+	CompleteSubtasks bool
+	// CompletedAt and CompletedBy are recorded on tasks the patch
+	// completes; tasks that were already completed keep theirs, and
+	// reopening clears them.
+	CompletedAt *time.Time
+	CompletedBy *string
+	// CustomFields sets the listed fields' values; a JSON null removes
+	// a value. Fields not listed are left unchanged.
+	CustomFields map[string]json.RawMessage
+}
+
+// ChildPolicy decides what happens to a task's subtasks when it is deleted.
+type ChildPolicy string
+
+const (
+	// ChildrenCascade deletes the whole subtree.
+	ChildrenCascade ChildPolicy = "cascade"
+	// ChildrenReparent moves direct subtasks up to the deleted task's parent.
+	ChildrenReparent ChildPolicy = "reparent"
+)
+
+// TaskStatus is the board column a task sits in.
+type TaskStatus string
+
+const (
+	StatusTodo       TaskStatus = "todo"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusDone       TaskStatus = "done"
+)
+
+// TaskStatuses lists every status in board column order.
+var TaskStatuses = []TaskStatus{StatusTodo, StatusInProgress, StatusDone}
+
+func (s TaskStatus) Valid() bool {
+	return s == StatusTodo || s == StatusInProgress || s == StatusDone
+}
+
+// Priority is stored as a small integer so that it sorts naturally;
+// it is exposed as a name over the API.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+	PriorityUrgent
+)
+
+var priorityNames = []string{"none", "low", "medium", "high", "urgent"}
+
+func (p Priority) Valid() bool { return p >= PriorityNone && p <= PriorityUrgent }
+
+func (p Priority) String() string {
+	if !p.Valid() {
+		return fmt.Sprintf("Priority(%d)", int(p))
+	}
+	return priorityNames[p]
+}
+
+func ParsePriority(s string) (Priority, error) {
+	for i, name := range priorityNames {
+		if s == name {
+			return Priority(i), nil
+		}
+	}
+	return PriorityNone, fmt.Errorf("priority must be one of: none, low, medium, high, urgent")
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("priority must be a string")
+	}
+	v, err := ParsePriority(s)
+	if err != nil {
+		return err
+	}
+	*p = v
+	return nil
+}
+
+// ParseTaskKey splits a task key such as WEB-123, in any case, into the
+// upper-cased project key and the task number.
+func ParseTaskKey(s string) (projectKey string, number int, ok bool) {
+	i := strings.LastIndexByte(s, '-')
+	if i < 0 {
+		return "", 0, false
+	}
+	projectKey = strings.ToUpper(s[:i])
+	if !projectKeyPattern.MatchString(projectKey) {
+		return "", 0, false
+	}
+	digits := s[i+1:]
+	if digits == "" || digits[0] == '0' || len(digits) > 9 || strings.Trim(digits, "0123456789") != "" {
+		return "", 0, false
+	}
+	number, _ = strconv.Atoi(digits)
+	return projectKey, number, true
 }
@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// Template is a reusable outline of a project: its labels and a tree of
+// tasks. Text may contain variables such as {{client}}, which are filled in
+// when the template is instantiated.
+type Template struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	// ProjectName names the projects created from the template.
+	ProjectName  string          `json:"projectName"`
+	EstimateUnit EstimateUnit    `json:"estimateUnit"`
+	Labels       []TemplateLabel `json:"labels"`
+	Tasks        []TemplateTask  `json:"tasks"`
+	// Variables lists the variables used in ProjectName, task titles and
+	// descriptions, in order of first use.
+	Variables []string  `json:"variables"`
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+type TemplateLabel struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+// TemplateTask is a task of a template. Tasks are created open.
+type TemplateTask struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description,omitempty"`
+	Priority    Priority `json:"priority"`
+	Estimate    *float64 `json:"estimate,omitempty"`
+	// DueInDays sets the due date relative to the start date given when
+	// the template is instantiated; nil leaves the task without one.
+	DueInDays *int `json:"dueInDays,omitempty"`
+	// Labels are names of the template's labels.
+	Labels   []string       `json:"labels,omitempty"`
+	Subtasks []TemplateTask `json:"subtasks,omitempty"`
+}
+
+// ProjectBlueprint is a fully resolved project to create in one go, with
+// variables substituted and due dates computed.
+type ProjectBlueprint struct {
+	Name         string
+	EstimateUnit EstimateUnit
+	Labels       []TemplateLabel
+	Tasks        []BlueprintTask
+}
+
+// BlueprintTask is a task of a ProjectBlueprint. Its ParentTaskID is
+// ignored; the parent is the enclosing task.
+type BlueprintTask struct {
+	TaskInput
+	// Labels are names of the blueprint's labels.
+	Labels   []string
+	Subtasks []BlueprintTask
+}
+
+// TemplateInstance is the input for creating a project from a template.
+type TemplateInstance struct {
+	// Name overrides the template's project name.
+	Name string
+	// Variables gives a value to every variable the template uses.
+	Variables map[string]string
+	// StartDate is the date due date offsets count from; nil means now.
+	StartDate *time.Time
+}
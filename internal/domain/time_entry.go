@@ -0,0 +1,66 @@
+package domain
+
+import "time"
+
+// TimeEntry is a span of time a user spent on a task. A running timer is
+// an entry without an end; each user has at most one.
+type TimeEntry struct {
+	ID        string     `json:"id"`
+	TaskID    string     `json:"taskId"`
+	UserID    string     `json:"userId"`
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+	Note      string     `json:"note"`
+	Running   bool       `json:"running"`
+	// DurationSeconds counts up to now while the timer is running.
+	DurationSeconds int64     `json:"durationSeconds"`
+	CreatedAt       time.Time `json:"createdAt"`
+	UpdatedAt       time.Time `json:"updatedAt"`
+}
+
+// TimeEntryInput carries the fields of a manually logged entry, or the
+// full new state of an edited one.
+type TimeEntryInput struct {
+	StartedAt time.Time
+	EndedAt   *time.Time
+	Note      string
+}
+
+// TimeEntryPatch describes a partial edit of an entry. Nil fields are left
+// unchanged; setting EndedAt on a running timer stops it.
+type TimeEntryPatch struct {
+	StartedAt *time.Time
+	EndedAt   *time.Time
+	Note      *string
+}
+
+// TimeEntrySort is the Cursor.Sort of time entry listings, newest first;
+// Values holds the last entry's start time.
+const TimeEntrySort = "-startedAt"
+
+// TimeGroup is what time totals are summed per.
+type TimeGroup string
+
+const (
+	TimeByTask    TimeGroup = "task"
+	TimeByProject TimeGroup = "project"
+	TimeByUser    TimeGroup = "user"
+)
+
+// TimeTotalsQuery selects the entries summed into totals. Only the part of
+// each entry that falls within [From, To) counts. ProjectID and TaskID
+// optionally narrow the entries.
+type TimeTotalsQuery struct {
+	GroupBy   TimeGroup
+	From, To  time.Time
+	ProjectID *string
+	TaskID    *string
+}
+
+// TimeTotal is the time logged against one task, project or user. Name is
+// the task title, project name or user email.
+type TimeTotal struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	TotalSeconds int64  `json:"totalSeconds"`
+}
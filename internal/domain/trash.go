@@ -0,0 +1,23 @@
+package domain
+
+import "time"
+
+// Trash holds what a user has deleted and can still restore. Tasks are
+// listed only where a restore would bring them back: in live projects and
+// not under a deleted parent that takes them along.
+type Trash struct {
+	Projects []TrashedProject `json:"projects"`
+	Tasks    []TrashedTask    `json:"tasks"`
+}
+
+type TrashedProject struct {
+	Project
+	DeletedAt time.Time `json:"deletedAt"`
+}
+
+// TrashedTask is a deleted task. Subtasks deleted with it are restored
+// with it and not listed on their own.
+type TrashedTask struct {
+	Task
+	DeletedAt time.Time `json:"deletedAt"`
+}
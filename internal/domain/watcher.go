@@ -0,0 +1,22 @@
+package domain
+
+import "time"
+
+// WatchReason records how a user came to watch a task.
+type WatchReason string
+
+const (
+	WatchManual    WatchReason = "manual"
+	WatchCreator   WatchReason = "creator"
+	WatchAssignee  WatchReason = "assignee"
+	WatchCommenter WatchReason = "commenter"
+)
+
+// Watcher is a user subscribed to a task or project. Reason is empty for
+// project watchers, who always subscribe explicitly.
+type Watcher struct {
+	UserID    string      `json:"userId"`
+	Email     string      `json:"email"`
+	Reason    WatchReason `json:"reason,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
@@ -0,0 +1,113 @@
+// Package fracindex generates order keys for manual ordering. Keys are
+// base-62 fractions written as strings that sort by plain byte comparison,
+// so a key can always be found between any two neighbours and moving an
+// item only rewrites that item's key.
+//
+// A key is a non-empty string of digits from Digits that does not end in
+// the smallest digit '0'; that guarantees room below every key.
+package fracindex
+
+import (
+	"errors"
+	"strings"
+)
+
+// Digits is the key alphabet in ascending byte order.
+const Digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(Digits)
+
+var ErrInvalidKey = errors.New("invalid order key")
+
+// ErrOutOfOrder is returned by Between when a is not below b.
+var ErrOutOfOrder = errors.New("order keys out of order")
+
+// Valid reports whether k is a well-formed key.
+func Valid(k string) bool {
+	if k == "" || k[len(k)-1] == '0' {
+		return false
+	}
+	for i := 0; i < len(k); i++ {
+		if strings.IndexByte(Digits, k[i]) < 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Between returns a key strictly between a and b. An empty a means "before
+// everything" and an empty b "after everything", so Between("", "") yields
+// a first key.
+func Between(a, b string) (string, error) {
+	if (a != "" && !Valid(a)) || (b != "" && !Valid(b)) {
+		return "", ErrInvalidKey
+	}
+	if a != "" && b != "" && a >= b {
+		return "", ErrOutOfOrder
+	}
+	return midpoint(a, b), nil
+}
+
+// midpoint assumes a < b (or b is empty, meaning the upper bound).
+func midpoint(a, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(a) && n < len(b) && a[n] == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(a[n:], b[n:])
+		}
+	}
+
+	da := 0
+	if a != "" {
+		da = strings.IndexByte(Digits, a[0])
+	}
+	db := base
+	if b != "" {
+		db = strings.IndexByte(Digits, b[0])
+	}
+	if db-da > 1 {
+		return string(Digits[(da+db)/2])
+	}
+	if db == da {
+		// Only possible with an open lower end and b starting with '0';
+		// a bare "0" is not a valid key, so descend past it.
+		return b[:1] + midpoint("", b[1:])
+	}
+	// The first digits are adjacent. A longer b can be cut short, otherwise
+	// keep a's first digit and look for room after the rest of a.
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(Digits[da]) + midpoint(rest, "")
+}
+
+// Spread returns n ascending keys spaced evenly across the key space, all
+// as short as possible. It is used to rebalance keys that have grown long.
+func Spread(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	width, capacity := 1, base
+	for capacity <= n {
+		width++
+		capacity *= base
+	}
+	out := make([]string, n)
+	digits := make([]byte, width)
+	for i := 0; i < n; i++ {
+		v := (i + 1) * capacity / (n + 1)
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = Digits[v%base]
+			v /= base
+		}
+		out[i] = strings.TrimRight(string(digits), "0")
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package http
+
+import (
+	"errors"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type AttachmentHandler struct {
+	svc *service.AttachmentService
+}
+
+func NewAttachmentHandler(svc *service.AttachmentService) *AttachmentHandler {
+	return &AttachmentHandler{svc: svc}
+}
+
+// multipartOverhead allows for part headers and boundaries on top of the
+// file itself when capping the request body.
+const multipartOverhead = 64 << 10
+
+// Upload accepts a multipart/form-data body with the file in a part named
+// "file". The part is streamed to the service without being buffered in
+// memory.
+func (h *AttachmentHandler) Upload(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.svc.MaxSize()+multipartOverhead)
+	mr, err := r.MultipartReader()
+	if err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "expected multipart/form-data body", nil)
+		return
+	}
+
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "file", Message: "is required"}})
+			return
+		}
+		if err != nil {
+			if writeUploadTooLarge(w, err) {
+				return
+			}
+			WriteError(w, 400, "BAD_REQUEST", "malformed multipart body", nil)
+			return
+		}
+		if part.FormName() != "file" {
+			_ = part.Close()
+			continue
+		}
+
+		a, err := h.svc.Upload(r.Context(), uid, taskID, part.FileName(), part)
+		_ = part.Close()
+		if err != nil {
+			if err == service.ErrNotFound {
+				WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+				return
+			}
+			if err == service.ErrProjectArchived {
+				WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+				return
+			}
+			if writeUploadTooLarge(w, err) {
+				return
+			}
+			if err == service.ErrUnsupportedType {
+				WriteError(w, 415, "UNSUPPORTED_MEDIA_TYPE", "file type is not allowed", nil)
+				return
+			}
+			if writeValidationError(w, err) {
+				return
+			}
+			WriteError(w, 500, "INTERNAL", "failed to store attachment", nil)
+			return
+		}
+		WriteJSON(w, 201, map[string]any{"data": a})
+		return
+	}
+}
+
+func (h *AttachmentHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+	items, err := h.svc.List(r.Context(), uid, taskID)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list attachments", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": items})
+}
+
+// Download streams an attachment's contents. The stored, sniffed content
+// type is sent with nosniff, and the file is always served as a download
+// so uploaded HTML or SVG cannot run in the API's origin.
+func (h *AttachmentHandler) Download(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+	attachmentID := chi.URLParam(r, "attachmentId")
+	a, body, err := h.svc.Open(r.Context(), uid, taskID, attachmentID)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "attachment not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to read attachment", nil)
+		return
+	}
+	defer func() { _ = body.Close() }()
+
+	etag := `"` + a.SHA256 + `"`
+	w.Header().Set("ETag", etag)
+	if r.Header.Get("If-None-Match") == etag {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(a.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.Filename}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(200)
+	if _, err := io.Copy(w, body); err != nil {
+		log.Printf("attachment %s: download interrupted: %v", a.ID, err)
+	}
+}
+
+func (h *AttachmentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+	attachmentID := chi.URLParam(r, "attachmentId")
+	if err := h.svc.Delete(r.Context(), uid, taskID, attachmentID); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "attachment not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete attachment", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func writeUploadTooLarge(w http.ResponseWriter, err error) bool {
+	var mbe *http.MaxBytesError
+	if err != service.ErrTooLarge && !errors.As(err, &mbe) {
+		return false
+	}
+	WriteError(w, 413, "PAYLOAD_TOO_LARGE", "file exceeds the upload limit", nil)
+	return true
+}
@@ -0,0 +1,200 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *TaskHandler) Checklist(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	items, err := h.svc.Checklist(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list checklist", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": items})
+}
+
+type createChecklistItemReq struct {
+	Text    string `json:"text"`
+	Checked bool   `json:"checked"`
+}
+
+func (h *TaskHandler) AddChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req createChecklistItemReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	it, err := h.svc.AddChecklistItem(r.Context(), uid, chi.URLParam(r, "id"), req.Text, req.Checked)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to add checklist item", nil)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": it})
+}
+
+type updateChecklistItemReq struct {
+	Text    *string `json:"text"`
+	Checked *bool   `json:"checked"`
+}
+
+func (h *TaskHandler) UpdateChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req updateChecklistItemReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.Text == nil && req.Checked == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: text, checked"}})
+		return
+	}
+
+	it, err := h.svc.UpdateChecklistItem(r.Context(), uid, chi.URLParam(r, "id"),
+		domain.ChecklistItemPatch{Text: req.Text, Checked: req.Checked})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "checklist item not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to update checklist item", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": it})
+}
+
+func (h *TaskHandler) DeleteChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.DeleteChecklistItem(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "checklist item not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete checklist item", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *TaskHandler) MoveChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req moveTaskReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	it, err := h.svc.MoveChecklistItem(r.Context(), uid, chi.URLParam(r, "id"), req.AfterID, req.BeforeID)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "checklist item not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to move checklist item", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": it})
+}
+
+// ConvertChecklistItem turns a checklist item into a subtask and returns
+// the new task.
+func (h *TaskHandler) ConvertChecklistItem(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
+
+	task, err := h.svc.ConvertChecklistItem(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "checklist item not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to convert checklist item", nil)
+		return
+	}
+
+	if html && !renderTask(w, &task) {
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": task})
+}
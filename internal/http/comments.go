@@ -0,0 +1,226 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/markdown"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CommentHandler struct {
+	svc *service.CommentService
+}
+
+func NewCommentHandler(svc *service.CommentService) *CommentHandler {
+	return &CommentHandler{svc: svc}
+}
+
+type createCommentReq struct {
+	Body     string  `json:"body"`
+	ParentID *string `json:"parentId"`
+}
+
+func (h *CommentHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+
+	var req createCommentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	c, err := h.svc.Create(r.Context(), uid, taskID, req.ParentID, req.Body)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to create comment", nil)
+		return
+	}
+
+	if html && !renderComment(w, &c) {
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": c})
+}
+
+func (h *CommentHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "limit", Message: "must be an integer"}})
+			return
+		}
+		limit = n
+	}
+
+	var cursor *domain.Cursor
+	if tok := r.URL.Query().Get("cursor"); tok != "" {
+		c, err := domain.ParseCursorToken(tok)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "cursor", Message: "must be a nextCursorToken from a previous page"}})
+			return
+		}
+		cursor = &c
+	}
+
+	page, err := h.svc.List(r.Context(), uid, taskID, limit, cursor)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list comments", nil)
+		return
+	}
+
+	if html {
+		for i := range page.Items {
+			if !renderComment(w, &page.Items[i]) {
+				return
+			}
+		}
+	}
+
+	resp := map[string]any{"data": page.Items}
+	if page.NextCursor != nil {
+		resp["meta"] = map[string]any{
+			"nextCursor":      page.NextCursor,
+			"nextCursorToken": page.NextCursor.Token(),
+		}
+	}
+	WriteJSON(w, 200, resp)
+}
+
+type updateCommentReq struct {
+	Body *string `json:"body"`
+}
+
+func (h *CommentHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	var req updateCommentReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.Body == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "is required"}})
+		return
+	}
+
+	c, err := h.svc.Update(r.Context(), uid, id, strings.TrimSpace(*req.Body))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "comment not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to update comment", nil)
+		return
+	}
+
+	if html && !renderComment(w, &c) {
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": c})
+}
+
+func (h *CommentHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.svc.Delete(r.Context(), uid, id); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "comment not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete comment", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+// renderComment fills in BodyHTML for c and its replies, writing a 500 if
+// rendering fails.
+func renderComment(w http.ResponseWriter, c *domain.Comment) bool {
+	html, err := markdown.ToHTML(c.Body)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to render comment", nil)
+		return false
+	}
+	c.BodyHTML = html
+	for i := range c.Replies {
+		if !renderComment(w, &c.Replies[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,148 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type CustomFieldHandler struct {
+	svc *service.CustomFieldService
+}
+
+func NewCustomFieldHandler(svc *service.CustomFieldService) *CustomFieldHandler {
+	return &CustomFieldHandler{svc: svc}
+}
+
+type createCustomFieldReq struct {
+	Name    string                 `json:"name"`
+	Type    domain.CustomFieldType `json:"type"`
+	Options []string               `json:"options"`
+}
+
+func (h *CustomFieldHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req createCustomFieldReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	f, err := h.svc.Create(r.Context(), uid, chi.URLParam(r, "projectId"),
+		domain.CustomField{Name: req.Name, Type: req.Type, Options: req.Options})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrConflict {
+			WriteError(w, 409, "CONFLICT", "a custom field with this name already exists", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to create custom field", nil)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": f})
+}
+
+func (h *CustomFieldHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	fields, err := h.svc.List(r.Context(), uid, chi.URLParam(r, "projectId"))
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to list custom fields", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": fields})
+}
+
+type updateCustomFieldReq struct {
+	Name    *string  `json:"name"`
+	Options []string `json:"options"`
+}
+
+// Update renames a field or replaces a select field's options. The type
+// of a field cannot be changed.
+func (h *CustomFieldHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req updateCustomFieldReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.Name == nil && req.Options == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: name, options"}})
+		return
+	}
+
+	f, err := h.svc.Update(r.Context(), uid, chi.URLParam(r, "id"),
+		domain.CustomFieldPatch{Name: req.Name, Options: req.Options})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "custom field not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrConflict {
+			WriteError(w, 409, "CONFLICT", "a custom field with this name already exists", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to update custom field", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": f})
+}
+
+func (h *CustomFieldHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "custom field not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete custom field", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
@@ -0,0 +1,123 @@
+package http
+
+import (
+	"encoding/json"
+	"html/template"
+	"net/http"
+	"strings"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+)
+
+type DigestHandler struct {
+	svc *service.DigestService
+}
+
+func NewDigestHandler(svc *service.DigestService) *DigestHandler {
+	return &DigestHandler{svc: svc}
+}
+
+func (h *DigestHandler) Settings(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	s, err := h.svc.Settings(r.Context(), uid)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to load digest settings", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": s})
+}
+
+type updateDigestSettingsReq struct {
+	Frequency *domain.DigestFrequency `json:"frequency"`
+	Timezone  *string                 `json:"timezone"`
+	Hour      *int                    `json:"hour"`
+	Weekday   *int                    `json:"weekday"`
+}
+
+func (h *DigestHandler) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req updateDigestSettingsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.Frequency == nil && req.Timezone == nil && req.Hour == nil && req.Weekday == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: frequency, timezone, hour, weekday"}})
+		return
+	}
+
+	s, err := h.svc.UpdateSettings(r.Context(), uid, domain.DigestSettingsPatch{
+		Frequency: req.Frequency,
+		Timezone:  req.Timezone,
+		Hour:      req.Hour,
+		Weekday:   req.Weekday,
+	})
+	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to update digest settings", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": s})
+}
+
+// unsubscribePage asks whoever opened an unsubscribe link to confirm.
+// Opening the link must not unsubscribe anyone by itself, since mail
+// scanners and link previews fetch it too.
+var unsubscribePage = template.Must(template.New("unsubscribe").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Unsubscribe from TaskFlow digests</title></head>
+<body>
+{{if .Done}}<p>You will no longer receive TaskFlow email digests.</p>
+{{else}}<form method="post" action="?token={{.Token}}">
+<p>Stop receiving TaskFlow email digests?</p>
+<button type="submit">Unsubscribe</button>
+</form>
+{{end}}</body>
+</html>
+`))
+
+// ConfirmUnsubscribe serves the page an unsubscribe link opens. It changes
+// nothing; its form posts to Unsubscribe.
+func (h *DigestHandler) ConfirmUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		WriteError(w, 404, "NOT_FOUND", "unsubscribe link is invalid", nil)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_ = unsubscribePage.Execute(w, map[string]any{"Token": token})
+}
+
+// Unsubscribe turns off the digest of whoever holds the token from an
+// unsubscribe link. It needs no login and serves both the confirmation
+// page's form and one-click unsubscribe (RFC 8058) from mail clients.
+func (h *DigestHandler) Unsubscribe(w http.ResponseWriter, r *http.Request) {
+	if err := h.svc.Unsubscribe(r.Context(), r.URL.Query().Get("token")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "unsubscribe link is invalid", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to unsubscribe", nil)
+		return
+	}
+	if strings.Contains(r.Header.Get("Accept"), "text/html") {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_ = unsubscribePage.Execute(w, map[string]any{"Done": true})
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": map[string]any{"frequency": domain.DigestOff}})
+}
@@ -0,0 +1,210 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type LabelHandler struct {
+	svc *service.LabelService
+}
+
+func NewLabelHandler(svc *service.LabelService) *LabelHandler { return &LabelHandler{svc: svc} }
+
+type createLabelReq struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+func (h *LabelHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+
+	var req createLabelReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	l, err := h.svc.Create(r.Context(), uid, projectID, req.Name, strings.TrimSpace(req.Color))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrConflict {
+			WriteError(w, 409, "CONFLICT", "a label with this name already exists", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to create label", nil)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": l})
+}
+
+func (h *LabelHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+	labels, err := h.svc.List(r.Context(), uid, projectID)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to list labels", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": labels})
+}
+
+type updateLabelReq struct {
+	Name  *string `json:"name"`
+	Color *string `json:"color"`
+}
+
+func (h *LabelHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	var req updateLabelReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.Name == nil && req.Color == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: name, color"}})
+		return
+	}
+
+	l, err := h.svc.Update(r.Context(), uid, id, req.Name, req.Color)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "label not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrConflict {
+			WriteError(w, 409, "CONFLICT", "a label with this name already exists", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to update label", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": l})
+}
+
+func (h *LabelHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.svc.Delete(r.Context(), uid, id); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "label not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete label", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+type attachLabelReq struct {
+	LabelID string `json:"labelId"`
+}
+
+func (h *LabelHandler) Attach(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+
+	var req attachLabelReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if strings.TrimSpace(req.LabelID) == "" {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "labelId", Message: "is required"}})
+		return
+	}
+
+	if err := h.svc.Attach(r.Context(), uid, taskID, strings.TrimSpace(req.LabelID)); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task or label not found in the same project", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to attach label", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *LabelHandler) Detach(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+	labelID := chi.URLParam(r, "labelId")
+	if err := h.svc.Detach(r.Context(), uid, taskID, labelID); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "label not attached to task", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to detach label", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
@@ -0,0 +1,171 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type NotificationHandler struct {
+	svc *service.NotificationService
+}
+
+func NewNotificationHandler(svc *service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{svc: svc}
+}
+
+// List returns the caller's notifications, newest first, with the number
+// still unread.
+func (h *NotificationHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	q := r.URL.Query()
+	unread := false
+	if v := q.Get("unread"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "unread", Message: "must be true or false"}})
+			return
+		}
+		unread = b
+	}
+
+	limit := 20
+	if v := q.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "limit", Message: "must be an integer"}})
+			return
+		}
+		limit = n
+	}
+
+	var cursor *domain.Cursor
+	if tok := q.Get("cursor"); tok != "" {
+		c, err := domain.ParseCursorToken(tok)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "cursor", Message: "must be a nextCursorToken from a previous page"}})
+			return
+		}
+		cursor = &c
+	}
+
+	page, err := h.svc.List(r.Context(), uid, unread, limit, cursor)
+	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list notifications", nil)
+		return
+	}
+	count, err := h.svc.UnreadCount(r.Context(), uid)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to count notifications", nil)
+		return
+	}
+
+	meta := map[string]any{"unreadCount": count}
+	if page.NextCursor != nil {
+		meta["nextCursor"] = page.NextCursor
+		meta["nextCursorToken"] = page.NextCursor.Token()
+	}
+	WriteJSON(w, 200, map[string]any{"data": page.Items, "meta": meta})
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.MarkRead(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "notification not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to mark notification read", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	n, err := h.svc.MarkAllRead(r.Context(), uid)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to mark notifications read", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": map[string]any{"marked": n}})
+}
+
+func (h *NotificationHandler) Prefs(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	p, err := h.svc.Prefs(r.Context(), uid)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to load notification preferences", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": p})
+}
+
+type updateNotificationPrefsReq struct {
+	Assigned  *bool `json:"assigned"`
+	Commented *bool `json:"commented"`
+	Completed *bool `json:"completed"`
+	DueSoon   *bool `json:"dueSoon"`
+}
+
+func (h *NotificationHandler) UpdatePrefs(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req updateNotificationPrefsReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.Assigned == nil && req.Commented == nil && req.Completed == nil && req.DueSoon == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: assigned, commented, completed, dueSoon"}})
+		return
+	}
+
+	p, err := h.svc.UpdatePrefs(r.Context(), uid, domain.NotificationPrefsPatch{
+		Assigned:  req.Assigned,
+		Commented: req.Commented,
+		Completed: req.Completed,
+		DueSoon:   req.DueSoon,
+	})
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to update notification preferences", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": p})
+}
@@ -0,0 +1,78 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type duplicateProjectReq struct {
+	Name            string `json:"name"`
+	ResetCompletion bool   `json:"resetCompletion"`
+	ShiftDays       int    `json:"shiftDays"`
+}
+
+// Duplicate copies a project. Small projects are copied during the request
+// (201 with the copy); large ones are queued (202 with the background
+// copy, whose status is at the Location header).
+func (h *ProjectHandler) Duplicate(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req duplicateProjectReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	res, err := h.svc.Duplicate(r.Context(), uid, chi.URLParam(r, "id"), domain.DuplicateOptions{
+		Name:            req.Name,
+		ResetCompletion: req.ResetCompletion,
+		ShiftDays:       req.ShiftDays,
+	})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to duplicate project", nil)
+		return
+	}
+	if res.Duplication != nil {
+		w.Header().Set("Location", "/v1/duplications/"+res.Duplication.ID)
+		WriteJSON(w, 202, map[string]any{"data": res.Duplication})
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": res.Project})
+}
+
+// Duplication reports the status of a background project copy.
+func (h *ProjectHandler) Duplication(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	d, err := h.svc.Duplication(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "duplication not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to get duplication", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": d})
+}
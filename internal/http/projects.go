@@ -24,6 +24,7 @@ func NewProjectHandler(svc *service.ProjectService) *ProjectHandler {
 
 type createProjectReq struct {
 	Name string `json:"name"`
+	Key  string `json:"key"`
 }
 
 func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -45,8 +46,15 @@ func (h *ProjectHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	p, err := h.svc.Create(r.Context(), uid, req.Name)
+	p, err := h.svc.Create(r.Context(), uid, req.Name, req.Key)
 	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		if err == service.ErrConflict {
+			WriteError(w, 409, "CONFLICT", "project key is already in use", nil)
+			return
+		}
 		WriteError(w, 500, "INTERNAL", "failed to create project", nil)
 		return
 	}
@@ -90,7 +98,18 @@ func (h *ProjectHandler) List(w http.ResponseWriter, r *http.Request) {
 		cursor = &domain.Cursor{CreatedAt: tm, ID: cID}
 	}
 
-	page, err := h.svc.List(r.Context(), uid, limit, cursor)
+	archived := false
+	if v := r.URL.Query().Get("archived"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "archived", Message: "must be true or false"}})
+			return
+		}
+		archived = b
+	}
+
+	page, err := h.svc.List(r.Context(), uid, archived, limit, cursor)
 	if err != nil {
 		WriteError(w, 500, "INTERNAL", "failed to list projects", nil)
 		return
@@ -124,7 +143,9 @@ func (h *ProjectHandler) Get(w http.ResponseWriter, r *http.Request) {
 }
 
 type updateProjectReq struct {
-	Name *string `json:"name"`
+	Name         *string              `json:"name"`
+	Key          *string              `json:"key"`
+	EstimateUnit *domain.EstimateUnit `json:"estimateUnit"`
 }
 
 func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -140,24 +161,29 @@ func (h *ProjectHandler) Update(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
 		return
 	}
-	if req.Name == nil {
+	if req.Name == nil && req.Key == nil && req.EstimateUnit == nil {
 		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
-			[]ErrorDetail{{Field: "name", Message: "is required"}})
-		return
-	}
-	name := strings.TrimSpace(*req.Name)
-	if name == "" {
-		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
-			[]ErrorDetail{{Field: "name", Message: "cannot be empty"}})
+			[]ErrorDetail{{Field: "body", Message: "at least one of name, key, estimateUnit is required"}})
 		return
 	}
 
-	p, err := h.svc.UpdateName(r.Context(), uid, id, name)
+	p, err := h.svc.Update(r.Context(), uid, id, domain.ProjectPatch{Name: req.Name, Key: req.Key, EstimateUnit: req.EstimateUnit})
 	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
 		if err == service.ErrNotFound {
 			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
 			return
 		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrConflict {
+			WriteError(w, 409, "CONFLICT", "project key is already in use", nil)
+			return
+		}
 		WriteError(w, 500, "INTERNAL", "failed to update project", nil)
 		return
 	}
@@ -182,3 +208,37 @@ func (h *ProjectHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 	w.WriteHeader(204)
 }
+
+// Archive hides a project from the default list and makes its tasks
+// read-only.
+func (h *ProjectHandler) Archive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, true)
+}
+
+func (h *ProjectHandler) Unarchive(w http.ResponseWriter, r *http.Request) {
+	h.setArchived(w, r, false)
+}
+
+func (h *ProjectHandler) setArchived(w http.ResponseWriter, r *http.Request, archived bool) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	id := chi.URLParam(r, "id")
+
+	set := h.svc.Unarchive
+	if archived {
+		set = h.svc.Archive
+	}
+	p, err := set(r.Context(), uid, id)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to archive project", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": p})
+}
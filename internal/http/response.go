@@ -2,7 +2,10 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
+
+	"TaskFlow/internal/service"
 )
 
 func WriteJSON(w http.ResponseWriter, status int, v any) {
@@ -32,6 +35,18 @@ func WriteError(w http.ResponseWriter, status int, code, msg string, details []E
 	WriteJSON(w, status, body)
 }
 
+// writeValidationError writes a 422 response if err is a service validation
+// error and reports whether it did so.
+func writeValidationError(w http.ResponseWriter, err error) bool {
+	var ve *service.ValidationError
+	if !errors.As(err, &ve) {
+		return false
+	}
+	WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+		[]ErrorDetail{{Field: ve.Field, Message: err.Error()}})
+	return true
+}
+
 func RecovererJSON(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
@@ -17,6 +17,17 @@ type Deps struct {
 	AuthSvc    *service.AuthService
 	ProjectSvc *service.ProjectService
 	TaskSvc    *service.TaskService
+	LabelSvc   *service.LabelService
+	CommentSvc *service.CommentService
+	AttachSvc  *service.AttachmentService
+	TimeSvc    *service.TimeService
+	FieldSvc   *service.CustomFieldService
+	TemplSvc   *service.TemplateService
+	TrashSvc   *service.TrashService
+	SprintSvc  *service.SprintService
+	WatchSvc   *service.WatcherService
+	NotifySvc  *service.NotificationService
+	DigestSvc  *service.DigestService
 }
 
 func NewRouter(d Deps) http.Handler {
@@ -34,7 +45,7 @@ func NewRouter(d Deps) http.Handler {
 		},
 		AllowedMethods:   []string{"GET", "POST", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", "Location"},
 		AllowCredentials: false,
 		MaxAge:           300,
 	}))
@@ -50,6 +61,17 @@ func NewRouter(d Deps) http.Handler {
 	authH := NewAuthHandler(d.AuthSvc)
 	projH := NewProjectHandler(d.ProjectSvc)
 	taskH := NewTaskHandler(d.TaskSvc)
+	labelH := NewLabelHandler(d.LabelSvc)
+	commentH := NewCommentHandler(d.CommentSvc)
+	attachH := NewAttachmentHandler(d.AttachSvc)
+	timeH := NewTimeHandler(d.TimeSvc)
+	fieldH := NewCustomFieldHandler(d.FieldSvc)
+	templH := NewTemplateHandler(d.TemplSvc)
+	trashH := NewTrashHandler(d.TrashSvc)
+	sprintH := NewSprintHandler(d.SprintSvc)
+	watchH := NewWatcherHandler(d.WatchSvc)
+	notifyH := NewNotificationHandler(d.NotifySvc)
+	digestH := NewDigestHandler(d.DigestSvc)
 
 	r.Route("/v1", func(r chi.Router) {
 		// Public auth routes
@@ -58,6 +80,11 @@ func NewRouter(d Deps) http.Handler {
 			r.Post("/login", authH.Login)
 		})
 
+		// Public unsubscribe link from digest emails; only POST changes
+		// anything, so prefetching the link is harmless
+		r.Get("/digest/unsubscribe", digestH.ConfirmUnsubscribe)
+		r.Post("/digest/unsubscribe", digestH.Unsubscribe)
+
 		// Protected routes
 		r.Group(func(r chi.Router) {
 			r.Use(AuthJWT(d.Config.JWTSecret))
@@ -72,14 +99,114 @@ func NewRouter(d Deps) http.Handler {
 				r.Get("/{id}", projH.Get)
 				r.Patch("/{id}", projH.Update)
 				r.Delete("/{id}", projH.Delete)
+				r.Post("/{id}/duplicate", projH.Duplicate)
+				r.Post("/{id}/restore", trashH.RestoreProject)
+				r.Post("/{id}/archive", projH.Archive)
+				r.Post("/{id}/unarchive", projH.Unarchive)
+				r.Post("/{id}/watch", watchH.WatchProject)
+				r.Delete("/{id}/watch", watchH.UnwatchProject)
+				r.Get("/{id}/watchers", watchH.ProjectWatchers)
 
 				// tasks under a project
 				r.Post("/{projectId}/tasks", taskH.Create)
+				r.Get("/{projectId}/board", taskH.Board)
+				r.Get("/{projectId}/burndown", taskH.Burndown)
+
+				// labels under a project
+				r.Get("/{projectId}/labels", labelH.List)
+				r.Post("/{projectId}/labels", labelH.Create)
+
+				// custom fields under a project
+				r.Get("/{projectId}/custom-fields", fieldH.List)
+				r.Post("/{projectId}/custom-fields", fieldH.Create)
+
+				// sprints under a project
+				r.Get("/{projectId}/sprints", sprintH.List)
+				r.Post("/{projectId}/sprints", sprintH.Create)
+
+				// save a project as a template
+				r.Post("/{projectId}/template", templH.Capture)
 			})
 
+			// background project copies
+			r.Get("/duplications/{id}", projH.Duplication)
+
+			// notifications
+			r.Get("/notifications", notifyH.List)
+			r.Post("/notifications/read-all", notifyH.MarkAllRead)
+			r.Post("/notifications/{id}/read", notifyH.MarkRead)
+			r.Get("/notification-preferences", notifyH.Prefs)
+			r.Patch("/notification-preferences", notifyH.UpdatePrefs)
+			r.Get("/digest-settings", digestH.Settings)
+			r.Patch("/digest-settings", digestH.UpdateSettings)
+
+			// trash
+			r.Get("/trash", trashH.List)
+
+			// templates
+			r.Get("/templates", templH.List)
+			r.Post("/templates", templH.Create)
+			r.Get("/templates/{id}", templH.Get)
+			r.Delete("/templates/{id}", templH.Delete)
+			r.Post("/templates/{id}/instantiate", templH.Instantiate)
+
+			// labels
+			r.Patch("/labels/{id}", labelH.Update)
+			r.Delete("/labels/{id}", labelH.Delete)
+
+			// custom fields
+			r.Patch("/custom-fields/{id}", fieldH.Update)
+			r.Delete("/custom-fields/{id}", fieldH.Delete)
+
+			// sprints
+			r.Get("/sprints/{id}", sprintH.Get)
+			r.Patch("/sprints/{id}", sprintH.Update)
+			r.Delete("/sprints/{id}", sprintH.Delete)
+			r.Post("/sprints/{id}/complete", sprintH.Complete)
+			r.Get("/sprints/{id}/stats", sprintH.Stats)
+
+			// comments
+			r.Patch("/comments/{id}", commentH.Update)
+			r.Delete("/comments/{id}", commentH.Delete)
+
+			// checklists
+			r.Patch("/checklist-items/{id}", taskH.UpdateChecklistItem)
+			r.Delete("/checklist-items/{id}", taskH.DeleteChecklistItem)
+			r.Post("/checklist-items/{id}/move", taskH.MoveChecklistItem)
+			r.Post("/checklist-items/{id}/convert", taskH.ConvertChecklistItem)
+
+			// time tracking
+			r.Get("/timer", timeH.Running)
+			r.Post("/timer/stop", timeH.Stop)
+			r.Patch("/time-entries/{id}", timeH.Update)
+			r.Delete("/time-entries/{id}", timeH.Delete)
+			r.Get("/time-totals", timeH.Totals)
+
 			// tasks
 			r.Get("/tasks", taskH.List)
 			r.Get("/tasks/{id}", taskH.Get)
+			r.Get("/tasks/{id}/subtree", taskH.Subtree)
+			r.Post("/tasks/{id}/move", taskH.Move)
+			r.Post("/tasks/{id}/restore", trashH.RestoreTask)
+			r.Get("/tasks/{id}/dependencies", taskH.Dependencies)
+			r.Post("/tasks/{id}/blockers", taskH.AddBlocker)
+			r.Delete("/tasks/{id}/blockers/{blockerId}", taskH.RemoveBlocker)
+			r.Post("/tasks/{id}/watch", watchH.WatchTask)
+			r.Delete("/tasks/{id}/watch", watchH.UnwatchTask)
+			r.Get("/tasks/{id}/watchers", watchH.TaskWatchers)
+			r.Get("/tasks/{id}/checklist", taskH.Checklist)
+			r.Post("/tasks/{id}/checklist", taskH.AddChecklistItem)
+			r.Post("/tasks/{id}/labels", labelH.Attach)
+			r.Delete("/tasks/{id}/labels/{labelId}", labelH.Detach)
+			r.Get("/tasks/{id}/comments", commentH.List)
+			r.Post("/tasks/{id}/comments", commentH.Create)
+			r.Get("/tasks/{id}/attachments", attachH.List)
+			r.Post("/tasks/{id}/attachments", attachH.Upload)
+			r.Get("/tasks/{id}/attachments/{attachmentId}", attachH.Download)
+			r.Delete("/tasks/{id}/attachments/{attachmentId}", attachH.Delete)
+			r.Post("/tasks/{id}/timer", timeH.Start)
+			r.Get("/tasks/{id}/time-entries", timeH.List)
+			r.Post("/tasks/{id}/time-entries", timeH.Create)
 			r.Patch("/tasks/{id}", taskH.Update)
 			r.Delete("/tasks/{id}", taskH.Delete)
 		})
@@ -0,0 +1,229 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type SprintHandler struct {
+	svc *service.SprintService
+}
+
+func NewSprintHandler(svc *service.SprintService) *SprintHandler { return &SprintHandler{svc: svc} }
+
+type createSprintReq struct {
+	Name      string `json:"name"`
+	Goal      string `json:"goal"`
+	StartDate string `json:"startDate"`
+	EndDate   string `json:"endDate"`
+}
+
+func (h *SprintHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req createSprintReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	sp, err := h.svc.Create(r.Context(), uid, chi.URLParam(r, "projectId"), domain.Sprint{
+		Name:      req.Name,
+		Goal:      req.Goal,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to create sprint", nil)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": sp})
+}
+
+func (h *SprintHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	sprints, err := h.svc.List(r.Context(), uid, chi.URLParam(r, "projectId"))
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to list sprints", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": sprints})
+}
+
+func (h *SprintHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	sp, err := h.svc.Get(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "sprint not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to get sprint", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": sp})
+}
+
+type updateSprintReq struct {
+	Name      *string `json:"name"`
+	Goal      *string `json:"goal"`
+	StartDate *string `json:"startDate"`
+	EndDate   *string `json:"endDate"`
+}
+
+func (h *SprintHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req updateSprintReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.Name == nil && req.Goal == nil && req.StartDate == nil && req.EndDate == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: name, goal, startDate, endDate"}})
+		return
+	}
+
+	sp, err := h.svc.Update(r.Context(), uid, chi.URLParam(r, "id"), domain.SprintPatch{
+		Name:      req.Name,
+		Goal:      req.Goal,
+		StartDate: req.StartDate,
+		EndDate:   req.EndDate,
+	})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "sprint not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to update sprint", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": sp})
+}
+
+// Delete removes a sprint; its tasks go back to the backlog.
+func (h *SprintHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "sprint not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete sprint", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+type completeSprintReq struct {
+	NextSprintID *string `json:"nextSprintId"`
+}
+
+// Complete closes a sprint and carries its unfinished tasks to the sprint
+// named in the optional body, or else to the project's next open sprint.
+func (h *SprintHandler) Complete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req completeSprintReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	res, err := h.svc.Complete(r.Context(), uid, chi.URLParam(r, "id"), req.NextSprintID)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "sprint not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrSprintCompleted {
+			WriteError(w, 409, "SPRINT_COMPLETED", "sprint is already completed", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to complete sprint", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": res})
+}
+
+func (h *SprintHandler) Stats(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	st, err := h.svc.Stats(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "sprint not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to load sprint stats", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": st})
+}
@@ -1,13 +1,17 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"TaskFlow/internal/domain"
+	"TaskFlow/internal/markdown"
 	"TaskFlow/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -20,7 +24,31 @@ type TaskHandler struct {
 func NewTaskHandler(svc *service.TaskService) *TaskHandler { return &TaskHandler{svc: svc} }
 
 type createTaskReq struct {
-	Title string `json:"title"`
+	Title        string         `json:"title"`
+	Description  string         `json:"description"`
+	Status       *string        `json:"status"`
+	Priority     *string        `json:"priority"`
+	DueDate      *time.Time     `json:"dueDate"`
+	Estimate     *float64       `json:"estimate"`
+	ParentTaskID *string        `json:"parentTaskId"`
+	SprintID     *string        `json:"sprintId"`
+	Recurrence   *recurrenceReq `json:"recurrence"`
+	// CustomFields maps custom field IDs to values.
+	CustomFields map[string]json.RawMessage `json:"customFields"`
+}
+
+type recurrenceReq struct {
+	Rule           string `json:"rule"`
+	Timezone       string `json:"timezone"`
+	RegenerateFrom string `json:"regenerateFrom"`
+}
+
+func (r recurrenceReq) toDomain() *domain.Recurrence {
+	return &domain.Recurrence{
+		Rule:           r.Rule,
+		Timezone:       r.Timezone,
+		RegenerateFrom: domain.RegenerateFrom(r.RegenerateFrom),
+	}
 }
 
 func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
@@ -29,6 +57,10 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
 		return
 	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
 
 	projectID := chi.URLParam(r, "projectId")
 
@@ -38,21 +70,51 @@ func (h *TaskHandler) Create(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	task, err := h.svc.Create(r.Context(), uid, projectID, req.Title)
+	in := domain.TaskInput{
+		Title:        req.Title,
+		Description:  req.Description,
+		DueDate:      req.DueDate,
+		Estimate:     req.Estimate,
+		ParentTaskID: req.ParentTaskID,
+		SprintID:     req.SprintID,
+		CustomFields: req.CustomFields,
+	}
+	if req.Recurrence != nil {
+		in.Recurrence = req.Recurrence.toDomain()
+	}
+	if req.Status != nil {
+		in.Status = domain.TaskStatus(*req.Status)
+	}
+	if req.Priority != nil {
+		p, err := domain.ParsePriority(*req.Priority)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "priority", Message: err.Error()}})
+			return
+		}
+		in.Priority = p
+	}
+
+	task, err := h.svc.Create(r.Context(), uid, projectID, in)
 	if err != nil {
 		if err == service.ErrNotFound {
 			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
 			return
 		}
-		if strings.Contains(err.Error(), "title") {
-			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
-				[]ErrorDetail{{Field: "title", Message: err.Error()}})
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
 			return
 		}
 		WriteError(w, 500, "INTERNAL", "failed to create task", nil)
 		return
 	}
 
+	if html && !renderTask(w, &task) {
+		return
+	}
 	WriteJSON(w, 201, map[string]any{"data": task})
 }
 
@@ -62,6 +124,10 @@ func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
 		return
 	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
 
 	projectID := strings.TrimSpace(r.URL.Query().Get("projectId"))
 	if projectID == "" {
@@ -92,10 +158,25 @@ func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
 		limit = n
 	}
 
+	sort, err := domain.ParseTaskSort(r.URL.Query().Get("sort"))
+	if err != nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "sort", Message: err.Error()}})
+		return
+	}
+
 	var cursor *domain.Cursor
 	cAt := r.URL.Query().Get("cursorCreatedAt")
 	cID := r.URL.Query().Get("cursorId")
-	if cAt != "" || cID != "" {
+	if tok := r.URL.Query().Get("cursor"); tok != "" {
+		c, err := domain.ParseCursorToken(tok)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "cursor", Message: "must be a nextCursorToken from a previous page"}})
+			return
+		}
+		cursor = &c
+	} else if cAt != "" || cID != "" {
 		if cAt == "" || cID == "" {
 			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
 				[]ErrorDetail{{Field: "cursor", Message: "cursorCreatedAt and cursorId must both be provided"}})
@@ -110,15 +191,69 @@ func (h *TaskHandler) List(w http.ResponseWriter, r *http.Request) {
 		cursor = &domain.Cursor{CreatedAt: tm, ID: cID}
 	}
 
-	page, err := h.svc.List(r.Context(), uid, projectID, completed, limit, cursor)
+	filter := domain.TaskFilter{
+		ProjectID:  projectID,
+		Completed:  completed,
+		LabelMatch: domain.LabelMatch(r.URL.Query().Get("labelMatch")),
+	}
+	if v := r.URL.Query().Get("status"); v != "" {
+		st := domain.TaskStatus(v)
+		filter.Status = &st
+	}
+	// completedAfter is inclusive and completedBefore exclusive, so
+	// consecutive ranges never count a task twice.
+	for _, p := range []struct {
+		name string
+		dst  **time.Time
+	}{{"completedAfter", &filter.CompletedAfter}, {"completedBefore", &filter.CompletedBefore}} {
+		v := r.URL.Query().Get(p.name)
+		if v == "" {
+			continue
+		}
+		tm, err := parseDateOrTime(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: p.name, Message: "must be a date (YYYY-MM-DD) or RFC3339 timestamp"}})
+			return
+		}
+		*p.dst = &tm
+	}
+	// sprintId=none lists the backlog: tasks in no sprint.
+	if v := r.URL.Query().Get("sprintId"); v == "none" {
+		filter.Backlog = true
+	} else {
+		filter.SprintID = v
+	}
+	for _, name := range strings.Split(r.URL.Query().Get("labels"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			filter.Labels = append(filter.Labels, name)
+		}
+	}
+	filter.CustomFields = parseCustomFieldFilters(r.URL.Query())
+
+	page, err := h.svc.List(r.Context(), uid, filter, sort, limit, cursor)
 	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
 		WriteError(w, 500, "INTERNAL", "failed to list tasks", nil)
 		return
 	}
 
+	if html {
+		for i := range page.Items {
+			if !renderTask(w, &page.Items[i]) {
+				return
+			}
+		}
+	}
+
 	resp := map[string]any{"data": page.Items}
 	if page.NextCursor != nil {
-		resp["meta"] = map[string]any{"nextCursor": page.NextCursor}
+		resp["meta"] = map[string]any{
+			"nextCursor":      page.NextCursor,
+			"nextCursorToken": page.NextCursor.Token(),
+		}
 	}
 	WriteJSON(w, 200, resp)
 }
@@ -129,6 +264,10 @@ func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
 		return
 	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
 
 	id := chi.URLParam(r, "id")
 	task, err := h.svc.Get(r.Context(), uid, id)
@@ -140,12 +279,54 @@ func (h *TaskHandler) Get(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 500, "INTERNAL", "failed to get task", nil)
 		return
 	}
+	if html && !renderTask(w, &task) {
+		return
+	}
 	WriteJSON(w, 200, map[string]any{"data": task})
 }
 
+func (h *TaskHandler) Subtree(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	tree, err := h.svc.Subtree(r.Context(), uid, id)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to get subtree", nil)
+		return
+	}
+	if html && !renderTree(w, &tree) {
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": tree})
+}
+
 type updateTaskReq struct {
-	Title     *string `json:"title"`
-	Completed *bool   `json:"completed"`
+	Title            *string         `json:"title"`
+	Description      *string         `json:"description"`
+	Completed        *bool           `json:"completed"`
+	Status           *string         `json:"status"`
+	CompleteSubtasks bool            `json:"completeSubtasks"`
+	Priority         *string         `json:"priority"`
+	DueDate          json.RawMessage `json:"dueDate"`
+	Estimate         json.RawMessage `json:"estimate"`
+	ParentTaskID     json.RawMessage `json:"parentTaskId"`
+	ProjectID        *string         `json:"projectId"`
+	SprintID         json.RawMessage `json:"sprintId"`
+	Recurrence       json.RawMessage `json:"recurrence"`
+	// CustomFields sets the listed values; null removes one.
+	CustomFields map[string]json.RawMessage `json:"customFields"`
 }
 
 func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
@@ -154,6 +335,10 @@ func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
 		return
 	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
 
 	id := chi.URLParam(r, "id")
 
@@ -162,27 +347,130 @@ func (h *TaskHandler) Update(w http.ResponseWriter, r *http.Request) {
 		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
 		return
 	}
-	if req.Title == nil && req.Completed == nil {
+	if req.Title == nil && req.Description == nil && req.Completed == nil && req.Status == nil && req.Priority == nil &&
+		req.DueDate == nil && req.Estimate == nil && req.ParentTaskID == nil && req.ProjectID == nil && req.SprintID == nil &&
+		req.Recurrence == nil && req.CustomFields == nil {
 		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
-			[]ErrorDetail{{Field: "body", Message: "must include at least one of: title, completed"}})
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: title, description, completed, status, priority, dueDate, estimate, parentTaskId, projectId, sprintId, recurrence, customFields"}})
 		return
 	}
 
-	task, err := h.svc.Update(r.Context(), uid, id, req.Title, req.Completed)
+	patch := domain.TaskPatch{
+		Title:            req.Title,
+		Description:      req.Description,
+		Completed:        req.Completed,
+		CompleteSubtasks: req.CompleteSubtasks,
+		ProjectID:        req.ProjectID,
+		CustomFields:     req.CustomFields,
+	}
+	if req.Status != nil {
+		st := domain.TaskStatus(*req.Status)
+		patch.Status = &st
+	}
+	if req.Priority != nil {
+		p, err := domain.ParsePriority(*req.Priority)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "priority", Message: err.Error()}})
+			return
+		}
+		patch.Priority = &p
+	}
+	// dueDate: null clears the due date; omitting it leaves it unchanged.
+	if req.DueDate != nil {
+		if bytes.Equal(req.DueDate, []byte("null")) {
+			patch.ClearDueDate = true
+		} else {
+			var due time.Time
+			if err := json.Unmarshal(req.DueDate, &due); err != nil {
+				WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+					[]ErrorDetail{{Field: "dueDate", Message: "must be RFC3339 timestamp or null"}})
+				return
+			}
+			patch.DueDate = &due
+		}
+	}
+	// estimate: null clears the estimate.
+	if req.Estimate != nil {
+		if bytes.Equal(req.Estimate, []byte("null")) {
+			patch.ClearEstimate = true
+		} else {
+			var est float64
+			if err := json.Unmarshal(req.Estimate, &est); err != nil {
+				WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+					[]ErrorDetail{{Field: "estimate", Message: "must be a number or null"}})
+				return
+			}
+			patch.Estimate = &est
+		}
+	}
+	// parentTaskId: null makes the task top-level again.
+	if req.ParentTaskID != nil {
+		if bytes.Equal(req.ParentTaskID, []byte("null")) {
+			patch.ClearParent = true
+		} else {
+			var parent string
+			if err := json.Unmarshal(req.ParentTaskID, &parent); err != nil || parent == "" {
+				WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+					[]ErrorDetail{{Field: "parentTaskId", Message: "must be a task id or null"}})
+				return
+			}
+			patch.ParentTaskID = &parent
+		}
+	}
+	// sprintId: null moves the task back to the backlog.
+	if req.SprintID != nil {
+		if bytes.Equal(req.SprintID, []byte("null")) {
+			patch.ClearSprint = true
+		} else {
+			var sprint string
+			if err := json.Unmarshal(req.SprintID, &sprint); err != nil || sprint == "" {
+				WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+					[]ErrorDetail{{Field: "sprintId", Message: "must be a sprint id or null"}})
+				return
+			}
+			patch.SprintID = &sprint
+		}
+	}
+	// recurrence: null stops the task from repeating.
+	if req.Recurrence != nil {
+		if bytes.Equal(req.Recurrence, []byte("null")) {
+			patch.ClearRecurrence = true
+		} else {
+			var rec recurrenceReq
+			if err := json.Unmarshal(req.Recurrence, &rec); err != nil {
+				WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+					[]ErrorDetail{{Field: "recurrence", Message: "must be an object or null"}})
+				return
+			}
+			patch.Recurrence = rec.toDomain()
+		}
+	}
+
+	task, err := h.svc.Update(r.Context(), uid, id, patch)
 	if err != nil {
 		if err == service.ErrNotFound {
 			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
 			return
 		}
-		if strings.Contains(err.Error(), "title") {
-			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
-				[]ErrorDetail{{Field: "title", Message: err.Error()}})
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrBlocked {
+			WriteError(w, 409, "BLOCKED", "task is blocked by open dependencies", nil)
+			return
+		}
+		if writeValidationError(w, err) {
 			return
 		}
 		WriteError(w, 500, "INTERNAL", "failed to update task", nil)
 		return
 	}
 
+	if html && !renderTask(w, &task) {
+		return
+	}
 	WriteJSON(w, 200, map[string]any{"data": task})
 }
 
@@ -194,13 +482,346 @@ func (h *TaskHandler) Delete(w http.ResponseWriter, r *http.Request) {
 	}
 
 	id := chi.URLParam(r, "id")
-	if err := h.svc.Delete(r.Context(), uid, id); err != nil {
+	children := domain.ChildPolicy(r.URL.Query().Get("children"))
+	if err := h.svc.Delete(r.Context(), uid, id, children); err != nil {
 		if err == service.ErrNotFound {
 			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
 			return
 		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
 		WriteError(w, 500, "INTERNAL", "failed to delete task", nil)
 		return
 	}
 	w.WriteHeader(204)
 }
+
+func (h *TaskHandler) Dependencies(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	deps, err := h.svc.Dependencies(r.Context(), uid, id)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list dependencies", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": deps})
+}
+
+type addBlockerReq struct {
+	BlockerID string `json:"blockerId"`
+}
+
+func (h *TaskHandler) AddBlocker(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	var req addBlockerReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	if err := h.svc.AddBlocker(r.Context(), uid, id, strings.TrimSpace(req.BlockerID)); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to add blocker", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *TaskHandler) RemoveBlocker(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	blockerID := chi.URLParam(r, "blockerId")
+	if err := h.svc.RemoveBlocker(r.Context(), uid, id, blockerID); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "dependency not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to remove blocker", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+type boardColumnResp struct {
+	Status          domain.TaskStatus `json:"status"`
+	Count           int               `json:"count"`
+	Tasks           []domain.Task     `json:"tasks"`
+	NextCursor      *domain.Cursor    `json:"nextCursor"`
+	NextCursorToken *string           `json:"nextCursorToken"`
+}
+
+func (h *TaskHandler) Board(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+
+	var q domain.BoardQuery
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "limit", Message: "must be an integer"}})
+			return
+		}
+		q.Limit = n
+	}
+	for _, st := range strings.Split(r.URL.Query().Get("columns"), ",") {
+		if st = strings.TrimSpace(st); st != "" {
+			q.Statuses = append(q.Statuses, domain.TaskStatus(st))
+		}
+	}
+	// Each column's cursor token names its column, so ?cursor= may repeat.
+	for _, tok := range r.URL.Query()["cursor"] {
+		c, err := domain.ParseCursorToken(tok)
+		if err != nil || len(c.Values) == 0 {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "cursor", Message: "must be a column nextCursorToken from a previous board"}})
+			return
+		}
+		if q.Cursors == nil {
+			q.Cursors = map[domain.TaskStatus]domain.Cursor{}
+		}
+		q.Cursors[domain.TaskStatus(c.Values[0])] = c
+	}
+
+	cols, err := h.svc.Board(r.Context(), uid, projectID, q)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to load board", nil)
+		return
+	}
+
+	out := make([]boardColumnResp, len(cols))
+	for i, c := range cols {
+		if html {
+			for j := range c.Tasks {
+				if !renderTask(w, &c.Tasks[j]) {
+					return
+				}
+			}
+		}
+		out[i] = boardColumnResp{Status: c.Status, Count: c.Count, Tasks: c.Tasks, NextCursor: c.NextCursor}
+		if c.NextCursor != nil {
+			tok := c.NextCursor.Token()
+			out[i].NextCursorToken = &tok
+		}
+	}
+	WriteJSON(w, 200, map[string]any{"data": map[string]any{"columns": out}})
+}
+
+// Burndown returns daily open/completed counts and estimate sums for a
+// project. from and to are YYYY-MM-DD dates, read in tz (default UTC).
+func (h *TaskHandler) Burndown(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	projectID := chi.URLParam(r, "projectId")
+
+	q := domain.BurndownQuery{Location: time.UTC}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		v := r.URL.Query().Get(p.name)
+		if v == "" {
+			continue
+		}
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: p.name, Message: "must be a YYYY-MM-DD date"}})
+			return
+		}
+		*p.dst = d
+	}
+	if tz := r.URL.Query().Get("tz"); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil || tz == "Local" {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "tz", Message: "must be an IANA time zone"}})
+			return
+		}
+		q.Location = loc
+	}
+
+	b, err := h.svc.Burndown(r.Context(), uid, projectID, q)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to load burndown", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": b})
+}
+
+type moveTaskReq struct {
+	AfterID  *string `json:"afterId"`
+	BeforeID *string `json:"beforeId"`
+}
+
+func (h *TaskHandler) Move(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+	html, ok := parseRender(w, r)
+	if !ok {
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	var req moveTaskReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	task, err := h.svc.Move(r.Context(), uid, id, req.AfterID, req.BeforeID)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to move task", nil)
+		return
+	}
+
+	if html && !renderTask(w, &task) {
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": task})
+}
+
+// parseRender reports whether the client asked for server-rendered
+// descriptions via ?render=html, writing a 422 for any other value.
+func parseRender(w http.ResponseWriter, r *http.Request) (html bool, ok bool) {
+	switch r.URL.Query().Get("render") {
+	case "":
+		return false, true
+	case "html":
+		return true, true
+	}
+	WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+		[]ErrorDetail{{Field: "render", Message: "must be html"}})
+	return false, false
+}
+
+// renderTask fills in t.DescriptionHTML, writing a 500 if rendering fails.
+func renderTask(w http.ResponseWriter, t *domain.Task) bool {
+	html, err := markdown.ToHTML(t.Description)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to render description", nil)
+		return false
+	}
+	t.DescriptionHTML = html
+	return true
+}
+
+func renderTree(w http.ResponseWriter, n *domain.TaskNode) bool {
+	if !renderTask(w, &n.Task) {
+		return false
+	}
+	for i := range n.Subtasks {
+		if !renderTree(w, &n.Subtasks[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// parseCustomFieldFilters reads "cf.<fieldId>=value" and
+// "cf.<fieldId>.<op>=value" query parameters. A parameter may repeat, and
+// every occurrence must match. Field IDs and operators are checked by the
+// service.
+func parseCustomFieldFilters(q url.Values) []domain.CustomFieldFilter {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		if strings.HasPrefix(k, domain.CustomFieldSortPrefix) {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+
+	var out []domain.CustomFieldFilter
+	for _, k := range keys {
+		id, op, found := strings.Cut(strings.TrimPrefix(k, domain.CustomFieldSortPrefix), ".")
+		if !found {
+			op = string(domain.OpEq)
+		}
+		for _, v := range q[k] {
+			out = append(out, domain.CustomFieldFilter{FieldID: id, Op: domain.CustomFieldOp(op), Value: v})
+		}
+	}
+	return out
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+	"gopkg.in/yaml.v3"
+)
+
+// maxTemplateBody caps the size of a template definition.
+const maxTemplateBody = 1 << 20
+
+type TemplateHandler struct {
+	svc *service.TemplateService
+}
+
+func NewTemplateHandler(svc *service.TemplateService) *TemplateHandler {
+	return &TemplateHandler{svc: svc}
+}
+
+type createTemplateReq struct {
+	Name         string                 `json:"name"`
+	ProjectName  string                 `json:"projectName"`
+	EstimateUnit domain.EstimateUnit    `json:"estimateUnit"`
+	Labels       []domain.TemplateLabel `json:"labels"`
+	Tasks        []domain.TemplateTask  `json:"tasks"`
+}
+
+// decodeTemplate reads a template definition given as JSON or, with a
+// YAML content type, as YAML with the same field names.
+func decodeTemplate(r *http.Request, req *createTemplateReq) error {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mt {
+	case "application/yaml", "application/x-yaml", "text/yaml":
+		var doc any
+		if err := yaml.Unmarshal(body, &doc); err != nil {
+			return err
+		}
+		if body, err = json.Marshal(doc); err != nil {
+			return err
+		}
+	}
+	return json.Unmarshal(body, req)
+}
+
+func (h *TemplateHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxTemplateBody)
+	var req createTemplateReq
+	if err := decodeTemplate(r, &req); err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			WriteError(w, 413, "PAYLOAD_TOO_LARGE", "template exceeds 1 MiB", nil)
+			return
+		}
+		WriteError(w, 400, "BAD_REQUEST", "invalid template: "+err.Error(), nil)
+		return
+	}
+
+	t, err := h.svc.Create(r.Context(), uid, domain.Template{
+		Name:         req.Name,
+		ProjectName:  req.ProjectName,
+		EstimateUnit: req.EstimateUnit,
+		Labels:       req.Labels,
+		Tasks:        req.Tasks,
+	})
+	if err != nil {
+		h.writeSaveError(w, err)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": t})
+}
+
+type captureTemplateReq struct {
+	Name string `json:"name"`
+}
+
+// Capture saves a project, with its labels and tasks, as a template.
+func (h *TemplateHandler) Capture(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req captureTemplateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	t, err := h.svc.Capture(r.Context(), uid, chi.URLParam(r, "projectId"), req.Name)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		h.writeSaveError(w, err)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": t})
+}
+
+func (h *TemplateHandler) writeSaveError(w http.ResponseWriter, err error) {
+	if err == service.ErrConflict {
+		WriteError(w, 409, "CONFLICT", "a template with this name already exists", nil)
+		return
+	}
+	if writeValidationError(w, err) {
+		return
+	}
+	WriteError(w, 500, "INTERNAL", "failed to save template", nil)
+}
+
+func (h *TemplateHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	templates, err := h.svc.List(r.Context(), uid)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to list templates", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": templates})
+}
+
+func (h *TemplateHandler) Get(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	t, err := h.svc.Get(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "template not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to get template", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": t})
+}
+
+func (h *TemplateHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.Delete(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "template not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete template", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+type instantiateTemplateReq struct {
+	Name      string            `json:"name"`
+	Variables map[string]string `json:"variables"`
+	StartDate *time.Time        `json:"startDate"`
+}
+
+// Instantiate creates a project, with its labels and tasks, from a
+// template.
+func (h *TemplateHandler) Instantiate(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	var req instantiateTemplateReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	p, err := h.svc.Instantiate(r.Context(), uid, chi.URLParam(r, "id"), domain.TemplateInstance{
+		Name:      req.Name,
+		Variables: req.Variables,
+		StartDate: req.StartDate,
+	})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "template not found", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to create project from template", nil)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": p})
+}
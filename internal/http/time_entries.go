@@ -0,0 +1,319 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TimeHandler struct {
+	svc *service.TimeService
+}
+
+func NewTimeHandler(svc *service.TimeService) *TimeHandler { return &TimeHandler{svc: svc} }
+
+type startTimerReq struct {
+	Note string `json:"note"`
+}
+
+func (h *TimeHandler) Start(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+
+	// The body is optional; an empty one starts a timer without a note.
+	var req startTimerReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	e, err := h.svc.Start(r.Context(), uid, taskID, req.Note)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if err == service.ErrTimerRunning {
+			WriteError(w, 409, "TIMER_RUNNING", "stop the running timer first", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to start timer", nil)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": e})
+}
+
+func (h *TimeHandler) Stop(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	e, err := h.svc.Stop(r.Context(), uid)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "no timer is running", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to stop timer", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": e})
+}
+
+func (h *TimeHandler) Running(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	e, err := h.svc.Running(r.Context(), uid)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to load timer", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": e})
+}
+
+type logTimeReq struct {
+	StartedAt time.Time  `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+	Note      string     `json:"note"`
+}
+
+func (h *TimeHandler) Create(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+
+	var req logTimeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+
+	e, err := h.svc.Log(r.Context(), uid, taskID, domain.TimeEntryInput{
+		StartedAt: req.StartedAt,
+		EndedAt:   req.EndedAt,
+		Note:      req.Note,
+	})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to log time", nil)
+		return
+	}
+	WriteJSON(w, 201, map[string]any{"data": e})
+}
+
+func (h *TimeHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	taskID := chi.URLParam(r, "id")
+
+	limit := 20
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "limit", Message: "must be an integer"}})
+			return
+		}
+		limit = n
+	}
+
+	var cursor *domain.Cursor
+	if tok := r.URL.Query().Get("cursor"); tok != "" {
+		c, err := domain.ParseCursorToken(tok)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: "cursor", Message: "must be a nextCursorToken from a previous page"}})
+			return
+		}
+		cursor = &c
+	}
+
+	page, err := h.svc.List(r.Context(), uid, taskID, limit, cursor)
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list time entries", nil)
+		return
+	}
+
+	resp := map[string]any{"data": page.Items}
+	if page.NextCursor != nil {
+		resp["meta"] = map[string]any{
+			"nextCursor":      page.NextCursor,
+			"nextCursorToken": page.NextCursor.Token(),
+		}
+	}
+	WriteJSON(w, 200, resp)
+}
+
+type updateTimeReq struct {
+	StartedAt *time.Time `json:"startedAt"`
+	EndedAt   *time.Time `json:"endedAt"`
+	Note      *string    `json:"note"`
+}
+
+func (h *TimeHandler) Update(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+
+	var req updateTimeReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		WriteError(w, 400, "BAD_REQUEST", "invalid json", nil)
+		return
+	}
+	if req.StartedAt == nil && req.EndedAt == nil && req.Note == nil {
+		WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+			[]ErrorDetail{{Field: "body", Message: "must include at least one of: startedAt, endedAt, note"}})
+		return
+	}
+
+	e, err := h.svc.Update(r.Context(), uid, id, domain.TimeEntryPatch{
+		StartedAt: req.StartedAt,
+		EndedAt:   req.EndedAt,
+		Note:      req.Note,
+	})
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "time entry not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to update time entry", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": e})
+}
+
+func (h *TimeHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	id := chi.URLParam(r, "id")
+	if err := h.svc.Delete(r.Context(), uid, id); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "time entry not found", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to delete time entry", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *TimeHandler) Totals(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	q := domain.TimeTotalsQuery{GroupBy: domain.TimeGroup(r.URL.Query().Get("groupBy"))}
+	for _, p := range []struct {
+		name string
+		dst  *time.Time
+	}{{"from", &q.From}, {"to", &q.To}} {
+		v := r.URL.Query().Get(p.name)
+		if v == "" {
+			continue
+		}
+		t, err := parseDateOrTime(v)
+		if err != nil {
+			WriteError(w, 422, "VALIDATION_ERROR", "invalid request",
+				[]ErrorDetail{{Field: p.name, Message: "must be a date (YYYY-MM-DD) or RFC3339 timestamp"}})
+			return
+		}
+		*p.dst = t
+	}
+	if v := strings.TrimSpace(r.URL.Query().Get("projectId")); v != "" {
+		q.ProjectID = &v
+	}
+	if v := strings.TrimSpace(r.URL.Query().Get("taskId")); v != "" {
+		q.TaskID = &v
+	}
+
+	totals, err := h.svc.Totals(r.Context(), uid, q)
+	if err != nil {
+		if writeValidationError(w, err) {
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to sum time", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": totals})
+}
+
+// parseDateOrTime accepts an RFC3339 timestamp or a bare date, which is
+// taken as midnight UTC.
+func parseDateOrTime(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	return time.Parse("2006-01-02", s)
+}
@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TrashHandler struct {
+	svc *service.TrashService
+}
+
+func NewTrashHandler(svc *service.TrashService) *TrashHandler { return &TrashHandler{svc: svc} }
+
+func (h *TrashHandler) List(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	trash, err := h.svc.List(r.Context(), uid)
+	if err != nil {
+		WriteError(w, 500, "INTERNAL", "failed to list trash", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": trash})
+}
+
+func (h *TrashHandler) RestoreProject(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	p, err := h.svc.RestoreProject(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found in trash", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to restore project", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": p})
+}
+
+func (h *TrashHandler) RestoreTask(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	t, err := h.svc.RestoreTask(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found in trash", nil)
+			return
+		}
+		if err == service.ErrProjectArchived {
+			WriteError(w, 409, "PROJECT_ARCHIVED", "project is archived", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to restore task", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": t})
+}
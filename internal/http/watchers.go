@@ -0,0 +1,127 @@
+package http
+
+import (
+	"net/http"
+
+	"TaskFlow/internal/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type WatcherHandler struct {
+	svc *service.WatcherService
+}
+
+func NewWatcherHandler(svc *service.WatcherService) *WatcherHandler {
+	return &WatcherHandler{svc: svc}
+}
+
+func (h *WatcherHandler) WatchTask(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.WatchTask(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to watch task", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *WatcherHandler) UnwatchTask(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.UnwatchTask(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to unwatch task", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *WatcherHandler) TaskWatchers(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	watchers, err := h.svc.TaskWatchers(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "task not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list watchers", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": watchers})
+}
+
+func (h *WatcherHandler) WatchProject(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.WatchProject(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to watch project", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *WatcherHandler) UnwatchProject(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	if err := h.svc.UnwatchProject(r.Context(), uid, chi.URLParam(r, "id")); err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to unwatch project", nil)
+		return
+	}
+	w.WriteHeader(204)
+}
+
+func (h *WatcherHandler) ProjectWatchers(w http.ResponseWriter, r *http.Request) {
+	uid, ok := UserID(r.Context())
+	if !ok {
+		WriteError(w, 401, "UNAUTHORIZED", "missing user", nil)
+		return
+	}
+
+	watchers, err := h.svc.ProjectWatchers(r.Context(), uid, chi.URLParam(r, "id"))
+	if err != nil {
+		if err == service.ErrNotFound {
+			WriteError(w, 404, "NOT_FOUND", "project not found", nil)
+			return
+		}
+		WriteError(w, 500, "INTERNAL", "failed to list watchers", nil)
+		return
+	}
+	WriteJSON(w, 200, map[string]any{"data": watchers})
+}
@@ -0,0 +1,43 @@
+// Package jobs runs periodic background work alongside the HTTP server.
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of work run every Interval until its context is cancelled.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs each job in its own goroutine, first immediately and then on
+// every tick. Errors are logged and the job keeps its schedule. The
+// returned function blocks until every job has returned after ctx is
+// cancelled.
+func Start(ctx context.Context, js ...Job) (wait func()) {
+	var wg sync.WaitGroup
+	for _, j := range js {
+		wg.Add(1)
+		go func(j Job) {
+			defer wg.Done()
+			t := time.NewTicker(j.Interval)
+			defer t.Stop()
+			for {
+				if err := j.Run(ctx); err != nil && ctx.Err() == nil {
+					log.Printf("job %s: %v", j.Name, err)
+				}
+				select {
+				case <-ctx.Done():
+					return
+				case <-t.C:
+				}
+			}
+		}(j)
+	}
+	return wg.Wait
+}
@@ -0,0 +1,53 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+// FileTransport writes each message to its own .eml file in a directory
+// instead of sending it. It suits development and tests.
+type FileTransport struct {
+	dir  string
+	from string
+	now  func() time.Time
+}
+
+func NewFileTransport(dir, from string) (*FileTransport, error) {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return nil, err
+	}
+	return &FileTransport{dir: dir, from: from, now: time.Now}, nil
+}
+
+// Send writes m to a temporary file and renames it into place, so readers
+// of the directory never see a partial message. Files are named by send
+// time, so listing them in order lists messages in the order sent.
+func (t *FileTransport) Send(ctx context.Context, m Message) error {
+	now := t.now()
+	b, err := encode(t.from, m, now)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(t.dir, ".mail-*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	_, err = tmp.Write(b)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	name := strconv.FormatInt(now.UnixNano(), 10) + "-" + filepath.Base(tmp.Name())[len(".mail-"):] + ".eml"
+	return os.Rename(tmp.Name(), filepath.Join(t.dir, name))
+}
@@ -0,0 +1,110 @@
+// Package mail renders and delivers outgoing email.
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/textproto"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is an email with a plain text body and an optional HTML
+// alternative. Headers holds extra headers such as List-Unsubscribe.
+type Message struct {
+	To      string
+	Subject string
+	Text    string
+	HTML    string
+	Headers map[string]string
+}
+
+var errHeader = errors.New("mail header contains a line break")
+
+// encode renders m as an RFC 5322 message from the given sender.
+func encode(from string, m Message, now time.Time) ([]byte, error) {
+	head := map[string]string{
+		"From":         from,
+		"To":           m.To,
+		"Subject":      mime.QEncoding.Encode("utf-8", m.Subject),
+		"Date":         now.Format(time.RFC1123Z),
+		"Message-ID":   messageID(from),
+		"MIME-Version": "1.0",
+	}
+	for k, v := range m.Headers {
+		head[textproto.CanonicalMIMEHeaderKey(k)] = v
+	}
+
+	var body bytes.Buffer
+	if m.HTML == "" {
+		head["Content-Type"] = "text/plain; charset=utf-8"
+		head["Content-Transfer-Encoding"] = "quoted-printable"
+		if err := writeQP(&body, m.Text); err != nil {
+			return nil, err
+		}
+	} else {
+		mw := multipart.NewWriter(&body)
+		head["Content-Type"] = "multipart/alternative; boundary=" + mw.Boundary()
+		for _, part := range []struct{ typ, content string }{
+			{"text/plain; charset=utf-8", m.Text},
+			{"text/html; charset=utf-8", m.HTML},
+		} {
+			w, err := mw.CreatePart(textproto.MIMEHeader{
+				"Content-Type":              {part.typ},
+				"Content-Transfer-Encoding": {"quoted-printable"},
+			})
+			if err != nil {
+				return nil, err
+			}
+			if err := writeQP(w, part.content); err != nil {
+				return nil, err
+			}
+		}
+		if err := mw.Close(); err != nil {
+			return nil, err
+		}
+	}
+
+	keys := make([]string, 0, len(head))
+	for k, v := range head {
+		if strings.ContainsAny(k+v, "\r\n") {
+			return nil, errHeader
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var out bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&out, "%s: %s\r\n", k, head[k])
+	}
+	out.WriteString("\r\n")
+	out.Write(body.Bytes())
+	return out.Bytes(), nil
+}
+
+func writeQP(w interface{ Write([]byte) (int, error) }, s string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(s)); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// messageID returns a unique Message-ID in the sender's domain.
+func messageID(from string) string {
+	domain := "localhost"
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		domain = strings.TrimRight(from[i+1:], ">")
+	}
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return "<" + hex.EncodeToString(b) + "@" + domain + ">"
+}
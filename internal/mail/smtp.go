@@ -0,0 +1,62 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net"
+	netmail "net/mail"
+	"net/smtp"
+	"time"
+)
+
+// SMTPConfig describes the relay messages are handed to. Username and
+// Password are optional; when set they are sent with PLAIN auth, which
+// net/smtp only allows over TLS or to localhost.
+type SMTPConfig struct {
+	Addr     string // host:port, e.g. smtp.example.com:587
+	Username string
+	Password string
+	From     string // e.g. TaskFlow <no-reply@example.com>
+}
+
+// SMTPTransport delivers messages through an SMTP relay, upgrading to TLS
+// when the server offers STARTTLS.
+type SMTPTransport struct {
+	cfg    SMTPConfig
+	sender string
+	auth   smtp.Auth
+	now    func() time.Time
+}
+
+func NewSMTPTransport(cfg SMTPConfig) (*SMTPTransport, error) {
+	host, _, err := net.SplitHostPort(cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid SMTP address %q", cfg.Addr)
+	}
+	from, err := netmail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sender %q", cfg.From)
+	}
+	t := &SMTPTransport{cfg: cfg, sender: from.Address, now: time.Now}
+	if cfg.Username != "" {
+		t.auth = smtp.PlainAuth("", cfg.Username, cfg.Password, host)
+	}
+	return t, nil
+}
+
+// Send hands m to the relay. net/smtp takes no context, so cancellation is
+// only checked before connecting.
+func (t *SMTPTransport) Send(ctx context.Context, m Message) error {
+	to, err := netmail.ParseAddress(m.To)
+	if err != nil {
+		return fmt.Errorf("invalid recipient %q", m.To)
+	}
+	b, err := encode(t.cfg.From, m, t.now())
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return smtp.SendMail(t.cfg.Addr, t.auth, t.sender, []string{to.Address}, b)
+}
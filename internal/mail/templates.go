@@ -0,0 +1,55 @@
+package mail
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"io/fs"
+	"path"
+	"strings"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Each email is a pair of templates, templates/<name>.txt.tmpl and
+// templates/<name>.html.tmpl. They are parsed separately, so the two files
+// of a pair, and different emails, may define helpers of the same name.
+var (
+	textTemplates = map[string]*texttemplate.Template{}
+	htmlTemplates = map[string]*htmltemplate.Template{}
+)
+
+func init() {
+	files, _ := fs.Glob(templateFS, "templates/*.tmpl")
+	for _, f := range files {
+		base := path.Base(f)
+		switch {
+		case strings.HasSuffix(base, ".txt.tmpl"):
+			textTemplates[strings.TrimSuffix(base, ".txt.tmpl")] = texttemplate.Must(texttemplate.ParseFS(templateFS, f))
+		case strings.HasSuffix(base, ".html.tmpl"):
+			htmlTemplates[strings.TrimSuffix(base, ".html.tmpl")] = htmltemplate.Must(htmltemplate.ParseFS(templateFS, f))
+		}
+	}
+}
+
+// Render executes the text and HTML templates of the named email with
+// data. The HTML template escapes data for its context; the text template
+// leaves it as is.
+func Render(name string, data any) (text, html string, err error) {
+	tt, ok := textTemplates[name]
+	ht, ok2 := htmlTemplates[name]
+	if !ok || !ok2 {
+		return "", "", fmt.Errorf("unknown mail template %q", name)
+	}
+	var tb, hb bytes.Buffer
+	if err := tt.Execute(&tb, data); err != nil {
+		return "", "", err
+	}
+	if err := ht.Execute(&hb, data); err != nil {
+		return "", "", err
+	}
+	return tb.String(), hb.String(), nil
+}
@@ -0,0 +1,31 @@
+package markdown
+
+import (
+	"bytes"
+
+	"github.com/microcosm-cc/bluemonday"
+	"github.com/yuin/goldmark"
+	"github.com/yuin/goldmark/extension"
+)
+
+// GitHub-flavoured Markdown without raw HTML passthrough. The output is
+// additionally run through a user-generated-content policy so that links,
+// images and attributes are safe to embed directly in a web or mobile view.
+var (
+	md = goldmark.New(
+		goldmark.WithExtensions(extension.GFM),
+	)
+	policy = bluemonday.UGCPolicy()
+)
+
+// ToHTML renders Markdown source to sanitized HTML.
+func ToHTML(src string) (string, error) {
+	if src == "" {
+		return "", nil
+	}
+	var buf bytes.Buffer
+	if err := md.Convert([]byte(src), &buf); err != nil {
+		return "", err
+	}
+	return policy.Sanitize(buf.String()), nil
+}
@@ -0,0 +1,305 @@
+// Package recurrence implements the subset of RFC 5545 recurrence rules
+// used for repeating tasks: FREQ (DAILY, WEEKLY, MONTHLY, YEARLY),
+// INTERVAL, BYDAY, COUNT and UNTIL. Weeks start on Monday.
+package recurrence
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Freq string
+
+const (
+	Daily   Freq = "DAILY"
+	Weekly  Freq = "WEEKLY"
+	Monthly Freq = "MONTHLY"
+	Yearly  Freq = "YEARLY"
+)
+
+// WeekdayNum is a BYDAY entry. A non-zero N selects the Nth (or, when
+// negative, Nth-from-last) such weekday within the month or year.
+type WeekdayNum struct {
+	Day time.Weekday
+	N   int
+}
+
+// Rule is a parsed recurrence rule. At most one of Count and Until is set.
+type Rule struct {
+	Freq     Freq
+	Interval int
+	ByDay    []WeekdayNum
+	Count    int
+	Until    *time.Time
+	// untilDate records that UNTIL was given as a DATE, so String can
+	// reproduce it.
+	untilDate bool
+}
+
+var dayCodes = map[string]time.Weekday{
+	"MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday, "TH": time.Thursday,
+	"FR": time.Friday, "SA": time.Saturday, "SU": time.Sunday,
+}
+
+var dayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse reads an RRULE value such as "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE".
+// An optional "RRULE:" prefix is accepted. A date-only UNTIL is taken to
+// include the whole of that day in loc.
+func Parse(s string, loc *time.Location) (Rule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	if s == "" {
+		return Rule{}, fmt.Errorf("rule is empty")
+	}
+	r := Rule{Interval: 1}
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		name = strings.ToUpper(strings.TrimSpace(name))
+		value = strings.ToUpper(strings.TrimSpace(value))
+		if !ok || value == "" {
+			return Rule{}, fmt.Errorf("malformed part %q", part)
+		}
+		if seen[name] {
+			return Rule{}, fmt.Errorf("%s given more than once", name)
+		}
+		seen[name] = true
+
+		switch name {
+		case "FREQ":
+			switch f := Freq(value); f {
+			case Daily, Weekly, Monthly, Yearly:
+				r.Freq = f
+			default:
+				return Rule{}, fmt.Errorf("FREQ must be DAILY, WEEKLY, MONTHLY or YEARLY")
+			}
+		case "INTERVAL":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 || n > 1000 {
+				return Rule{}, fmt.Errorf("INTERVAL must be between 1 and 1000")
+			}
+			r.Interval = n
+		case "COUNT":
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 1 {
+				return Rule{}, fmt.Errorf("COUNT must be a positive integer")
+			}
+			r.Count = n
+		case "UNTIL":
+			t, dateOnly, err := parseUntil(value, loc)
+			if err != nil {
+				return Rule{}, err
+			}
+			r.Until, r.untilDate = &t, dateOnly
+		case "BYDAY":
+			for _, code := range strings.Split(value, ",") {
+				wd, err := parseWeekdayNum(code)
+				if err != nil {
+					return Rule{}, err
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		default:
+			return Rule{}, fmt.Errorf("%s is not supported", name)
+		}
+	}
+
+	if r.Freq == "" {
+		return Rule{}, fmt.Errorf("FREQ is required")
+	}
+	if r.Count > 0 && r.Until != nil {
+		return Rule{}, fmt.Errorf("COUNT and UNTIL cannot both be set")
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return Rule{}, fmt.Errorf("numbered BYDAY is only allowed with MONTHLY or YEARLY")
+		}
+	}
+	return r, nil
+}
+
+func parseUntil(v string, loc *time.Location) (time.Time, bool, error) {
+	if t, err := time.Parse("20060102T150405Z", v); err == nil {
+		return t, false, nil
+	}
+	if t, err := time.ParseInLocation("20060102", v, loc); err == nil {
+		return t.AddDate(0, 0, 1).Add(-time.Second), true, nil
+	}
+	return time.Time{}, false, fmt.Errorf("UNTIL must be YYYYMMDD or YYYYMMDDTHHMMSSZ")
+}
+
+func parseWeekdayNum(code string) (WeekdayNum, error) {
+	code = strings.TrimSpace(code)
+	if len(code) < 2 {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY value %q", code)
+	}
+	day, ok := dayCodes[code[len(code)-2:]]
+	if !ok {
+		return WeekdayNum{}, fmt.Errorf("invalid BYDAY value %q", code)
+	}
+	wd := WeekdayNum{Day: day}
+	if num := code[:len(code)-2]; num != "" {
+		n, err := strconv.Atoi(num)
+		if err != nil || n == 0 || n < -53 || n > 53 {
+			return WeekdayNum{}, fmt.Errorf("invalid BYDAY value %q", code)
+		}
+		wd.N = n
+	}
+	return wd, nil
+}
+
+// String renders the rule in canonical form.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		codes := make([]string, len(r.ByDay))
+		for i, wd := range r.ByDay {
+			codes[i] = dayNames[wd.Day]
+			if wd.N != 0 {
+				codes[i] = strconv.Itoa(wd.N) + codes[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(codes, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		if r.untilDate {
+			parts = append(parts, "UNTIL="+r.Until.Format("20060102"))
+		} else {
+			parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+		}
+	}
+	return strings.Join(parts, ";")
+}
+
+// maxPeriods bounds the search for the next occurrence so that rules which
+// never match again (e.g. the 5th Monday with a large interval) terminate.
+const maxPeriods = 10000
+
+// Next returns the first occurrence strictly after start, treating start
+// as the series' DTSTART. Occurrences keep start's wall-clock time in
+// start's location, so they stay at the same local time across DST
+// changes. COUNT is not applied here since it depends on how many
+// occurrences came before; ok is false once UNTIL has passed.
+func (r Rule) Next(start time.Time) (next time.Time, ok bool) {
+	interval := r.Interval
+	if interval < 1 {
+		interval = 1
+	}
+	for k := 0; k <= maxPeriods; k += interval {
+		for _, c := range r.candidates(start, k) {
+			if !c.After(start) {
+				continue
+			}
+			if r.Until != nil && c.After(*r.Until) {
+				return time.Time{}, false
+			}
+			return c, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// candidates lists the occurrences in the k-th period after start's period,
+// in chronological order.
+func (r Rule) candidates(start time.Time, k int) []time.Time {
+	y, m, d := start.Date()
+	hh, mm, ss := start.Clock()
+	loc := start.Location()
+	at := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, hh, mm, ss, 0, loc) }
+
+	switch r.Freq {
+	case Daily:
+		c := at(y, m, d+k)
+		if len(r.ByDay) > 0 && !r.hasDay(c.Weekday()) {
+			return nil
+		}
+		return []time.Time{c}
+
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			return []time.Time{at(y, m, d+7*k)}
+		}
+		monday := d - (int(start.Weekday())+6)%7 + 7*k
+		var out []time.Time
+		for i := 0; i < 7; i++ {
+			c := at(y, m, monday+i)
+			if r.hasDay(c.Weekday()) {
+				out = append(out, c)
+			}
+		}
+		return out
+
+	case Monthly:
+		first := time.Date(y, m+time.Month(k), 1, 0, 0, 0, 0, loc)
+		if len(r.ByDay) == 0 {
+			if c := at(first.Year(), first.Month(), d); c.Month() == first.Month() {
+				return []time.Time{c}
+			}
+			return nil
+		}
+		last := first.AddDate(0, 1, -1).Day()
+		return r.expandDays(first.Year(), first.Month(), 1, last, at)
+
+	case Yearly:
+		year := y + k
+		if len(r.ByDay) == 0 {
+			if c := at(year, m, d); c.Month() == m {
+				return []time.Time{c}
+			}
+			return nil
+		}
+		days := time.Date(year, 12, 31, 0, 0, 0, 0, loc).YearDay()
+		return r.expandDays(year, time.January, 1, days, at)
+	}
+	return nil
+}
+
+// expandDays returns the days from..to of a month (or, for January with a
+// year-long range, of a year) that match BYDAY, honouring numbered entries.
+func (r Rule) expandDays(y int, m time.Month, from, to int, at func(int, time.Month, int) time.Time) []time.Time {
+	byDay := map[time.Weekday][]time.Time{}
+	for d := from; d <= to; d++ {
+		c := at(y, m, d)
+		byDay[c.Weekday()] = append(byDay[c.Weekday()], c)
+	}
+	seen := map[int64]bool{}
+	var out []time.Time
+	for _, wd := range r.ByDay {
+		days := byDay[wd.Day]
+		var picked []time.Time
+		switch {
+		case wd.N == 0:
+			picked = days
+		case wd.N > 0 && wd.N <= len(days):
+			picked = days[wd.N-1 : wd.N]
+		case wd.N < 0 && -wd.N <= len(days):
+			picked = days[len(days)+wd.N : len(days)+wd.N+1]
+		}
+		for _, c := range picked {
+			if !seen[c.Unix()] {
+				seen[c.Unix()] = true
+				out = append(out, c)
+			}
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Before(out[j]) })
+	return out
+}
+
+func (r Rule) hasDay(d time.Weekday) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day == d {
+			return true
+		}
+	}
+	return false
+}
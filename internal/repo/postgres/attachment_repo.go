@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"TaskFlow/internal/domain"
+)
+
+type AttachmentRepo struct{ db *sql.DB }
+
+func NewAttachmentRepo(db *sql.DB) *AttachmentRepo { return &AttachmentRepo{db: db} }
+
+const attachmentColumns = `
+	a.id, a.task_id, a.uploader_id, a.filename, a.content_type,
+	a.size_bytes, a.sha256, a.storage_key, a.created_at`
+
+func scanAttachment(s rowScanner) (domain.Attachment, error) {
+	var a domain.Attachment
+	err := s.Scan(&a.ID, &a.TaskID, &a.UploaderID, &a.Filename, &a.ContentType,
+		&a.Size, &a.SHA256, &a.StorageKey, &a.CreatedAt)
+	return a, err
+}
+
+// Create records an uploaded attachment on a task owned by userID, who is
+// stored as the uploader. It returns sql.ErrNoRows if the task does not
+// exist or is not owned by userID.
+func (r *AttachmentRepo) Create(ctx context.Context, userID string, a domain.Attachment) (domain.Attachment, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO attachments AS a (id, task_id, uploader_id, filename, content_type, size_bytes, sha256, storage_key)
+		SELECT $1, t.id, $3, $4, $5, $6, $7, $8
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $2 AND p.user_id = $3 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		  AND p.archived_at IS NULL
+		RETURNING `+attachmentColumns+`
+	`, a.ID, a.TaskID, userID, a.Filename, a.ContentType, a.Size, a.SHA256, a.StorageKey)
+	return scanAttachment(row)
+}
+
+// TaskArchived reports whether the project of an owned task is archived.
+// It returns sql.ErrNoRows if the task does not exist or is not owned by
+// userID.
+func (r *AttachmentRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	return taskArchived(ctx, r.db, userID, taskID)
+}
+
+// List returns a task's attachments, oldest first. It returns sql.ErrNoRows
+// if the task does not exist or is not owned by userID.
+func (r *AttachmentRepo) List(ctx context.Context, userID, taskID string) ([]domain.Attachment, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		)
+	`, taskID, userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM attachments a
+		WHERE a.task_id = $1
+		ORDER BY a.created_at ASC, a.id ASC
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Attachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, a)
+	}
+	return out, rows.Err()
+}
+
+func (r *AttachmentRepo) Get(ctx context.Context, userID, taskID, attachmentID string) (domain.Attachment, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+attachmentColumns+`
+		FROM attachments a
+		JOIN tasks t ON t.id = a.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE a.id = $1 AND a.task_id = $2 AND p.user_id = $3 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, attachmentID, taskID, userID)
+	return scanAttachment(row)
+}
+
+// Delete removes an attachment row. Its blob is queued for deletion by the
+// attachments_queue_blob_deletion trigger.
+func (r *AttachmentRepo) Delete(ctx context.Context, userID, taskID, attachmentID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM attachments a
+		USING tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = a.task_id
+		  AND a.id = $1
+		  AND a.task_id = $2
+		  AND p.user_id = $3
+		  AND t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+	`, attachmentID, taskID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// PendingBlobDeletions returns up to limit storage keys whose attachment
+// rows have been deleted, oldest first.
+func (r *AttachmentRepo) PendingBlobDeletions(ctx context.Context, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT storage_key
+		FROM blob_deletions
+		ORDER BY queued_at
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var keys []string
+	for rows.Next() {
+		var k string
+		if err := rows.Scan(&k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+// ClearBlobDeletions removes keys from the deletion queue once their blobs
+// are gone.
+func (r *AttachmentRepo) ClearBlobDeletions(ctx context.Context, keys []string) error {
+	_, err := r.db.ExecContext(ctx, `
+		DELETE FROM blob_deletions WHERE storage_key = ANY($1::text[])
+	`, keys)
+	return err
+}
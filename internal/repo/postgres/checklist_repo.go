@@ -0,0 +1,306 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/fracindex"
+
+	"github.com/google/uuid"
+)
+
+type ChecklistRepo struct{ db *sql.DB }
+
+func NewChecklistRepo(db *sql.DB) *ChecklistRepo { return &ChecklistRepo{db: db} }
+
+const checklistColumns = "ci.id, ci.task_id, ci.text, ci.checked, ci.position, ci.created_at, ci.updated_at"
+
+func scanChecklistItem(s rowScanner) (domain.ChecklistItem, error) {
+	var it domain.ChecklistItem
+	err := s.Scan(&it.ID, &it.TaskID, &it.Text, &it.Checked, &it.Position, &it.CreatedAt, &it.UpdatedAt)
+	return it, err
+}
+
+// lockTask locks an owned task's row so that concurrent writers to its
+// checklist order are serialized. It returns sql.ErrNoRows if the task does
+// not exist or is not owned by userID.
+func lockTask(ctx context.Context, tx *sql.Tx, userID, taskID string) error {
+	var id string
+	return tx.QueryRowContext(ctx, `
+		SELECT t.id
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		FOR NO KEY UPDATE OF t
+	`, taskID, userID).Scan(&id)
+}
+
+// Create appends an item to the end of a task's checklist. It returns
+// sql.ErrNoRows if the task does not exist or is not owned by userID.
+func (r *ChecklistRepo) Create(ctx context.Context, userID, taskID, text string, checked bool) (domain.ChecklistItem, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockTask(ctx, tx, userID, taskID); err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	var last sql.NullString
+	if err := tx.QueryRowContext(ctx, `
+		SELECT max(position) FROM checklist_items WHERE task_id = $1
+	`, taskID).Scan(&last); err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	position, err := fracindex.Between(last.String, "")
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+
+	it, err := scanChecklistItem(tx.QueryRowContext(ctx, `
+		INSERT INTO checklist_items AS ci (id, task_id, text, checked, position)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING `+checklistColumns,
+		uuid.NewString(), taskID, text, checked, position))
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	return it, tx.Commit()
+}
+
+// List returns a task's checklist in order. It returns sql.ErrNoRows if the
+// task does not exist or is not owned by userID.
+func (r *ChecklistRepo) List(ctx context.Context, userID, taskID string) ([]domain.ChecklistItem, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM tasks t JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		)
+	`, taskID, userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+checklistColumns+`
+		FROM checklist_items ci
+		WHERE ci.task_id = $1
+		ORDER BY ci.position, ci.id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.ChecklistItem{}
+	for rows.Next() {
+		it, err := scanChecklistItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, it)
+	}
+	return out, rows.Err()
+}
+
+// Get returns an owned checklist item.
+func (r *ChecklistRepo) Get(ctx context.Context, userID, itemID string) (domain.ChecklistItem, error) {
+	return scanChecklistItem(r.db.QueryRowContext(ctx, `
+		SELECT `+checklistColumns+`
+		FROM checklist_items ci
+		JOIN tasks t ON t.id = ci.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE ci.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, itemID, userID))
+}
+
+func (r *ChecklistRepo) Update(ctx context.Context, userID, itemID string, patch domain.ChecklistItemPatch) (domain.ChecklistItem, error) {
+	return scanChecklistItem(r.db.QueryRowContext(ctx, `
+		UPDATE checklist_items ci
+		SET text = COALESCE($3, ci.text),
+			checked = COALESCE($4, ci.checked),
+			updated_at = now()
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = ci.task_id
+		  AND p.user_id = $2
+		  AND t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND ci.id = $1
+		RETURNING `+checklistColumns,
+		itemID, userID, patch.Text, patch.Checked))
+}
+
+func (r *ChecklistRepo) Delete(ctx context.Context, userID, itemID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM checklist_items ci
+		USING tasks t, projects p
+		WHERE t.id = ci.task_id
+		  AND p.id = t.project_id
+		  AND p.user_id = $2
+		  AND t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND ci.id = $1
+	`, itemID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Move places an item in its checklist right after afterID and/or right
+// before beforeID, which must be other items of the same task and, when
+// both are given, adjacent in that order once itemID is left out. Only the
+// moved item's position changes.
+func (r *ChecklistRepo) Move(ctx context.Context, userID, itemID string, afterID, beforeID *string) (domain.ChecklistItem, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var taskID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT t.id
+		FROM checklist_items ci
+		JOIN tasks t ON t.id = ci.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE ci.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		FOR NO KEY UPDATE OF t
+	`, itemID, userID).Scan(&taskID)
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+
+	neighbor := func(id string) (string, error) {
+		var pos string
+		err := tx.QueryRowContext(ctx, `
+			SELECT position FROM checklist_items WHERE id = $1 AND task_id = $2 AND id <> $3
+		`, id, taskID, itemID).Scan(&pos)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrInvalidNeighbor
+		}
+		return pos, err
+	}
+	adjacent := func(pos string, below bool) (string, error) {
+		q := `SELECT max(position) FROM checklist_items WHERE task_id = $1 AND id <> $2 AND position < $3`
+		if !below {
+			q = `SELECT min(position) FROM checklist_items WHERE task_id = $1 AND id <> $2 AND position > $3`
+		}
+		var out sql.NullString
+		err := tx.QueryRowContext(ctx, q, taskID, itemID, pos).Scan(&out)
+		return out.String, err
+	}
+
+	var lo, hi string
+	if afterID != nil {
+		if lo, err = neighbor(*afterID); err != nil {
+			return domain.ChecklistItem{}, err
+		}
+	}
+	if beforeID != nil {
+		if hi, err = neighbor(*beforeID); err != nil {
+			return domain.ChecklistItem{}, err
+		}
+	}
+	switch {
+	case afterID == nil:
+		lo, err = adjacent(hi, true)
+	case beforeID == nil:
+		hi, err = adjacent(lo, false)
+	default:
+		// Both neighbors were given, so nothing may sit between them.
+		var next string
+		if next, err = adjacent(lo, false); err == nil && next != hi {
+			err = domain.ErrInvalidNeighbor
+		}
+	}
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+
+	position, err := fracindex.Between(lo, hi)
+	if errors.Is(err, fracindex.ErrOutOfOrder) {
+		return domain.ChecklistItem{}, domain.ErrInvalidNeighbor
+	}
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+
+	it, err := scanChecklistItem(tx.QueryRowContext(ctx, `
+		UPDATE checklist_items ci
+		SET position = $2, updated_at = now()
+		WHERE ci.id = $1
+		RETURNING `+checklistColumns,
+		itemID, position))
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	return it, tx.Commit()
+}
+
+// ConvertToTask replaces a checklist item with a subtask of the item's
+// task, titled with the item's text and completed if the item was checked.
+// The subtask goes to the end of the project's manual order. It returns
+// sql.ErrNoRows if the item does not exist or is not owned by userID.
+func (r *ChecklistRepo) ConvertToTask(ctx context.Context, userID, itemID string) (domain.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		projectID, taskID, text string
+		checked                 bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.id, t.id, ci.text, ci.checked
+		FROM checklist_items ci
+		JOIN tasks t ON t.id = ci.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE ci.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		FOR NO KEY UPDATE OF p
+	`, itemID, userID).Scan(&projectID, &taskID, &text, &checked)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	position, err := nextPosition(ctx, tx, projectID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	number, err := allocTaskNumbers(ctx, tx, projectID, 1)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	status := domain.StatusTodo
+	if checked {
+		status = domain.StatusDone
+	}
+	t, err := scanTask(tx.QueryRowContext(ctx, `
+		INSERT INTO tasks AS t (id, project_id, parent_task_id, title, position, status, number, completed_at, completed_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, CASE WHEN $6 = 'done' THEN now() END, CASE WHEN $6 = 'done' THEN $8::uuid END)
+		RETURNING `+taskColumns,
+		uuid.NewString(), projectID, taskID, text, position, string(status), number, userID))
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM checklist_items WHERE id = $1`, itemID); err != nil {
+		return domain.Task{}, err
+	}
+	return t, tx.Commit()
+}
@@ -0,0 +1,206 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type CommentRepo struct{ db *sql.DB }
+
+func NewCommentRepo(db *sql.DB) *CommentRepo { return &CommentRepo{db: db} }
+
+// commentColumns selects a comment aliased as c. The body of a deleted
+// comment is never returned.
+const commentColumns = `
+	c.id, c.task_id, c.parent_id, c.author_id,
+	CASE WHEN c.deleted_at IS NULL THEN c.body ELSE '' END,
+	c.edited_at, c.deleted_at IS NOT NULL,
+	c.created_at, c.updated_at`
+
+func scanComment(s rowScanner) (domain.Comment, error) {
+	var c domain.Comment
+	var parentID sql.NullString
+	var editedAt sql.NullTime
+	if err := s.Scan(&c.ID, &c.TaskID, &parentID, &c.AuthorID, &c.Body,
+		&editedAt, &c.Deleted, &c.CreatedAt, &c.UpdatedAt); err != nil {
+		return domain.Comment{}, err
+	}
+	if parentID.Valid {
+		c.ParentID = &parentID.String
+	}
+	if editedAt.Valid {
+		c.EditedAt = &editedAt.Time
+		c.Edited = true
+	}
+	return c, nil
+}
+
+// Create adds a comment to a task owned by userID. It returns sql.ErrNoRows
+// if the task does not exist or is not owned by userID.
+func (r *CommentRepo) Create(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+	row := r.db.QueryRowContext(ctx, `
+		INSERT INTO comments AS c (id, task_id, parent_id, author_id, body)
+		SELECT $1, t.id, $2, $3, $4
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $5 AND p.user_id = $3 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		RETURNING `+commentColumns+`
+	`, uuid.NewString(), parentID, userID, body, taskID)
+	return scanComment(row)
+}
+
+// TaskArchived reports whether the project of an owned task is archived.
+// It returns sql.ErrNoRows if the task does not exist or is not owned by
+// userID.
+func (r *CommentRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	return taskArchived(ctx, r.db, userID, taskID)
+}
+
+func (r *CommentRepo) Get(ctx context.Context, userID, commentID string) (domain.Comment, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments c
+		JOIN tasks t ON t.id = c.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE c.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, commentID, userID)
+	return scanComment(row)
+}
+
+// List returns a page of a task's top-level comments, oldest first, each
+// with all of its replies. It returns sql.ErrNoRows if the task does not
+// exist or is not owned by userID.
+func (r *CommentRepo) List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.Comment, *domain.Cursor, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		)
+	`, taskID, userID).Scan(&exists); err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, sql.ErrNoRows
+	}
+
+	q := `
+		SELECT ` + commentColumns + `
+		FROM comments c
+		WHERE c.task_id = $1 AND c.parent_id IS NULL`
+	args := []any{taskID, limit + 1}
+	if cursor != nil {
+		q += ` AND (c.created_at, c.id) > ($3, $4)`
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+	q += ` ORDER BY c.created_at ASC, c.id ASC LIMIT $2`
+
+	out, err := r.queryComments(ctx, q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var next *domain.Cursor
+	if len(out) > limit {
+		last := out[limit-1]
+		next = &domain.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		out = out[:limit]
+	}
+	if len(out) == 0 {
+		return out, next, nil
+	}
+
+	ids := make([]string, len(out))
+	index := make(map[string]int, len(out))
+	for i, c := range out {
+		ids[i] = c.ID
+		index[c.ID] = i
+	}
+	replies, err := r.queryComments(ctx, `
+		SELECT `+commentColumns+`
+		FROM comments c
+		WHERE c.parent_id = ANY($1::uuid[])
+		ORDER BY c.created_at ASC, c.id ASC
+	`, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, reply := range replies {
+		i := index[*reply.ParentID]
+		out[i].Replies = append(out[i].Replies, reply)
+	}
+
+	return out, next, nil
+}
+
+// Update replaces the body of a live comment written by userID and marks it
+// edited. It returns sql.ErrNoRows if there is no such comment.
+func (r *CommentRepo) Update(ctx context.Context, userID, commentID, body string) (domain.Comment, error) {
+	row := r.db.QueryRowContext(ctx, `
+		UPDATE comments c
+		SET body = $3, edited_at = now(), updated_at = now()
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = c.task_id
+		  AND p.user_id = $2
+		  AND t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND c.id = $1
+		  AND c.author_id = $2
+		  AND c.deleted_at IS NULL
+		RETURNING `+commentColumns+`
+	`, commentID, userID, body)
+	return scanComment(row)
+}
+
+// Delete soft-deletes a live comment written by userID. Replies are kept.
+func (r *CommentRepo) Delete(ctx context.Context, userID, commentID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE comments c
+		SET deleted_at = now(), updated_at = now()
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = c.task_id
+		  AND p.user_id = $2
+		  AND t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		  AND c.id = $1
+		  AND c.author_id = $2
+		  AND c.deleted_at IS NULL
+	`, commentID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *CommentRepo) queryComments(ctx context.Context, q string, args ...any) ([]domain.Comment, error) {
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Comment{}
+	for rows.Next() {
+		c, err := scanComment(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type CustomFieldRepo struct{ db *sql.DB }
+
+func NewCustomFieldRepo(db *sql.DB) *CustomFieldRepo { return &CustomFieldRepo{db: db} }
+
+const customFieldColumns = "f.id, f.project_id, f.name, f.type, f.options, f.created_at, f.updated_at"
+
+func scanCustomField(s rowScanner) (domain.CustomField, error) {
+	var (
+		f       domain.CustomField
+		options []byte
+	)
+	if err := s.Scan(&f.ID, &f.ProjectID, &f.Name, &f.Type, &options, &f.CreatedAt, &f.UpdatedAt); err != nil {
+		return f, err
+	}
+	err := json.Unmarshal(options, &f.Options)
+	return f, err
+}
+
+func optionsArg(options []string) (string, error) {
+	if options == nil {
+		options = []string{}
+	}
+	b, err := json.Marshal(options)
+	return string(b), err
+}
+
+// Create adds a field definition to an owned project. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID,
+// and domain.ErrDuplicate if the project already has a field by that name.
+func (r *CustomFieldRepo) Create(ctx context.Context, userID, projectID string, f domain.CustomField) (domain.CustomField, error) {
+	options, err := optionsArg(f.Options)
+	if err != nil {
+		return domain.CustomField{}, err
+	}
+	out, err := scanCustomField(r.db.QueryRowContext(ctx, `
+		INSERT INTO custom_fields AS f (id, project_id, name, type, options)
+		SELECT $1, p.id, $2, $3, $4::jsonb
+		FROM projects p
+		WHERE p.id = $5 AND p.user_id = $6 AND p.deleted_at IS NULL
+		RETURNING `+customFieldColumns,
+		uuid.NewString(), f.Name, string(f.Type), options, projectID, userID))
+	if isUniqueViolation(err) {
+		return domain.CustomField{}, domain.ErrDuplicate
+	}
+	return out, err
+}
+
+// List returns a project's field definitions ordered by name.
+func (r *CustomFieldRepo) List(ctx context.Context, userID, projectID string) ([]domain.CustomField, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+customFieldColumns+`
+		FROM custom_fields f
+		JOIN projects p ON p.id = f.project_id
+		WHERE f.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		ORDER BY lower(f.name)
+	`, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.CustomField{}
+	for rows.Next() {
+		f, err := scanCustomField(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, f)
+	}
+	return out, rows.Err()
+}
+
+// ProjectArchived reports whether an owned project is archived. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID.
+func (r *CustomFieldRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return projectArchived(ctx, r.db, userID, projectID)
+}
+
+// FieldArchived reports whether the project of an owned custom field is
+// archived. It returns sql.ErrNoRows if the field does not exist or is not
+// owned by userID.
+func (r *CustomFieldRepo) FieldArchived(ctx context.Context, userID, fieldID string) (bool, error) {
+	var archived bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.archived_at IS NOT NULL
+		FROM custom_fields f
+		JOIN projects p ON p.id = f.project_id
+		WHERE f.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+	`, fieldID, userID).Scan(&archived)
+	return archived, err
+}
+
+// Assignable returns those of userIDs that belong to users who may read
+// projectID, and so may be set in its user fields.
+func (r *CustomFieldRepo) Assignable(ctx context.Context, projectID string, userIDs []string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id
+		FROM users u
+		JOIN projects p ON p.id = $1
+		WHERE u.id = ANY($2::uuid[]) AND p.user_id = u.id
+		ORDER BY u.id
+	`, projectID, userIDs)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+func (r *CustomFieldRepo) Get(ctx context.Context, userID, fieldID string) (domain.CustomField, error) {
+	return scanCustomField(r.db.QueryRowContext(ctx, `
+		SELECT `+customFieldColumns+`
+		FROM custom_fields f
+		JOIN projects p ON p.id = f.project_id
+		WHERE f.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+	`, fieldID, userID))
+}
+
+// Update renames a field or replaces its options. Values holding options
+// that were removed lose them, and values left empty are deleted, in the
+// same transaction.
+func (r *CustomFieldRepo) Update(ctx context.Context, userID, fieldID string, patch domain.CustomFieldPatch) (domain.CustomField, error) {
+	var options *string
+	if patch.Options != nil {
+		v, err := optionsArg(patch.Options)
+		if err != nil {
+			return domain.CustomField{}, err
+		}
+		options = &v
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.CustomField{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	f, err := scanCustomField(tx.QueryRowContext(ctx, `
+		UPDATE custom_fields f
+		SET name = COALESCE($3, f.name),
+			options = COALESCE($4::jsonb, f.options),
+			updated_at = now()
+		FROM projects p
+		WHERE p.id = f.project_id
+		  AND p.user_id = $2
+		  AND p.deleted_at IS NULL
+		  AND f.id = $1
+		RETURNING `+customFieldColumns,
+		fieldID, userID, patch.Name, options))
+	if isUniqueViolation(err) {
+		return domain.CustomField{}, domain.ErrDuplicate
+	}
+	if err != nil {
+		return domain.CustomField{}, err
+	}
+
+	if options != nil && f.Type.HasOptions() {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE task_custom_values v
+			SET value = (
+				SELECT COALESCE(jsonb_agg(e), '[]')
+				FROM jsonb_array_elements(v.value) e
+				WHERE f.options @> jsonb_build_array(e)
+			)
+			FROM custom_fields f
+			WHERE f.id = v.field_id AND v.field_id = $1 AND jsonb_typeof(v.value) = 'array'
+		`, fieldID); err != nil {
+			return domain.CustomField{}, err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			DELETE FROM task_custom_values v
+			USING custom_fields f
+			WHERE f.id = v.field_id AND v.field_id = $1
+			  AND (v.value = '[]' OR (jsonb_typeof(v.value) = 'string' AND NOT f.options @> jsonb_build_array(v.value)))
+		`, fieldID); err != nil {
+			return domain.CustomField{}, err
+		}
+	}
+	return f, tx.Commit()
+}
+
+// Delete removes a field definition together with every task's value.
+func (r *CustomFieldRepo) Delete(ctx context.Context, userID, fieldID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM custom_fields f
+		USING projects p
+		WHERE p.id = f.project_id
+		  AND p.user_id = $2
+		  AND p.deleted_at IS NULL
+		  AND f.id = $1
+	`, fieldID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// writeCustomValues upserts a task's custom field values, deleting those
+// set to JSON null. A value for a field outside the task's project, or for
+// a task not owned by userID, yields sql.ErrNoRows.
+func writeCustomValues(ctx context.Context, tx *sql.Tx, userID, taskID string, values map[string]json.RawMessage) error {
+	for fieldID, v := range values {
+		if string(v) == "null" {
+			if _, err := tx.ExecContext(ctx, `
+				DELETE FROM task_custom_values v
+				USING tasks t, projects p
+				WHERE v.task_id = $1 AND v.field_id = $2
+				  AND t.id = v.task_id AND p.id = t.project_id AND p.user_id = $3 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+			`, taskID, fieldID, userID); err != nil {
+				return err
+			}
+			continue
+		}
+
+		res, err := tx.ExecContext(ctx, `
+			INSERT INTO task_custom_values (task_id, field_id, value)
+			SELECT t.id, f.id, $3::jsonb
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			JOIN custom_fields f ON f.project_id = t.project_id
+			WHERE t.id = $1 AND f.id = $2 AND p.user_id = $4 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+			ON CONFLICT (task_id, field_id) DO UPDATE SET value = EXCLUDED.value
+		`, taskID, fieldID, string(v), userID)
+		if err != nil {
+			return err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"TaskFlow/internal/domain"
+)
+
+type DependencyRepo struct{ db *sql.DB }
+
+func NewDependencyRepo(db *sql.DB) *DependencyRepo { return &DependencyRepo{db: db} }
+
+// Add records that blockerID blocks blockedID. Both tasks must be owned by
+// userID; adding an existing link is a no-op. The cycle check and the
+// insert run under a transaction-scoped advisory lock on userID's
+// dependency graph, which links never leave. It returns
+// domain.ErrDependencyCycle if blockedID already blocks blockerID, directly
+// or transitively, and sql.ErrNoRows if either task is missing or not
+// owned by userID.
+func (r *DependencyRepo) Add(ctx context.Context, userID, blockerID, blockedID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if _, err := tx.ExecContext(ctx, `
+		SELECT pg_advisory_xact_lock(hashtext('task_dependencies'), hashtext($1))
+	`, userID); err != nil {
+		return err
+	}
+	var cyclic bool
+	if err := tx.QueryRowContext(ctx, `
+		WITH RECURSIVE reach(id) AS (
+			SELECT $1::uuid
+			UNION
+			SELECT d.blocked_id
+			FROM task_dependencies d
+			JOIN reach r ON r.id = d.blocker_id
+		)
+		SELECT EXISTS (SELECT 1 FROM reach WHERE id = $2::uuid)
+	`, blockedID, blockerID).Scan(&cyclic); err != nil {
+		return err
+	}
+	if cyclic {
+		return domain.ErrDependencyCycle
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO task_dependencies (blocker_id, blocked_id)
+		SELECT a.id, b.id
+		FROM tasks a
+		JOIN projects pa ON pa.id = a.project_id
+		CROSS JOIN tasks b
+		JOIN projects pb ON pb.id = b.project_id
+		WHERE a.id = $1 AND pa.user_id = $3 AND a.deleted_at IS NULL AND pa.deleted_at IS NULL
+		  AND b.id = $2 AND pb.user_id = $3 AND b.deleted_at IS NULL AND pb.deleted_at IS NULL
+		ON CONFLICT (blocker_id, blocked_id) DO UPDATE SET created_at = task_dependencies.created_at
+	`, blockerID, blockedID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return tx.Commit()
+}
+
+func (r *DependencyRepo) Remove(ctx context.Context, userID, blockerID, blockedID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM task_dependencies d
+		USING tasks b
+		JOIN projects p ON p.id = b.project_id
+		WHERE d.blocked_id = b.id
+		  AND d.blocker_id = $1
+		  AND b.id = $2
+		  AND p.user_id = $3
+		  AND b.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+	`, blockerID, blockedID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// List returns the tasks blocking taskID and the tasks it blocks.
+func (r *DependencyRepo) List(ctx context.Context, userID, taskID string) (domain.TaskDependencies, error) {
+	deps := domain.TaskDependencies{BlockedBy: []domain.Task{}, Blocks: []domain.Task{}}
+
+	blockedBy, err := r.listTasks(ctx, `
+		SELECT `+taskColumns+`
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.blocker_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE d.blocked_id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY t.created_at, t.id
+	`, taskID, userID)
+	if err != nil {
+		return deps, err
+	}
+	blocks, err := r.listTasks(ctx, `
+		SELECT `+taskColumns+`
+		FROM task_dependencies d
+		JOIN tasks t ON t.id = d.blocked_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE d.blocker_id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		ORDER BY t.created_at, t.id
+	`, taskID, userID)
+	if err != nil {
+		return deps, err
+	}
+	deps.BlockedBy = append(deps.BlockedBy, blockedBy...)
+	deps.Blocks = append(deps.Blocks, blocks...)
+	return deps, nil
+}
+
+func (r *DependencyRepo) listTasks(ctx context.Context, query string, args ...any) ([]domain.Task, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
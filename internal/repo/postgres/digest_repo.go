@@ -0,0 +1,173 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"TaskFlow/internal/domain"
+)
+
+type DigestRepo struct{ db *sql.DB }
+
+func NewDigestRepo(db *sql.DB) *DigestRepo { return &DigestRepo{db: db} }
+
+const digestSettingsColumns = "frequency, timezone, send_hour, send_weekday, last_sent_at"
+
+func scanDigestSettings(r rowScanner) (domain.DigestSettings, error) {
+	var s domain.DigestSettings
+	err := r.Scan(&s.Frequency, &s.Timezone, &s.Hour, &s.Weekday, &s.LastSentAt)
+	return s, err
+}
+
+// Settings returns userID's digest settings, or the defaults if they were
+// never stored.
+func (r *DigestRepo) Settings(ctx context.Context, userID string) (domain.DigestSettings, error) {
+	s, err := scanDigestSettings(r.db.QueryRowContext(ctx, `
+		SELECT `+digestSettingsColumns+` FROM digest_settings WHERE user_id = $1
+	`, userID))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.DefaultDigestSettings(), nil
+	}
+	return s, err
+}
+
+// UpdateSettings changes the given settings of userID. token becomes the
+// user's unsubscribe token if no settings were stored yet.
+func (r *DigestRepo) UpdateSettings(ctx context.Context, userID string, patch domain.DigestSettingsPatch, token string) (domain.DigestSettings, error) {
+	var freq *string
+	if patch.Frequency != nil {
+		f := string(*patch.Frequency)
+		freq = &f
+	}
+	def := domain.DefaultDigestSettings()
+	return scanDigestSettings(r.db.QueryRowContext(ctx, `
+		INSERT INTO digest_settings AS ds (user_id, frequency, timezone, send_hour, send_weekday, unsubscribe_token)
+		VALUES ($1, COALESCE($2, $7), COALESCE($3, $8), COALESCE($4, $9), COALESCE($5, $10), $6)
+		ON CONFLICT (user_id) DO UPDATE
+		SET frequency = COALESCE($2, ds.frequency),
+			timezone = COALESCE($3, ds.timezone),
+			send_hour = COALESCE($4, ds.send_hour),
+			send_weekday = COALESCE($5, ds.send_weekday),
+			updated_at = now()
+		RETURNING `+digestSettingsColumns,
+		userID, freq, patch.Timezone, patch.Hour, patch.Weekday, token,
+		string(def.Frequency), def.Timezone, def.Hour, def.Weekday))
+}
+
+// Unsubscribe turns off the digest of the user holding token. It returns
+// sql.ErrNoRows if no user does.
+func (r *DigestRepo) Unsubscribe(ctx context.Context, token string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE digest_settings
+		SET frequency = 'off', updated_at = now()
+		WHERE unsubscribe_token = $1
+	`, token)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// digestableFrom joins each notification n to its user u, task t, project p
+// and the user's digest settings ds, if any.
+const digestableFrom = `
+	FROM notifications n
+	JOIN users u ON u.id = n.user_id
+	JOIN tasks t ON t.id = n.task_id
+	JOIN projects p ON p.id = t.project_id
+	LEFT JOIN digest_settings ds ON ds.user_id = n.user_id`
+
+// digestableWhere keeps the unread notifications of tasks outside the trash
+// that the user may still read and the user's last digest did not cover.
+var digestableWhere = `
+	WHERE n.read_at IS NULL
+	  AND t.deleted_at IS NULL
+	  AND p.deleted_at IS NULL
+	  AND n.created_at > COALESCE(ds.last_sent_at, '-infinity')
+	  AND p.user_id = n.user_id`
+
+// Pending returns the users with digests on who have notifications for a
+// digest, whether or not their digest is due yet.
+func (r *DigestRepo) Pending(ctx context.Context) ([]domain.DigestRecipient, error) {
+	def := domain.DefaultDigestSettings()
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT u.id, u.email,
+			COALESCE(ds.frequency, $1), COALESCE(ds.timezone, $2),
+			COALESCE(ds.send_hour, $3), COALESCE(ds.send_weekday, $4),
+			ds.last_sent_at, COALESCE(ds.unsubscribe_token, ''),
+			min(n.created_at)
+		`+digestableFrom+digestableWhere+`
+		  AND COALESCE(ds.frequency, $1) <> 'off'
+		GROUP BY u.id, ds.user_id
+		ORDER BY u.id
+	`, string(def.Frequency), def.Timezone, def.Hour, def.Weekday)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.DigestRecipient{}
+	for rows.Next() {
+		var d domain.DigestRecipient
+		s := &d.Settings
+		if err := rows.Scan(&d.UserID, &d.Email, &s.Frequency, &s.Timezone, &s.Hour, &s.Weekday,
+			&s.LastSentAt, &d.Token, &d.Since); err != nil {
+			return nil, err
+		}
+		out = append(out, d)
+	}
+	return out, rows.Err()
+}
+
+// Activity returns up to limit of the notifications of userID for a digest
+// created no later than until, newest first, and how many there are in all.
+func (r *DigestRepo) Activity(ctx context.Context, userID string, until time.Time, limit int) ([]domain.DigestItem, int, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT n.type, n.task_id, t.title, p.name, COALESCE(a.email, ''), n.due_date, n.created_at,
+			count(*) OVER ()
+		`+digestableFrom+`
+		LEFT JOIN users a ON a.id = n.actor_id
+		`+digestableWhere+`
+		  AND n.user_id = $1
+		  AND n.created_at <= $2
+		ORDER BY n.created_at DESC, n.id DESC
+		LIMIT $3
+	`, userID, until, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.DigestItem{}
+	total := 0
+	for rows.Next() {
+		var it domain.DigestItem
+		if err := rows.Scan(&it.Type, &it.TaskID, &it.TaskTitle, &it.ProjectName, &it.Actor,
+			&it.DueDate, &it.CreatedAt, &total); err != nil {
+			return nil, 0, err
+		}
+		out = append(out, it)
+	}
+	return out, total, rows.Err()
+}
+
+// MarkSent records that userID was sent a digest covering notifications up
+// to at. token becomes the user's unsubscribe token if no settings were
+// stored yet.
+func (r *DigestRepo) MarkSent(ctx context.Context, userID, token string, at time.Time) error {
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO digest_settings AS ds (user_id, unsubscribe_token, last_sent_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id) DO UPDATE SET last_sent_at = $3
+	`, userID, token, at)
+	return err
+}
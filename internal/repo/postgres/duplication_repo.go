@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type DuplicationRepo struct{ db *sql.DB }
+
+func NewDuplicationRepo(db *sql.DB) *DuplicationRepo { return &DuplicationRepo{db: db} }
+
+const duplicationColumns = "d.id, d.user_id, d.source_project_id, d.options, d.status, d.project_id, d.error, d.created_at, d.updated_at"
+
+func scanDuplication(s rowScanner) (domain.Duplication, error) {
+	var (
+		d       domain.Duplication
+		options []byte
+	)
+	if err := s.Scan(&d.ID, &d.UserID, &d.SourceProjectID, &options, &d.Status, &d.ProjectID, &d.Error, &d.CreatedAt, &d.UpdatedAt); err != nil {
+		return d, err
+	}
+	err := json.Unmarshal(options, &d.Options)
+	return d, err
+}
+
+// Create queues a copy of an owned project. It returns sql.ErrNoRows if
+// the project does not exist or is not owned by userID.
+func (r *DuplicationRepo) Create(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Duplication, error) {
+	options, err := json.Marshal(opts)
+	if err != nil {
+		return domain.Duplication{}, err
+	}
+	return scanDuplication(r.db.QueryRowContext(ctx, `
+		INSERT INTO project_duplications AS d (id, user_id, source_project_id, options)
+		SELECT $1, p.user_id, p.id, $2::jsonb
+		FROM projects p
+		WHERE p.id = $3 AND p.user_id = $4 AND p.deleted_at IS NULL
+		RETURNING `+duplicationColumns,
+		uuid.NewString(), string(options), projectID, userID))
+}
+
+func (r *DuplicationRepo) Get(ctx context.Context, userID, id string) (domain.Duplication, error) {
+	return scanDuplication(r.db.QueryRowContext(ctx, `
+		SELECT `+duplicationColumns+`
+		FROM project_duplications d
+		WHERE d.id = $1 AND d.user_id = $2
+	`, id, userID))
+}
+
+// RunNext claims the oldest pending copy and makes it in the same
+// transaction that marks it done. It reports false when nothing is
+// pending. A copy that fails is marked failed and not retried; the
+// returned error says why.
+func (r *DuplicationRepo) RunNext(ctx context.Context) (domain.Duplication, bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Duplication{}, false, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	d, err := scanDuplication(tx.QueryRowContext(ctx, `
+		SELECT `+duplicationColumns+`
+		FROM project_duplications d
+		WHERE d.status = 'pending'
+		ORDER BY d.created_at
+		LIMIT 1
+		FOR UPDATE SKIP LOCKED
+	`))
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Duplication{}, false, nil
+	}
+	if err != nil {
+		return domain.Duplication{}, false, err
+	}
+
+	var p domain.Project
+	err = sql.ErrNoRows
+	if d.SourceProjectID != nil {
+		p, err = duplicateProject(ctx, tx, d.UserID, *d.SourceProjectID, d.Options)
+	}
+	if err != nil {
+		_ = tx.Rollback()
+		// A copy cut short by shutdown stays pending for the next run.
+		if ctx.Err() != nil {
+			return d, true, err
+		}
+		reason := "internal error"
+		if errors.Is(err, sql.ErrNoRows) {
+			reason = "source project not found"
+		}
+		failed, ferr := scanDuplication(r.db.QueryRowContext(ctx, `
+			UPDATE project_duplications d
+			SET status = 'failed', error = $2, updated_at = now()
+			WHERE d.id = $1
+			RETURNING `+duplicationColumns,
+			d.ID, reason))
+		if ferr != nil {
+			return d, true, ferr
+		}
+		return failed, true, err
+	}
+
+	d, err = scanDuplication(tx.QueryRowContext(ctx, `
+		UPDATE project_duplications d
+		SET status = 'done', project_id = $2, updated_at = now()
+		WHERE d.id = $1
+		RETURNING `+duplicationColumns,
+		d.ID, p.ID))
+	if err != nil {
+		return domain.Duplication{}, true, err
+	}
+	return d, true, tx.Commit()
+}
@@ -0,0 +1,174 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type LabelRepo struct{ db *sql.DB }
+
+func NewLabelRepo(db *sql.DB) *LabelRepo { return &LabelRepo{db: db} }
+
+func (r *LabelRepo) Create(ctx context.Context, userID, projectID, name, color string) (domain.Label, error) {
+	l := domain.Label{ID: uuid.NewString(), ProjectID: projectID, Name: name, Color: color}
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO labels (id, project_id, name, color)
+		SELECT $1, p.id, $2, $3
+		FROM projects p
+		WHERE p.id = $4 AND p.user_id = $5 AND p.deleted_at IS NULL
+		RETURNING created_at, updated_at
+	`, l.ID, name, color, projectID, userID).Scan(&l.CreatedAt, &l.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.Label{}, domain.ErrDuplicate
+	}
+	return l, err
+}
+
+func (r *LabelRepo) List(ctx context.Context, userID, projectID string) ([]domain.Label, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT l.id, l.project_id, l.name, l.color, l.created_at, l.updated_at
+		FROM labels l
+		JOIN projects p ON p.id = l.project_id
+		WHERE l.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		ORDER BY lower(l.name)
+	`, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Label{}
+	for rows.Next() {
+		var l domain.Label
+		if err := rows.Scan(&l.ID, &l.ProjectID, &l.Name, &l.Color, &l.CreatedAt, &l.UpdatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, l)
+	}
+	return out, rows.Err()
+}
+
+func (r *LabelRepo) Update(ctx context.Context, userID, labelID string, name, color *string) (domain.Label, error) {
+	var l domain.Label
+	err := r.db.QueryRowContext(ctx, `
+		UPDATE labels l
+		SET
+			name = COALESCE($3, l.name),
+			color = COALESCE($4, l.color),
+			updated_at = now()
+		FROM projects p
+		WHERE p.id = l.project_id
+		  AND p.user_id = $2
+		  AND p.deleted_at IS NULL
+		  AND l.id = $1
+		RETURNING l.id, l.project_id, l.name, l.color, l.created_at, l.updated_at
+	`, labelID, userID, name, color).Scan(&l.ID, &l.ProjectID, &l.Name, &l.Color, &l.CreatedAt, &l.UpdatedAt)
+	if isUniqueViolation(err) {
+		return domain.Label{}, domain.ErrDuplicate
+	}
+	return l, err
+}
+
+func (r *LabelRepo) Delete(ctx context.Context, userID, labelID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM labels l
+		USING projects p
+		WHERE p.id = l.project_id
+		  AND p.user_id = $2
+		  AND p.deleted_at IS NULL
+		  AND l.id = $1
+	`, labelID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ProjectArchived reports whether an owned project is archived. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID.
+func (r *LabelRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return projectArchived(ctx, r.db, userID, projectID)
+}
+
+// TaskArchived reports whether the project of an owned task is archived.
+// It returns sql.ErrNoRows if the task does not exist or is not owned by
+// userID.
+func (r *LabelRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	return taskArchived(ctx, r.db, userID, taskID)
+}
+
+// LabelArchived reports whether the project of an owned label is archived.
+// It returns sql.ErrNoRows if the label does not exist or is not owned by
+// userID.
+func (r *LabelRepo) LabelArchived(ctx context.Context, userID, labelID string) (bool, error) {
+	var archived bool
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.archived_at IS NOT NULL
+		FROM labels l
+		JOIN projects p ON p.id = l.project_id
+		WHERE l.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+	`, labelID, userID).Scan(&archived)
+	return archived, err
+}
+
+// Attach links a label to a task in the same project. Attaching a label
+// twice is a no-op. It returns sql.ErrNoRows if either side is missing,
+// not owned by userID, or the two belong to different projects.
+func (r *LabelRepo) Attach(ctx context.Context, userID, taskID, labelID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO task_labels (task_id, label_id)
+		SELECT t.id, l.id
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		JOIN labels l ON l.project_id = t.project_id
+		WHERE t.id = $1 AND l.id = $2 AND p.user_id = $3 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		ON CONFLICT (task_id, label_id) DO UPDATE SET created_at = task_labels.created_at
+	`, taskID, labelID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+func (r *LabelRepo) Detach(ctx context.Context, userID, taskID, labelID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM task_labels tl
+		USING tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE tl.task_id = t.id
+		  AND t.id = $1
+		  AND tl.label_id = $2
+		  AND p.user_id = $3
+		  AND t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+	`, taskID, labelID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"TaskFlow/internal/domain"
+)
+
+type NotificationRepo struct{ db *sql.DB }
+
+func NewNotificationRepo(db *sql.DB) *NotificationRepo { return &NotificationRepo{db: db} }
+
+// notificationColumns selects a notification aliased as n with its task t.
+const notificationColumns = "n.id, n.type, n.task_id, t.project_id, t.title, n.actor_id, n.comment_id, n.due_date, n.read_at, n.created_at"
+
+func scanNotification(r rowScanner) (domain.Notification, error) {
+	var n domain.Notification
+	err := r.Scan(&n.ID, &n.Type, &n.TaskID, &n.ProjectID, &n.TaskTitle, &n.ActorID, &n.CommentID, &n.DueDate, &n.ReadAt, &n.CreatedAt)
+	return n, err
+}
+
+// prefEnabled is true when the preferences row np, if any, lets a
+// notification of type $2 through.
+const prefEnabled = `
+	CASE $2::text
+		WHEN 'assigned' THEN np.assigned
+		WHEN 'commented' THEN np.commented
+		WHEN 'completed' THEN np.completed
+		ELSE np.due_soon
+	END IS NOT FALSE`
+
+// Create stores a notification of ev for each of its recipients who has
+// not turned the event type off, and returns how many were stored.
+// Recipients that do not exist or may not read the task are skipped.
+func (r *NotificationRepo) Create(ctx context.Context, ev domain.TaskEvent) (int, error) {
+	if len(ev.Recipients) == 0 {
+		return 0, nil
+	}
+	var actor *string
+	if ev.ActorID != "" {
+		actor = &ev.ActorID
+	}
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO notifications (id, user_id, type, task_id, actor_id, comment_id)
+		SELECT gen_random_uuid(), u.id, $2, t.id, $3, $4
+		FROM users u
+		JOIN tasks t ON t.id = $1
+		JOIN projects p ON p.id = t.project_id
+		LEFT JOIN notification_preferences np ON np.user_id = u.id
+		WHERE u.id = ANY($5::uuid[]) AND p.user_id = u.id AND `+prefEnabled,
+		ev.TaskID, string(ev.Type), actor, ev.CommentID, ev.Recipients)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// CreateDueSoon notifies the watchers of every open task due in (from, to]
+// whose project is neither archived nor in the trash. Each watcher who may
+// still read the task hears about a due date once. It returns how many
+// notifications were stored.
+func (r *NotificationRepo) CreateDueSoon(ctx context.Context, from, to time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		WITH due AS (
+			SELECT t.id, t.project_id, t.due_date
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE NOT t.completed
+			  AND t.due_date > $1 AND t.due_date <= $3
+			  AND t.deleted_at IS NULL
+			  AND p.deleted_at IS NULL
+			  AND p.archived_at IS NULL
+		), watching AS (
+			SELECT d.id AS task_id, d.due_date, w.user_id
+			FROM due d JOIN task_watchers w ON w.task_id = d.id
+			UNION
+			SELECT d.id, d.due_date, pw.user_id
+			FROM due d JOIN project_watchers pw ON pw.project_id = d.project_id
+		)
+		INSERT INTO notifications (id, user_id, type, task_id, due_date)
+		SELECT gen_random_uuid(), w.user_id, $2, w.task_id, w.due_date
+		FROM watching w
+		JOIN tasks t ON t.id = w.task_id
+		JOIN projects p ON p.id = t.project_id
+		LEFT JOIN notification_preferences np ON np.user_id = w.user_id
+		WHERE p.user_id = w.user_id AND `+prefEnabled+`
+		ON CONFLICT (user_id, task_id, due_date) WHERE type = 'due_soon' DO NOTHING
+	`, from, string(domain.NotifyDueSoon), to)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// List returns a page of userID's notifications, newest first. Those about
+// trashed tasks are left out until the task is restored, and those about
+// tasks userID may no longer read are left out.
+func (r *NotificationRepo) List(ctx context.Context, userID string, unreadOnly bool, limit int, cursor *domain.Cursor) ([]domain.Notification, *domain.Cursor, error) {
+	q := `
+		SELECT ` + notificationColumns + `
+		FROM notifications n
+		JOIN tasks t ON t.id = n.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE n.user_id = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL AND p.user_id = $1`
+	args := []any{userID, limit + 1}
+	if unreadOnly {
+		q += ` AND n.read_at IS NULL`
+	}
+	if cursor != nil {
+		q += ` AND (n.created_at, n.id) < ($3, $4)`
+		args = append(args, cursor.CreatedAt, cursor.ID)
+	}
+	q += ` ORDER BY n.created_at DESC, n.id DESC LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Notification{}
+	for rows.Next() {
+		n, err := scanNotification(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var next *domain.Cursor
+	if len(out) > limit {
+		last := out[limit-1]
+		next = &domain.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		out = out[:limit]
+	}
+	return out, next, nil
+}
+
+// UnreadCount counts the unread notifications List would return.
+func (r *NotificationRepo) UnreadCount(ctx context.Context, userID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT count(*)
+		FROM notifications n
+		JOIN tasks t ON t.id = n.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE n.user_id = $1 AND n.read_at IS NULL AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		  AND p.user_id = $1
+	`, userID).Scan(&n)
+	return n, err
+}
+
+// MarkRead marks one of userID's notifications read; marking it again keeps
+// the first read time. It returns sql.ErrNoRows if there is no such
+// notification.
+func (r *NotificationRepo) MarkRead(ctx context.Context, userID, notificationID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE notifications
+		SET read_at = COALESCE(read_at, now())
+		WHERE id = $1 AND user_id = $2
+	`, notificationID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// MarkAllRead marks every unread notification of userID read and returns
+// how many there were.
+func (r *NotificationRepo) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	res, err := r.db.ExecContext(ctx, `
+		UPDATE notifications SET read_at = now() WHERE user_id = $1 AND read_at IS NULL
+	`, userID)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return int(n), err
+}
+
+// Prefs returns userID's notification preferences, all on by default.
+func (r *NotificationRepo) Prefs(ctx context.Context, userID string) (domain.NotificationPrefs, error) {
+	p := domain.NotificationPrefs{Assigned: true, Commented: true, Completed: true, DueSoon: true}
+	err := r.db.QueryRowContext(ctx, `
+		SELECT assigned, commented, completed, due_soon
+		FROM notification_preferences
+		WHERE user_id = $1
+	`, userID).Scan(&p.Assigned, &p.Commented, &p.Completed, &p.DueSoon)
+	if errors.Is(err, sql.ErrNoRows) {
+		err = nil
+	}
+	return p, err
+}
+
+// UpdatePrefs changes the given preferences of userID.
+func (r *NotificationRepo) UpdatePrefs(ctx context.Context, userID string, patch domain.NotificationPrefsPatch) (domain.NotificationPrefs, error) {
+	var p domain.NotificationPrefs
+	err := r.db.QueryRowContext(ctx, `
+		INSERT INTO notification_preferences AS np (user_id, assigned, commented, completed, due_soon)
+		VALUES ($1, COALESCE($2, TRUE), COALESCE($3, TRUE), COALESCE($4, TRUE), COALESCE($5, TRUE))
+		ON CONFLICT (user_id) DO UPDATE
+		SET assigned = COALESCE($2, np.assigned),
+			commented = COALESCE($3, np.commented),
+			completed = COALESCE($4, np.completed),
+			due_soon = COALESCE($5, np.due_soon),
+			updated_at = now()
+		RETURNING assigned, commented, completed, due_soon
+	`, userID, patch.Assigned, patch.Commented, patch.Completed, patch.DueSoon).
+		Scan(&p.Assigned, &p.Commented, &p.Completed, &p.DueSoon)
+	return p, err
+}
@@ -0,0 +1,180 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+// TaskCount returns the number of tasks in an owned project, subtasks
+// included. It returns sql.ErrNoRows if the project does not exist or is
+// not owned by userID.
+func (r *ProjectRepo) TaskCount(ctx context.Context, userID, projectID string) (int, error) {
+	var n int
+	err := r.db.QueryRowContext(ctx, `
+		SELECT (SELECT count(*) FROM tasks t WHERE t.project_id = p.id AND t.deleted_at IS NULL)
+		FROM projects p
+		WHERE p.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+	`, projectID, userID).Scan(&n)
+	return n, err
+}
+
+// Duplicate copies an owned project in a single transaction. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID.
+func (r *ProjectRepo) Duplicate(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	p, err := duplicateProject(ctx, tx, userID, projectID, opts)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	return p, tx.Commit()
+}
+
+// duplicateProject copies a project with its labels, custom fields and
+// tasks, including subtasks, manual order, label links, custom field
+// values, checklists and dependencies between its own tasks. Comments,
+// attachments, time entries and completion history are not copied. Every
+// table is copied with one statement, so the work grows with the number
+// of tables rather than the number of rows. Tasks in the trash are left
+// behind.
+func duplicateProject(ctx context.Context, tx *sql.Tx, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error) {
+	// The lock keeps tasks from being added or moved mid-copy.
+	if err := lockProject(ctx, tx, userID, projectID); err != nil {
+		return domain.Project{}, err
+	}
+	p := domain.Project{ID: uuid.NewString(), UserID: userID}
+	if err := insertWithGeneratedKey(ctx, tx, func() error {
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO projects (id, user_id, name, estimate_unit, last_task_number)
+			SELECT $1, p.user_id, COALESCE(NULLIF($3, ''), p.name || ' (copy)'), p.estimate_unit, p.last_task_number
+			FROM projects p
+			WHERE p.id = $2
+			RETURNING name, key, estimate_unit, created_at, updated_at
+		`, p.ID, projectID, opts.Name).Scan(&p.Name, &p.Key, &p.EstimateUnit, &p.CreatedAt, &p.UpdatedAt)
+	}); err != nil {
+		return domain.Project{}, err
+	}
+
+	labelsOld, labelsNew, err := copyIDs(ctx, tx, `SELECT id FROM labels WHERE project_id = $1`, projectID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	fieldsOld, fieldsNew, err := copyIDs(ctx, tx, `SELECT id FROM custom_fields WHERE project_id = $1`, projectID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	tasksOld, tasksNew, err := copyIDs(ctx, tx, `SELECT id FROM tasks WHERE project_id = $1 AND deleted_at IS NULL`, projectID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	itemsOld, itemsNew, err := copyIDs(ctx, tx, `
+		SELECT ci.id FROM checklist_items ci JOIN tasks t ON t.id = ci.task_id WHERE t.project_id = $1 AND t.deleted_at IS NULL
+	`, projectID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{`
+			INSERT INTO labels (id, project_id, name, color)
+			SELECT m.new_id, $1, l.name, l.color
+			FROM unnest($2::uuid[], $3::uuid[]) AS m(old_id, new_id)
+			JOIN labels l ON l.id = m.old_id
+		`, []any{p.ID, labelsOld, labelsNew}},
+		{`
+			INSERT INTO custom_fields (id, project_id, name, type, options)
+			SELECT m.new_id, $1, f.name, f.type, f.options
+			FROM unnest($2::uuid[], $3::uuid[]) AS m(old_id, new_id)
+			JOIN custom_fields f ON f.id = m.old_id
+		`, []any{p.ID, fieldsOld, fieldsNew}},
+		// Parent links are checked at the end of the statement, so the
+		// order in which the copies are inserted does not matter. Copies
+		// keep their numbers, so WEB-12 is copied to, say, WEB2-12.
+		{`
+			WITH m AS (SELECT * FROM unnest($2::uuid[], $3::uuid[]) AS m(old_id, new_id))
+			INSERT INTO tasks (id, project_id, number, parent_task_id, title, description, completed,
+				completed_at, completed_by, status, priority,
+				due_date, estimate, position, recurrence_rule, recurrence_timezone, recurrence_from,
+				recurrence_occurrence, next_occurrence_id)
+			SELECT m.new_id, $1, t.number, pm.new_id, t.title, t.description,
+				t.completed AND NOT $4,
+				CASE WHEN NOT $4 THEN t.completed_at END,
+				CASE WHEN NOT $4 THEN t.completed_by END,
+				CASE WHEN $4 AND t.status = 'done' THEN 'todo' ELSE t.status END,
+				t.priority, t.due_date + make_interval(days => $5), t.estimate, t.position,
+				t.recurrence_rule, t.recurrence_timezone, t.recurrence_from, t.recurrence_occurrence, nm.new_id
+			FROM m
+			JOIN tasks t ON t.id = m.old_id
+			LEFT JOIN m pm ON pm.old_id = t.parent_task_id
+			LEFT JOIN m nm ON nm.old_id = t.next_occurrence_id
+		`, []any{p.ID, tasksOld, tasksNew, opts.ResetCompletion, opts.ShiftDays}},
+		{`
+			INSERT INTO task_labels (task_id, label_id)
+			SELECT tm.new_id, lm.new_id
+			FROM task_labels tl
+			JOIN unnest($1::uuid[], $2::uuid[]) AS tm(old_id, new_id) ON tm.old_id = tl.task_id
+			JOIN unnest($3::uuid[], $4::uuid[]) AS lm(old_id, new_id) ON lm.old_id = tl.label_id
+		`, []any{tasksOld, tasksNew, labelsOld, labelsNew}},
+		{`
+			INSERT INTO task_custom_values (task_id, field_id, value)
+			SELECT tm.new_id, fm.new_id, v.value
+			FROM task_custom_values v
+			JOIN unnest($1::uuid[], $2::uuid[]) AS tm(old_id, new_id) ON tm.old_id = v.task_id
+			JOIN unnest($3::uuid[], $4::uuid[]) AS fm(old_id, new_id) ON fm.old_id = v.field_id
+		`, []any{tasksOld, tasksNew, fieldsOld, fieldsNew}},
+		{`
+			INSERT INTO checklist_items (id, task_id, text, checked, position)
+			SELECT cm.new_id, tm.new_id, ci.text, ci.checked AND NOT $5, ci.position
+			FROM unnest($1::uuid[], $2::uuid[]) AS cm(old_id, new_id)
+			JOIN checklist_items ci ON ci.id = cm.old_id
+			JOIN unnest($3::uuid[], $4::uuid[]) AS tm(old_id, new_id) ON tm.old_id = ci.task_id
+		`, []any{itemsOld, itemsNew, tasksOld, tasksNew, opts.ResetCompletion}},
+		// Links to tasks in other projects stay with the original.
+		{`
+			WITH m AS (SELECT * FROM unnest($1::uuid[], $2::uuid[]) AS m(old_id, new_id))
+			INSERT INTO task_dependencies (blocker_id, blocked_id)
+			SELECT a.new_id, b.new_id
+			FROM task_dependencies d
+			JOIN m a ON a.old_id = d.blocker_id
+			JOIN m b ON b.old_id = d.blocked_id
+		`, []any{tasksOld, tasksNew}},
+	}
+	for _, st := range statements {
+		if _, err := tx.ExecContext(ctx, st.query, st.args...); err != nil {
+			return domain.Project{}, err
+		}
+	}
+	return p, nil
+}
+
+// copyIDs returns the IDs selected by query together with fresh IDs for
+// their copies, in the same order.
+func copyIDs(ctx context.Context, tx *sql.Tx, query string, args ...any) (old, fresh []string, err error) {
+	rows, err := tx.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	old, fresh = []string{}, []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, nil, err
+		}
+		old = append(old, id)
+		fresh = append(fresh, uuid.NewString())
+	}
+	return old, fresh, rows.Err()
+}
@@ -3,8 +3,10 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"strings"
 
 	"TaskFlow/internal/domain"
+	"TaskFlow/internal/fracindex"
 
 	"github.com/google/uuid"
 )
@@ -13,23 +15,145 @@ type ProjectRepo struct{ db *sql.DB }
 
 func NewProjectRepo(db *sql.DB) *ProjectRepo { return &ProjectRepo{db: db} }
 
-func (r *ProjectRepo) Create(ctx context.Context, userID, name string) (domain.Project, error) {
+const projectColumns = "id, user_id, name, key, estimate_unit, archived_at, created_at, updated_at"
+
+func scanProject(s rowScanner) (domain.Project, error) {
+	var p domain.Project
+	err := s.Scan(&p.ID, &p.UserID, &p.Name, &p.Key, &p.EstimateUnit, &p.ArchivedAt, &p.CreatedAt, &p.UpdatedAt)
+	return p, err
+}
+
+// projectKeyAttempts bounds how often a project insert is retried when the
+// key derived for it was taken by a concurrent insert.
+const projectKeyAttempts = 10
+
+// insertWithGeneratedKey runs insert, which adds a project whose key is
+// derived by project_key_for, and retries it from a savepoint if another
+// transaction committed the same key first. The derivation only sees
+// committed keys, so two projects named alike can both pick WEB; the retry
+// then sees WEB taken and moves on to WEB2.
+func insertWithGeneratedKey(ctx context.Context, tx *sql.Tx, insert func() error) error {
+	for attempt := 1; ; attempt++ {
+		if _, err := tx.ExecContext(ctx, `SAVEPOINT project_key`); err != nil {
+			return err
+		}
+		err := insert()
+		if err == nil || !isProjectKeyViolation(err) || attempt == projectKeyAttempts {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `ROLLBACK TO SAVEPOINT project_key`); err != nil {
+			return err
+		}
+	}
+}
+
+// Create adds a project. An empty key is derived from the name. It returns
+// domain.ErrDuplicate if another project of userID has, or had, the key
+// the caller asked for.
+func (r *ProjectRepo) Create(ctx context.Context, userID, name, key string) (domain.Project, error) {
 	p := domain.Project{
 		ID:     uuid.NewString(),
 		UserID: userID,
 		Name:   name,
 	}
 
-	err := r.db.QueryRowContext(ctx, `
-		INSERT INTO projects (id, user_id, name)
-		VALUES ($1, $2, $3)
-		RETURNING created_at, updated_at
-	`, p.ID, p.UserID, p.Name).Scan(&p.CreatedAt, &p.UpdatedAt)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
 
-	return p, err
+	insert := func() error {
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO projects (id, user_id, name, key)
+			VALUES ($1, $2, $3, NULLIF($4, ''))
+			RETURNING key, estimate_unit, created_at, updated_at
+		`, p.ID, p.UserID, p.Name, key).Scan(&p.Key, &p.EstimateUnit, &p.CreatedAt, &p.UpdatedAt)
+	}
+	if key == "" {
+		err = insertWithGeneratedKey(ctx, tx, insert)
+	} else {
+		err = insert()
+	}
+	if isUniqueViolation(err) {
+		return domain.Project{}, domain.ErrDuplicate
+	}
+	if err != nil {
+		return domain.Project{}, err
+	}
+	return p, tx.Commit()
 }
 
-func (r *ProjectRepo) List(ctx context.Context, userID string, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error) {
+// CreateFromBlueprint creates a project with its labels and task tree in a
+// single transaction. Tasks are ordered depth-first, each task followed by
+// its subtasks.
+func (r *ProjectRepo) CreateFromBlueprint(ctx context.Context, userID string, bp domain.ProjectBlueprint) (domain.Project, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	p := domain.Project{ID: uuid.NewString(), UserID: userID, Name: bp.Name}
+	if err := insertWithGeneratedKey(ctx, tx, func() error {
+		return tx.QueryRowContext(ctx, `
+			INSERT INTO projects (id, user_id, name, estimate_unit)
+			VALUES ($1, $2, $3, $4)
+			RETURNING key, estimate_unit, created_at, updated_at
+		`, p.ID, userID, p.Name, string(bp.EstimateUnit)).Scan(&p.Key, &p.EstimateUnit, &p.CreatedAt, &p.UpdatedAt)
+	}); err != nil {
+		return domain.Project{}, err
+	}
+
+	labelIDs := make(map[string]string, len(bp.Labels))
+	for _, l := range bp.Labels {
+		id := uuid.NewString()
+		if _, err := tx.ExecContext(ctx, `
+			INSERT INTO labels (id, project_id, name, color) VALUES ($1, $2, $3, $4)
+		`, id, p.ID, l.Name, l.Color); err != nil {
+			if isUniqueViolation(err) {
+				return domain.Project{}, domain.ErrDuplicate
+			}
+			return domain.Project{}, err
+		}
+		labelIDs[strings.ToLower(l.Name)] = id
+	}
+
+	position := ""
+	var insert func(parentID *string, tasks []domain.BlueprintTask) error
+	insert = func(parentID *string, tasks []domain.BlueprintTask) error {
+		for _, bt := range tasks {
+			if position, err = fracindex.Between(position, ""); err != nil {
+				return err
+			}
+			in := bt.TaskInput
+			in.ParentTaskID = parentID
+			t, err := insertTask(ctx, tx, p.ID, position, in)
+			if err != nil {
+				return err
+			}
+			for _, name := range bt.Labels {
+				if _, err := tx.ExecContext(ctx, `
+					INSERT INTO task_labels (task_id, label_id) VALUES ($1, $2) ON CONFLICT DO NOTHING
+				`, t.ID, labelIDs[strings.ToLower(name)]); err != nil {
+					return err
+				}
+			}
+			if err := insert(&t.ID, bt.Subtasks); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if err := insert(nil, bp.Tasks); err != nil {
+		return domain.Project{}, err
+	}
+	return p, tx.Commit()
+}
+
+// List returns a page of a user's live projects, newest first: the
+// archived ones if archived is set, the others otherwise.
+func (r *ProjectRepo) List(ctx context.Context, userID string, archived bool, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error) {
 	if limit <= 0 || limit > 100 {
 		limit = 20
 	}
@@ -43,21 +167,21 @@ func (r *ProjectRepo) List(ctx context.Context, userID string, limit int, cursor
 
 	if cursor == nil {
 		rows, err = r.db.QueryContext(ctx, `
-			SELECT id, user_id, name, created_at, updated_at
+			SELECT `+projectColumns+`
 			FROM projects
-			WHERE user_id = $1
+			WHERE user_id = $1 AND deleted_at IS NULL AND (archived_at IS NOT NULL) = $2
 			ORDER BY created_at DESC, id DESC
-			LIMIT $2
-		`, userID, fetch)
+			LIMIT $3
+		`, userID, archived, fetch)
 	} else {
 		rows, err = r.db.QueryContext(ctx, `
-			SELECT id, user_id, name, created_at, updated_at
+			SELECT `+projectColumns+`
 			FROM projects
-			WHERE user_id = $1
-			  AND (created_at, id) < ($2, $3)
+			WHERE user_id = $1 AND deleted_at IS NULL AND (archived_at IS NOT NULL) = $2
+			  AND (created_at, id) < ($3, $4)
 			ORDER BY created_at DESC, id DESC
-			LIMIT $4
-		`, userID, cursor.CreatedAt, cursor.ID, fetch)
+			LIMIT $5
+		`, userID, archived, cursor.CreatedAt, cursor.ID, fetch)
 	}
 
 	if err != nil {
@@ -67,8 +191,8 @@ func (r *ProjectRepo) List(ctx context.Context, userID string, limit int, cursor
 
 	var out []domain.Project
 	for rows.Next() {
-		var p domain.Project
-		if err := rows.Scan(&p.ID, &p.UserID, &p.Name, &p.CreatedAt, &p.UpdatedAt); err != nil {
+		p, err := scanProject(rows)
+		if err != nil {
 			return nil, nil, err
 		}
 		out = append(out, p)
@@ -88,30 +212,60 @@ func (r *ProjectRepo) List(ctx context.Context, userID string, limit int, cursor
 }
 
 func (r *ProjectRepo) Get(ctx context.Context, userID, projectID string) (domain.Project, error) {
-	var p domain.Project
-	err := r.db.QueryRowContext(ctx, `
-		SELECT id, user_id, name, created_at, updated_at
+	return scanProject(r.db.QueryRowContext(ctx, `
+		SELECT `+projectColumns+`
 		FROM projects
-		WHERE user_id = $1 AND id = $2
-	`, userID, projectID).Scan(&p.ID, &p.UserID, &p.Name, &p.CreatedAt, &p.UpdatedAt)
-	return p, err
+		WHERE user_id = $1 AND id = $2 AND deleted_at IS NULL
+	`, userID, projectID))
 }
 
-func (r *ProjectRepo) UpdateName(ctx context.Context, userID, projectID, name string) (domain.Project, error) {
-	var p domain.Project
-	err := r.db.QueryRowContext(ctx, `
+// Update applies a partial update to an owned project. A project's old
+// keys stay reserved for it, so task keys using them keep resolving. It
+// returns sql.ErrNoRows if the project does not exist or is not owned by
+// userID, and domain.ErrDuplicate if another project of userID has, or
+// had, the new key.
+func (r *ProjectRepo) Update(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
+	var unit *string
+	if patch.EstimateUnit != nil {
+		v := string(*patch.EstimateUnit)
+		unit = &v
+	}
+	p, err := scanProject(r.db.QueryRowContext(ctx, `
 		UPDATE projects
-		SET name = $3, updated_at = now()
-		WHERE user_id = $1 AND id = $2
-		RETURNING id, user_id, name, created_at, updated_at
-	`, userID, projectID, name).Scan(&p.ID, &p.UserID, &p.Name, &p.CreatedAt, &p.UpdatedAt)
+		SET name = COALESCE($3, name),
+			estimate_unit = COALESCE($4, estimate_unit),
+			key = COALESCE($5, key),
+			updated_at = now()
+		WHERE user_id = $1 AND id = $2 AND deleted_at IS NULL
+		RETURNING `+projectColumns,
+		userID, projectID, patch.Name, unit, patch.Key))
+	if isUniqueViolation(err) {
+		return domain.Project{}, domain.ErrDuplicate
+	}
 	return p, err
 }
 
+// SetArchived archives or unarchives an owned project. Archiving an
+// archived project keeps its original archive time. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID.
+func (r *ProjectRepo) SetArchived(ctx context.Context, userID, projectID string, archived bool) (domain.Project, error) {
+	return scanProject(r.db.QueryRowContext(ctx, `
+		UPDATE projects
+		SET archived_at = CASE WHEN $3::boolean THEN COALESCE(archived_at, now()) END,
+			updated_at = now()
+		WHERE user_id = $1 AND id = $2 AND deleted_at IS NULL
+		RETURNING `+projectColumns,
+		userID, projectID, archived))
+}
+
+// Delete moves an owned project, and with it all of its tasks, to the
+// trash. It returns sql.ErrNoRows if the project does not exist, is
+// already in the trash or is not owned by userID.
 func (r *ProjectRepo) Delete(ctx context.Context, userID, projectID string) error {
 	res, err := r.db.ExecContext(ctx, `
-		DELETE FROM projects
-		WHERE user_id = $1 AND id = $2
+		UPDATE projects
+		SET deleted_at = now()
+		WHERE user_id = $1 AND id = $2 AND deleted_at IS NULL
 	`, userID, projectID)
 	if err != nil {
 		return err
@@ -0,0 +1,242 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type SprintRepo struct{ db *sql.DB }
+
+func NewSprintRepo(db *sql.DB) *SprintRepo { return &SprintRepo{db: db} }
+
+const sprintColumns = "s.id, s.project_id, s.name, s.goal, to_char(s.start_date, 'YYYY-MM-DD'), to_char(s.end_date, 'YYYY-MM-DD'), " +
+	"s.completed_at, s.created_at, s.updated_at"
+
+func scanSprint(r rowScanner) (domain.Sprint, error) {
+	var s domain.Sprint
+	err := r.Scan(&s.ID, &s.ProjectID, &s.Name, &s.Goal, &s.StartDate, &s.EndDate, &s.CompletedAt, &s.CreatedAt, &s.UpdatedAt)
+	return s, err
+}
+
+// Create adds a sprint to an owned project. It returns sql.ErrNoRows if the
+// project does not exist or is not owned by userID.
+func (r *SprintRepo) Create(ctx context.Context, userID, projectID string, sp domain.Sprint) (domain.Sprint, error) {
+	return scanSprint(r.db.QueryRowContext(ctx, `
+		INSERT INTO sprints AS s (id, project_id, name, goal, start_date, end_date)
+		SELECT $1, p.id, $2, $3, $4::date, $5::date
+		FROM projects p
+		WHERE p.id = $6 AND p.user_id = $7 AND p.deleted_at IS NULL
+		RETURNING `+sprintColumns,
+		uuid.NewString(), sp.Name, sp.Goal, sp.StartDate, sp.EndDate, projectID, userID))
+}
+
+// List returns a project's sprints in schedule order.
+func (r *SprintRepo) List(ctx context.Context, userID, projectID string) ([]domain.Sprint, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT `+sprintColumns+`
+		FROM sprints s
+		JOIN projects p ON p.id = s.project_id
+		WHERE s.project_id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		ORDER BY s.start_date, s.created_at, s.id
+	`, projectID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Sprint{}
+	for rows.Next() {
+		s, err := scanSprint(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, s)
+	}
+	return out, rows.Err()
+}
+
+func (r *SprintRepo) Get(ctx context.Context, userID, sprintID string) (domain.Sprint, error) {
+	return scanSprint(r.db.QueryRowContext(ctx, `
+		SELECT `+sprintColumns+`
+		FROM sprints s
+		JOIN projects p ON p.id = s.project_id
+		WHERE s.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+	`, sprintID, userID))
+}
+
+// Update applies a partial update to an owned sprint. It returns
+// sql.ErrNoRows if the sprint does not exist or is not owned by userID.
+func (r *SprintRepo) Update(ctx context.Context, userID, sprintID string, patch domain.SprintPatch) (domain.Sprint, error) {
+	return scanSprint(r.db.QueryRowContext(ctx, `
+		UPDATE sprints s
+		SET name = COALESCE($3, s.name),
+			goal = COALESCE($4, s.goal),
+			start_date = COALESCE($5::date, s.start_date),
+			end_date = COALESCE($6::date, s.end_date),
+			updated_at = now()
+		FROM projects p
+		WHERE p.id = s.project_id
+		  AND p.user_id = $2
+		  AND p.deleted_at IS NULL
+		  AND s.id = $1
+		RETURNING `+sprintColumns,
+		sprintID, userID, patch.Name, patch.Goal, patch.StartDate, patch.EndDate))
+}
+
+// Delete removes an owned sprint; its tasks go back to the backlog.
+func (r *SprintRepo) Delete(ctx context.Context, userID, sprintID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM sprints s
+		USING projects p
+		WHERE p.id = s.project_id
+		  AND p.user_id = $2
+		  AND p.deleted_at IS NULL
+		  AND s.id = $1
+	`, sprintID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Complete closes an owned sprint and carries its unfinished tasks to
+// nextID, or, when nextID is nil, to the project's earliest open sprint
+// scheduled after it. Without one they go back to the backlog. It returns
+// sql.ErrNoRows if the sprint does not exist or is not owned by userID,
+// domain.ErrSprintCompleted if it is already completed, and
+// domain.ErrInvalidSprint if nextID is not another open sprint of the
+// same project.
+func (r *SprintRepo) Complete(ctx context.Context, userID, sprintID string, nextID *string) (domain.SprintCompletion, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.SprintCompletion{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	cur, err := scanSprint(tx.QueryRowContext(ctx, `
+		SELECT `+sprintColumns+`
+		FROM sprints s
+		JOIN projects p ON p.id = s.project_id
+		WHERE s.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		FOR UPDATE OF s
+	`, sprintID, userID))
+	if err != nil {
+		return domain.SprintCompletion{}, err
+	}
+	if cur.CompletedAt != nil {
+		return domain.SprintCompletion{}, domain.ErrSprintCompleted
+	}
+
+	var next *string
+	if nextID != nil {
+		var id string
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM sprints
+			WHERE id = $1 AND project_id = $2 AND id <> $3 AND completed_at IS NULL
+		`, *nextID, cur.ProjectID, sprintID).Scan(&id)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.SprintCompletion{}, domain.ErrInvalidSprint
+		}
+		if err != nil {
+			return domain.SprintCompletion{}, err
+		}
+		next = &id
+	} else {
+		var id string
+		err := tx.QueryRowContext(ctx, `
+			SELECT s.id
+			FROM sprints s, sprints c
+			WHERE c.id = $1
+			  AND s.project_id = c.project_id
+			  AND s.completed_at IS NULL
+			  AND (s.start_date, s.created_at, s.id) > (c.start_date, c.created_at, c.id)
+			ORDER BY s.start_date, s.created_at, s.id
+			LIMIT 1
+		`, sprintID).Scan(&id)
+		switch {
+		case err == nil:
+			next = &id
+		case !errors.Is(err, sql.ErrNoRows):
+			return domain.SprintCompletion{}, err
+		}
+	}
+
+	// Trashed tasks stay behind so that a restore puts them back where
+	// they were.
+	var (
+		carried  int
+		estimate float64
+	)
+	if err := tx.QueryRowContext(ctx, `
+		WITH moved AS (
+			UPDATE tasks
+			SET sprint_id = $2, updated_at = now()
+			WHERE sprint_id = $1 AND NOT completed AND deleted_at IS NULL
+			RETURNING estimate
+		)
+		SELECT count(*), COALESCE(sum(estimate), 0)::float8 FROM moved
+	`, sprintID, next).Scan(&carried, &estimate); err != nil {
+		return domain.SprintCompletion{}, err
+	}
+
+	cur, err = scanSprint(tx.QueryRowContext(ctx, `
+		UPDATE sprints s
+		SET completed_at = now(), carried_over_tasks = $2, carried_over_estimate = $3, updated_at = now()
+		WHERE s.id = $1
+		RETURNING `+sprintColumns,
+		sprintID, carried, estimate))
+	if err != nil {
+		return domain.SprintCompletion{}, err
+	}
+	return domain.SprintCompletion{Sprint: cur, NextSprintID: next, CarriedOver: carried}, tx.Commit()
+}
+
+// Stats counts an owned sprint's tasks, adding back those carried over when
+// it was completed. It returns sql.ErrNoRows if the sprint does not exist or
+// is not owned by userID.
+func (r *SprintRepo) Stats(ctx context.Context, userID, sprintID string) (domain.SprintStats, error) {
+	var (
+		st              = domain.SprintStats{SprintID: sprintID}
+		carriedEstimate float64
+		tasks           int
+	)
+	err := r.db.QueryRowContext(ctx, `
+		SELECT p.estimate_unit,
+			s.carried_over_tasks,
+			s.carried_over_estimate::float8,
+			count(t.id),
+			count(t.id) FILTER (WHERE t.completed),
+			COALESCE(sum(t.estimate), 0)::float8,
+			COALESCE(sum(t.estimate) FILTER (WHERE t.completed), 0)::float8
+		FROM sprints s
+		JOIN projects p ON p.id = s.project_id
+		LEFT JOIN tasks t ON t.sprint_id = s.id AND t.deleted_at IS NULL
+		WHERE s.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		GROUP BY p.estimate_unit, s.carried_over_tasks, s.carried_over_estimate
+	`, sprintID, userID).Scan(&st.EstimateUnit, &st.CarriedOverTasks, &carriedEstimate,
+		&tasks, &st.CompletedTasks, &st.TotalEstimate, &st.CompletedEstimate)
+	if err != nil {
+		return domain.SprintStats{}, err
+	}
+	st.TotalTasks = tasks + st.CarriedOverTasks
+	st.TotalEstimate += carriedEstimate
+	return st, nil
+}
+
+// ProjectArchived reports whether an owned project is archived. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID.
+func (r *SprintRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return projectArchived(ctx, r.db, userID, projectID)
+}
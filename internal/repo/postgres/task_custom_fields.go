@@ -0,0 +1,112 @@
+package postgres
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+
+	"TaskFlow/internal/domain"
+)
+
+// customValueExpr selects a task's value for the field bound at fieldArg
+// as text, or NULL when the task has none.
+func customValueExpr(fieldArg string) string {
+	return "(SELECT v.value #>> '{}' FROM task_custom_values v WHERE v.task_id = t.id AND v.field_id = " + fieldArg + "::uuid)"
+}
+
+// textSortMax sorts after every stored text value, so tasks without a value
+// come last in ascending order; in descending order the empty string, which
+// no value can be, does the same.
+const textSortMax = "\U0010FFFF"
+
+// customSortExpr is taskSortExpr for a custom field key. Tasks without a
+// value sort last in either direction, like a missing due date.
+func customSortExpr(k domain.SortKey, fieldArg string) (expr, typ string) {
+	val := customValueExpr(fieldArg)
+	switch k.FieldType {
+	case domain.FieldNumber:
+		if k.Desc {
+			return "COALESCE(" + val + "::float8, '-Infinity'::float8)", "float8"
+		}
+		return "COALESCE(" + val + "::float8, 'Infinity'::float8)", "float8"
+	case domain.FieldDate:
+		if k.Desc {
+			return "COALESCE(" + val + "::date, '-infinity'::date)", "date"
+		}
+		return "COALESCE(" + val + "::date, 'infinity'::date)", "date"
+	case domain.FieldText, domain.FieldSingleSelect, domain.FieldUser, domain.FieldURL:
+		if k.Desc {
+			return "(COALESCE(" + val + ", '') COLLATE \"C\")", "text"
+		}
+		return "(COALESCE(" + val + ", chr(1114111)) COLLATE \"C\")", "text"
+	}
+	panic("postgres: custom field type " + string(k.FieldType) + " cannot be sorted")
+}
+
+// customSortValue renders a task's raw value in the form compared by
+// customSortExpr.
+func customSortValue(raw json.RawMessage, k domain.SortKey) string {
+	if raw == nil {
+		switch {
+		case k.FieldType == domain.FieldNumber && k.Desc:
+			return "-Infinity"
+		case k.FieldType == domain.FieldNumber:
+			return "Infinity"
+		case k.FieldType == domain.FieldDate && k.Desc:
+			return "-infinity"
+		case k.FieldType == domain.FieldDate:
+			return "infinity"
+		case k.Desc:
+			return ""
+		}
+		return textSortMax
+	}
+	if k.FieldType == domain.FieldNumber {
+		var n float64
+		_ = json.Unmarshal(raw, &n)
+		return strconv.FormatFloat(n, 'g', -1, 64)
+	}
+	var v string
+	_ = json.Unmarshal(raw, &v)
+	return v
+}
+
+var customFilterOps = map[domain.CustomFieldOp]string{
+	domain.OpEq:  " = ",
+	domain.OpGt:  " > ",
+	domain.OpGte: " >= ",
+	domain.OpLt:  " < ",
+	domain.OpLte: " <= ",
+}
+
+// writeCustomFieldFilter appends the predicate for one custom field filter.
+// The field ID and value are always bound as parameters; only the operator
+// and casts, chosen from fixed lists, are written into the SQL.
+func writeCustomFieldFilter(b *strings.Builder, arg func(any) string, f domain.CustomFieldFilter) {
+	field := arg(f.FieldID)
+	if f.Op == domain.OpEmpty {
+		if f.Value == "true" {
+			b.WriteString(" AND NOT")
+		} else {
+			b.WriteString(" AND")
+		}
+		b.WriteString(" EXISTS (SELECT 1 FROM task_custom_values v WHERE v.task_id = t.id AND v.field_id = " + field + "::uuid)")
+		return
+	}
+
+	b.WriteString(" AND EXISTS (SELECT 1 FROM task_custom_values v WHERE v.task_id = t.id AND v.field_id = " + field + "::uuid AND ")
+	op := customFilterOps[f.Op]
+	switch f.Type {
+	case domain.FieldNumber:
+		b.WriteString("(v.value #>> '{}')::float8" + op + arg(f.Value) + "::float8")
+	case domain.FieldDate:
+		b.WriteString("(v.value #>> '{}')::date" + op + arg(f.Value) + "::date")
+	case domain.FieldMultiSelect:
+		b.WriteString("v.value @> jsonb_build_array(" + arg(f.Value) + "::text)")
+	case domain.FieldText:
+		b.WriteString("lower(v.value #>> '{}') = lower(" + arg(f.Value) + "::text)")
+	default:
+		b.WriteString("v.value #>> '{}' = " + arg(f.Value) + "::text")
+	}
+	b.WriteString(")")
+}
@@ -3,10 +3,15 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"TaskFlow/internal/domain"
+	"TaskFlow/internal/fracindex"
 
 	"github.com/google/uuid"
 )
@@ -15,32 +20,314 @@ type TaskRepo struct{ db *sql.DB }
 
 func NewTaskRepo(db *sql.DB) *TaskRepo { return &TaskRepo{db: db} }
 
-func (r *TaskRepo) Create(ctx context.Context, userID, projectID, title string) (domain.Task, error) {
-	title = strings.TrimSpace(title)
+const taskColumns = "t.id, t.project_id, t.number, (SELECT pk.key FROM projects pk WHERE pk.id = t.project_id), t.parent_task_id, t.title, t.description, t.completed, t.completed_at, t.completed_by, t.status, t.priority, t.due_date, t.estimate::float8, t.sprint_id, " +
+	"(SELECT count(*) FROM tasks c WHERE c.parent_task_id = t.id AND c.deleted_at IS NULL), " +
+	"(SELECT count(*) FROM tasks c WHERE c.parent_task_id = t.id AND c.deleted_at IS NULL AND c.completed), " +
+	"(SELECT count(*) FROM checklist_items ci WHERE ci.task_id = t.id), " +
+	"(SELECT count(*) FROM checklist_items ci WHERE ci.task_id = t.id AND ci.checked), " +
+	"EXISTS (SELECT 1 FROM task_dependencies d JOIN tasks b ON b.id = d.blocker_id JOIN projects bp ON bp.id = b.project_id " +
+	"WHERE d.blocked_id = t.id AND NOT b.completed AND b.deleted_at IS NULL AND bp.deleted_at IS NULL), " +
+	"COALESCE((SELECT json_agg(json_build_object('id', l.id, 'name', l.name, 'color', l.color) ORDER BY lower(l.name)) " +
+	"FROM task_labels tl JOIN labels l ON l.id = tl.label_id WHERE tl.task_id = t.id), '[]'), " +
+	"t.position, t.recurrence_rule, t.recurrence_timezone, t.recurrence_from, t.recurrence_occurrence, t.next_occurrence_id, " +
+	"COALESCE((SELECT jsonb_object_agg(v.field_id::text, v.value) FROM task_custom_values v WHERE v.task_id = t.id), '{}'), " +
+	"t.created_at, t.updated_at"
 
-	t := domain.Task{
-		ID:        uuid.NewString(),
-		ProjectID: projectID,
-		Title:     title,
-		Completed: false,
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(s rowScanner) (domain.Task, error) {
+	var (
+		t                domain.Task
+		projectKey       string
+		labels           []byte
+		customFields     []byte
+		rule, tz, from   sql.NullString
+		occurrence       int
+		nextOccurrenceID *string
+	)
+	err := s.Scan(&t.ID, &t.ProjectID, &t.Number, &projectKey, &t.ParentTaskID, &t.Title, &t.Description, &t.Completed, &t.CompletedAt, &t.CompletedBy, &t.Status, &t.Priority, &t.DueDate, &t.Estimate, &t.SprintID,
+		&t.SubtaskCount, &t.CompletedSubtaskCount, &t.ChecklistTotal, &t.ChecklistChecked, &t.IsBlocked, &labels, &t.Position,
+		&rule, &tz, &from, &occurrence, &nextOccurrenceID, &customFields, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return t, err
+	}
+	t.Key = projectKey + "-" + strconv.Itoa(t.Number)
+	t.ChecklistProgress = fmt.Sprintf("%d/%d", t.ChecklistChecked, t.ChecklistTotal)
+	if rule.Valid {
+		t.Recurrence = &domain.Recurrence{
+			Rule:           rule.String,
+			Timezone:       tz.String,
+			RegenerateFrom: domain.RegenerateFrom(from.String),
+			Occurrence:     occurrence,
+			NextTaskID:     nextOccurrenceID,
+		}
+	}
+	if err := json.Unmarshal(customFields, &t.CustomFields); err != nil {
+		return t, err
 	}
+	err = json.Unmarshal(labels, &t.Labels)
+	return t, err
+}
 
-	err := r.db.QueryRowContext(ctx, `
-		INSERT INTO tasks (id, project_id, title)
-		SELECT $1, p.id, $2
-		FROM projects p
-		WHERE p.id = $3 AND p.user_id = $4
-		RETURNING created_at, updated_at, completed
-	`, t.ID, t.Title, projectID, userID).Scan(&t.CreatedAt, &t.UpdatedAt, &t.Completed)
+// recurrenceArgs splits a recurrence into nullable column values.
+func recurrenceArgs(rec *domain.Recurrence) (rule, tz, from *string) {
+	if rec == nil {
+		return nil, nil, nil
+	}
+	f := string(rec.RegenerateFrom)
+	return &rec.Rule, &rec.Timezone, &f
+}
 
-	return t, err
+// Create adds a task at the end of its project's manual order and gives it
+// the project's next task number.
+func (r *TaskRepo) Create(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	if err := lockProject(ctx, tx, userID, projectID); err != nil {
+		return domain.Task{}, err
+	}
+	position, err := nextPosition(ctx, tx, projectID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	t, err := insertTask(ctx, tx, projectID, position, in)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if len(in.CustomFields) > 0 {
+		if err := writeCustomValues(ctx, tx, userID, t.ID, in.CustomFields); err != nil {
+			return domain.Task{}, err
+		}
+		if t, err = scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks t WHERE t.id = $1`, t.ID)); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	return t, tx.Commit()
+}
+
+// insertTask inserts a task at position without checking ownership or the
+// parent; callers hold the project lock.
+func insertTask(ctx context.Context, tx *sql.Tx, projectID, position string, in domain.TaskInput) (domain.Task, error) {
+	status := in.Status
+	if status == "" {
+		status = domain.StatusTodo
+	}
+	number, err := allocTaskNumbers(ctx, tx, projectID, 1)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	rule, tz, from := recurrenceArgs(in.Recurrence)
+	// A task created done was completed by the project's owner, the only
+	// one who can create it.
+	return scanTask(tx.QueryRowContext(ctx, `
+		INSERT INTO tasks AS t (id, project_id, title, description, priority, due_date, parent_task_id,
+			recurrence_rule, recurrence_timezone, recurrence_from, position, status, estimate, sprint_id, number,
+			completed_at, completed_by)
+		VALUES ($1, $6, $2, $3, $4, $5, $7, $8, $9, $10, $11, $12, $13, $14, $15,
+			CASE WHEN $12 = 'done' THEN now() END,
+			CASE WHEN $12 = 'done' THEN (SELECT user_id FROM projects WHERE id = $6) END)
+		RETURNING `+taskColumns,
+		uuid.NewString(), strings.TrimSpace(in.Title), in.Description, int(in.Priority), in.DueDate, projectID, in.ParentTaskID,
+		rule, tz, from, position, string(status), in.Estimate, in.SprintID, number))
+}
+
+// allocTaskNumbers reserves the project's next n task numbers and returns
+// the first. The counter's row lock is held until the transaction ends, so
+// concurrent inserts into the project are handed distinct numbers, and a
+// rolled-back insert leaves no gap.
+func allocTaskNumbers(ctx context.Context, tx *sql.Tx, projectID string, n int) (int, error) {
+	var first int
+	err := tx.QueryRowContext(ctx, `
+		UPDATE projects SET last_task_number = last_task_number + $2
+		WHERE id = $1
+		RETURNING last_task_number - $2 + 1
+	`, projectID, n).Scan(&first)
+	return first, err
+}
+
+// lockProject locks an owned project's row so that concurrent writers to
+// the project's task order are serialized. It returns sql.ErrNoRows if the
+// project does not exist, is in the trash or is not owned by userID.
+func lockProject(ctx context.Context, tx *sql.Tx, userID, projectID string) error {
+	var id string
+	return tx.QueryRowContext(ctx, `
+		SELECT id FROM projects WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL FOR NO KEY UPDATE
+	`, projectID, userID).Scan(&id)
+}
+
+// nextPosition returns a key after every task in the project, including
+// tasks in the trash, which keep their place for a restore.
+func nextPosition(ctx context.Context, tx *sql.Tx, projectID string) (string, error) {
+	var last sql.NullString
+	if err := tx.QueryRowContext(ctx, `
+		SELECT max(position) FROM tasks WHERE project_id = $1
+	`, projectID).Scan(&last); err != nil {
+		return "", err
+	}
+	return fracindex.Between(last.String, "")
+}
+
+// CreateNextOccurrence copies the recurring task prevID, with its labels,
+// as occurrence number occurrence due at due, and links prevID to the copy.
+// It returns sql.ErrNoRows if prevID is not a recurring task owned by
+// userID or already has a next occurrence.
+func (r *TaskRepo) CreateNextOccurrence(ctx context.Context, userID, prevID string, due *time.Time, occurrence int) (domain.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var projectID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.id
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		FOR NO KEY UPDATE OF p
+	`, prevID, userID).Scan(&projectID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	position, err := nextPosition(ctx, tx, projectID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	number, err := allocTaskNumbers(ctx, tx, projectID, 1)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	nextID := uuid.NewString()
+	res, err := tx.ExecContext(ctx, `
+		INSERT INTO tasks (id, project_id, parent_task_id, title, description, priority, due_date, estimate,
+			recurrence_rule, recurrence_timezone, recurrence_from, recurrence_occurrence, position, number)
+		SELECT $1, t.project_id, t.parent_task_id, t.title, t.description, t.priority, $3, t.estimate,
+			t.recurrence_rule, t.recurrence_timezone, t.recurrence_from, $4, $5, $6
+		FROM tasks t
+		WHERE t.id = $2
+		  AND t.recurrence_rule IS NOT NULL
+		  AND t.next_occurrence_id IS NULL
+	`, nextID, prevID, due, occurrence, position, number)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return domain.Task{}, err
+	} else if n == 0 {
+		return domain.Task{}, sql.ErrNoRows
+	}
+
+	// A concurrent completion may have linked an occurrence since the check
+	// above; the row lock taken here makes the loser see it and back out.
+	res, err = tx.ExecContext(ctx, `
+		UPDATE tasks SET next_occurrence_id = $1
+		WHERE id = $2 AND next_occurrence_id IS NULL
+	`, nextID, prevID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return domain.Task{}, err
+	} else if n == 0 {
+		return domain.Task{}, sql.ErrNoRows
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO task_labels (task_id, label_id)
+		SELECT $1, label_id FROM task_labels WHERE task_id = $2
+	`, nextID, prevID); err != nil {
+		return domain.Task{}, err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO task_custom_values (task_id, field_id, value)
+		SELECT $1, field_id, value FROM task_custom_values WHERE task_id = $2
+	`, nextID, prevID); err != nil {
+		return domain.Task{}, err
+	}
+
+	// The checklist starts over unticked on every occurrence.
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO checklist_items (id, task_id, text, position)
+		SELECT gen_random_uuid(), $1, text, position FROM checklist_items WHERE task_id = $2
+	`, nextID, prevID); err != nil {
+		return domain.Task{}, err
+	}
+
+	next, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks t WHERE t.id = $1`, nextID))
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return next, tx.Commit()
+}
+
+// taskSortExpr returns the SQL expression ordered on for a sort field and the
+// type its cursor value is cast to. Missing due dates sort last in either
+// direction by substituting +/- infinity. Custom field keys bind their
+// field ID through arg.
+func taskSortExpr(k domain.SortKey, arg func(any) string) (expr, typ string) {
+	if id := domain.CustomFieldSortID(k.Field); id != "" {
+		return customSortExpr(k, arg(id))
+	}
+	switch k.Field {
+	case "priority":
+		return "t.priority", "smallint"
+	case "dueDate":
+		if k.Desc {
+			return "COALESCE(t.due_date, '-infinity'::timestamptz)", "timestamptz"
+		}
+		return "COALESCE(t.due_date, 'infinity'::timestamptz)", "timestamptz"
+	case "title":
+		return "t.title", "text"
+	case "position":
+		return "t.position", "text"
+	case "createdAt":
+		return "t.created_at", "timestamptz"
+	case "updatedAt":
+		return "t.updated_at", "timestamptz"
+	}
+	panic("postgres: unknown task sort field " + k.Field)
+}
+
+// taskSortValue renders t's value for k in the form compared by taskSortExpr.
+func taskSortValue(t domain.Task, k domain.SortKey) string {
+	if id := domain.CustomFieldSortID(k.Field); id != "" {
+		return customSortValue(t.CustomFields[id], k)
+	}
+	switch k.Field {
+	case "priority":
+		return strconv.Itoa(int(t.Priority))
+	case "dueDate":
+		if t.DueDate == nil {
+			if k.Desc {
+				return "-infinity"
+			}
+			return "infinity"
+		}
+		return t.DueDate.Format(time.RFC3339Nano)
+	case "title":
+		return t.Title
+	case "position":
+		return t.Position
+	case "createdAt":
+		return t.CreatedAt.Format(time.RFC3339Nano)
+	case "updatedAt":
+		return t.UpdatedAt.Format(time.RFC3339Nano)
+	}
+	return ""
 }
 
 func (r *TaskRepo) List(
 	ctx context.Context,
 	userID string,
-	projectID string,
-	completed *bool,
+	f domain.TaskFilter,
+	sort []domain.SortKey,
 	limit int,
 	cursor *domain.Cursor,
 ) ([]domain.Task, *domain.Cursor, error) {
@@ -58,29 +345,78 @@ func (r *TaskRepo) List(
 	}
 
 	b.WriteString(
-		"SELECT t.id, t.project_id, t.title, t.completed, t.created_at, t.updated_at " +
+		"SELECT " + taskColumns + " " +
 			"FROM tasks t " +
 			"JOIN projects p ON p.id = t.project_id " +
-			"WHERE p.user_id = ",
+			"WHERE t.deleted_at IS NULL AND p.deleted_at IS NULL AND p.user_id = ",
 	)
 	b.WriteString(arg(userID))
 	b.WriteString(" AND t.project_id = ")
-	b.WriteString(arg(projectID))
+	b.WriteString(arg(f.ProjectID))
 
-	if completed != nil {
+	if f.Completed != nil {
 		b.WriteString(" AND t.completed = ")
-		b.WriteString(arg(*completed))
+		b.WriteString(arg(*f.Completed))
 	}
 
-	if cursor != nil {
-		b.WriteString(" AND (t.created_at, t.id) < (")
-		b.WriteString(arg(cursor.CreatedAt))
-		b.WriteString(", ")
-		b.WriteString(arg(cursor.ID))
-		b.WriteString(")")
+	if f.Status != nil {
+		b.WriteString(" AND t.status = ")
+		b.WriteString(arg(string(*f.Status)))
+	}
+
+	if f.CompletedAfter != nil {
+		b.WriteString(" AND t.completed_at >= ")
+		b.WriteString(arg(*f.CompletedAfter))
+	}
+	if f.CompletedBefore != nil {
+		b.WriteString(" AND t.completed_at < ")
+		b.WriteString(arg(*f.CompletedBefore))
+	}
+
+	if f.SprintID != "" {
+		b.WriteString(" AND t.sprint_id = ")
+		b.WriteString(arg(f.SprintID))
+	} else if f.Backlog {
+		b.WriteString(" AND t.sprint_id IS NULL")
+	}
+
+	if len(f.Labels) > 0 {
+		names := make([]string, len(f.Labels))
+		for i, n := range f.Labels {
+			names[i] = strings.ToLower(n)
+		}
+		// "all" counts how many distinct requested labels the task carries.
+		if f.LabelMatch == domain.LabelMatchAll {
+			b.WriteString(" AND (SELECT count(DISTINCT lower(l.name)) FROM task_labels tl JOIN labels l ON l.id = tl.label_id" +
+				" WHERE tl.task_id = t.id AND lower(l.name) = ANY(")
+			b.WriteString(arg(names))
+			b.WriteString("::text[])) = ")
+			b.WriteString(arg(len(names)))
+		} else {
+			b.WriteString(" AND EXISTS (SELECT 1 FROM task_labels tl JOIN labels l ON l.id = tl.label_id" +
+				" WHERE tl.task_id = t.id AND lower(l.name) = ANY(")
+			b.WriteString(arg(names))
+			b.WriteString("::text[]))")
+		}
 	}
 
-	b.WriteString(" ORDER BY t.created_at DESC, t.id DESC LIMIT ")
+	for _, cf := range f.CustomFields {
+		writeCustomFieldFilter(&b, arg, cf)
+	}
+
+	if len(sort) == 0 {
+		if cursor != nil {
+			b.WriteString(" AND (t.created_at, t.id) < (")
+			b.WriteString(arg(cursor.CreatedAt))
+			b.WriteString(", ")
+			b.WriteString(arg(cursor.ID))
+			b.WriteString(")")
+		}
+		b.WriteString(" ORDER BY t.created_at DESC, t.id DESC LIMIT ")
+	} else {
+		writeTaskKeyset(&b, arg, sort, cursor)
+		b.WriteString(" LIMIT ")
+	}
 	b.WriteString(arg(fetch))
 
 	rows, err := r.db.QueryContext(ctx, b.String(), args...)
@@ -91,8 +427,8 @@ func (r *TaskRepo) List(
 
 	var out []domain.Task
 	for rows.Next() {
-		var t domain.Task
-		if err := rows.Scan(&t.ID, &t.ProjectID, &t.Title, &t.Completed, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		t, err := scanTask(rows)
+		if err != nil {
 			return nil, nil, err
 		}
 		out = append(out, t)
@@ -105,47 +441,430 @@ func (r *TaskRepo) List(
 	if len(out) > limit {
 		last := out[limit-1]
 		next = &domain.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+		if len(sort) > 0 {
+			next.Sort = domain.FormatSort(sort)
+			for _, k := range sort {
+				next.Values = append(next.Values, taskSortValue(last, k))
+			}
+		}
 		out = out[:limit]
 	}
 
 	return out, next, nil
 }
 
+// writeTaskKeyset appends the cursor predicate and ORDER BY clause for a
+// multi-key sort. Keys may mix directions, so the predicate is expanded as
+// (k1 > v1) OR (k1 = v1 AND k2 < v2) OR ... rather than a row comparison.
+// created_at DESC and id DESC are always appended as tie-breakers.
+func writeTaskKeyset(b *strings.Builder, arg func(any) string, sort []domain.SortKey, cursor *domain.Cursor) {
+	type term struct {
+		expr string
+		desc bool
+		val  string
+	}
+
+	terms := make([]term, 0, len(sort)+2)
+	hasCreated := false
+	for i, k := range sort {
+		expr, typ := taskSortExpr(k, arg)
+		t := term{expr: expr, desc: k.Desc}
+		if cursor != nil {
+			t.val = arg(cursor.Values[i]) + "::text::" + typ
+		}
+		terms = append(terms, t)
+		hasCreated = hasCreated || k.Field == "createdAt"
+	}
+	if !hasCreated {
+		t := term{expr: "t.created_at", desc: true}
+		if cursor != nil {
+			t.val = arg(cursor.CreatedAt)
+		}
+		terms = append(terms, t)
+	}
+	idTerm := term{expr: "t.id", desc: true}
+	if cursor != nil {
+		idTerm.val = arg(cursor.ID) + "::uuid"
+	}
+	terms = append(terms, idTerm)
+
+	if cursor != nil {
+		b.WriteString(" AND (")
+		for i, t := range terms {
+			if i > 0 {
+				b.WriteString(" OR ")
+			}
+			b.WriteString("(")
+			for _, eq := range terms[:i] {
+				b.WriteString(eq.expr + " = " + eq.val + " AND ")
+			}
+			op := " > "
+			if t.desc {
+				op = " < "
+			}
+			b.WriteString(t.expr + op + t.val)
+			b.WriteString(")")
+		}
+		b.WriteString(")")
+	}
+
+	b.WriteString(" ORDER BY ")
+	for i, t := range terms {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(t.expr)
+		if t.desc {
+			b.WriteString(" DESC")
+		} else {
+			b.WriteString(" ASC")
+		}
+	}
+}
+
 func (r *TaskRepo) Get(ctx context.Context, userID, taskID string) (domain.Task, error) {
-	var t domain.Task
-	err := r.db.QueryRowContext(ctx, `
-		SELECT t.id, t.project_id, t.title, t.completed, t.created_at, t.updated_at
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+taskColumns+`
 		FROM tasks t
 		JOIN projects p ON p.id = t.project_id
-		WHERE t.id = $1 AND p.user_id = $2
-	`, taskID, userID).Scan(&t.ID, &t.ProjectID, &t.Title, &t.Completed, &t.CreatedAt, &t.UpdatedAt)
-	return t, err
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, taskID, userID)
+	return scanTask(row)
+}
+
+// GetByKey returns the task numbered number in the project that has, or
+// had, the key projectKey. It returns sql.ErrNoRows if there is no such
+// task owned by userID.
+func (r *TaskRepo) GetByKey(ctx context.Context, userID, projectKey string, number int) (domain.Task, error) {
+	row := r.db.QueryRowContext(ctx, `
+		SELECT `+taskColumns+`
+		FROM project_keys k
+		JOIN tasks t ON t.id = COALESCE(
+			(SELECT c.id FROM tasks c WHERE c.project_id = k.project_id AND c.number = $3),
+			(SELECT f.task_id FROM task_former_numbers f WHERE f.project_id = k.project_id AND f.number = $3)
+		)
+		JOIN projects p ON p.id = t.project_id
+		WHERE k.user_id = $1 AND k.key = $2 AND p.user_id = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, userID, projectKey, number)
+	return scanTask(row)
+}
+
+// ProjectArchived reports whether an owned project is archived. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID.
+func (r *TaskRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return projectArchived(ctx, r.db, userID, projectID)
+}
+
+func projectArchived(ctx context.Context, db *sql.DB, userID, projectID string) (bool, error) {
+	var archived bool
+	err := db.QueryRowContext(ctx, `
+		SELECT archived_at IS NOT NULL FROM projects WHERE id = $1 AND user_id = $2 AND deleted_at IS NULL
+	`, projectID, userID).Scan(&archived)
+	return archived, err
+}
+
+// taskArchived reports whether the project of an owned task is archived.
+// It returns sql.ErrNoRows if the task does not exist or is not owned by
+// userID.
+func taskArchived(ctx context.Context, db *sql.DB, userID, taskID string) (bool, error) {
+	var archived bool
+	err := db.QueryRowContext(ctx, `
+		SELECT p.archived_at IS NOT NULL
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, taskID, userID).Scan(&archived)
+	return archived, err
+}
+
+// Ancestors returns the IDs of taskID's ancestors, nearest first. It returns
+// sql.ErrNoRows if the task does not exist or is not owned by userID.
+func (r *TaskRepo) Ancestors(ctx context.Context, userID, taskID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT t.id, t.parent_task_id, 0 AS lvl
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, t.parent_task_id, c.lvl + 1
+			FROM tasks t
+			JOIN chain c ON t.id = c.parent_task_id
+			WHERE c.lvl < 100
+		)
+		SELECT id FROM chain ORDER BY lvl
+	`, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return ids[1:], nil
 }
 
-func (r *TaskRepo) Update(ctx context.Context, userID, taskID string, title *string, completed *bool) (domain.Task, error) {
-	var t domain.Task
-	err := r.db.QueryRowContext(ctx, `
+// Subtree returns taskID and all of its descendants ordered by depth, so
+// every task appears after its parent. It returns sql.ErrNoRows if the task
+// does not exist or is not owned by userID.
+func (r *TaskRepo) Subtree(ctx context.Context, userID, taskID string) ([]domain.Task, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH RECURSIVE sub AS (
+			SELECT t.id, 0 AS depth
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+			UNION ALL
+			SELECT c.id, s.depth + 1
+			FROM tasks c
+			JOIN sub s ON c.parent_task_id = s.id
+			WHERE s.depth < 100 AND c.deleted_at IS NULL
+		)
+		SELECT `+taskColumns+`
+		FROM sub s
+		JOIN tasks t ON t.id = s.id
+		ORDER BY s.depth, t.created_at, t.id
+	`, taskID, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.Task
+	for rows.Next() {
+		t, err := scanTask(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, sql.ErrNoRows
+	}
+	return out, nil
+}
+
+func (r *TaskRepo) Update(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// The move comes first so that a new parent and custom field values in
+	// the same patch refer to the target project.
+	if patch.ProjectID != nil {
+		if err := moveToProject(ctx, tx, userID, taskID, *patch.ProjectID); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	if patch.ParentTaskID != nil {
+		if err := checkParent(ctx, tx, userID, taskID, *patch.ParentTaskID, patch.MaxDepth); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	// Descendants are completed first so the roll-up counts returned for
+	// the parent below already reflect them.
+	if patch.CompleteSubtasks && patch.Completed != nil && *patch.Completed {
+		if _, err := tx.ExecContext(ctx, `
+			WITH RECURSIVE sub AS (
+				SELECT t.id
+				FROM tasks t
+				JOIN projects p ON p.id = t.project_id
+				WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+				UNION ALL
+				SELECT c.id
+				FROM tasks c
+				JOIN sub s ON c.parent_task_id = s.id
+				WHERE c.deleted_at IS NULL
+			)
+			UPDATE tasks
+			SET completed = TRUE, completed_at = COALESCE($3, now()), completed_by = $4, updated_at = now()
+			WHERE id IN (SELECT id FROM sub) AND id <> $1 AND NOT completed
+		`, taskID, userID, patch.CompletedAt, patch.CompletedBy); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	// Values are written first so that the row returned below includes them.
+	if err := writeCustomValues(ctx, tx, userID, taskID, patch.CustomFields); err != nil {
+		return domain.Task{}, err
+	}
+
+	var priority *int
+	if patch.Priority != nil {
+		v := int(*patch.Priority)
+		priority = &v
+	}
+	var status *string
+	if patch.Status != nil {
+		v := string(*patch.Status)
+		status = &v
+	}
+	rule, tz, from := recurrenceArgs(patch.Recurrence)
+	// The completion is stamped only when completed actually flips, so a
+	// repeated completion keeps the original time and actor.
+	row := tx.QueryRowContext(ctx, `
 		UPDATE tasks t
 		SET
 			title = COALESCE($3, t.title),
 			completed = COALESCE($4, t.completed),
+			completed_at = CASE
+				WHEN $4 IS NULL OR $4 = t.completed THEN t.completed_at
+				WHEN $4 THEN COALESCE($20, now())
+			END,
+			completed_by = CASE
+				WHEN $4 IS NULL OR $4 = t.completed THEN t.completed_by
+				WHEN $4 THEN $21
+			END,
+			priority = COALESCE($5, t.priority),
+			due_date = CASE WHEN $7 THEN NULL ELSE COALESCE($6, t.due_date) END,
+			description = COALESCE($8, t.description),
+			parent_task_id = CASE WHEN $10 THEN NULL ELSE COALESCE($9, t.parent_task_id) END,
+			recurrence_rule = CASE WHEN $14 THEN NULL ELSE COALESCE($11, t.recurrence_rule) END,
+			recurrence_timezone = CASE WHEN $14 THEN NULL ELSE COALESCE($12, t.recurrence_timezone) END,
+			recurrence_from = CASE WHEN $14 THEN NULL ELSE COALESCE($13, t.recurrence_from) END,
+			status = COALESCE($15, t.status),
+			estimate = CASE WHEN $17 THEN NULL ELSE COALESCE($16, t.estimate) END,
+			sprint_id = CASE WHEN $19 THEN NULL ELSE COALESCE($18, t.sprint_id) END,
 			updated_at = now()
 		FROM projects p
 		WHERE p.id = t.project_id
 		  AND p.user_id = $2
 		  AND t.id = $1
-		RETURNING t.id, t.project_id, t.title, t.completed, t.created_at, t.updated_at
-	`, taskID, userID, title, completed).Scan(&t.ID, &t.ProjectID, &t.Title, &t.Completed, &t.CreatedAt, &t.UpdatedAt)
-	return t, err
+		  AND t.deleted_at IS NULL
+		  AND p.deleted_at IS NULL
+		RETURNING `+taskColumns,
+		taskID, userID, patch.Title, patch.Completed, priority, patch.DueDate, patch.ClearDueDate, patch.Description,
+		patch.ParentTaskID, patch.ClearParent, rule, tz, from, patch.ClearRecurrence, status,
+		patch.Estimate, patch.ClearEstimate, patch.SprintID, patch.ClearSprint, patch.CompletedAt, patch.CompletedBy)
+	t, err := scanTask(row)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return t, tx.Commit()
 }
 
-func (r *TaskRepo) Delete(ctx context.Context, userID, taskID string) error {
-	res, err := r.db.ExecContext(ctx, `
-		DELETE FROM tasks t
-		USING projects p
-		WHERE p.id = t.project_id
-		  AND p.user_id = $2
-		  AND t.id = $1
+// checkParent validates moving taskID and its subtree under parentID. The
+// task's project is locked first, so that concurrent reparents in it cannot
+// together close a cycle or exceed maxDepth. It returns sql.ErrNoRows if
+// the task does not exist or is not owned by userID, domain.ErrInvalidParent
+// if parentID is not a task of the same project, domain.ErrParentCycle if
+// it is the task or one of its subtasks, and domain.ErrTooDeep if the
+// subtree would end up deeper than maxDepth.
+func checkParent(ctx context.Context, tx *sql.Tx, userID, taskID, parentID string, maxDepth int) error {
+	var projectID string
+	err := tx.QueryRowContext(ctx, `
+		SELECT p.id
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		FOR NO KEY UPDATE OF p
+	`, taskID, userID).Scan(&projectID)
+	if err != nil {
+		return err
+	}
+
+	var (
+		depth int
+		cycle bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		WITH RECURSIVE chain AS (
+			SELECT id, parent_task_id FROM tasks WHERE id = $1 AND project_id = $2 AND deleted_at IS NULL
+			UNION ALL
+			SELECT t.id, t.parent_task_id FROM tasks t JOIN chain c ON t.id = c.parent_task_id
+		)
+		SELECT count(*) - 1, COALESCE(bool_or(id = $3), false) FROM chain
+	`, parentID, projectID, taskID).Scan(&depth, &cycle)
+	switch {
+	case err != nil:
+		return err
+	case depth < 0:
+		return domain.ErrInvalidParent
+	case cycle:
+		return domain.ErrParentCycle
+	case maxDepth <= 0:
+		return nil
+	}
+
+	var height int
+	if err := tx.QueryRowContext(ctx, `
+		WITH RECURSIVE sub AS (
+			SELECT id, 0 AS lvl FROM tasks WHERE id = $1
+			UNION ALL
+			SELECT c.id, s.lvl + 1 FROM tasks c JOIN sub s ON c.parent_task_id = s.id WHERE c.deleted_at IS NULL
+		)
+		SELECT max(lvl) FROM sub
+	`, taskID).Scan(&height); err != nil {
+		return err
+	}
+	if depth+1+height > maxDepth {
+		return domain.ErrTooDeep
+	}
+	return nil
+}
+
+func (r *TaskRepo) Delete(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	// Subtasks go to the trash with the task unless they are first moved
+	// up to the task's own parent. The whole subtree shares one deleted_at,
+	// which is how a restore finds it again.
+	if children == domain.ChildrenReparent {
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE tasks c
+			SET parent_task_id = t.parent_task_id, updated_at = now()
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE c.parent_task_id = t.id
+			  AND c.deleted_at IS NULL
+			  AND p.user_id = $2
+			  AND p.deleted_at IS NULL
+			  AND t.id = $1
+			  AND t.deleted_at IS NULL
+		`, taskID, userID); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `
+		WITH RECURSIVE sub AS (
+			SELECT t.id
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+			UNION ALL
+			SELECT c.id
+			FROM tasks c
+			JOIN sub s ON c.parent_task_id = s.id
+			WHERE c.deleted_at IS NULL
+		)
+		UPDATE tasks t
+		SET deleted_at = now()
+		FROM sub
+		WHERE t.id = sub.id
 	`, taskID, userID)
 	if err != nil {
 		return err
@@ -157,5 +876,349 @@ func (r *TaskRepo) Delete(ctx context.Context, userID, taskID string) error {
 	if n == 0 {
 		return sql.ErrNoRows
 	}
-	return nil
+	return tx.Commit()
+}
+
+// Move places taskID between two tasks of its project in the manual order,
+// rewriting only taskID's position. afterID is the task it should follow
+// and beforeID the task it should precede; when only one is given the
+// other is that task's current neighbor. It returns sql.ErrNoRows if taskID
+// is missing or not owned by userID, and domain.ErrInvalidNeighbor if a
+// neighbor is missing, in another project, or the two are not adjacent in
+// that order once taskID is left out.
+func (r *TaskRepo) Move(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var projectID string
+	err = tx.QueryRowContext(ctx, `
+		SELECT p.id
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		FOR NO KEY UPDATE OF p
+	`, taskID, userID).Scan(&projectID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	neighbor := func(id string) (string, error) {
+		var pos string
+		err := tx.QueryRowContext(ctx, `
+			SELECT position FROM tasks WHERE id = $1 AND project_id = $2 AND id <> $3 AND deleted_at IS NULL
+		`, id, projectID, taskID).Scan(&pos)
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", domain.ErrInvalidNeighbor
+		}
+		return pos, err
+	}
+	// adjacent finds the closest position on one side of pos, ignoring the
+	// task being moved; an empty result means pos is at that end.
+	adjacent := func(pos string, below bool) (string, error) {
+		q := `SELECT max(position) FROM tasks WHERE project_id = $1 AND id <> $2 AND position < $3 AND deleted_at IS NULL`
+		if !below {
+			q = `SELECT min(position) FROM tasks WHERE project_id = $1 AND id <> $2 AND position > $3 AND deleted_at IS NULL`
+		}
+		var out sql.NullString
+		err := tx.QueryRowContext(ctx, q, projectID, taskID, pos).Scan(&out)
+		return out.String, err
+	}
+
+	var lo, hi string
+	if afterID != nil {
+		if lo, err = neighbor(*afterID); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if beforeID != nil {
+		if hi, err = neighbor(*beforeID); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	switch {
+	case afterID == nil:
+		lo, err = adjacent(hi, true)
+	case beforeID == nil:
+		hi, err = adjacent(lo, false)
+	default:
+		// Both neighbors were given, so nothing may sit between them.
+		var next string
+		if next, err = adjacent(lo, false); err == nil && next != hi {
+			err = domain.ErrInvalidNeighbor
+		}
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	position, err := fracindex.Between(lo, hi)
+	if errors.Is(err, fracindex.ErrOutOfOrder) {
+		return domain.Task{}, domain.ErrInvalidNeighbor
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+
+	row := tx.QueryRowContext(ctx, `
+		UPDATE tasks t
+		SET position = $2, updated_at = now()
+		WHERE t.id = $1
+		RETURNING `+taskColumns,
+		taskID, position)
+	t, err := scanTask(row)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return t, tx.Commit()
+}
+
+// ProjectsToRebalance returns up to limit projects with a position key
+// longer than maxLen or two tasks sharing a key.
+func (r *TaskRepo) ProjectsToRebalance(ctx context.Context, maxLen, limit int) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT project_id
+		FROM tasks
+		GROUP BY project_id
+		HAVING max(length(position)) > $1 OR count(*) > count(DISTINCT position)
+		LIMIT $2
+	`, maxLen, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
+
+// RebalancePositions rewrites every position in a project with short,
+// evenly spaced keys, keeping the current order.
+func (r *TaskRepo) RebalancePositions(ctx context.Context, projectID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var locked string
+	if err := tx.QueryRowContext(ctx, `
+		SELECT id FROM projects WHERE id = $1 FOR NO KEY UPDATE
+	`, projectID).Scan(&locked); err != nil {
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		SELECT id FROM tasks WHERE project_id = $1 ORDER BY position, created_at, id
+	`, projectID)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	// Rebalancing is bookkeeping, so updated_at is left alone.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tasks t
+		SET position = k.position
+		FROM unnest($1::uuid[], $2::text[]) AS k(id, position)
+		WHERE t.id = k.id
+	`, ids, fracindex.Spread(len(ids))); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Board returns the requested status columns of a project in one query:
+// each column's total and up to q.Limit tasks in manual order, starting
+// after the column's cursor if one is given. It returns sql.ErrNoRows if
+// the project does not exist or is not owned by userID.
+func (r *TaskRepo) Board(ctx context.Context, userID, projectID string, q domain.BoardQuery) ([]domain.BoardColumn, error) {
+	statuses := make([]string, len(q.Statuses))
+	afterPos := make([]*string, len(q.Statuses))
+	afterID := make([]*string, len(q.Statuses))
+	for i, st := range q.Statuses {
+		statuses[i] = string(st)
+		if c, ok := q.Cursors[st]; ok {
+			afterPos[i], afterID[i] = &c.Values[1], &c.ID
+		}
+	}
+
+	// Every column yields at least one row, with a NULL task when the page
+	// is empty, so that counts and ownership survive empty columns. One
+	// extra task per column tells whether it has another page.
+	rows, err := r.db.QueryContext(ctx, `
+		WITH cols AS (
+			SELECT * FROM unnest($3::text[], $4::text[], $5::uuid[])
+				WITH ORDINALITY AS c(status, after_pos, after_id, ord)
+		)
+		SELECT c.status,
+			(SELECT count(*) FROM tasks n WHERE n.project_id = p.id AND n.status = c.status AND n.deleted_at IS NULL),
+			b.id IS NOT NULL,
+			b.*
+		FROM projects p
+		CROSS JOIN cols c
+		LEFT JOIN LATERAL (
+			SELECT `+taskColumns+`
+			FROM tasks t
+			WHERE t.project_id = p.id
+			  AND t.deleted_at IS NULL
+			  AND t.status = c.status
+			  AND (c.after_pos IS NULL OR (t.position, t.id) > (c.after_pos COLLATE "C", c.after_id))
+			ORDER BY t.position, t.id
+			LIMIT $6
+		) b ON TRUE
+		WHERE p.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		ORDER BY c.ord, b.position, b.id
+	`, projectID, userID, statuses, afterPos, afterID, q.Limit+1)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		status  domain.TaskStatus
+		count   int
+		hasTask bool
+	)
+	// The leading columns are scanned first; the task columns are only
+	// scanned when present, since they are all NULL otherwise.
+	head := []any{&status, &count, &hasTask}
+	for len(head) < len(cols) {
+		head = append(head, new(any))
+	}
+
+	var out []domain.BoardColumn
+	for rows.Next() {
+		if err := rows.Scan(head...); err != nil {
+			return nil, err
+		}
+		if len(out) == 0 || out[len(out)-1].Status != status {
+			out = append(out, domain.BoardColumn{Status: status, Count: count, Tasks: []domain.Task{}})
+		}
+		if !hasTask {
+			continue
+		}
+		t, err := scanTask(skipScanner{rows, 3})
+		if err != nil {
+			return nil, err
+		}
+		col := &out[len(out)-1]
+		col.Tasks = append(col.Tasks, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(out) == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	for i := range out {
+		col := &out[i]
+		if len(col.Tasks) > q.Limit {
+			last := col.Tasks[q.Limit-1]
+			col.NextCursor = &domain.Cursor{
+				CreatedAt: last.CreatedAt,
+				ID:        last.ID,
+				Sort:      domain.BoardSort,
+				Values:    []string{string(col.Status), last.Position},
+			}
+			col.Tasks = col.Tasks[:q.Limit]
+		}
+	}
+	return out, nil
+}
+
+// skipScanner scans a row whose first n columns have already been read.
+type skipScanner struct {
+	rows *sql.Rows
+	n    int
+}
+
+func (s skipScanner) Scan(dest ...any) error {
+	all := make([]any, s.n, s.n+len(dest))
+	for i := range all {
+		all[i] = new(any)
+	}
+	return s.rows.Scan(append(all, dest...)...)
+}
+
+// Burndown counts, for each day from q.From to q.To, the project's tasks
+// open and completed at the end of that day in q.Location, replaying
+// completion history so reopened tasks count as open again. It returns
+// sql.ErrNoRows if the project does not exist or is not owned by userID.
+func (r *TaskRepo) Burndown(ctx context.Context, userID, projectID string, q domain.BurndownQuery) (domain.Burndown, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		WITH days AS (
+			SELECT p.estimate_unit, d::date AS day, (d + interval '1 day') AT TIME ZONE $5::text AS cutoff
+			FROM projects p
+			CROSS JOIN generate_series($3::text::date::timestamp, $4::text::date::timestamp, interval '1 day') d
+			WHERE p.id = $1 AND p.user_id = $2 AND p.deleted_at IS NULL
+		)
+		SELECT d.estimate_unit, to_char(d.day, 'YYYY-MM-DD'),
+			count(x.done) FILTER (WHERE NOT x.done),
+			count(x.done) FILTER (WHERE x.done),
+			COALESCE(sum(x.estimate) FILTER (WHERE NOT x.done), 0)::float8,
+			COALESCE(sum(x.estimate) FILTER (WHERE x.done), 0)::float8
+		FROM days d
+		LEFT JOIN LATERAL (
+			SELECT t.estimate,
+				COALESCE((
+					SELECT e.completed
+					FROM task_completion_events e
+					WHERE e.task_id = t.id AND e.at < d.cutoff
+					ORDER BY e.at DESC, e.id DESC
+					LIMIT 1
+				), FALSE) AS done
+			FROM tasks t
+			WHERE t.project_id = $1 AND t.deleted_at IS NULL AND t.created_at < d.cutoff
+		) x ON TRUE
+		GROUP BY d.estimate_unit, d.day
+		ORDER BY d.day
+	`, projectID, userID, q.From.Format("2006-01-02"), q.To.Format("2006-01-02"), q.Location.String())
+	if err != nil {
+		return domain.Burndown{}, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	b := domain.Burndown{ProjectID: projectID, Timezone: q.Location.String(), Days: []domain.BurndownDay{}}
+	for rows.Next() {
+		var day domain.BurndownDay
+		if err := rows.Scan(&b.EstimateUnit, &day.Date, &day.RemainingTasks, &day.CompletedTasks,
+			&day.RemainingEstimate, &day.CompletedEstimate); err != nil {
+			return domain.Burndown{}, err
+		}
+		b.Days = append(b.Days, day)
+	}
+	if err := rows.Err(); err != nil {
+		return domain.Burndown{}, err
+	}
+	if len(b.Days) == 0 {
+		return domain.Burndown{}, sql.ErrNoRows
+	}
+	return b, nil
 }
@@ -0,0 +1,308 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"slices"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/fracindex"
+
+	"github.com/google/uuid"
+)
+
+// moveToProject moves taskID and its subtree to projectID, where taskID
+// becomes a top-level task and the subtree is appended to the manual order
+// keeping its relative order. The moved tasks are numbered afresh in the
+// target project; their old numbers go to task_former_numbers, so their old
+// keys still resolve. Subtasks in the trash stay behind and come back as
+// top-level tasks of the source project if restored. Labels and custom
+// field values go along: each is matched by name in the target project and
+// created there if missing. User values naming someone who may not read
+// the target are dropped. Sprints belong to the source project, so the
+// moved tasks leave theirs. It returns sql.ErrNoRows if the task does not
+// exist or is not owned by userID, and domain.ErrInvalidProject if the
+// target project is not owned by userID.
+func moveToProject(ctx context.Context, tx *sql.Tx, userID, taskID, projectID string) error {
+	var from string
+	err := tx.QueryRowContext(ctx, `
+		SELECT t.project_id
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, taskID, userID).Scan(&from)
+	if err != nil {
+		return err
+	}
+	if from == projectID {
+		return nil
+	}
+
+	// Both projects are locked in a fixed order so that moves in opposite
+	// directions cannot deadlock.
+	locks := []string{from, projectID}
+	slices.Sort(locks)
+	for _, id := range locks {
+		err := lockProject(ctx, tx, userID, id)
+		if errors.Is(err, sql.ErrNoRows) && id == projectID {
+			return domain.ErrInvalidProject
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+		WITH RECURSIVE sub AS (
+			SELECT id FROM tasks WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM tasks c JOIN sub s ON c.parent_task_id = s.id WHERE c.deleted_at IS NULL
+		)
+		SELECT t.id FROM sub JOIN tasks t ON t.id = sub.id
+		ORDER BY t.position, t.created_at, t.id
+	`, taskID)
+	if err != nil {
+		return err
+	}
+	var ids []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		ids = append(ids, id)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	if err := transferLabels(ctx, tx, ids, projectID); err != nil {
+		return err
+	}
+	if err := transferCustomValues(ctx, tx, ids, projectID); err != nil {
+		return err
+	}
+
+	positions := make([]string, len(ids))
+	last, err := nextPosition(ctx, tx, projectID)
+	if err != nil {
+		return err
+	}
+	for i := range ids {
+		if i > 0 {
+			if last, err = fracindex.Between(last, ""); err != nil {
+				return err
+			}
+		}
+		positions[i] = last
+	}
+	first, err := allocTaskNumbers(ctx, tx, projectID, len(ids))
+	if err != nil {
+		return err
+	}
+	numbers := make([]int64, len(ids))
+	for i := range ids {
+		numbers[i] = int64(first + i)
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		INSERT INTO task_former_numbers (project_id, number, task_id)
+		SELECT project_id, number, id FROM tasks WHERE id = ANY($1::uuid[])
+	`, ids); err != nil {
+		return err
+	}
+
+	// Subtasks follow through the ON UPDATE CASCADE parent key, so the ones
+	// in the trash are cut loose first. The moved ones' old numbers may clash
+	// in the target until the renumbering below, which the deferred
+	// uniqueness check tolerates.
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tasks SET parent_task_id = NULL, updated_at = now()
+		WHERE parent_task_id = ANY($1::uuid[]) AND deleted_at IS NOT NULL
+	`, ids); err != nil {
+		return err
+	}
+	if _, err := tx.ExecContext(ctx, `
+		UPDATE tasks SET project_id = $2, parent_task_id = NULL, updated_at = now() WHERE id = $1
+	`, taskID, projectID); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+		UPDATE tasks t
+		SET position = k.position, number = k.number, sprint_id = NULL
+		FROM unnest($1::uuid[], $2::text[], $3::int[]) AS k(id, position, number)
+		WHERE t.id = k.id
+	`, ids, positions, numbers)
+	return err
+}
+
+// transferLabels repoints the labels of the tasks ids to the same-named
+// labels of projectID, creating any that the project lacks.
+func transferLabels(ctx context.Context, tx *sql.Tx, ids []string, projectID string) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT DISTINCT l.id, l.name, l.color
+		FROM task_labels tl
+		JOIN labels l ON l.id = tl.label_id
+		WHERE tl.task_id = ANY($1::uuid[])
+	`, ids)
+	if err != nil {
+		return err
+	}
+	var labels []domain.Label
+	for rows.Next() {
+		var l domain.Label
+		if err := rows.Scan(&l.ID, &l.Name, &l.Color); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		labels = append(labels, l)
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, l := range labels {
+		var target string
+		err := tx.QueryRowContext(ctx, `
+			SELECT id FROM labels WHERE project_id = $1 AND lower(name) = lower($2)
+		`, projectID, l.Name).Scan(&target)
+		if errors.Is(err, sql.ErrNoRows) {
+			target = uuid.NewString()
+			_, err = tx.ExecContext(ctx, `
+				INSERT INTO labels (id, project_id, name, color) VALUES ($1, $2, $3, $4)
+			`, target, projectID, l.Name, l.Color)
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE task_labels SET label_id = $2 WHERE label_id = $1 AND task_id = ANY($3::uuid[])
+		`, l.ID, target, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// transferCustomValues repoints the custom field values of the tasks ids
+// to the same-named fields of projectID. A missing field is created with
+// the source field's type and options, and select options used by the
+// values are added to an existing field. Values for a field whose
+// namesake in projectID has another type are dropped, as are user values
+// naming someone who may not read projectID.
+func transferCustomValues(ctx context.Context, tx *sql.Tx, ids []string, projectID string) error {
+	rows, err := tx.QueryContext(ctx, `
+		SELECT `+customFieldColumns+`, v.value
+		FROM task_custom_values v
+		JOIN custom_fields f ON f.id = v.field_id
+		WHERE v.task_id = ANY($1::uuid[])
+		ORDER BY f.id
+	`, ids)
+	if err != nil {
+		return err
+	}
+	var (
+		fields []domain.CustomField
+		used   = map[string][]string{}
+	)
+	for rows.Next() {
+		var (
+			f       domain.CustomField
+			options []byte
+			value   []byte
+		)
+		if err := rows.Scan(&f.ID, &f.ProjectID, &f.Name, &f.Type, &options, &f.CreatedAt, &f.UpdatedAt, &value); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		if len(fields) == 0 || fields[len(fields)-1].ID != f.ID {
+			if err := json.Unmarshal(options, &f.Options); err != nil {
+				_ = rows.Close()
+				return err
+			}
+			fields = append(fields, f)
+		}
+		switch f.Type {
+		case domain.FieldSingleSelect:
+			var o string
+			_ = json.Unmarshal(value, &o)
+			used[f.ID] = append(used[f.ID], o)
+		case domain.FieldMultiSelect:
+			var opts []string
+			_ = json.Unmarshal(value, &opts)
+			used[f.ID] = append(used[f.ID], opts...)
+		}
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, f := range fields {
+		target, err := scanCustomField(tx.QueryRowContext(ctx, `
+			SELECT `+customFieldColumns+` FROM custom_fields f WHERE f.project_id = $1 AND lower(f.name) = lower($2)
+		`, projectID, f.Name))
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			target = domain.CustomField{ID: uuid.NewString()}
+			options, err := optionsArg(f.Options)
+			if err != nil {
+				return err
+			}
+			if _, err := tx.ExecContext(ctx, `
+				INSERT INTO custom_fields (id, project_id, name, type, options) VALUES ($1, $2, $3, $4, $5::jsonb)
+			`, target.ID, projectID, f.Name, string(f.Type), options); err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		case target.Type != f.Type:
+			if _, err := tx.ExecContext(ctx, `
+				DELETE FROM task_custom_values WHERE field_id = $1 AND task_id = ANY($2::uuid[])
+			`, f.ID, ids); err != nil {
+				return err
+			}
+			continue
+		default:
+			missing := false
+			for _, o := range used[f.ID] {
+				if !slices.Contains(target.Options, o) {
+					target.Options = append(target.Options, o)
+					missing = true
+				}
+			}
+			if missing {
+				options, err := optionsArg(target.Options)
+				if err != nil {
+					return err
+				}
+				if _, err := tx.ExecContext(ctx, `
+					UPDATE custom_fields SET options = $2::jsonb, updated_at = now() WHERE id = $1
+				`, target.ID, options); err != nil {
+					return err
+				}
+			}
+		}
+		if f.Type == domain.FieldUser {
+			if _, err := tx.ExecContext(ctx, `
+				DELETE FROM task_custom_values v
+				USING projects p
+				WHERE v.field_id = $1 AND v.task_id = ANY($2::uuid[]) AND p.id = $3
+				  AND v.value #>> '{}' IS DISTINCT FROM p.user_id::text
+			`, f.ID, ids, projectID); err != nil {
+				return err
+			}
+		}
+		if _, err := tx.ExecContext(ctx, `
+			UPDATE task_custom_values SET field_id = $2 WHERE field_id = $1 AND task_id = ANY($3::uuid[])
+		`, f.ID, target.ID, ids); err != nil {
+			return err
+		}
+	}
+	return nil
+}
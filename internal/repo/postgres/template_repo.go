@@ -0,0 +1,111 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type TemplateRepo struct{ db *sql.DB }
+
+func NewTemplateRepo(db *sql.DB) *TemplateRepo { return &TemplateRepo{db: db} }
+
+// templateBody is the part of a template stored in the body column.
+type templateBody struct {
+	ProjectName  string                 `json:"projectName"`
+	EstimateUnit domain.EstimateUnit    `json:"estimateUnit"`
+	Labels       []domain.TemplateLabel `json:"labels"`
+	Tasks        []domain.TemplateTask  `json:"tasks"`
+}
+
+func scanTemplate(s rowScanner) (domain.Template, error) {
+	var (
+		t    domain.Template
+		raw  []byte
+		body templateBody
+	)
+	if err := s.Scan(&t.ID, &t.Name, &raw, &t.CreatedAt, &t.UpdatedAt); err != nil {
+		return t, err
+	}
+	if err := json.Unmarshal(raw, &body); err != nil {
+		return t, err
+	}
+	t.ProjectName, t.EstimateUnit, t.Labels, t.Tasks = body.ProjectName, body.EstimateUnit, body.Labels, body.Tasks
+	return t, nil
+}
+
+// Create stores a template for userID. It returns domain.ErrDuplicate if
+// the user already has a template by that name.
+func (r *TemplateRepo) Create(ctx context.Context, userID string, t domain.Template) (domain.Template, error) {
+	body, err := json.Marshal(templateBody{
+		ProjectName:  t.ProjectName,
+		EstimateUnit: t.EstimateUnit,
+		Labels:       t.Labels,
+		Tasks:        t.Tasks,
+	})
+	if err != nil {
+		return domain.Template{}, err
+	}
+	out, err := scanTemplate(r.db.QueryRowContext(ctx, `
+		INSERT INTO project_templates (id, user_id, name, body)
+		VALUES ($1, $2, $3, $4::jsonb)
+		RETURNING id, name, body, created_at, updated_at
+	`, uuid.NewString(), userID, t.Name, string(body)))
+	if isUniqueViolation(err) {
+		return domain.Template{}, domain.ErrDuplicate
+	}
+	return out, err
+}
+
+// List returns the user's templates ordered by name.
+func (r *TemplateRepo) List(ctx context.Context, userID string) ([]domain.Template, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT id, name, body, created_at, updated_at
+		FROM project_templates
+		WHERE user_id = $1
+		ORDER BY lower(name), id
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Template{}
+	for rows.Next() {
+		t, err := scanTemplate(rows)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
+
+func (r *TemplateRepo) Get(ctx context.Context, userID, templateID string) (domain.Template, error) {
+	return scanTemplate(r.db.QueryRowContext(ctx, `
+		SELECT id, name, body, created_at, updated_at
+		FROM project_templates
+		WHERE id = $1 AND user_id = $2
+	`, templateID, userID))
+}
+
+func (r *TemplateRepo) Delete(ctx context.Context, userID, templateID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM project_templates WHERE id = $1 AND user_id = $2
+	`, templateID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
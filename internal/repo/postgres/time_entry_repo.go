@@ -0,0 +1,259 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"strconv"
+	"strings"
+	"time"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type TimeEntryRepo struct{ db *sql.DB }
+
+func NewTimeEntryRepo(db *sql.DB) *TimeEntryRepo { return &TimeEntryRepo{db: db} }
+
+// timeEntryColumns selects a time entry aliased as e. A running entry's
+// duration is measured up to now.
+const timeEntryColumns = `
+	e.id, e.task_id, e.user_id, e.started_at, e.ended_at, e.note,
+	e.ended_at IS NULL,
+	floor(extract(epoch FROM COALESCE(e.ended_at, now()) - e.started_at))::bigint,
+	e.created_at, e.updated_at`
+
+func scanTimeEntry(s rowScanner) (domain.TimeEntry, error) {
+	var e domain.TimeEntry
+	var endedAt sql.NullTime
+	if err := s.Scan(&e.ID, &e.TaskID, &e.UserID, &e.StartedAt, &endedAt, &e.Note,
+		&e.Running, &e.DurationSeconds, &e.CreatedAt, &e.UpdatedAt); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	if endedAt.Valid {
+		e.EndedAt = &endedAt.Time
+	}
+	return e, nil
+}
+
+// Start begins a timer for userID on taskID. It returns sql.ErrNoRows if
+// the task does not exist or is not owned by userID, and
+// domain.ErrDuplicate if the user already has a running timer.
+func (r *TimeEntryRepo) Start(ctx context.Context, userID, taskID string, at time.Time, note string) (domain.TimeEntry, error) {
+	e, err := scanTimeEntry(r.db.QueryRowContext(ctx, `
+		INSERT INTO time_entries AS e (id, task_id, user_id, started_at, note)
+		SELECT $1, t.id, $2, $3, $4
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $5 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		RETURNING `+timeEntryColumns,
+		uuid.NewString(), userID, at, note, taskID))
+	if isUniqueViolation(err) {
+		return domain.TimeEntry{}, domain.ErrDuplicate
+	}
+	return e, err
+}
+
+// Stop ends userID's running timer at at, or at its start if at is earlier.
+// It returns sql.ErrNoRows if no timer is running.
+func (r *TimeEntryRepo) Stop(ctx context.Context, userID string, at time.Time) (domain.TimeEntry, error) {
+	return scanTimeEntry(r.db.QueryRowContext(ctx, `
+		UPDATE time_entries e
+		SET ended_at = GREATEST($2, e.started_at), updated_at = now()
+		WHERE e.user_id = $1 AND e.ended_at IS NULL
+		RETURNING `+timeEntryColumns,
+		userID, at))
+}
+
+// Running returns userID's running timer, or sql.ErrNoRows if there is none.
+func (r *TimeEntryRepo) Running(ctx context.Context, userID string) (domain.TimeEntry, error) {
+	return scanTimeEntry(r.db.QueryRowContext(ctx, `
+		SELECT `+timeEntryColumns+`
+		FROM time_entries e
+		WHERE e.user_id = $1 AND e.ended_at IS NULL
+	`, userID))
+}
+
+// Create logs a finished entry by userID on taskID. It returns
+// sql.ErrNoRows if the task does not exist or is not owned by userID.
+func (r *TimeEntryRepo) Create(ctx context.Context, userID, taskID string, in domain.TimeEntryInput) (domain.TimeEntry, error) {
+	return scanTimeEntry(r.db.QueryRowContext(ctx, `
+		INSERT INTO time_entries AS e (id, task_id, user_id, started_at, ended_at, note)
+		SELECT $1, t.id, $2, $3, $4, $5
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $6 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		RETURNING `+timeEntryColumns,
+		uuid.NewString(), userID, in.StartedAt, in.EndedAt, in.Note, taskID))
+}
+
+// TaskArchived reports whether the project of an owned task is archived.
+// It returns sql.ErrNoRows if the task does not exist or is not owned by
+// userID.
+func (r *TimeEntryRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	return taskArchived(ctx, r.db, userID, taskID)
+}
+
+// Get returns an entry logged by userID on one of their tasks.
+func (r *TimeEntryRepo) Get(ctx context.Context, userID, entryID string) (domain.TimeEntry, error) {
+	return scanTimeEntry(r.db.QueryRowContext(ctx, `
+		SELECT `+timeEntryColumns+`
+		FROM time_entries e
+		JOIN tasks t ON t.id = e.task_id
+		JOIN projects p ON p.id = t.project_id
+		WHERE e.id = $1 AND e.user_id = $2 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, entryID, userID))
+}
+
+// List returns a page of a task's entries, most recently started first. It
+// returns sql.ErrNoRows if the task does not exist or is not owned by userID.
+func (r *TimeEntryRepo) List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.TimeEntry, *domain.Cursor, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		)
+	`, taskID, userID).Scan(&exists); err != nil {
+		return nil, nil, err
+	}
+	if !exists {
+		return nil, nil, sql.ErrNoRows
+	}
+
+	q := `
+		SELECT ` + timeEntryColumns + `
+		FROM time_entries e
+		WHERE e.task_id = $1`
+	args := []any{taskID, limit + 1}
+	if cursor != nil {
+		q += ` AND (e.started_at, e.id) < ($3::text::timestamptz, $4::uuid)`
+		args = append(args, cursor.Values[0], cursor.ID)
+	}
+	q += ` ORDER BY e.started_at DESC, e.id DESC LIMIT $2`
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	var out []domain.TimeEntry
+	for rows.Next() {
+		e, err := scanTimeEntry(rows)
+		if err != nil {
+			return nil, nil, err
+		}
+		out = append(out, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	var next *domain.Cursor
+	if len(out) > limit {
+		last := out[limit-1]
+		next = &domain.Cursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+			Sort:      domain.TimeEntrySort,
+			Values:    []string{last.StartedAt.UTC().Format(time.RFC3339Nano)},
+		}
+		out = out[:limit]
+	}
+	return out, next, nil
+}
+
+// Update replaces an entry's times and note. A nil in.EndedAt leaves a
+// running timer running. It returns sql.ErrNoRows if userID did not log
+// the entry.
+func (r *TimeEntryRepo) Update(ctx context.Context, userID, entryID string, in domain.TimeEntryInput) (domain.TimeEntry, error) {
+	return scanTimeEntry(r.db.QueryRowContext(ctx, `
+		UPDATE time_entries e
+		SET started_at = $3, ended_at = COALESCE($4, e.ended_at), note = $5, updated_at = now()
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = e.task_id
+		  AND e.id = $1 AND e.user_id = $2 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		RETURNING `+timeEntryColumns,
+		entryID, userID, in.StartedAt, in.EndedAt, in.Note))
+}
+
+func (r *TimeEntryRepo) Delete(ctx context.Context, userID, entryID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		DELETE FROM time_entries e
+		USING tasks t, projects p
+		WHERE t.id = e.task_id AND p.id = t.project_id
+		  AND e.id = $1 AND e.user_id = $2 AND p.user_id = $2 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+	`, entryID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// Totals sums the time logged on userID's tasks within [q.From, q.To),
+// grouped by q.GroupBy, largest first. Entries straddling the range only
+// count for their overlap; running timers count up to now.
+func (r *TimeEntryRepo) Totals(ctx context.Context, userID string, q domain.TimeTotalsQuery) ([]domain.TimeTotal, error) {
+	var key, name string
+	switch q.GroupBy {
+	case domain.TimeByTask:
+		key, name = "t.id", "t.title"
+	case domain.TimeByProject:
+		key, name = "p.id", "p.name"
+	default:
+		key, name = "u.id", "u.email"
+	}
+
+	var b strings.Builder
+	args := []any{userID, q.From, q.To}
+	arg := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	b.WriteString(`
+		SELECT ` + key + `, ` + name + `,
+			floor(sum(extract(epoch FROM LEAST(COALESCE(e.ended_at, now()), $3) - GREATEST(e.started_at, $2))))::bigint
+		FROM time_entries e
+		JOIN tasks t ON t.id = e.task_id
+		JOIN projects p ON p.id = t.project_id
+		JOIN users u ON u.id = e.user_id
+		WHERE p.user_id = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL
+		  AND e.started_at < $3
+		  AND COALESCE(e.ended_at, now()) > $2`)
+	if q.ProjectID != nil {
+		b.WriteString(" AND p.id = " + arg(*q.ProjectID))
+	}
+	if q.TaskID != nil {
+		b.WriteString(" AND t.id = " + arg(*q.TaskID))
+	}
+	b.WriteString(" GROUP BY " + key + ", " + name + " ORDER BY 3 DESC, 1")
+
+	rows, err := r.db.QueryContext(ctx, b.String(), args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.TimeTotal{}
+	for rows.Next() {
+		var t domain.TimeTotal
+		if err := rows.Scan(&t.ID, &t.Name, &t.TotalSeconds); err != nil {
+			return nil, err
+		}
+		out = append(out, t)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,168 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"TaskFlow/internal/domain"
+)
+
+type TrashRepo struct{ db *sql.DB }
+
+func NewTrashRepo(db *sql.DB) *TrashRepo { return &TrashRepo{db: db} }
+
+// List returns a user's deleted projects and the deleted tasks of their
+// live projects, most recently deleted first. A task deleted together with
+// its parent is left out; it comes back when the parent does.
+func (r *TrashRepo) List(ctx context.Context, userID string) (domain.Trash, error) {
+	out := domain.Trash{Projects: []domain.TrashedProject{}, Tasks: []domain.TrashedTask{}}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT deleted_at, `+projectColumns+`
+		FROM projects
+		WHERE user_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id
+	`, userID)
+	if err != nil {
+		return out, err
+	}
+	for rows.Next() {
+		var deletedAt time.Time
+		p, err := scanProject(headScanner{rows, []any{&deletedAt}})
+		if err != nil {
+			_ = rows.Close()
+			return out, err
+		}
+		out.Projects = append(out.Projects, domain.TrashedProject{Project: p, DeletedAt: deletedAt})
+	}
+	_ = rows.Close()
+	if err := rows.Err(); err != nil {
+		return out, err
+	}
+
+	rows, err = r.db.QueryContext(ctx, `
+		SELECT t.deleted_at, `+taskColumns+`
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE p.user_id = $1
+		  AND p.deleted_at IS NULL
+		  AND t.deleted_at IS NOT NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM tasks pt WHERE pt.id = t.parent_task_id AND pt.deleted_at = t.deleted_at
+		  )
+		ORDER BY t.deleted_at DESC, t.id
+	`, userID)
+	if err != nil {
+		return out, err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var deletedAt time.Time
+		t, err := scanTask(headScanner{rows, []any{&deletedAt}})
+		if err != nil {
+			return out, err
+		}
+		out.Tasks = append(out.Tasks, domain.TrashedTask{Task: t, DeletedAt: deletedAt})
+	}
+	return out, rows.Err()
+}
+
+// RestoreProject takes an owned project, and with it all of its tasks, out
+// of the trash. It returns sql.ErrNoRows if the project is not in the
+// trash or is not owned by userID.
+func (r *TrashRepo) RestoreProject(ctx context.Context, userID, projectID string) (domain.Project, error) {
+	return scanProject(r.db.QueryRowContext(ctx, `
+		UPDATE projects
+		SET deleted_at = NULL, updated_at = now()
+		WHERE user_id = $1 AND id = $2 AND deleted_at IS NOT NULL
+		RETURNING `+projectColumns,
+		userID, projectID))
+}
+
+// RestoreTask takes a deleted task out of the trash together with the
+// subtasks deleted with it. A task whose parent is still in the trash
+// comes back as a top-level task. It returns sql.ErrNoRows if the task is
+// not in the trash, its project is, or it is not owned by userID, and
+// domain.ErrArchived if its project is archived.
+func (r *TrashRepo) RestoreTask(ctx context.Context, userID, taskID string) (domain.Task, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	var (
+		deletedAt time.Time
+		orphaned  bool
+		archived  bool
+	)
+	err = tx.QueryRowContext(ctx, `
+		SELECT t.deleted_at, COALESCE(pt.deleted_at IS NOT NULL, false), p.archived_at IS NOT NULL
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		LEFT JOIN tasks pt ON pt.id = t.parent_task_id
+		WHERE t.id = $1 AND p.user_id = $2 AND t.deleted_at IS NOT NULL AND p.deleted_at IS NULL
+		FOR NO KEY UPDATE OF p
+	`, taskID, userID).Scan(&deletedAt, &orphaned, &archived)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if archived {
+		return domain.Task{}, domain.ErrArchived
+	}
+
+	if _, err := tx.ExecContext(ctx, `
+		WITH RECURSIVE sub AS (
+			SELECT id FROM tasks WHERE id = $1
+			UNION ALL
+			SELECT c.id FROM tasks c JOIN sub s ON c.parent_task_id = s.id WHERE c.deleted_at = $2
+		)
+		UPDATE tasks t
+		SET deleted_at = NULL,
+			parent_task_id = CASE WHEN t.id = $1 AND $3::boolean THEN NULL ELSE t.parent_task_id END,
+			updated_at = now()
+		FROM sub
+		WHERE t.id = sub.id
+	`, taskID, deletedAt, orphaned); err != nil {
+		return domain.Task{}, err
+	}
+
+	t, err := scanTask(tx.QueryRowContext(ctx, `SELECT `+taskColumns+` FROM tasks t WHERE t.id = $1`, taskID))
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return t, tx.Commit()
+}
+
+// Purge deletes for good the projects and tasks that went to the trash
+// before cutoff, and returns how many rows it removed. Subtasks, comments,
+// attachments and the rest go with them through ON DELETE CASCADE.
+func (r *TrashRepo) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	var total int64
+	for _, q := range []string{
+		`DELETE FROM projects WHERE deleted_at < $1`,
+		`DELETE FROM tasks WHERE deleted_at < $1`,
+	} {
+		res, err := r.db.ExecContext(ctx, q, cutoff)
+		if err != nil {
+			return total, err
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return total, err
+		}
+		total += n
+	}
+	return total, nil
+}
+
+// headScanner scans a row whose first columns go to head.
+type headScanner struct {
+	rows *sql.Rows
+	head []any
+}
+
+func (s headScanner) Scan(dest ...any) error {
+	return s.rows.Scan(append(s.head, dest...)...)
+}
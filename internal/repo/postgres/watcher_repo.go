@@ -0,0 +1,248 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"TaskFlow/internal/domain"
+)
+
+type WatcherRepo struct{ db *sql.DB }
+
+func NewWatcherRepo(db *sql.DB) *WatcherRepo { return &WatcherRepo{db: db} }
+
+// WatchTask subscribes userID to a task it owns. Watching a task twice is a
+// no-op and keeps the original reason. It returns sql.ErrNoRows if the task
+// does not exist or is not owned by userID.
+func (r *WatcherRepo) WatchTask(ctx context.Context, userID, taskID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO task_watchers (task_id, user_id, reason)
+		SELECT t.id, $2, 'manual'
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.id = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL AND p.user_id = $2
+		ON CONFLICT (task_id, user_id) DO UPDATE SET created_at = task_watchers.created_at
+	`, taskID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UnwatchTask removes userID's subscription to a task, if any. It returns
+// sql.ErrNoRows if the task does not exist or is not owned by userID.
+func (r *WatcherRepo) UnwatchTask(ctx context.Context, userID, taskID string) error {
+	var found int
+	err := r.db.QueryRowContext(ctx, `
+		WITH owned AS (
+			SELECT t.id
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL AND p.user_id = $2
+		), removed AS (
+			DELETE FROM task_watchers w
+			USING owned
+			WHERE w.task_id = owned.id AND w.user_id = $2
+		)
+		SELECT count(*) FROM owned
+	`, taskID, userID).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if found == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// TaskWatchers lists the users subscribed to a task, oldest first. It
+// returns sql.ErrNoRows if the task does not exist or is not owned by
+// userID.
+func (r *WatcherRepo) TaskWatchers(ctx context.Context, userID, taskID string) ([]domain.Watcher, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1
+			FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.id = $1 AND t.deleted_at IS NULL AND p.deleted_at IS NULL AND p.user_id = $2
+		)
+	`, taskID, userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT w.user_id, u.email, w.reason, w.created_at
+		FROM task_watchers w
+		JOIN users u ON u.id = w.user_id
+		WHERE w.task_id = $1
+		ORDER BY w.created_at, w.user_id
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Watcher{}
+	for rows.Next() {
+		var w domain.Watcher
+		if err := rows.Scan(&w.UserID, &w.Email, &w.Reason, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// WatchProject subscribes userID to every task of a project it owns. It
+// returns sql.ErrNoRows if the project does not exist or is not owned by
+// userID.
+func (r *WatcherRepo) WatchProject(ctx context.Context, userID, projectID string) error {
+	res, err := r.db.ExecContext(ctx, `
+		INSERT INTO project_watchers (project_id, user_id)
+		SELECT p.id, $2
+		FROM projects p
+		WHERE p.id = $1 AND p.deleted_at IS NULL AND p.user_id = $2
+		ON CONFLICT (project_id, user_id) DO UPDATE SET created_at = project_watchers.created_at
+	`, projectID, userID)
+	if err != nil {
+		return err
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UnwatchProject removes userID's subscription to a project, if any. It
+// returns sql.ErrNoRows if the project does not exist or is not owned by
+// userID.
+func (r *WatcherRepo) UnwatchProject(ctx context.Context, userID, projectID string) error {
+	var found int
+	err := r.db.QueryRowContext(ctx, `
+		WITH owned AS (
+			SELECT p.id
+			FROM projects p
+			WHERE p.id = $1 AND p.deleted_at IS NULL AND p.user_id = $2
+		), removed AS (
+			DELETE FROM project_watchers w
+			USING owned
+			WHERE w.project_id = owned.id AND w.user_id = $2
+		)
+		SELECT count(*) FROM owned
+	`, projectID, userID).Scan(&found)
+	if err != nil {
+		return err
+	}
+	if found == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ProjectWatchers lists the users subscribed to a project, oldest first. It
+// returns sql.ErrNoRows if the project does not exist or is not owned by
+// userID.
+func (r *WatcherRepo) ProjectWatchers(ctx context.Context, userID, projectID string) ([]domain.Watcher, error) {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `
+		SELECT EXISTS (
+			SELECT 1 FROM projects p
+			WHERE p.id = $1 AND p.deleted_at IS NULL AND p.user_id = $2
+		)
+	`, projectID, userID).Scan(&exists); err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, sql.ErrNoRows
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT w.user_id, u.email, w.created_at
+		FROM project_watchers w
+		JOIN users u ON u.id = w.user_id
+		WHERE w.project_id = $1
+		ORDER BY w.created_at, w.user_id
+	`, projectID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []domain.Watcher{}
+	for rows.Next() {
+		var w domain.Watcher
+		if err := rows.Scan(&w.UserID, &w.Email, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, w)
+	}
+	return out, rows.Err()
+}
+
+// Subscribe adds userIDs as watchers of a task for the given reason. Users
+// already watching keep their original reason, and users other than the
+// owner of the task's project are skipped.
+func (r *WatcherRepo) Subscribe(ctx context.Context, taskID string, userIDs []string, reason domain.WatchReason) error {
+	if len(userIDs) == 0 {
+		return nil
+	}
+	_, err := r.db.ExecContext(ctx, `
+		INSERT INTO task_watchers (task_id, user_id, reason)
+		SELECT t.id, u.id, $3
+		FROM users u
+		JOIN tasks t ON t.id = $1
+		JOIN projects p ON p.id = t.project_id
+		WHERE u.id = ANY($2::uuid[]) AND p.user_id = u.id
+		ON CONFLICT (task_id, user_id) DO NOTHING
+	`, taskID, userIDs, string(reason))
+	return err
+}
+
+// Recipients returns the users watching a task or its project who still
+// own it, by ID.
+func (r *WatcherRepo) Recipients(ctx context.Context, taskID string) ([]string, error) {
+	rows, err := r.db.QueryContext(ctx, `
+		SELECT w.user_id
+		FROM (
+			SELECT w.user_id FROM task_watchers w WHERE w.task_id = $1
+			UNION
+			SELECT pw.user_id
+			FROM tasks t
+			JOIN project_watchers pw ON pw.project_id = t.project_id
+			WHERE t.id = $1
+		) w
+		JOIN tasks t ON t.id = $1
+		JOIN projects p ON p.id = t.project_id
+		WHERE p.user_id = w.user_id
+		ORDER BY 1
+	`, taskID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	out := []string{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		out = append(out, id)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+var (
+	ErrTooLarge        = errors.New("attachment too large")
+	ErrUnsupportedType = errors.New("unsupported attachment type")
+)
+
+type AttachmentRepo interface {
+	Create(ctx context.Context, userID string, a domain.Attachment) (domain.Attachment, error)
+	List(ctx context.Context, userID, taskID string) ([]domain.Attachment, error)
+	Get(ctx context.Context, userID, taskID, attachmentID string) (domain.Attachment, error)
+	Delete(ctx context.Context, userID, taskID, attachmentID string) error
+	PendingBlobDeletions(ctx context.Context, limit int) ([]string, error)
+	ClearBlobDeletions(ctx context.Context, keys []string) error
+	TaskArchived(ctx context.Context, userID, taskID string) (bool, error)
+}
+
+// BlobStore holds attachment contents by key. Delete must succeed for keys
+// that are already gone.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+const (
+	// DefaultMaxAttachmentSize is the upload limit unless configured otherwise.
+	DefaultMaxAttachmentSize = 25 << 20
+	maxFilenameLength        = 255
+	sweepBatchSize           = 100
+)
+
+// DefaultAttachmentTypes are the MIME types accepted unless configured
+// otherwise. A trailing "/*" matches every subtype.
+var DefaultAttachmentTypes = []string{"image/*", "application/pdf", "text/plain", "application/zip"}
+
+type AttachmentService struct {
+	repo    AttachmentRepo
+	store   BlobStore
+	maxSize int64
+	types   []string
+	tempDir string
+}
+
+type AttachmentOption func(*AttachmentService)
+
+// WithMaxAttachmentSize limits uploads to n bytes.
+func WithMaxAttachmentSize(n int64) AttachmentOption {
+	return func(s *AttachmentService) {
+		if n > 0 {
+			s.maxSize = n
+		}
+	}
+}
+
+// WithAllowedTypes replaces the accepted MIME types.
+func WithAllowedTypes(types []string) AttachmentOption {
+	return func(s *AttachmentService) {
+		if len(types) > 0 {
+			s.types = types
+		}
+	}
+}
+
+// WithTempDir sets where uploads are spooled before they are stored.
+func WithTempDir(dir string) AttachmentOption {
+	return func(s *AttachmentService) { s.tempDir = dir }
+}
+
+func NewAttachmentService(repo AttachmentRepo, store BlobStore, opts ...AttachmentOption) *AttachmentService {
+	s := &AttachmentService{
+		repo:    repo,
+		store:   store,
+		maxSize: DefaultMaxAttachmentSize,
+		types:   DefaultAttachmentTypes,
+	}
+	for _, o := range opts {
+		o(s)
+	}
+	return s
+}
+
+// MaxSize reports the upload limit in bytes.
+func (s *AttachmentService) MaxSize() int64 { return s.maxSize }
+
+// Upload stores r as a new attachment on a task. The content type is
+// sniffed from the data rather than trusted from the client. The task is
+// checked before anything is read, then the upload is spooled to a
+// temporary file so its size and SHA-256 are known before it reaches the
+// blob store.
+func (s *AttachmentService) Upload(ctx context.Context, userID, taskID, filename string, r io.Reader) (domain.Attachment, error) {
+	filename, err := cleanFilename(filename)
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if err := checkTaskWritable(ctx, s.repo, userID, taskID); err != nil {
+		return domain.Attachment{}, err
+	}
+
+	tmp, err := os.CreateTemp(s.tempDir, "taskflow-upload-*")
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	defer func() {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+	}()
+
+	h := sha256.New()
+	var head bytes.Buffer
+	sniff := &prefixWriter{buf: &head, n: 512}
+	size, err := io.Copy(io.MultiWriter(tmp, h, sniff), io.LimitReader(r, s.maxSize+1))
+	if err != nil {
+		return domain.Attachment{}, err
+	}
+	if size > s.maxSize {
+		return domain.Attachment{}, ErrTooLarge
+	}
+	if size == 0 {
+		return domain.Attachment{}, invalid("file", "cannot be empty")
+	}
+
+	contentType, _, _ := mime.ParseMediaType(http.DetectContentType(head.Bytes()))
+	if !s.allowed(contentType) {
+		return domain.Attachment{}, ErrUnsupportedType
+	}
+
+	if _, err := tmp.Seek(0, io.SeekStart); err != nil {
+		return domain.Attachment{}, err
+	}
+
+	a := domain.Attachment{
+		ID:          uuid.NewString(),
+		TaskID:      taskID,
+		Filename:    filename,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      hex.EncodeToString(h.Sum(nil)),
+	}
+	a.StorageKey = "tasks/" + taskID + "/" + a.ID
+	if err := s.store.Put(ctx, a.StorageKey, tmp, size, contentType); err != nil {
+		return domain.Attachment{}, err
+	}
+
+	// The task may have been deleted or archived since it was checked.
+	created, err := s.repo.Create(ctx, userID, a)
+	if err != nil {
+		_ = s.store.Delete(ctx, a.StorageKey)
+		if errors.Is(err, sql.ErrNoRows) {
+			return domain.Attachment{}, ErrNotFound
+		}
+		return domain.Attachment{}, err
+	}
+	return created, nil
+}
+
+func (s *AttachmentService) List(ctx context.Context, userID, taskID string) ([]domain.Attachment, error) {
+	items, err := s.repo.List(ctx, userID, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return items, err
+}
+
+// Open returns an attachment and a reader for its contents. The caller
+// must close the reader.
+func (s *AttachmentService) Open(ctx context.Context, userID, taskID, attachmentID string) (domain.Attachment, io.ReadCloser, error) {
+	a, err := s.repo.Get(ctx, userID, taskID, attachmentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Attachment{}, nil, ErrNotFound
+	}
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	rc, err := s.store.Get(ctx, a.StorageKey)
+	if err != nil {
+		return domain.Attachment{}, nil, err
+	}
+	return a, rc, nil
+}
+
+// Delete removes an attachment. Its blob is removed by the next sweep.
+func (s *AttachmentService) Delete(ctx context.Context, userID, taskID, attachmentID string) error {
+	if err := checkTaskWritable(ctx, s.repo, userID, taskID); err != nil {
+		return err
+	}
+	err := s.repo.Delete(ctx, userID, taskID, attachmentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// SweepDeletedBlobs removes the blobs of attachments deleted directly or
+// through task and project deletion. Keys whose blob cannot be deleted stay
+// queued for the next sweep. It returns the number of blobs removed.
+func (s *AttachmentService) SweepDeletedBlobs(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		keys, err := s.repo.PendingBlobDeletions(ctx, sweepBatchSize)
+		if err != nil || len(keys) == 0 {
+			return removed, err
+		}
+		done := keys[:0]
+		var firstErr error
+		for _, k := range keys {
+			if err := s.store.Delete(ctx, k); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			done = append(done, k)
+		}
+		if len(done) > 0 {
+			if err := s.repo.ClearBlobDeletions(ctx, done); err != nil {
+				return removed, err
+			}
+			removed += len(done)
+		}
+		if firstErr != nil {
+			return removed, firstErr
+		}
+		if len(keys) < sweepBatchSize {
+			return removed, nil
+		}
+	}
+}
+
+func (s *AttachmentService) allowed(contentType string) bool {
+	for _, t := range s.types {
+		if t == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(t, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// cleanFilename keeps the base name of a client-supplied filename and drops
+// control characters so it is safe to echo in a Content-Disposition header.
+func cleanFilename(name string) (string, error) {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "", invalid("file", "filename required")
+	}
+	if utf8.RuneCountInString(name) > maxFilenameLength {
+		return "", invalid("file", "filename must be at most 255 characters")
+	}
+	return name, nil
+}
+
+// prefixWriter keeps the first n bytes written to it.
+type prefixWriter struct {
+	buf *bytes.Buffer
+	n   int
+}
+
+func (p *prefixWriter) Write(b []byte) (int, error) {
+	if rest := p.n - p.buf.Len(); rest > 0 {
+		if len(b) < rest {
+			rest = len(b)
+		}
+		p.buf.Write(b[:rest])
+	}
+	return len(b), nil
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"strings"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+)
+
+type CommentRepo interface {
+	Create(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error)
+	Get(ctx context.Context, userID, commentID string) (domain.Comment, error)
+	List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.Comment, *domain.Cursor, error)
+	Update(ctx context.Context, userID, commentID, body string) (domain.Comment, error)
+	Delete(ctx context.Context, userID, commentID string) error
+	TaskArchived(ctx context.Context, userID, taskID string) (bool, error)
+}
+
+// MaxCommentLength caps a comment body, counted in characters.
+const MaxCommentLength = 10000
+
+type CommentService struct {
+	repo     CommentRepo
+	watchers WatcherRepo
+	notifier Notifier
+}
+
+type CommentOption func(*CommentService)
+
+// WithCommentWatchers subscribes commenters to the tasks they comment on.
+func WithCommentWatchers(repo WatcherRepo) CommentOption {
+	return func(s *CommentService) { s.watchers = repo }
+}
+
+// WithCommentNotifier tells the task's watchers about new comments through
+// n.
+func WithCommentNotifier(n Notifier) CommentOption {
+	return func(s *CommentService) { s.notifier = n }
+}
+
+func NewCommentService(repo CommentRepo, opts ...CommentOption) *CommentService {
+	s := &CommentService{repo: repo}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Create posts a comment on a task. A non-nil parentID makes it a reply;
+// the parent must be a top-level comment on the same task.
+func (s *CommentService) Create(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	if err := checkTaskWritable(ctx, s.repo, userID, taskID); err != nil {
+		return domain.Comment{}, err
+	}
+	if parentID != nil {
+		parent, err := s.repo.Get(ctx, userID, *parentID)
+		if errors.Is(err, sql.ErrNoRows) || (err == nil && parent.TaskID != taskID) {
+			return domain.Comment{}, invalid("parentId", "must be a comment on this task")
+		}
+		if err != nil {
+			return domain.Comment{}, err
+		}
+		if parent.ParentID != nil {
+			return domain.Comment{}, invalid("parentId", "cannot reply to a reply")
+		}
+	}
+	c, err := s.repo.Create(ctx, userID, taskID, parentID, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Comment{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	s.followUp(ctx, userID, taskID, c)
+	return c, nil
+}
+
+// followUp subscribes the author of c and tells the task's other watchers
+// about it. The comment is already saved, so failures are logged rather
+// than returned.
+func (s *CommentService) followUp(ctx context.Context, userID, taskID string, c domain.Comment) {
+	if s.watchers != nil {
+		if err := s.watchers.Subscribe(ctx, taskID, []string{userID}, domain.WatchCommenter); err != nil {
+			log.Printf("task %s: subscribe commenter: %v", taskID, err)
+		}
+	}
+	if s.notifier == nil {
+		return
+	}
+	recipients, err := watchersExcept(ctx, s.watchers, taskID, userID)
+	if err != nil {
+		log.Printf("task %s: watchers: %v", taskID, err)
+		return
+	}
+	if len(recipients) == 0 {
+		return
+	}
+	err = s.notifier.Notify(ctx, domain.TaskEvent{
+		Type:       domain.NotifyCommented,
+		TaskID:     taskID,
+		ActorID:    userID,
+		CommentID:  &c.ID,
+		Recipients: recipients,
+	})
+	if err != nil {
+		log.Printf("task %s: notify %s: %v", taskID, domain.NotifyCommented, err)
+	}
+}
+
+func (s *CommentService) List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) (Page[domain.Comment], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if cursor != nil && (cursor.Sort != "" || len(cursor.Values) != 0) {
+		return Page[domain.Comment]{}, invalid("cursor", "was not issued for comments")
+	}
+	items, next, err := s.repo.List(ctx, userID, taskID, limit, cursor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Page[domain.Comment]{}, ErrNotFound
+	}
+	if err != nil {
+		return Page[domain.Comment]{}, err
+	}
+	return Page[domain.Comment]{Items: items, NextCursor: next}, nil
+}
+
+// Update edits a comment's body. Only the author may edit, and deleted
+// comments cannot be edited.
+func (s *CommentService) Update(ctx context.Context, userID, commentID, body string) (domain.Comment, error) {
+	body, err := validateCommentBody(body)
+	if err != nil {
+		return domain.Comment{}, err
+	}
+	if err := s.checkWritable(ctx, userID, commentID); err != nil {
+		return domain.Comment{}, err
+	}
+	c, err := s.repo.Update(ctx, userID, commentID, body)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Comment{}, ErrNotFound
+	}
+	return c, err
+}
+
+func (s *CommentService) Delete(ctx context.Context, userID, commentID string) error {
+	if err := s.checkWritable(ctx, userID, commentID); err != nil {
+		return err
+	}
+	err := s.repo.Delete(ctx, userID, commentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// checkWritable returns ErrNotFound if there is no such comment and
+// ErrProjectArchived if its task's project is archived.
+func (s *CommentService) checkWritable(ctx context.Context, userID, commentID string) error {
+	c, err := s.repo.Get(ctx, userID, commentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return checkTaskWritable(ctx, s.repo, userID, c.TaskID)
+}
+
+func validateCommentBody(body string) (string, error) {
+	body = strings.TrimSpace(body)
+	if body == "" {
+		return "", invalid("body", "required")
+	}
+	if utf8.RuneCountInString(body) > MaxCommentLength {
+		return "", invalid("body", "must be at most 10000 characters")
+	}
+	return body, nil
+}
@@ -0,0 +1,380 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+
+	"github.com/google/uuid"
+)
+
+type CustomFieldRepo interface {
+	Create(ctx context.Context, userID, projectID string, f domain.CustomField) (domain.CustomField, error)
+	List(ctx context.Context, userID, projectID string) ([]domain.CustomField, error)
+	Get(ctx context.Context, userID, fieldID string) (domain.CustomField, error)
+	Update(ctx context.Context, userID, fieldID string, patch domain.CustomFieldPatch) (domain.CustomField, error)
+	Delete(ctx context.Context, userID, fieldID string) error
+	Assignable(ctx context.Context, projectID string, userIDs []string) ([]string, error)
+	ProjectArchived(ctx context.Context, userID, projectID string) (bool, error)
+	FieldArchived(ctx context.Context, userID, fieldID string) (bool, error)
+}
+
+// Limits on custom field definitions and values.
+const (
+	maxCustomFieldName   = 50
+	MaxCustomFieldOption = 100
+	MaxCustomFieldOpts   = 100
+	MaxCustomTextLength  = 1000
+	MaxCustomURLLength   = 2048
+)
+
+type CustomFieldService struct {
+	repo CustomFieldRepo
+}
+
+func NewCustomFieldService(repo CustomFieldRepo) *CustomFieldService {
+	return &CustomFieldService{repo: repo}
+}
+
+// Create defines a custom field on a project. Select fields need at least
+// one option; other types take none.
+func (s *CustomFieldService) Create(ctx context.Context, userID, projectID string, f domain.CustomField) (domain.CustomField, error) {
+	f.Name = strings.TrimSpace(f.Name)
+	if err := validateCustomFieldName(f.Name); err != nil {
+		return domain.CustomField{}, err
+	}
+	if !f.Type.Valid() {
+		return domain.CustomField{}, invalid("type", "must be one of: text, number, date, single_select, multi_select, user, url")
+	}
+	options, err := normalizeOptions(f.Type, f.Options)
+	if err != nil {
+		return domain.CustomField{}, err
+	}
+	f.Options = options
+	if err := checkProjectWritable(ctx, s.repo, userID, projectID); err != nil {
+		return domain.CustomField{}, err
+	}
+
+	out, err := s.repo.Create(ctx, userID, projectID, f)
+	return out, mapLabelErr(err)
+}
+
+func (s *CustomFieldService) List(ctx context.Context, userID, projectID string) ([]domain.CustomField, error) {
+	return s.repo.List(ctx, userID, projectID)
+}
+
+// Update renames a field or replaces a select field's options. Task values
+// that used a removed option lose it.
+func (s *CustomFieldService) Update(ctx context.Context, userID, fieldID string, patch domain.CustomFieldPatch) (domain.CustomField, error) {
+	if patch.Name != nil {
+		name := strings.TrimSpace(*patch.Name)
+		if err := validateCustomFieldName(name); err != nil {
+			return domain.CustomField{}, err
+		}
+		patch.Name = &name
+	}
+	if err := archivedErr(s.repo.FieldArchived(ctx, userID, fieldID)); err != nil {
+		return domain.CustomField{}, err
+	}
+	if patch.Options != nil {
+		cur, err := s.repo.Get(ctx, userID, fieldID)
+		if err != nil {
+			return domain.CustomField{}, mapLabelErr(err)
+		}
+		if patch.Options, err = normalizeOptions(cur.Type, patch.Options); err != nil {
+			return domain.CustomField{}, err
+		}
+	}
+	out, err := s.repo.Update(ctx, userID, fieldID, patch)
+	return out, mapLabelErr(err)
+}
+
+func (s *CustomFieldService) Delete(ctx context.Context, userID, fieldID string) error {
+	if err := archivedErr(s.repo.FieldArchived(ctx, userID, fieldID)); err != nil {
+		return err
+	}
+	return mapLabelErr(s.repo.Delete(ctx, userID, fieldID))
+}
+
+func validateCustomFieldName(name string) error {
+	if name == "" {
+		return invalid("name", "required")
+	}
+	if utf8.RuneCountInString(name) > maxCustomFieldName {
+		return invalid("name", "must be at most 50 characters")
+	}
+	return nil
+}
+
+func normalizeOptions(t domain.CustomFieldType, options []string) ([]string, error) {
+	if !t.HasOptions() {
+		if len(options) > 0 {
+			return nil, invalid("options", "are only allowed for select fields")
+		}
+		return []string{}, nil
+	}
+	if len(options) == 0 {
+		return nil, invalid("options", "must list at least one option")
+	}
+	if len(options) > MaxCustomFieldOpts {
+		return nil, invalid("options", "must list at most 100 options")
+	}
+	out := make([]string, 0, len(options))
+	seen := map[string]bool{}
+	for _, o := range options {
+		o = strings.TrimSpace(o)
+		if o == "" {
+			return nil, invalid("options", "cannot contain empty options")
+		}
+		if utf8.RuneCountInString(o) > MaxCustomFieldOption {
+			return nil, invalid("options", "must each be at most 100 characters")
+		}
+		if seen[o] {
+			return nil, invalid("options", fmt.Sprintf("contain %q twice", o))
+		}
+		seen[o] = true
+		out = append(out, o)
+	}
+	return out, nil
+}
+
+// normalizeCustomValue checks raw against the field's type and returns it
+// in canonical JSON form. JSON null is passed through: it removes a value.
+func normalizeCustomValue(f domain.CustomField, raw json.RawMessage) (json.RawMessage, error) {
+	field := "customFields." + f.ID
+	raw = bytes.TrimSpace(raw)
+	if string(raw) == "null" {
+		return raw, nil
+	}
+
+	if f.Type == domain.FieldNumber {
+		var n float64
+		if err := json.Unmarshal(raw, &n); err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return nil, invalid(field, "must be a number")
+		}
+		return json.Marshal(n)
+	}
+	if f.Type == domain.FieldMultiSelect {
+		var picked []string
+		if err := json.Unmarshal(raw, &picked); err != nil {
+			return nil, invalid(field, "must be an array of options")
+		}
+		seen := map[string]bool{}
+		out := []string{}
+		for _, p := range picked {
+			if !containsString(f.Options, p) {
+				return nil, invalid(field, fmt.Sprintf("%q is not an option", p))
+			}
+			if !seen[p] {
+				seen[p] = true
+				out = append(out, p)
+			}
+		}
+		if len(out) == 0 {
+			return json.RawMessage("null"), nil
+		}
+		return json.Marshal(out)
+	}
+
+	var v string
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, invalid(field, "must be a string")
+	}
+	v, err := normalizeCustomString(f, v)
+	if err != nil {
+		return nil, invalid(field, err.Error())
+	}
+	return json.Marshal(v)
+}
+
+// normalizeCustomString validates a scalar string value, also used for
+// filter values given in the query string.
+func normalizeCustomString(f domain.CustomField, v string) (string, error) {
+	switch f.Type {
+	case domain.FieldText:
+		v = strings.TrimSpace(v)
+		if v == "" {
+			return "", errors.New("must not be empty")
+		}
+		if !utf8.ValidString(v) || strings.ContainsRune(v, 0) {
+			return "", errors.New("must be valid UTF-8 text")
+		}
+		if utf8.RuneCountInString(v) > MaxCustomTextLength {
+			return "", errors.New("must be at most 1000 characters")
+		}
+	case domain.FieldNumber:
+		n, err := strconv.ParseFloat(v, 64)
+		if err != nil || math.IsInf(n, 0) || math.IsNaN(n) {
+			return "", errors.New("must be a number")
+		}
+		return strconv.FormatFloat(n, 'g', -1, 64), nil
+	case domain.FieldDate:
+		d, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return "", errors.New("must be a YYYY-MM-DD date")
+		}
+		return d.Format("2006-01-02"), nil
+	case domain.FieldSingleSelect, domain.FieldMultiSelect:
+		if !containsString(f.Options, v) {
+			return "", fmt.Errorf("%q is not an option", v)
+		}
+	case domain.FieldUser:
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return "", errors.New("must be a user ID")
+		}
+		return id.String(), nil
+	case domain.FieldURL:
+		if len(v) > MaxCustomURLLength {
+			return "", errors.New("must be at most 2048 characters")
+		}
+		u, err := url.Parse(v)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", errors.New("must be an absolute http or https URL")
+		}
+	}
+	return v, nil
+}
+
+func containsString(list []string, v string) bool {
+	for _, s := range list {
+		if s == v {
+			return true
+		}
+	}
+	return false
+}
+
+var errCustomFieldsUnset = errors.New("task custom fields are not configured")
+
+// customFieldsByID loads a project's field definitions keyed by ID.
+func (s *TaskService) customFieldsByID(ctx context.Context, userID, projectID string) (map[string]domain.CustomField, error) {
+	if s.fields == nil {
+		return nil, errCustomFieldsUnset
+	}
+	defs, err := s.fields.List(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]domain.CustomField, len(defs))
+	for _, d := range defs {
+		out[d.ID] = d
+	}
+	return out, nil
+}
+
+// normalizeCustomValues validates values against projectID's fields. Users
+// set in fields of type user must exist and be able to read the project.
+func (s *TaskService) normalizeCustomValues(ctx context.Context, userID, projectID string, values map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	if len(values) == 0 {
+		return values, nil
+	}
+	defs, err := s.customFieldsByID(ctx, userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[string]json.RawMessage, len(values))
+	users := map[string]string{} // user ID -> field ID
+	for id, raw := range values {
+		f, ok := defs[id]
+		if !ok {
+			return nil, invalid("customFields."+id, "is not a field of this project")
+		}
+		v, err := normalizeCustomValue(f, raw)
+		if err != nil {
+			return nil, err
+		}
+		out[id] = v
+		var u string
+		if f.Type == domain.FieldUser && json.Unmarshal(v, &u) == nil {
+			users[u] = id
+		}
+	}
+	if len(users) == 0 {
+		return out, nil
+	}
+
+	ids := make([]string, 0, len(users))
+	for u := range users {
+		ids = append(ids, u)
+	}
+	assignable, err := s.fields.Assignable(ctx, projectID, ids)
+	if err != nil {
+		return nil, err
+	}
+	for u, id := range users {
+		if !containsString(assignable, u) {
+			return nil, invalid("customFields."+id, "must be a user with access to the project")
+		}
+	}
+	return out, nil
+}
+
+// resolveCustomQuery checks custom field filters and sort keys against the
+// project's fields, filling in their types and canonical filter values.
+func (s *TaskService) resolveCustomQuery(ctx context.Context, userID string, f *domain.TaskFilter, sort []domain.SortKey) error {
+	needed := len(f.CustomFields) > 0
+	for _, k := range sort {
+		needed = needed || domain.CustomFieldSortID(k.Field) != ""
+	}
+	if !needed {
+		return nil
+	}
+	defs, err := s.customFieldsByID(ctx, userID, f.ProjectID)
+	if err != nil {
+		return err
+	}
+
+	for i := range f.CustomFields {
+		cf := &f.CustomFields[i]
+		param := "cf." + cf.FieldID
+		def, ok := defs[cf.FieldID]
+		if !ok {
+			return invalid(param, "is not a field of this project")
+		}
+		cf.Type = def.Type
+		switch {
+		case cf.Op == domain.OpEmpty:
+			if cf.Value != "true" && cf.Value != "false" {
+				return invalid(param+".empty", "must be true or false")
+			}
+			continue
+		case cf.Op == domain.OpEq:
+		case def.Type.Ordered() && (cf.Op == domain.OpGt || cf.Op == domain.OpGte || cf.Op == domain.OpLt || cf.Op == domain.OpLte):
+			param += "." + string(cf.Op)
+		default:
+			return invalid(param, fmt.Sprintf("does not support the %q operator", cf.Op))
+		}
+		v, err := normalizeCustomString(def, cf.Value)
+		if err != nil {
+			return invalid(param, err.Error())
+		}
+		cf.Value = v
+	}
+
+	for i, k := range sort {
+		id := domain.CustomFieldSortID(k.Field)
+		if id == "" {
+			continue
+		}
+		def, ok := defs[id]
+		if !ok {
+			return invalid("sort", fmt.Sprintf("%q is not a field of this project", k.Field))
+		}
+		if def.Type == domain.FieldMultiSelect {
+			return invalid("sort", fmt.Sprintf("multi-select field %q cannot be sorted on", k.Field))
+		}
+		sort[i].FieldType = def.Type
+	}
+	return nil
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/mail"
+)
+
+type DigestRepo interface {
+	Settings(ctx context.Context, userID string) (domain.DigestSettings, error)
+	UpdateSettings(ctx context.Context, userID string, patch domain.DigestSettingsPatch, token string) (domain.DigestSettings, error)
+	Unsubscribe(ctx context.Context, token string) error
+	Pending(ctx context.Context) ([]domain.DigestRecipient, error)
+	Activity(ctx context.Context, userID string, until time.Time, limit int) ([]domain.DigestItem, int, error)
+	MarkSent(ctx context.Context, userID, token string, at time.Time) error
+}
+
+// MailTransport delivers email. The mail package provides SMTP and file
+// transports.
+type MailTransport interface {
+	Send(ctx context.Context, m mail.Message) error
+}
+
+// DefaultDigestItems is how many notifications a digest lists before
+// summing up the rest.
+const DefaultDigestItems = 50
+
+// DigestService emails users a daily or weekly summary of their unread
+// notifications.
+type DigestService struct {
+	repo      DigestRepo
+	transport MailTransport
+	baseURL   string
+	maxItems  int
+	now       func() time.Time
+}
+
+type DigestOption func(*DigestService)
+
+// WithDigestBaseURL sets the public URL of the API, which unsubscribe links
+// point at.
+func WithDigestBaseURL(u string) DigestOption {
+	return func(s *DigestService) { s.baseURL = strings.TrimRight(u, "/") }
+}
+
+// WithDigestMaxItems caps how many notifications a digest lists. A
+// non-positive n keeps the default.
+func WithDigestMaxItems(n int) DigestOption {
+	return func(s *DigestService) {
+		if n > 0 {
+			s.maxItems = n
+		}
+	}
+}
+
+// WithDigestClock replaces time.Now, which decides whose digest is due.
+func WithDigestClock(now func() time.Time) DigestOption {
+	return func(s *DigestService) { s.now = now }
+}
+
+func NewDigestService(repo DigestRepo, transport MailTransport, opts ...DigestOption) *DigestService {
+	s := &DigestService{repo: repo, transport: transport, maxItems: DefaultDigestItems, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *DigestService) Settings(ctx context.Context, userID string) (domain.DigestSettings, error) {
+	return s.repo.Settings(ctx, userID)
+}
+
+func (s *DigestService) UpdateSettings(ctx context.Context, userID string, patch domain.DigestSettingsPatch) (domain.DigestSettings, error) {
+	if f := patch.Frequency; f != nil && *f != domain.DigestOff && *f != domain.DigestDaily && *f != domain.DigestWeekly {
+		return domain.DigestSettings{}, invalid("frequency", "must be one of: off, daily, weekly")
+	}
+	if tz := patch.Timezone; tz != nil {
+		if _, err := time.LoadLocation(*tz); err != nil || *tz == "" || *tz == "Local" {
+			return domain.DigestSettings{}, invalid("timezone", "must be an IANA time zone such as Europe/Berlin")
+		}
+	}
+	if h := patch.Hour; h != nil && (*h < 0 || *h > 23) {
+		return domain.DigestSettings{}, invalid("hour", "must be between 0 and 23")
+	}
+	if d := patch.Weekday; d != nil && (*d < 0 || *d > 6) {
+		return domain.DigestSettings{}, invalid("weekday", "must be between 0 (Sunday) and 6 (Saturday)")
+	}
+	return s.repo.UpdateSettings(ctx, userID, patch, newDigestToken())
+}
+
+// Unsubscribe turns off the digest of the user an unsubscribe link was
+// sent to.
+func (s *DigestService) Unsubscribe(ctx context.Context, token string) error {
+	if token == "" {
+		return ErrNotFound
+	}
+	err := s.repo.Unsubscribe(ctx, token)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Send emails a digest to every user whose digest has come due since their
+// oldest notification not yet in one, and returns how many were sent. A
+// failure for one user does not stop the others; the errors are returned
+// together and those users are tried again on the next run.
+func (s *DigestService) Send(ctx context.Context) (int, error) {
+	pending, err := s.repo.Pending(ctx)
+	if err != nil {
+		return 0, err
+	}
+	now := s.now()
+	sent := 0
+	var errs []error
+	for _, p := range pending {
+		if ctx.Err() != nil {
+			break
+		}
+		if !digestDue(p.Settings, p.Since, now) {
+			continue
+		}
+		if err := s.send(ctx, p, now); err != nil {
+			errs = append(errs, fmt.Errorf("digest for user %s: %w", p.UserID, err))
+			continue
+		}
+		sent++
+	}
+	return sent, errors.Join(errs...)
+}
+
+func (s *DigestService) send(ctx context.Context, p domain.DigestRecipient, now time.Time) error {
+	items, total, err := s.repo.Activity(ctx, p.UserID, now, s.maxItems)
+	if err != nil {
+		return err
+	}
+	token := p.Token
+	if token == "" {
+		token = newDigestToken()
+	}
+	if len(items) > 0 {
+		msg, err := s.digestMessage(p, token, items, total)
+		if err != nil {
+			return err
+		}
+		if err := s.transport.Send(ctx, msg); err != nil {
+			return err
+		}
+	}
+	return s.repo.MarkSent(ctx, p.UserID, token, now)
+}
+
+// digestData is what the digest templates are executed with.
+type digestData struct {
+	Frequency      domain.DigestFrequency
+	Items          []domain.DigestItem
+	More           int
+	UnsubscribeURL string
+}
+
+func (s *DigestService) digestMessage(p domain.DigestRecipient, token string, items []domain.DigestItem, total int) (mail.Message, error) {
+	loc, err := time.LoadLocation(p.Settings.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	for i := range items {
+		if d := items[i].DueDate; d != nil {
+			local := d.In(loc)
+			items[i].DueDate = &local
+		}
+		items[i].CreatedAt = items[i].CreatedAt.In(loc)
+	}
+	unsubscribe := s.baseURL + "/v1/digest/unsubscribe?token=" + url.QueryEscape(token)
+	text, html, err := mail.Render("digest", digestData{
+		Frequency:      p.Settings.Frequency,
+		Items:          items,
+		More:           total - len(items),
+		UnsubscribeURL: unsubscribe,
+	})
+	if err != nil {
+		return mail.Message{}, err
+	}
+
+	subject := fmt.Sprintf("Your %s TaskFlow digest: %d update", p.Settings.Frequency, total)
+	if total != 1 {
+		subject += "s"
+	}
+	return mail.Message{
+		To:      p.Email,
+		Subject: subject,
+		Text:    text,
+		HTML:    html,
+		Headers: map[string]string{
+			"List-Unsubscribe":      "<" + unsubscribe + ">",
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		},
+	}, nil
+}
+
+// digestDue reports whether a digest sent on set's schedule falls due in
+// (since, now], that is whether a user with notifications pending since
+// then should be sent one at now. Send times are taken in the user's time
+// zone, so they follow daylight saving changes.
+func digestDue(set domain.DigestSettings, since, now time.Time) bool {
+	if set.Frequency != domain.DigestDaily && set.Frequency != domain.DigestWeekly {
+		return false
+	}
+	loc, err := time.LoadLocation(set.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	last := time.Date(local.Year(), local.Month(), local.Day(), set.Hour, 0, 0, 0, loc)
+	step := 1
+	if set.Frequency == domain.DigestWeekly {
+		step = 7
+		last = last.AddDate(0, 0, -((int(last.Weekday()) - set.Weekday + 7) % 7))
+	}
+	if last.After(now) {
+		last = last.AddDate(0, 0, -step)
+	}
+	return last.After(since)
+}
+
+// newDigestToken returns a random unsubscribe token.
+func newDigestToken() string {
+	b := make([]byte, 24)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,12 @@
+package service
+
+// ValidationError reports invalid caller input for a single field. Its
+// message reads naturally on its own, e.g. "title required".
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e *ValidationError) Error() string { return e.Field + " " + e.Message }
+
+func invalid(field, msg string) error { return &ValidationError{Field: field, Message: msg} }
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+)
+
+var ErrConflict = errors.New("conflict")
+
+type LabelRepo interface {
+	Create(ctx context.Context, userID, projectID, name, color string) (domain.Label, error)
+	List(ctx context.Context, userID, projectID string) ([]domain.Label, error)
+	Update(ctx context.Context, userID, labelID string, name, color *string) (domain.Label, error)
+	Delete(ctx context.Context, userID, labelID string) error
+	Attach(ctx context.Context, userID, taskID, labelID string) error
+	Detach(ctx context.Context, userID, taskID, labelID string) error
+	ProjectArchived(ctx context.Context, userID, projectID string) (bool, error)
+	TaskArchived(ctx context.Context, userID, taskID string) (bool, error)
+	LabelArchived(ctx context.Context, userID, labelID string) (bool, error)
+}
+
+type LabelService struct {
+	repo LabelRepo
+}
+
+func NewLabelService(repo LabelRepo) *LabelService { return &LabelService{repo: repo} }
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+const maxLabelName = 50
+
+func (s *LabelService) Create(ctx context.Context, userID, projectID, name, color string) (domain.Label, error) {
+	name = strings.TrimSpace(name)
+	if err := validateLabelName(name); err != nil {
+		return domain.Label{}, err
+	}
+	if !hexColor.MatchString(color) {
+		return domain.Label{}, invalid("color", "must be a hex color like #1d76db")
+	}
+	if err := checkProjectWritable(ctx, s.repo, userID, projectID); err != nil {
+		return domain.Label{}, err
+	}
+	l, err := s.repo.Create(ctx, userID, projectID, name, strings.ToLower(color))
+	return l, mapLabelErr(err)
+}
+
+func (s *LabelService) List(ctx context.Context, userID, projectID string) ([]domain.Label, error) {
+	return s.repo.List(ctx, userID, projectID)
+}
+
+func (s *LabelService) Update(ctx context.Context, userID, labelID string, name, color *string) (domain.Label, error) {
+	if name != nil {
+		trim := strings.TrimSpace(*name)
+		if err := validateLabelName(trim); err != nil {
+			return domain.Label{}, err
+		}
+		name = &trim
+	}
+	if color != nil {
+		if !hexColor.MatchString(*color) {
+			return domain.Label{}, invalid("color", "must be a hex color like #1d76db")
+		}
+		lower := strings.ToLower(*color)
+		color = &lower
+	}
+	if err := archivedErr(s.repo.LabelArchived(ctx, userID, labelID)); err != nil {
+		return domain.Label{}, err
+	}
+	l, err := s.repo.Update(ctx, userID, labelID, name, color)
+	return l, mapLabelErr(err)
+}
+
+func (s *LabelService) Delete(ctx context.Context, userID, labelID string) error {
+	if err := archivedErr(s.repo.LabelArchived(ctx, userID, labelID)); err != nil {
+		return err
+	}
+	return mapLabelErr(s.repo.Delete(ctx, userID, labelID))
+}
+
+// Attach adds a label to a task. The label must belong to the task's project.
+func (s *LabelService) Attach(ctx context.Context, userID, taskID, labelID string) error {
+	if err := checkTaskWritable(ctx, s.repo, userID, taskID); err != nil {
+		return err
+	}
+	return mapLabelErr(s.repo.Attach(ctx, userID, taskID, labelID))
+}
+
+func (s *LabelService) Detach(ctx context.Context, userID, taskID, labelID string) error {
+	if err := checkTaskWritable(ctx, s.repo, userID, taskID); err != nil {
+		return err
+	}
+	return mapLabelErr(s.repo.Detach(ctx, userID, taskID, labelID))
+}
+
+func validateLabelName(name string) error {
+	if name == "" {
+		return invalid("name", "required")
+	}
+	if utf8.RuneCountInString(name) > maxLabelName {
+		return invalid("name", "must be at most 50 characters")
+	}
+	if strings.Contains(name, ",") {
+		return invalid("name", "cannot contain commas")
+	}
+	return nil
+}
+
+func mapLabelErr(err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrDuplicate):
+		return ErrConflict
+	}
+	return err
+}
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"TaskFlow/internal/domain"
+)
+
+type NotificationRepo interface {
+	Create(ctx context.Context, ev domain.TaskEvent) (int, error)
+	CreateDueSoon(ctx context.Context, from, to time.Time) (int, error)
+	List(ctx context.Context, userID string, unreadOnly bool, limit int, cursor *domain.Cursor) ([]domain.Notification, *domain.Cursor, error)
+	UnreadCount(ctx context.Context, userID string) (int, error)
+	MarkRead(ctx context.Context, userID, notificationID string) error
+	MarkAllRead(ctx context.Context, userID string) (int, error)
+	Prefs(ctx context.Context, userID string) (domain.NotificationPrefs, error)
+	UpdatePrefs(ctx context.Context, userID string, patch domain.NotificationPrefsPatch) (domain.NotificationPrefs, error)
+}
+
+// Notifier is told about task events once they are stored. TaskService and
+// CommentService report through it, so delivery can change without them.
+type Notifier interface {
+	Notify(ctx context.Context, ev domain.TaskEvent) error
+}
+
+// DefaultDueSoonWindow is how long before its due date a task counts as
+// due soon.
+const DefaultDueSoonWindow = 24 * time.Hour
+
+type NotificationService struct {
+	repo    NotificationRepo
+	dueSoon time.Duration
+	now     func() time.Time
+}
+
+type NotificationOption func(*NotificationService)
+
+// WithDueSoonWindow sets how far ahead NotifyDueSoon looks. A non-positive
+// duration keeps the default.
+func WithDueSoonWindow(d time.Duration) NotificationOption {
+	return func(s *NotificationService) {
+		if d > 0 {
+			s.dueSoon = d
+		}
+	}
+}
+
+// WithNotificationClock replaces time.Now, which the due-soon window starts
+// from.
+func WithNotificationClock(now func() time.Time) NotificationOption {
+	return func(s *NotificationService) { s.now = now }
+}
+
+func NewNotificationService(repo NotificationRepo, opts ...NotificationOption) *NotificationService {
+	s := &NotificationService{repo: repo, dueSoon: DefaultDueSoonWindow, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Notify stores a notification of ev for each recipient who wants it.
+func (s *NotificationService) Notify(ctx context.Context, ev domain.TaskEvent) error {
+	if len(ev.Recipients) == 0 {
+		return nil
+	}
+	_, err := s.repo.Create(ctx, ev)
+	return err
+}
+
+// NotifyDueSoon tells the watchers of open tasks falling due within the
+// window. Each watcher is told once per due date, so running it often is
+// safe.
+func (s *NotificationService) NotifyDueSoon(ctx context.Context) (int, error) {
+	now := s.now()
+	return s.repo.CreateDueSoon(ctx, now, now.Add(s.dueSoon))
+}
+
+func (s *NotificationService) List(ctx context.Context, userID string, unreadOnly bool, limit int, cursor *domain.Cursor) (Page[domain.Notification], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if cursor != nil && (cursor.Sort != "" || len(cursor.Values) != 0) {
+		return Page[domain.Notification]{}, invalid("cursor", "was not issued for notifications")
+	}
+	items, next, err := s.repo.List(ctx, userID, unreadOnly, limit, cursor)
+	if err != nil {
+		return Page[domain.Notification]{}, err
+	}
+	return Page[domain.Notification]{Items: items, NextCursor: next}, nil
+}
+
+func (s *NotificationService) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return s.repo.UnreadCount(ctx, userID)
+}
+
+func (s *NotificationService) MarkRead(ctx context.Context, userID, notificationID string) error {
+	err := s.repo.MarkRead(ctx, userID, notificationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// MarkAllRead marks every unread notification of userID read and returns
+// how many there were.
+func (s *NotificationService) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	return s.repo.MarkAllRead(ctx, userID)
+}
+
+func (s *NotificationService) Prefs(ctx context.Context, userID string) (domain.NotificationPrefs, error) {
+	return s.repo.Prefs(ctx, userID)
+}
+
+func (s *NotificationService) UpdatePrefs(ctx context.Context, userID string, patch domain.NotificationPrefsPatch) (domain.NotificationPrefs, error) {
+	return s.repo.UpdatePrefs(ctx, userID, patch)
+}
+
+// notify hands ev to the configured notifier, if any. The change ev
+// reports is already committed, so failures are logged, not returned.
+func (s *TaskService) notify(ctx context.Context, ev domain.TaskEvent) {
+	if s.notifier == nil || len(ev.Recipients) == 0 {
+		return
+	}
+	if err := s.notifier.Notify(ctx, ev); err != nil {
+		log.Printf("task %s: notify %s: %v", ev.TaskID, ev.Type, err)
+	}
+}
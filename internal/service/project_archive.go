@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"TaskFlow/internal/domain"
+)
+
+// ErrProjectArchived is returned for writes to an archived project or to
+// its tasks.
+var ErrProjectArchived = errors.New("project is archived")
+
+// Archive hides a project from the default project list and makes it and
+// its tasks read-only until it is unarchived.
+func (s *ProjectService) Archive(ctx context.Context, userID, projectID string) (domain.Project, error) {
+	return s.setArchived(ctx, userID, projectID, true)
+}
+
+func (s *ProjectService) Unarchive(ctx context.Context, userID, projectID string) (domain.Project, error) {
+	return s.setArchived(ctx, userID, projectID, false)
+}
+
+func (s *ProjectService) setArchived(ctx context.Context, userID, projectID string, archived bool) (domain.Project, error) {
+	p, err := s.repo.SetArchived(ctx, userID, projectID, archived)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Project{}, ErrNotFound
+	}
+	return p, err
+}
+
+// checkWritable returns ErrProjectArchived if an owned project is archived,
+// and ErrNotFound if there is no such project.
+func (s *TaskService) checkWritable(ctx context.Context, userID, projectID string) error {
+	return checkProjectWritable(ctx, s.repo, userID, projectID)
+}
+
+type archivedProjects interface {
+	ProjectArchived(ctx context.Context, userID, projectID string) (bool, error)
+}
+
+func checkProjectWritable(ctx context.Context, repo archivedProjects, userID, projectID string) error {
+	return archivedErr(repo.ProjectArchived(ctx, userID, projectID))
+}
+
+type archivedTasks interface {
+	TaskArchived(ctx context.Context, userID, taskID string) (bool, error)
+}
+
+// checkTaskWritable is checkProjectWritable for the project of an owned
+// task.
+func checkTaskWritable(ctx context.Context, repo archivedTasks, userID, taskID string) error {
+	return archivedErr(repo.TaskArchived(ctx, userID, taskID))
+}
+
+// archivedErr turns the result of an archived lookup into ErrNotFound,
+// ErrProjectArchived or nil.
+func archivedErr(archived bool, err error) error {
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case err != nil:
+		return err
+	case archived:
+		return ErrProjectArchived
+	}
+	return nil
+}
+
+// writableTask returns an owned task, or ErrProjectArchived if its project
+// is archived.
+func (s *TaskService) writableTask(ctx context.Context, userID, taskID string) (domain.Task, error) {
+	t, err := s.repo.Get(ctx, userID, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Task{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+	return t, s.checkWritable(ctx, userID, t.ProjectID)
+}
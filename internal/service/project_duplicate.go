@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"TaskFlow/internal/domain"
+)
+
+// DefaultDuplicateAsyncTasks is the task count above which a project is
+// copied in the background unless configured otherwise.
+const DefaultDuplicateAsyncTasks = 1000
+
+var errDuplicationsUnset = errors.New("background project duplication is not configured")
+
+type DuplicationRepo interface {
+	Create(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Duplication, error)
+	Get(ctx context.Context, userID, id string) (domain.Duplication, error)
+	RunNext(ctx context.Context) (domain.Duplication, bool, error)
+}
+
+type ProjectOption func(*ProjectService)
+
+// WithDuplications copies projects with more than asyncTasks tasks in the
+// background instead of during the request.
+func WithDuplications(repo DuplicationRepo, asyncTasks int) ProjectOption {
+	return func(s *ProjectService) {
+		s.dups = repo
+		if asyncTasks >= 0 {
+			s.asyncTasks = asyncTasks
+		}
+	}
+}
+
+// DuplicateResult holds either the copy, made right away, or the queued
+// background copy of a large project.
+type DuplicateResult struct {
+	Project     *domain.Project
+	Duplication *domain.Duplication
+}
+
+// Duplicate copies a project with its labels, custom fields and tasks in
+// one transaction. Large projects are queued for a background job when one
+// is configured.
+func (s *ProjectService) Duplicate(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (DuplicateResult, error) {
+	opts.Name = strings.TrimSpace(opts.Name)
+	if opts.ShiftDays < -MaxDueInDays || opts.ShiftDays > MaxDueInDays {
+		return DuplicateResult{}, invalid("shiftDays", fmt.Sprintf("must be between -%d and %d", MaxDueInDays, MaxDueInDays))
+	}
+
+	if s.dups != nil {
+		n, err := s.repo.TaskCount(ctx, userID, projectID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return DuplicateResult{}, ErrNotFound
+		}
+		if err != nil {
+			return DuplicateResult{}, err
+		}
+		if n > s.asyncTasks {
+			d, err := s.dups.Create(ctx, userID, projectID, opts)
+			if errors.Is(err, sql.ErrNoRows) {
+				return DuplicateResult{}, ErrNotFound
+			}
+			if err != nil {
+				return DuplicateResult{}, err
+			}
+			return DuplicateResult{Duplication: &d}, nil
+		}
+	}
+
+	p, err := s.repo.Duplicate(ctx, userID, projectID, opts)
+	if errors.Is(err, sql.ErrNoRows) {
+		return DuplicateResult{}, ErrNotFound
+	}
+	if err != nil {
+		return DuplicateResult{}, err
+	}
+	return DuplicateResult{Project: &p}, nil
+}
+
+// Duplication returns the state of a background copy.
+func (s *ProjectService) Duplication(ctx context.Context, userID, id string) (domain.Duplication, error) {
+	if s.dups == nil {
+		return domain.Duplication{}, errDuplicationsUnset
+	}
+	d, err := s.dups.Get(ctx, userID, id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Duplication{}, ErrNotFound
+	}
+	return d, err
+}
+
+// RunDuplications makes queued copies, oldest first, until none is left.
+// It stops at the first error; a copy that failed has been marked failed,
+// so the next run moves on to the rest. It returns the number of copies
+// attempted.
+func (s *ProjectService) RunDuplications(ctx context.Context) (int, error) {
+	if s.dups == nil {
+		return 0, errDuplicationsUnset
+	}
+	ran := 0
+	for ctx.Err() == nil {
+		_, ok, err := s.dups.RunNext(ctx)
+		if ok {
+			ran++
+		}
+		if !ok || err != nil {
+			return ran, err
+		}
+	}
+	return ran, nil
+}
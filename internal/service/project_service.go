@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 
 	"TaskFlow/internal/domain"
 )
@@ -11,26 +12,54 @@ import (
 var ErrNotFound = errors.New("not found")
 
 type ProjectRepo interface {
-	Create(ctx context.Context, userID, name string) (domain.Project, error)
-	List(ctx context.Context, userID string, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error)
+	Create(ctx context.Context, userID, name, key string) (domain.Project, error)
+	List(ctx context.Context, userID string, archived bool, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error)
 	Get(ctx context.Context, userID, projectID string) (domain.Project, error)
-	UpdateName(ctx context.Context, userID, projectID, name string) (domain.Project, error)
+	Update(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error)
 	Delete(ctx context.Context, userID, projectID string) error
+	CreateFromBlueprint(ctx context.Context, userID string, bp domain.ProjectBlueprint) (domain.Project, error)
+	TaskCount(ctx context.Context, userID, projectID string) (int, error)
+	Duplicate(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error)
+	SetArchived(ctx context.Context, userID, projectID string, archived bool) (domain.Project, error)
 }
 
 type ProjectService struct {
 	repo ProjectRepo
+	dups DuplicationRepo
+	// asyncTasks is the task count above which copies run in the background.
+	asyncTasks int
 }
 
-func NewProjectService(repo ProjectRepo) *ProjectService {
-	return &ProjectService{repo: repo}
+func NewProjectService(repo ProjectRepo, opts ...ProjectOption) *ProjectService {
+	s := &ProjectService{repo: repo, asyncTasks: DefaultDuplicateAsyncTasks}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
 }
 
-func (s *ProjectService) Create(ctx context.Context, userID, name string) (domain.Project, error) {
-	return s.repo.Create(ctx, userID, name)
+// Create adds a project. An empty key is derived from the name; a given
+// one is upper-cased and may not belong, or have belonged, to another of
+// the user's projects.
+func (s *ProjectService) Create(ctx context.Context, userID, name, key string) (domain.Project, error) {
+	if key != "" {
+		var ok bool
+		if key, ok = domain.NormalizeProjectKey(key); !ok {
+			return domain.Project{}, invalid("key", projectKeyMessage)
+		}
+	}
+	p, err := s.repo.Create(ctx, userID, name, key)
+	if errors.Is(err, domain.ErrDuplicate) {
+		return domain.Project{}, ErrConflict
+	}
+	return p, err
 }
 
-func (s *ProjectService) List(ctx context.Context, userID string, limit int, cursor *domain.Cursor) (Page[domain.Project], error) {
+const projectKeyMessage = "must be 2 to 10 letters and digits, starting with a letter"
+
+// List returns a page of projects: the archived ones if archived is set,
+// the others otherwise.
+func (s *ProjectService) List(ctx context.Context, userID string, archived bool, limit int, cursor *domain.Cursor) (Page[domain.Project], error) {
 	if limit <= 0 {
 		limit = 20
 	}
@@ -38,7 +67,7 @@ func (s *ProjectService) List(ctx context.Context, userID string, limit int, cur
 		limit = 100
 	}
 
-	items, next, err := s.repo.List(ctx, userID, limit, cursor)
+	items, next, err := s.repo.List(ctx, userID, archived, limit, cursor)
 	if err != nil {
 		return Page[domain.Project]{}, err
 	}
@@ -53,10 +82,42 @@ func (s *ProjectService) Get(ctx context.Context, userID, projectID string) (dom
 	return p, err
 }
 
-func (s *ProjectService) UpdateName(ctx context.Context, userID, projectID, name string) (domain.Project, error) {
-	p, err := s.repo.UpdateName(ctx, userID, projectID, name)
-	if errors.Is(err, sql.ErrNoRows) {
+// Update applies a partial update. The name is trimmed and may not be
+// empty; the estimate unit must be one of the known units. A new key is
+// upper-cased, and the old one keeps resolving to the project's tasks. An
+// archived project has to be unarchived first.
+func (s *ProjectService) Update(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
+	if patch.Name != nil {
+		name := strings.TrimSpace(*patch.Name)
+		if name == "" {
+			return domain.Project{}, invalid("name", "cannot be empty")
+		}
+		patch.Name = &name
+	}
+	if patch.EstimateUnit != nil && !patch.EstimateUnit.Valid() {
+		return domain.Project{}, invalid("estimateUnit", "must be one of: points, hours")
+	}
+	if patch.Key != nil {
+		key, ok := domain.NormalizeProjectKey(*patch.Key)
+		if !ok {
+			return domain.Project{}, invalid("key", projectKeyMessage)
+		}
+		patch.Key = &key
+	}
+	cur, err := s.Get(ctx, userID, projectID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+	if cur.ArchivedAt != nil {
+		return domain.Project{}, ErrProjectArchived
+	}
+
+	p, err := s.repo.Update(ctx, userID, projectID, patch)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
 		return domain.Project{}, ErrNotFound
+	case errors.Is(err, domain.ErrDuplicate):
+		return domain.Project{}, ErrConflict
 	}
 	return p, err
 }
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+)
+
+type SprintRepo interface {
+	Create(ctx context.Context, userID, projectID string, sp domain.Sprint) (domain.Sprint, error)
+	List(ctx context.Context, userID, projectID string) ([]domain.Sprint, error)
+	Get(ctx context.Context, userID, sprintID string) (domain.Sprint, error)
+	Update(ctx context.Context, userID, sprintID string, patch domain.SprintPatch) (domain.Sprint, error)
+	Delete(ctx context.Context, userID, sprintID string) error
+	Complete(ctx context.Context, userID, sprintID string, nextID *string) (domain.SprintCompletion, error)
+	Stats(ctx context.Context, userID, sprintID string) (domain.SprintStats, error)
+	ProjectArchived(ctx context.Context, userID, projectID string) (bool, error)
+}
+
+// ErrSprintCompleted is returned when completing a sprint that is already
+// completed.
+var ErrSprintCompleted = errors.New("sprint is already completed")
+
+var errSprintsUnset = errors.New("sprints are not configured")
+
+// Limits on sprint fields.
+const (
+	maxSprintName       = 100
+	MaxSprintGoalLength = 1000
+)
+
+type SprintService struct {
+	repo SprintRepo
+}
+
+func NewSprintService(repo SprintRepo) *SprintService { return &SprintService{repo: repo} }
+
+// Create schedules a sprint in a project. Sprints may overlap; the order
+// in which unfinished work is carried over follows their start dates.
+func (s *SprintService) Create(ctx context.Context, userID, projectID string, sp domain.Sprint) (domain.Sprint, error) {
+	sp.Name = strings.TrimSpace(sp.Name)
+	if err := validateSprintName(sp.Name); err != nil {
+		return domain.Sprint{}, err
+	}
+	if err := validateSprintGoal(sp.Goal); err != nil {
+		return domain.Sprint{}, err
+	}
+	if err := validateSprintDates(sp.StartDate, sp.EndDate); err != nil {
+		return domain.Sprint{}, err
+	}
+	if err := checkProjectWritable(ctx, s.repo, userID, projectID); err != nil {
+		return domain.Sprint{}, err
+	}
+	out, err := s.repo.Create(ctx, userID, projectID, sp)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Sprint{}, ErrNotFound
+	}
+	return out, err
+}
+
+func (s *SprintService) List(ctx context.Context, userID, projectID string) ([]domain.Sprint, error) {
+	return s.repo.List(ctx, userID, projectID)
+}
+
+func (s *SprintService) Get(ctx context.Context, userID, sprintID string) (domain.Sprint, error) {
+	sp, err := s.repo.Get(ctx, userID, sprintID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Sprint{}, ErrNotFound
+	}
+	return sp, err
+}
+
+// Update renames a sprint or changes its goal or dates. A new start or end
+// is checked against the date the patch leaves unchanged.
+func (s *SprintService) Update(ctx context.Context, userID, sprintID string, patch domain.SprintPatch) (domain.Sprint, error) {
+	if patch.Name != nil {
+		name := strings.TrimSpace(*patch.Name)
+		if err := validateSprintName(name); err != nil {
+			return domain.Sprint{}, err
+		}
+		patch.Name = &name
+	}
+	if patch.Goal != nil {
+		if err := validateSprintGoal(*patch.Goal); err != nil {
+			return domain.Sprint{}, err
+		}
+	}
+	cur, err := s.writable(ctx, userID, sprintID)
+	if err != nil {
+		return domain.Sprint{}, err
+	}
+	if patch.StartDate != nil || patch.EndDate != nil {
+		start, end := cur.StartDate, cur.EndDate
+		if patch.StartDate != nil {
+			start = *patch.StartDate
+		}
+		if patch.EndDate != nil {
+			end = *patch.EndDate
+		}
+		if err := validateSprintDates(start, end); err != nil {
+			return domain.Sprint{}, err
+		}
+	}
+	out, err := s.repo.Update(ctx, userID, sprintID, patch)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Sprint{}, ErrNotFound
+	}
+	return out, err
+}
+
+// Delete removes a sprint and returns its tasks to the backlog.
+func (s *SprintService) Delete(ctx context.Context, userID, sprintID string) error {
+	if _, err := s.writable(ctx, userID, sprintID); err != nil {
+		return err
+	}
+	err := s.repo.Delete(ctx, userID, sprintID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Complete closes a sprint and carries its unfinished tasks to nextID, or
+// by default to the project's next open sprint by start date. Without one
+// they go back to the backlog.
+func (s *SprintService) Complete(ctx context.Context, userID, sprintID string, nextID *string) (domain.SprintCompletion, error) {
+	if nextID != nil && *nextID == sprintID {
+		return domain.SprintCompletion{}, invalid("nextSprintId", "cannot be the sprint itself")
+	}
+	cur, err := s.writable(ctx, userID, sprintID)
+	if err != nil {
+		return domain.SprintCompletion{}, err
+	}
+	if cur.CompletedAt != nil {
+		return domain.SprintCompletion{}, ErrSprintCompleted
+	}
+	out, err := s.repo.Complete(ctx, userID, sprintID, nextID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.SprintCompletion{}, ErrNotFound
+	case errors.Is(err, domain.ErrSprintCompleted):
+		return domain.SprintCompletion{}, ErrSprintCompleted
+	case errors.Is(err, domain.ErrInvalidSprint):
+		return domain.SprintCompletion{}, invalid("nextSprintId", "must be another open sprint of the same project")
+	}
+	return out, err
+}
+
+// Stats reports a sprint's task counts, estimate sums and completion rate.
+func (s *SprintService) Stats(ctx context.Context, userID, sprintID string) (domain.SprintStats, error) {
+	st, err := s.repo.Stats(ctx, userID, sprintID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.SprintStats{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.SprintStats{}, err
+	}
+	if st.TotalTasks > 0 {
+		st.CompletionRate = float64(st.CompletedTasks) / float64(st.TotalTasks)
+	}
+	return st, nil
+}
+
+// writable returns an owned sprint, or ErrProjectArchived if its project
+// is archived.
+func (s *SprintService) writable(ctx context.Context, userID, sprintID string) (domain.Sprint, error) {
+	sp, err := s.Get(ctx, userID, sprintID)
+	if err != nil {
+		return domain.Sprint{}, err
+	}
+	return sp, checkProjectWritable(ctx, s.repo, userID, sp.ProjectID)
+}
+
+// checkSprint returns a validation error unless sprintID is an open sprint
+// of projectID.
+func (s *TaskService) checkSprint(ctx context.Context, userID, projectID, sprintID string) error {
+	if s.sprints == nil {
+		return errSprintsUnset
+	}
+	if sprintID == "" {
+		return invalid("sprintId", "cannot be empty")
+	}
+	sp, err := s.sprints.Get(ctx, userID, sprintID)
+	if errors.Is(err, sql.ErrNoRows) || (err == nil && sp.ProjectID != projectID) {
+		return invalid("sprintId", "must be a sprint of the task's project")
+	}
+	if err != nil {
+		return err
+	}
+	if sp.CompletedAt != nil {
+		return invalid("sprintId", "is already completed")
+	}
+	return nil
+}
+
+func validateSprintName(name string) error {
+	if name == "" {
+		return invalid("name", "required")
+	}
+	if utf8.RuneCountInString(name) > maxSprintName {
+		return invalid("name", "must be at most 100 characters")
+	}
+	return nil
+}
+
+func validateSprintGoal(goal string) error {
+	if utf8.RuneCountInString(goal) > MaxSprintGoalLength {
+		return invalid("goal", "must be at most 1000 characters")
+	}
+	return nil
+}
+
+func validateSprintDates(start, end string) error {
+	from, err := time.Parse("2006-01-02", start)
+	if err != nil {
+		return invalid("startDate", "must be a YYYY-MM-DD date")
+	}
+	to, err := time.Parse("2006-01-02", end)
+	if err != nil {
+		return invalid("endDate", "must be a YYYY-MM-DD date")
+	}
+	if to.Before(from) {
+		return invalid("endDate", "cannot be before startDate")
+	}
+	return nil
+}
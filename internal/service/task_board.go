@@ -0,0 +1,56 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+
+	"TaskFlow/internal/domain"
+)
+
+// DefaultBoardLimit is how many tasks each board column returns unless the
+// caller asks otherwise; MaxBoardLimit caps it.
+const (
+	DefaultBoardLimit = 20
+	MaxBoardLimit     = 100
+)
+
+// Board returns a project's tasks grouped into status columns. Columns not
+// listed in q.Statuses are omitted, and each column pages independently
+// through its own cursor.
+func (s *TaskService) Board(ctx context.Context, userID, projectID string, q domain.BoardQuery) ([]domain.BoardColumn, error) {
+	if q.Limit <= 0 {
+		q.Limit = DefaultBoardLimit
+	}
+	if q.Limit > MaxBoardLimit {
+		q.Limit = MaxBoardLimit
+	}
+	if len(q.Statuses) == 0 {
+		q.Statuses = domain.TaskStatuses
+	}
+	seen := map[domain.TaskStatus]bool{}
+	for _, st := range q.Statuses {
+		if !st.Valid() {
+			return nil, invalid("columns", "must be statuses: todo, in_progress, done")
+		}
+		if seen[st] {
+			return nil, invalid("columns", "must not repeat a status")
+		}
+		seen[st] = true
+	}
+	for st, c := range q.Cursors {
+		if c.Sort != domain.BoardSort || len(c.Values) != 2 || c.Values[0] != string(st) {
+			return nil, invalid("cursor", "must be a column nextCursorToken from a previous board")
+		}
+		if !slices.Contains(q.Statuses, st) {
+			return nil, invalid("cursor", "is for a column that was not requested")
+		}
+	}
+
+	cols, err := s.repo.Board(ctx, userID, projectID, q)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return cols, err
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+
+	"TaskFlow/internal/domain"
+)
+
+// MaxEstimate is the largest task estimate accepted, in the project's
+// estimate unit. Estimates are stored with two decimal places.
+const MaxEstimate = 999999.99
+
+// MaxBurndownDays caps how many days a single burndown request may span.
+const MaxBurndownDays = 366
+
+func validateEstimate(e float64) error {
+	if math.IsNaN(e) || e < 0 || e > MaxEstimate {
+		return invalid("estimate", "must be between 0 and 999999.99")
+	}
+	return nil
+}
+
+// Burndown returns the project's open and completed task counts and
+// estimate sums at the end of each day from q.From to q.To inclusive.
+// Days are calendar days in q.Location, which defaults to UTC.
+func (s *TaskService) Burndown(ctx context.Context, userID, projectID string, q domain.BurndownQuery) (domain.Burndown, error) {
+	if q.From.IsZero() {
+		return domain.Burndown{}, invalid("from", "is required")
+	}
+	if q.To.IsZero() {
+		return domain.Burndown{}, invalid("to", "is required")
+	}
+	if q.Location == nil {
+		q.Location = time.UTC
+	}
+	from := time.Date(q.From.Year(), q.From.Month(), q.From.Day(), 0, 0, 0, 0, time.UTC)
+	to := time.Date(q.To.Year(), q.To.Month(), q.To.Day(), 0, 0, 0, 0, time.UTC)
+	if to.Before(from) {
+		return domain.Burndown{}, invalid("to", "must not be before from")
+	}
+	if int(to.Sub(from).Hours()/24)+1 > MaxBurndownDays {
+		return domain.Burndown{}, invalid("to", "must be at most 366 days after from")
+	}
+	q.From, q.To = from, to
+
+	b, err := s.repo.Burndown(ctx, userID, projectID, q)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Burndown{}, ErrNotFound
+	}
+	return b, err
+}
@@ -0,0 +1,194 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+)
+
+var errChecklistsUnset = errors.New("task checklists are not configured")
+
+// MaxChecklistItemLength is the maximum length of a checklist item's text,
+// in characters.
+const MaxChecklistItemLength = 500
+
+type ChecklistRepo interface {
+	Create(ctx context.Context, userID, taskID, text string, checked bool) (domain.ChecklistItem, error)
+	List(ctx context.Context, userID, taskID string) ([]domain.ChecklistItem, error)
+	Get(ctx context.Context, userID, itemID string) (domain.ChecklistItem, error)
+	Update(ctx context.Context, userID, itemID string, patch domain.ChecklistItemPatch) (domain.ChecklistItem, error)
+	Delete(ctx context.Context, userID, itemID string) error
+	Move(ctx context.Context, userID, itemID string, afterID, beforeID *string) (domain.ChecklistItem, error)
+	ConvertToTask(ctx context.Context, userID, itemID string) (domain.Task, error)
+}
+
+// WithChecklists enables checklist items inside tasks.
+func WithChecklists(repo ChecklistRepo) TaskOption {
+	return func(s *TaskService) { s.checklists = repo }
+}
+
+func (s *TaskService) AddChecklistItem(ctx context.Context, userID, taskID, text string, checked bool) (domain.ChecklistItem, error) {
+	if s.checklists == nil {
+		return domain.ChecklistItem{}, errChecklistsUnset
+	}
+	text, err := normalizeChecklistText(text)
+	if err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	if _, err := s.writableTask(ctx, userID, taskID); err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	it, err := s.checklists.Create(ctx, userID, taskID, text, checked)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ChecklistItem{}, ErrNotFound
+	}
+	return it, err
+}
+
+// Checklist returns a task's checklist items in order.
+func (s *TaskService) Checklist(ctx context.Context, userID, taskID string) ([]domain.ChecklistItem, error) {
+	if s.checklists == nil {
+		return nil, errChecklistsUnset
+	}
+	items, err := s.checklists.List(ctx, userID, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return items, err
+}
+
+func (s *TaskService) UpdateChecklistItem(ctx context.Context, userID, itemID string, patch domain.ChecklistItemPatch) (domain.ChecklistItem, error) {
+	if s.checklists == nil {
+		return domain.ChecklistItem{}, errChecklistsUnset
+	}
+	if patch.Text != nil {
+		text, err := normalizeChecklistText(*patch.Text)
+		if err != nil {
+			return domain.ChecklistItem{}, err
+		}
+		patch.Text = &text
+	}
+	if err := s.checkItemWritable(ctx, userID, itemID); err != nil {
+		return domain.ChecklistItem{}, err
+	}
+	it, err := s.checklists.Update(ctx, userID, itemID, patch)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.ChecklistItem{}, ErrNotFound
+	}
+	return it, err
+}
+
+func (s *TaskService) DeleteChecklistItem(ctx context.Context, userID, itemID string) error {
+	if s.checklists == nil {
+		return errChecklistsUnset
+	}
+	if err := s.checkItemWritable(ctx, userID, itemID); err != nil {
+		return err
+	}
+	err := s.checklists.Delete(ctx, userID, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// MoveChecklistItem places an item right after afterID and/or right before
+// beforeID within its task's checklist.
+func (s *TaskService) MoveChecklistItem(ctx context.Context, userID, itemID string, afterID, beforeID *string) (domain.ChecklistItem, error) {
+	if s.checklists == nil {
+		return domain.ChecklistItem{}, errChecklistsUnset
+	}
+	if afterID == nil && beforeID == nil {
+		return domain.ChecklistItem{}, invalid("afterId", "or beforeId required")
+	}
+	if afterID != nil && *afterID == itemID {
+		return domain.ChecklistItem{}, invalid("afterId", "cannot be the item itself")
+	}
+	if beforeID != nil && *beforeID == itemID {
+		return domain.ChecklistItem{}, invalid("beforeId", "cannot be the item itself")
+	}
+	if err := s.checkItemWritable(ctx, userID, itemID); err != nil {
+		return domain.ChecklistItem{}, err
+	}
+
+	it, err := s.checklists.Move(ctx, userID, itemID, afterID, beforeID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.ChecklistItem{}, ErrNotFound
+	case errors.Is(err, domain.ErrInvalidNeighbor):
+		field := "afterId"
+		if afterID == nil {
+			field = "beforeId"
+		}
+		return domain.ChecklistItem{}, invalid(field, "must be an adjacent pair of other items in the same checklist")
+	}
+	return it, err
+}
+
+// ConvertChecklistItem turns a checklist item into a subtask of its task
+// and removes it from the checklist. The subtask must fit within the
+// nesting limit.
+func (s *TaskService) ConvertChecklistItem(ctx context.Context, userID, itemID string) (domain.Task, error) {
+	if s.checklists == nil {
+		return domain.Task{}, errChecklistsUnset
+	}
+	it, err := s.checklists.Get(ctx, userID, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Task{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if _, err := s.writableTask(ctx, userID, it.TaskID); err != nil {
+		return domain.Task{}, err
+	}
+	ancestors, err := s.repo.Ancestors(ctx, userID, it.TaskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Task{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if len(ancestors)+1 > s.maxDepth {
+		return domain.Task{}, invalid("id", fmt.Sprintf("would nest subtasks deeper than %d levels", s.maxDepth))
+	}
+
+	t, err := s.checklists.ConvertToTask(ctx, userID, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Task{}, ErrNotFound
+	}
+	return t, err
+}
+
+// checkItemWritable returns ErrNotFound if there is no such item and
+// ErrProjectArchived if its task's project is archived.
+func (s *TaskService) checkItemWritable(ctx context.Context, userID, itemID string) error {
+	it, err := s.checklists.Get(ctx, userID, itemID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	_, err = s.writableTask(ctx, userID, it.TaskID)
+	return err
+}
+
+func normalizeChecklistText(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", invalid("text", "required")
+	}
+	if !utf8.ValidString(text) || strings.ContainsRune(text, 0) {
+		return "", invalid("text", "must be valid UTF-8 text")
+	}
+	if utf8.RuneCountInString(text) > MaxChecklistItemLength {
+		return "", invalid("text", "must be at most 500 characters")
+	}
+	return text, nil
+}
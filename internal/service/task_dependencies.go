@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"TaskFlow/internal/domain"
+)
+
+var (
+	ErrBlocked           = errors.New("task is blocked by open dependencies")
+	errDependenciesUnset = errors.New("task dependencies are not configured")
+)
+
+type DependencyRepo interface {
+	Add(ctx context.Context, userID, blockerID, blockedID string) error
+	Remove(ctx context.Context, userID, blockerID, blockedID string) error
+	List(ctx context.Context, userID, taskID string) (domain.TaskDependencies, error)
+}
+
+// AddBlocker records that blockerID blocks taskID. The tasks may live in
+// different projects as long as the caller can access both. Links that
+// would close a cycle are rejected; the repository checks for cycles and
+// stores the link in one transaction.
+func (s *TaskService) AddBlocker(ctx context.Context, userID, taskID, blockerID string) error {
+	if s.deps == nil {
+		return errDependenciesUnset
+	}
+	if blockerID == "" {
+		return invalid("blockerId", "required")
+	}
+	if blockerID == taskID {
+		return invalid("blockerId", "a task cannot block itself")
+	}
+	if _, err := s.writableTask(ctx, userID, taskID); err != nil {
+		return err
+	}
+	if _, err := s.repo.Get(ctx, userID, blockerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invalid("blockerId", "not found")
+		}
+		return err
+	}
+
+	err := s.deps.Add(ctx, userID, blockerID, taskID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrNotFound
+	case errors.Is(err, domain.ErrDependencyCycle):
+		return invalid("blockerId", "would create a dependency cycle")
+	}
+	return err
+}
+
+func (s *TaskService) RemoveBlocker(ctx context.Context, userID, taskID, blockerID string) error {
+	if s.deps == nil {
+		return errDependenciesUnset
+	}
+	if _, err := s.writableTask(ctx, userID, taskID); err != nil {
+		return err
+	}
+	err := s.deps.Remove(ctx, userID, blockerID, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+func (s *TaskService) Dependencies(ctx context.Context, userID, taskID string) (domain.TaskDependencies, error) {
+	if s.deps == nil {
+		return domain.TaskDependencies{}, errDependenciesUnset
+	}
+	if _, err := s.Get(ctx, userID, taskID); err != nil {
+		return domain.TaskDependencies{}, err
+	}
+	return s.deps.List(ctx, userID, taskID)
+}
@@ -0,0 +1,73 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"TaskFlow/internal/domain"
+)
+
+// MaxPositionLength is the position key length past which a project's
+// manual order is rewritten with short, evenly spaced keys.
+const MaxPositionLength = 24
+
+// rebalanceBatch caps how many projects one RebalancePositions call rewrites.
+const rebalanceBatch = 100
+
+// Move places a task in its project's manual order, right after afterID
+// and/or right before beforeID. Only the moved task's position changes.
+func (s *TaskService) Move(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error) {
+	if afterID == nil && beforeID == nil {
+		return domain.Task{}, invalid("afterId", "or beforeId required")
+	}
+	if afterID != nil && *afterID == taskID {
+		return domain.Task{}, invalid("afterId", "cannot be the task itself")
+	}
+	if beforeID != nil && *beforeID == taskID {
+		return domain.Task{}, invalid("beforeId", "cannot be the task itself")
+	}
+	if _, err := s.writableTask(ctx, userID, taskID); err != nil {
+		return domain.Task{}, err
+	}
+
+	t, err := s.repo.Move(ctx, userID, taskID, afterID, beforeID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Task{}, ErrNotFound
+	case errors.Is(err, domain.ErrInvalidNeighbor):
+		field := "afterId"
+		if afterID == nil {
+			field = "beforeId"
+		}
+		return domain.Task{}, invalid(field, "must be an adjacent pair of other tasks in the same project")
+	}
+	return t, err
+}
+
+// RebalancePositions rewrites the manual order of projects whose position
+// keys have grown too long or collided. A project that fails is skipped and
+// picked up again on the next run. It returns the number of projects fixed.
+func (s *TaskService) RebalancePositions(ctx context.Context) (int, error) {
+	ids, err := s.repo.ProjectsToRebalance(ctx, MaxPositionLength, rebalanceBatch)
+	if err != nil {
+		return 0, err
+	}
+	fixed := 0
+	var firstErr error
+	for _, id := range ids {
+		err := s.repo.RebalancePositions(ctx, id)
+		if errors.Is(err, sql.ErrNoRows) {
+			// The project was deleted in the meantime.
+			continue
+		}
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		fixed++
+	}
+	return fixed, firstErr
+}
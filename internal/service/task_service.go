@@ -4,76 +4,544 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"log"
+	"strconv"
 	"strings"
+	"time"
+	"unicode/utf8"
 
 	"TaskFlow/internal/domain"
+	"TaskFlow/internal/recurrence"
+
+	"github.com/google/uuid"
 )
 
 type TaskRepo interface {
-	Create(ctx context.Context, userID, projectID, title string) (domain.Task, error)
-	List(ctx context.Context, userID, projectID string, completed *bool, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error)
+	Create(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error)
+	List(ctx context.Context, userID string, f domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error)
 	Get(ctx context.Context, userID, taskID string) (domain.Task, error)
-	Update(ctx context.Context, userID, taskID string, title *string, completed *bool) (domain.Task, error)
-	Delete(ctx context.Context, userID, taskID string) error
+	GetByKey(ctx context.Context, userID, projectKey string, number int) (domain.Task, error)
+	Update(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error)
+	Delete(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error
+	Ancestors(ctx context.Context, userID, taskID string) ([]string, error)
+	Subtree(ctx context.Context, userID, taskID string) ([]domain.Task, error)
+	CreateNextOccurrence(ctx context.Context, userID, prevID string, due *time.Time, occurrence int) (domain.Task, error)
+	Move(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error)
+	ProjectsToRebalance(ctx context.Context, maxLen, limit int) ([]string, error)
+	RebalancePositions(ctx context.Context, projectID string) error
+	Board(ctx context.Context, userID, projectID string, q domain.BoardQuery) ([]domain.BoardColumn, error)
+	Burndown(ctx context.Context, userID, projectID string, q domain.BurndownQuery) (domain.Burndown, error)
+	ProjectArchived(ctx context.Context, userID, projectID string) (bool, error)
 }
 
+// MaxDescriptionLength is the maximum length of a task description, in characters.
+const MaxDescriptionLength = 20000
+
+// DefaultMaxDepth is how many levels of subtasks are allowed below a
+// top-level task unless configured otherwise.
+const DefaultMaxDepth = 5
+
 type TaskService struct {
-	repo TaskRepo
+	repo       TaskRepo
+	deps       DependencyRepo
+	checklists ChecklistRepo
+	fields     CustomFieldRepo
+	sprints    SprintRepo
+	watchers   WatcherRepo
+	notifier   Notifier
+	maxDepth   int
+	// enforceBlockers rejects completing a task while it has open blockers.
+	enforceBlockers bool
+	now             func() time.Time
+}
+
+type TaskOption func(*TaskService)
+
+// WithMaxDepth limits how deeply subtasks may be nested below a top-level task.
+func WithMaxDepth(n int) TaskOption {
+	return func(s *TaskService) {
+		if n >= 0 {
+			s.maxDepth = n
+		}
+	}
+}
+
+// WithDependencies enables blocker/blocked-by links between tasks.
+func WithDependencies(repo DependencyRepo) TaskOption {
+	return func(s *TaskService) { s.deps = repo }
+}
+
+// WithBlockerEnforcement makes Update refuse to complete a task that is
+// still blocked by an open task.
+func WithBlockerEnforcement(enabled bool) TaskOption {
+	return func(s *TaskService) { s.enforceBlockers = enabled }
+}
+
+// WithClock replaces time.Now, which dates occurrences of recurring tasks
+// regenerated from their completion.
+func WithClock(now func() time.Time) TaskOption {
+	return func(s *TaskService) { s.now = now }
+}
+
+// WithCustomFields enables per-project custom field values on tasks,
+// including filtering and sorting by them.
+func WithCustomFields(repo CustomFieldRepo) TaskOption {
+	return func(s *TaskService) { s.fields = repo }
+}
+
+// WithSprints lets tasks be planned into a project's sprints.
+func WithSprints(repo SprintRepo) TaskOption {
+	return func(s *TaskService) { s.sprints = repo }
 }
 
-func NewTaskService(repo TaskRepo) *TaskService { return &TaskService{repo: repo} }
+// WithWatchers subscribes the creator and assignees of a task to it, and
+// lets Recipients report who follows a task.
+func WithWatchers(repo WatcherRepo) TaskOption {
+	return func(s *TaskService) { s.watchers = repo }
+}
+
+// WithNotifier reports assignments and completions to n.
+func WithNotifier(n Notifier) TaskOption {
+	return func(s *TaskService) { s.notifier = n }
+}
 
-func (s *TaskService) Create(ctx context.Context, userID, projectID, title string) (domain.Task, error) {
-	title = strings.TrimSpace(title)
-	if title == "" {
-		return domain.Task{}, errors.New("title required")
+func NewTaskService(repo TaskRepo, opts ...TaskOption) *TaskService {
+	s := &TaskService{repo: repo, maxDepth: DefaultMaxDepth, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *TaskService) Create(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+	in.Title = strings.TrimSpace(in.Title)
+	if in.Title == "" {
+		return domain.Task{}, invalid("title", "required")
+	}
+	if !in.Priority.Valid() {
+		return domain.Task{}, invalid("priority", "is not a known priority")
+	}
+	if in.Status == "" {
+		in.Status = domain.StatusTodo
+	}
+	if !in.Status.Valid() {
+		return domain.Task{}, invalid("status", "must be todo, in_progress or done")
 	}
-	t, err := s.repo.Create(ctx, userID, projectID, title)
+	if err := validateDescription(in.Description); err != nil {
+		return domain.Task{}, err
+	}
+	if in.Estimate != nil {
+		if err := validateEstimate(*in.Estimate); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if in.Recurrence != nil {
+		if err := normalizeRecurrence(in.Recurrence); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if err := s.checkWritable(ctx, userID, projectID); err != nil {
+		return domain.Task{}, err
+	}
+	if in.SprintID != nil {
+		if err := s.checkSprint(ctx, userID, projectID, *in.SprintID); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if len(in.CustomFields) > 0 {
+		values, err := s.normalizeCustomValues(ctx, userID, projectID, in.CustomFields)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		in.CustomFields = values
+	}
+	if in.ParentTaskID != nil {
+		depth, err := s.parentDepth(ctx, userID, projectID, *in.ParentTaskID)
+		if err != nil {
+			return domain.Task{}, err
+		}
+		if depth+1 > s.maxDepth {
+			return domain.Task{}, invalid("parentTaskId", fmt.Sprintf("would nest subtasks deeper than %d levels", s.maxDepth))
+		}
+	}
+	t, err := s.repo.Create(ctx, userID, projectID, in)
 	if errors.Is(err, sql.ErrNoRows) {
 		return domain.Task{}, ErrNotFound
 	}
-	return t, err
+	if err != nil {
+		return domain.Task{}, err
+	}
+	s.followNew(ctx, userID, projectID, t)
+	return t, nil
 }
 
-func (s *TaskService) List(ctx context.Context, userID, projectID string, completed *bool, limit int, cursor *domain.Cursor) (Page[domain.Task], error) {
+func (s *TaskService) List(ctx context.Context, userID string, f domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) (Page[domain.Task], error) {
 	if limit <= 0 {
 		limit = 20
 	}
 	if limit > 100 {
 		limit = 100
 	}
-	items, next, err := s.repo.List(ctx, userID, projectID, completed, limit, cursor)
+	// A cursor is only meaningful for the ordering it was issued under.
+	if cursor != nil && (cursor.Sort != domain.FormatSort(sort) || len(cursor.Values) != len(sort)) {
+		return Page[domain.Task]{}, invalid("cursor", "does not match the requested sort")
+	}
+	switch f.LabelMatch {
+	case "":
+		f.LabelMatch = domain.LabelMatchAny
+	case domain.LabelMatchAny, domain.LabelMatchAll:
+	default:
+		return Page[domain.Task]{}, invalid("labelMatch", "must be any or all")
+	}
+	if f.Status != nil && !f.Status.Valid() {
+		return Page[domain.Task]{}, invalid("status", "must be todo, in_progress or done")
+	}
+	if f.CompletedAfter != nil && f.CompletedBefore != nil && !f.CompletedBefore.After(*f.CompletedAfter) {
+		return Page[domain.Task]{}, invalid("completedBefore", "must be after completedAfter")
+	}
+	if err := s.resolveCustomQuery(ctx, userID, &f, sort); err != nil {
+		return Page[domain.Task]{}, err
+	}
+	if cursor != nil && !validTaskCursor(sort, *cursor) {
+		return Page[domain.Task]{}, invalid("cursor", "does not match the requested sort")
+	}
+	items, next, err := s.repo.List(ctx, userID, f, sort, limit, cursor)
 	if err != nil {
 		return Page[domain.Task]{}, err
 	}
 	return Page[domain.Task]{Items: items, NextCursor: next}, nil
 }
 
+// validTaskCursor reports whether each of c's values parses as the type
+// its sort key is compared as, so a tampered cursor is rejected here rather
+// than failing the query. Custom keys must already carry their FieldType.
+func validTaskCursor(sort []domain.SortKey, c domain.Cursor) bool {
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return false
+	}
+	for i, k := range sort {
+		v := c.Values[i]
+		var err error
+		switch {
+		case k.Field == "priority":
+			_, err = strconv.ParseInt(v, 10, 16)
+		case k.Field == "dueDate" && (v == "infinity" || v == "-infinity"):
+		case k.Field == "dueDate", k.Field == "createdAt", k.Field == "updatedAt":
+			_, err = time.Parse(time.RFC3339Nano, v)
+		case k.FieldType == domain.FieldNumber:
+			_, err = strconv.ParseFloat(v, 64)
+		case k.FieldType == domain.FieldDate && (v == "infinity" || v == "-infinity"):
+		case k.FieldType == domain.FieldDate:
+			_, err = time.Parse("2006-01-02", v)
+		default:
+			// Text keys take any string Postgres can store.
+			if strings.ContainsRune(v, 0) {
+				return false
+			}
+		}
+		if err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// Get returns a task by ID or by key, such as WEB-123. Keys using one of
+// the project's earlier keys resolve too.
 func (s *TaskService) Get(ctx context.Context, userID, taskID string) (domain.Task, error) {
-	t, err := s.repo.Get(ctx, userID, taskID)
+	var (
+		t   domain.Task
+		err error
+	)
+	if key, number, ok := domain.ParseTaskKey(taskID); ok {
+		t, err = s.repo.GetByKey(ctx, userID, key, number)
+	} else {
+		t, err = s.repo.Get(ctx, userID, taskID)
+	}
 	if errors.Is(err, sql.ErrNoRows) {
 		return domain.Task{}, ErrNotFound
 	}
 	return t, err
 }
 
-func (s *TaskService) Update(ctx context.Context, userID, taskID string, title *string, completed *bool) (domain.Task, error) {
-	if title != nil {
-		trim := strings.TrimSpace(*title)
+func (s *TaskService) Update(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+	if patch.Title != nil {
+		trim := strings.TrimSpace(*patch.Title)
 		if trim == "" {
-			return domain.Task{}, errors.New("title cannot be empty")
+			return domain.Task{}, invalid("title", "cannot be empty")
+		}
+		patch.Title = &trim
+	}
+	if patch.Priority != nil && !patch.Priority.Valid() {
+		return domain.Task{}, invalid("priority", "is not a known priority")
+	}
+	// Moving to or out of the done column completes or reopens the task,
+	// so the status is folded into Completed for the checks below.
+	if patch.Status != nil {
+		if !patch.Status.Valid() {
+			return domain.Task{}, invalid("status", "must be todo, in_progress or done")
+		}
+		done := *patch.Status == domain.StatusDone
+		if patch.Completed != nil && *patch.Completed != done {
+			return domain.Task{}, invalid("status", "conflicts with completed")
+		}
+		patch.Completed = &done
+	}
+	if patch.Description != nil {
+		if err := validateDescription(*patch.Description); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if patch.Estimate != nil {
+		if err := validateEstimate(*patch.Estimate); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if patch.ProjectID != nil && *patch.ProjectID == "" {
+		return domain.Task{}, invalid("projectId", "cannot be empty")
+	}
+	cur, err := s.writableTask(ctx, userID, taskID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	// A task moved to another project takes its subtasks along; a new
+	// parent and custom field values are then checked against the target.
+	var moveTo string
+	if patch.ProjectID != nil {
+		if *patch.ProjectID == cur.ProjectID {
+			patch.ProjectID = nil
+		} else {
+			moveTo = *patch.ProjectID
+			err := s.checkWritable(ctx, userID, moveTo)
+			if errors.Is(err, ErrNotFound) {
+				return domain.Task{}, invalid("projectId", "not found")
+			}
+			if err != nil {
+				return domain.Task{}, err
+			}
+		}
+	}
+	// The new parent is checked by the repo, under a lock on the project,
+	// since a concurrent reparent could otherwise close a cycle.
+	if patch.ParentTaskID != nil {
+		if *patch.ParentTaskID == taskID {
+			return domain.Task{}, invalid("parentTaskId", "cannot be the task itself")
+		}
+		patch.MaxDepth = s.maxDepth
+	}
+	if patch.Recurrence != nil {
+		if err := normalizeRecurrence(patch.Recurrence); err != nil {
+			return domain.Task{}, err
 		}
-		title = &trim
 	}
-	t, err := s.repo.Update(ctx, userID, taskID, title, completed)
+	projectID := cur.ProjectID
+	if moveTo != "" {
+		projectID = moveTo
+	}
+	if patch.SprintID != nil {
+		if err := s.checkSprint(ctx, userID, projectID, *patch.SprintID); err != nil {
+			return domain.Task{}, err
+		}
+	}
+	if len(patch.CustomFields) > 0 {
+		if patch.CustomFields, err = s.normalizeCustomValues(ctx, userID, projectID, patch.CustomFields); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	// Completing a task may be refused while it is blocked, and completing
+	// an open recurring task generates its next occurrence. The completion
+	// is recorded against the caller; the repo only stamps tasks it
+	// actually completes and clears the stamp on reopen.
+	completing := patch.Completed != nil && *patch.Completed
+	if completing && s.enforceBlockers && cur.IsBlocked && !cur.Completed {
+		return domain.Task{}, ErrBlocked
+	}
+	if completing {
+		now := s.now()
+		patch.CompletedAt, patch.CompletedBy = &now, &userID
+	} else {
+		patch.CompletedAt, patch.CompletedBy = nil, nil
+	}
+	t, err := s.repo.Update(ctx, userID, taskID, patch)
 	if errors.Is(err, sql.ErrNoRows) {
 		return domain.Task{}, ErrNotFound
 	}
-	return t, err
+	if errors.Is(err, domain.ErrInvalidProject) {
+		return domain.Task{}, invalid("projectId", "not found")
+	}
+	if errors.Is(err, domain.ErrInvalidParent) {
+		return domain.Task{}, invalid("parentTaskId", "must be a task of the same project")
+	}
+	if errors.Is(err, domain.ErrParentCycle) {
+		return domain.Task{}, invalid("parentTaskId", "cannot be one of the task's own subtasks")
+	}
+	if errors.Is(err, domain.ErrTooDeep) {
+		return domain.Task{}, invalid("parentTaskId", fmt.Sprintf("would nest subtasks deeper than %d levels", s.maxDepth))
+	}
+	if err != nil {
+		return domain.Task{}, err
+	}
+	if completing && !cur.Completed && t.Recurrence != nil && t.Recurrence.NextTaskID == nil {
+		if err := s.scheduleNext(ctx, userID, &t); err != nil {
+			return domain.Task{}, err
+		}
+	}
+
+	// The update is committed; telling people about it must not fail it.
+	s.assign(ctx, userID, projectID, taskID, patch.CustomFields)
+	if completing && !cur.Completed && s.notifier != nil {
+		recipients, err := s.Recipients(ctx, taskID, userID)
+		if err != nil {
+			log.Printf("task %s: watchers: %v", taskID, err)
+		} else {
+			s.notify(ctx, domain.TaskEvent{Type: domain.NotifyCompleted, TaskID: taskID, ActorID: userID, Recipients: recipients})
+		}
+	}
+	return t, nil
 }
 
-func (s *TaskService) Delete(ctx context.Context, userID, taskID string) error {
-	err := s.repo.Delete(ctx, userID, taskID)
+// scheduleNext creates the occurrence following the just-completed task t
+// and records it in t.Recurrence.NextTaskID. Nothing is created once the
+// rule's COUNT or UNTIL is exhausted.
+func (s *TaskService) scheduleNext(ctx context.Context, userID string, t *domain.Task) error {
+	rec := t.Recurrence
+	loc, err := time.LoadLocation(rec.Timezone)
+	if err != nil {
+		return err
+	}
+	rule, err := recurrence.Parse(rec.Rule, loc)
+	if err != nil {
+		return err
+	}
+	if rule.Count > 0 && rec.Occurrence >= rule.Count {
+		return nil
+	}
+
+	var base time.Time
+	switch {
+	case rec.RegenerateFrom == domain.FromScheduled && t.DueDate != nil:
+		base = t.DueDate.In(loc)
+	case t.DueDate != nil:
+		// Restart from the completion day, keeping the usual time of day.
+		y, m, d := s.now().In(loc).Date()
+		due := t.DueDate.In(loc)
+		base = time.Date(y, m, d, due.Hour(), due.Minute(), due.Second(), 0, loc)
+	default:
+		base = s.now().In(loc)
+	}
+	due, ok := rule.Next(base)
+	if !ok {
+		return nil
+	}
+
+	next, err := s.repo.CreateNextOccurrence(ctx, userID, t.ID, &due, rec.Occurrence+1)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Another request completed this occurrence first.
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	rec.NextTaskID = &next.ID
+	return nil
+}
+
+// normalizeRecurrence validates rec and rewrites its rule in canonical form.
+// The timezone defaults to UTC and regeneration to the scheduled date.
+func normalizeRecurrence(rec *domain.Recurrence) error {
+	if rec.Timezone == "" {
+		rec.Timezone = "UTC"
+	}
+	loc, err := time.LoadLocation(rec.Timezone)
+	if err != nil || rec.Timezone == "Local" {
+		return invalid("recurrence.timezone", "must be an IANA time zone such as Europe/Berlin")
+	}
+	rule, err := recurrence.Parse(rec.Rule, loc)
+	if err != nil {
+		return invalid("recurrence.rule", err.Error())
+	}
+	rec.Rule = rule.String()
+	switch rec.RegenerateFrom {
+	case "":
+		rec.RegenerateFrom = domain.FromScheduled
+	case domain.FromScheduled, domain.FromCompletion:
+	default:
+		return invalid("recurrence.regenerateFrom", "must be scheduled or completion")
+	}
+	rec.Occurrence = 1
+	rec.NextTaskID = nil
+	return nil
+}
+
+func validateDescription(d string) error {
+	if !utf8.ValidString(d) || strings.ContainsRune(d, 0) {
+		return invalid("description", "must be valid UTF-8 text")
+	}
+	if utf8.RuneCountInString(d) > MaxDescriptionLength {
+		return invalid("description", "must be at most 20000 characters")
+	}
+	return nil
+}
+
+// Subtree returns taskID with its subtasks nested beneath it.
+func (s *TaskService) Subtree(ctx context.Context, userID, taskID string) (domain.TaskNode, error) {
+	tasks, err := s.repo.Subtree(ctx, userID, taskID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TaskNode{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.TaskNode{}, err
+	}
+
+	// tasks is ordered by depth, so every parent is seen before its children.
+	children := map[string][]domain.Task{}
+	for _, t := range tasks[1:] {
+		children[*t.ParentTaskID] = append(children[*t.ParentTaskID], t)
+	}
+	var build func(t domain.Task) domain.TaskNode
+	build = func(t domain.Task) domain.TaskNode {
+		n := domain.TaskNode{Task: t, Subtasks: []domain.TaskNode{}}
+		for _, c := range children[t.ID] {
+			n.Subtasks = append(n.Subtasks, build(c))
+		}
+		return n
+	}
+	return build(tasks[0]), nil
+}
+
+// parentDepth validates that parentID can hold a subtask in projectID and
+// returns the parent's depth (0 for a top-level task).
+func (s *TaskService) parentDepth(ctx context.Context, userID, projectID, parentID string) (int, error) {
+	parent, err := s.repo.Get(ctx, userID, parentID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, invalid("parentTaskId", "not found")
+	}
+	if err != nil {
+		return 0, err
+	}
+	if parent.ProjectID != projectID {
+		return 0, invalid("parentTaskId", "must belong to the same project")
+	}
+	ancestors, err := s.repo.Ancestors(ctx, userID, parentID)
+	if err != nil {
+		return 0, err
+	}
+	return len(ancestors), nil
+}
+
+func (s *TaskService) Delete(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error {
+	if children == "" {
+		children = domain.ChildrenCascade
+	}
+	if children != domain.ChildrenCascade && children != domain.ChildrenReparent {
+		return invalid("children", "must be cascade or reparent")
+	}
+	if _, err := s.writableTask(ctx, userID, taskID); err != nil {
+		return err
+	}
+	err := s.repo.Delete(ctx, userID, taskID, children)
 	if errors.Is(err, sql.ErrNoRows) {
 		return ErrNotFound
 	}
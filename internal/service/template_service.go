@@ -0,0 +1,432 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+)
+
+type TemplateRepo interface {
+	Create(ctx context.Context, userID string, t domain.Template) (domain.Template, error)
+	List(ctx context.Context, userID string) ([]domain.Template, error)
+	Get(ctx context.Context, userID, templateID string) (domain.Template, error)
+	Delete(ctx context.Context, userID, templateID string) error
+}
+
+// Limits on templates.
+const (
+	maxTemplateName = 100
+	// MaxTemplateTasks caps the tasks of a template, subtasks included.
+	MaxTemplateTasks = 500
+	// MaxDueInDays bounds a template task's due date offset either way.
+	MaxDueInDays     = 3650
+	maxVariableValue = 200
+)
+
+// templateVar matches a variable such as {{client}} or {{ client }}.
+var templateVar = regexp.MustCompile(`\{\{\s*([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// TemplateService stores project templates and creates projects from
+// them. Projects are captured and created through the project, label and
+// task repositories.
+type TemplateService struct {
+	repo     TemplateRepo
+	projects ProjectRepo
+	labels   LabelRepo
+	tasks    TaskRepo
+	maxDepth int
+	now      func() time.Time
+}
+
+type TemplateOption func(*TemplateService)
+
+// WithTemplateMaxDepth limits how deeply template subtasks may be nested;
+// it should match the task service's limit.
+func WithTemplateMaxDepth(n int) TemplateOption {
+	return func(s *TemplateService) {
+		if n >= 0 {
+			s.maxDepth = n
+		}
+	}
+}
+
+// WithTemplateClock replaces time.Now, the default start date due dates
+// are counted from.
+func WithTemplateClock(now func() time.Time) TemplateOption {
+	return func(s *TemplateService) { s.now = now }
+}
+
+func NewTemplateService(repo TemplateRepo, projects ProjectRepo, labels LabelRepo, tasks TaskRepo, opts ...TemplateOption) *TemplateService {
+	s := &TemplateService{repo: repo, projects: projects, labels: labels, tasks: tasks, maxDepth: DefaultMaxDepth, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Create validates and stores a template defined by the caller.
+func (s *TemplateService) Create(ctx context.Context, userID string, t domain.Template) (domain.Template, error) {
+	if err := s.normalize(&t); err != nil {
+		return domain.Template{}, err
+	}
+	out, err := s.repo.Create(ctx, userID, t)
+	if err != nil {
+		return domain.Template{}, mapLabelErr(err)
+	}
+	out.Variables = templateVariables(out)
+	return out, nil
+}
+
+func (s *TemplateService) List(ctx context.Context, userID string) ([]domain.Template, error) {
+	out, err := s.repo.List(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for i := range out {
+		out[i].Variables = templateVariables(out[i])
+	}
+	return out, nil
+}
+
+func (s *TemplateService) Get(ctx context.Context, userID, templateID string) (domain.Template, error) {
+	t, err := s.repo.Get(ctx, userID, templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Template{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Template{}, err
+	}
+	t.Variables = templateVariables(t)
+	return t, nil
+}
+
+func (s *TemplateService) Delete(ctx context.Context, userID, templateID string) error {
+	err := s.repo.Delete(ctx, userID, templateID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Capture saves an existing project as a template named name. Every task
+// is captured open, in manual order; due dates become offsets in whole
+// days from the earliest due date in the project.
+func (s *TemplateService) Capture(ctx context.Context, userID, projectID, name string) (domain.Template, error) {
+	p, err := s.projects.Get(ctx, userID, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Template{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.Template{}, err
+	}
+	labels, err := s.labels.List(ctx, userID, projectID)
+	if err != nil {
+		return domain.Template{}, err
+	}
+
+	var (
+		tasks  []domain.Task
+		cursor *domain.Cursor
+	)
+	filter := domain.TaskFilter{ProjectID: projectID, LabelMatch: domain.LabelMatchAny}
+	sort := []domain.SortKey{{Field: "position"}}
+	for {
+		page, next, err := s.tasks.List(ctx, userID, filter, sort, 100, cursor)
+		if err != nil {
+			return domain.Template{}, err
+		}
+		tasks = append(tasks, page...)
+		if len(tasks) > MaxTemplateTasks {
+			return domain.Template{}, invalid("tasks", fmt.Sprintf("a template holds at most %d tasks", MaxTemplateTasks))
+		}
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	t := domain.Template{
+		Name:         name,
+		ProjectName:  p.Name,
+		EstimateUnit: p.EstimateUnit,
+		Labels:       make([]domain.TemplateLabel, 0, len(labels)),
+		Tasks:        templateTasks(tasks),
+	}
+	for _, l := range labels {
+		t.Labels = append(t.Labels, domain.TemplateLabel{Name: l.Name, Color: l.Color})
+	}
+	return s.Create(ctx, userID, t)
+}
+
+// templateTasks turns a project's tasks, in manual order, into a template
+// task tree.
+func templateTasks(tasks []domain.Task) []domain.TemplateTask {
+	var base time.Time
+	for _, t := range tasks {
+		if t.DueDate != nil {
+			day := t.DueDate.UTC().Truncate(24 * time.Hour)
+			if base.IsZero() || day.Before(base) {
+				base = day
+			}
+		}
+	}
+
+	ids := make(map[string]bool, len(tasks))
+	for _, t := range tasks {
+		ids[t.ID] = true
+	}
+	children := map[string][]domain.Task{}
+	var roots []domain.Task
+	for _, t := range tasks {
+		if t.ParentTaskID != nil && ids[*t.ParentTaskID] {
+			children[*t.ParentTaskID] = append(children[*t.ParentTaskID], t)
+		} else {
+			roots = append(roots, t)
+		}
+	}
+
+	var build func(ts []domain.Task) []domain.TemplateTask
+	build = func(ts []domain.Task) []domain.TemplateTask {
+		out := make([]domain.TemplateTask, 0, len(ts))
+		for _, t := range ts {
+			tt := domain.TemplateTask{
+				Title:       t.Title,
+				Description: t.Description,
+				Priority:    t.Priority,
+				Estimate:    t.Estimate,
+				Subtasks:    build(children[t.ID]),
+			}
+			if t.DueDate != nil {
+				days := int(t.DueDate.UTC().Truncate(24*time.Hour).Sub(base) / (24 * time.Hour))
+				tt.DueInDays = &days
+			}
+			for _, l := range t.Labels {
+				tt.Labels = append(tt.Labels, l.Name)
+			}
+			out = append(out, tt)
+		}
+		return out
+	}
+	return build(roots)
+}
+
+// Instantiate creates a project from a template in one transaction. Every
+// variable the template uses must be given a value; due dates are counted
+// from in.StartDate, which defaults to now.
+func (s *TemplateService) Instantiate(ctx context.Context, userID, templateID string, in domain.TemplateInstance) (domain.Project, error) {
+	t, err := s.Get(ctx, userID, templateID)
+	if err != nil {
+		return domain.Project{}, err
+	}
+
+	vars := make(map[string]string, len(in.Variables))
+	names := make([]string, 0, len(in.Variables))
+	for name := range in.Variables {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		field := "variables." + name
+		if !slices.Contains(t.Variables, name) {
+			return domain.Project{}, invalid(field, "is not used by this template")
+		}
+		v := strings.TrimSpace(in.Variables[name])
+		if v == "" {
+			return domain.Project{}, invalid(field, "cannot be empty")
+		}
+		if utf8.RuneCountInString(v) > maxVariableValue || strings.ContainsAny(v, "\r\n\x00") {
+			return domain.Project{}, invalid(field, "must be a single line of at most 200 characters")
+		}
+		vars[name] = v
+	}
+	for _, name := range t.Variables {
+		if _, ok := vars[name]; !ok {
+			return domain.Project{}, invalid("variables."+name, "required")
+		}
+	}
+
+	start := s.now()
+	if in.StartDate != nil {
+		start = *in.StartDate
+	}
+	bp := domain.ProjectBlueprint{
+		Name:         substituteVariables(t.ProjectName, vars),
+		EstimateUnit: t.EstimateUnit,
+		Labels:       t.Labels,
+		Tasks:        blueprintTasks(t.Tasks, vars, start),
+	}
+	if in.Name != "" {
+		bp.Name = strings.TrimSpace(in.Name)
+	}
+	if strings.TrimSpace(bp.Name) == "" {
+		return domain.Project{}, invalid("name", "required")
+	}
+	return s.projects.CreateFromBlueprint(ctx, userID, bp)
+}
+
+func blueprintTasks(tasks []domain.TemplateTask, vars map[string]string, start time.Time) []domain.BlueprintTask {
+	out := make([]domain.BlueprintTask, 0, len(tasks))
+	for _, tt := range tasks {
+		bt := domain.BlueprintTask{
+			TaskInput: domain.TaskInput{
+				Title:       substituteVariables(tt.Title, vars),
+				Description: substituteVariables(tt.Description, vars),
+				Priority:    tt.Priority,
+				Estimate:    tt.Estimate,
+			},
+			Labels:   tt.Labels,
+			Subtasks: blueprintTasks(tt.Subtasks, vars, start),
+		}
+		if tt.DueInDays != nil {
+			due := start.AddDate(0, 0, *tt.DueInDays)
+			bt.DueDate = &due
+		}
+		out = append(out, bt)
+	}
+	return out
+}
+
+func substituteVariables(s string, vars map[string]string) string {
+	return templateVar.ReplaceAllStringFunc(s, func(m string) string {
+		if v, ok := vars[templateVar.FindStringSubmatch(m)[1]]; ok {
+			return v
+		}
+		return m
+	})
+}
+
+// templateVariables lists the variables t uses, in order of first use.
+func templateVariables(t domain.Template) []string {
+	out := []string{}
+	add := func(s string) {
+		for _, m := range templateVar.FindAllStringSubmatch(s, -1) {
+			if !slices.Contains(out, m[1]) {
+				out = append(out, m[1])
+			}
+		}
+	}
+	add(t.ProjectName)
+	var walk func(tasks []domain.TemplateTask)
+	walk = func(tasks []domain.TemplateTask) {
+		for _, tt := range tasks {
+			add(tt.Title)
+			add(tt.Description)
+			walk(tt.Subtasks)
+		}
+	}
+	walk(t.Tasks)
+	return out
+}
+
+// normalize trims and validates a template in place. Label references of
+// tasks are resolved to the template's label names.
+func (s *TemplateService) normalize(t *domain.Template) error {
+	t.Name = strings.TrimSpace(t.Name)
+	if t.Name == "" {
+		return invalid("name", "required")
+	}
+	if utf8.RuneCountInString(t.Name) > maxTemplateName {
+		return invalid("name", "must be at most 100 characters")
+	}
+	t.ProjectName = strings.TrimSpace(t.ProjectName)
+	if t.ProjectName == "" {
+		return invalid("projectName", "required")
+	}
+	if t.EstimateUnit == "" {
+		t.EstimateUnit = domain.EstimatePoints
+	}
+	if !t.EstimateUnit.Valid() {
+		return invalid("estimateUnit", "must be one of: points, hours")
+	}
+
+	labels := map[string]string{}
+	if t.Labels == nil {
+		t.Labels = []domain.TemplateLabel{}
+	}
+	for i := range t.Labels {
+		l := &t.Labels[i]
+		field := fmt.Sprintf("labels[%d]", i)
+		l.Name = strings.TrimSpace(l.Name)
+		if err := validateLabelName(l.Name); err != nil {
+			return atPath(field, err)
+		}
+		if !hexColor.MatchString(l.Color) {
+			return invalid(field+".color", "must be a hex color like #1d76db")
+		}
+		l.Color = strings.ToLower(l.Color)
+		key := strings.ToLower(l.Name)
+		if _, dup := labels[key]; dup {
+			return invalid(field+".name", "is listed twice")
+		}
+		labels[key] = l.Name
+	}
+
+	if t.Tasks == nil {
+		t.Tasks = []domain.TemplateTask{}
+	}
+	count := 0
+	var check func(tasks []domain.TemplateTask, path string, depth int) error
+	check = func(tasks []domain.TemplateTask, path string, depth int) error {
+		for i := range tasks {
+			tt := &tasks[i]
+			field := fmt.Sprintf("%s[%d]", path, i)
+			if count++; count > MaxTemplateTasks {
+				return invalid("tasks", fmt.Sprintf("a template holds at most %d tasks", MaxTemplateTasks))
+			}
+			if depth > s.maxDepth {
+				return invalid(field, fmt.Sprintf("nests subtasks deeper than %d levels", s.maxDepth))
+			}
+			tt.Title = strings.TrimSpace(tt.Title)
+			if tt.Title == "" {
+				return invalid(field+".title", "required")
+			}
+			if err := validateDescription(tt.Description); err != nil {
+				return atPath(field, err)
+			}
+			if !tt.Priority.Valid() {
+				return invalid(field+".priority", "is not a known priority")
+			}
+			if tt.Estimate != nil {
+				if err := validateEstimate(*tt.Estimate); err != nil {
+					return atPath(field, err)
+				}
+			}
+			if tt.DueInDays != nil && (*tt.DueInDays < -MaxDueInDays || *tt.DueInDays > MaxDueInDays) {
+				return invalid(field+".dueInDays", fmt.Sprintf("must be between %d and %d", -MaxDueInDays, MaxDueInDays))
+			}
+			var names []string
+			for _, ref := range tt.Labels {
+				name, ok := labels[strings.ToLower(strings.TrimSpace(ref))]
+				if !ok {
+					return invalid(field+".labels", fmt.Sprintf("%q is not a label of the template", ref))
+				}
+				if !slices.Contains(names, name) {
+					names = append(names, name)
+				}
+			}
+			tt.Labels = names
+			if err := check(tt.Subtasks, field+".subtasks", depth+1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return check(t.Tasks, "tasks", 0)
+}
+
+// atPath prefixes the field of a validation error with path.
+func atPath(path string, err error) error {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return invalid(path+"."+ve.Field, ve.Message)
+	}
+	return err
+}
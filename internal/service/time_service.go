@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"TaskFlow/internal/domain"
+)
+
+// ErrTimerRunning is returned when starting a timer while another one is
+// still running for the same user.
+var ErrTimerRunning = errors.New("a timer is already running")
+
+type TimeEntryRepo interface {
+	Start(ctx context.Context, userID, taskID string, at time.Time, note string) (domain.TimeEntry, error)
+	Stop(ctx context.Context, userID string, at time.Time) (domain.TimeEntry, error)
+	Running(ctx context.Context, userID string) (domain.TimeEntry, error)
+	Create(ctx context.Context, userID, taskID string, in domain.TimeEntryInput) (domain.TimeEntry, error)
+	Get(ctx context.Context, userID, entryID string) (domain.TimeEntry, error)
+	List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.TimeEntry, *domain.Cursor, error)
+	Update(ctx context.Context, userID, entryID string, in domain.TimeEntryInput) (domain.TimeEntry, error)
+	Delete(ctx context.Context, userID, entryID string) error
+	Totals(ctx context.Context, userID string, q domain.TimeTotalsQuery) ([]domain.TimeTotal, error)
+	TaskArchived(ctx context.Context, userID, taskID string) (bool, error)
+}
+
+// MaxTimeNoteLength caps a time entry note, counted in characters.
+const MaxTimeNoteLength = 1000
+
+type TimeService struct {
+	repo TimeEntryRepo
+	now  func() time.Time
+}
+
+type TimeOption func(*TimeService)
+
+// WithTimeClock replaces time.Now, which timers start and stop at.
+func WithTimeClock(now func() time.Time) TimeOption {
+	return func(s *TimeService) { s.now = now }
+}
+
+func NewTimeService(repo TimeEntryRepo, opts ...TimeOption) *TimeService {
+	s := &TimeService{repo: repo, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Start begins a timer on a task. A user can only have one timer running.
+func (s *TimeService) Start(ctx context.Context, userID, taskID, note string) (domain.TimeEntry, error) {
+	note, err := validateTimeNote(note)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+	if err := checkTaskWritable(ctx, s.repo, userID, taskID); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	e, err := s.repo.Start(ctx, userID, taskID, s.now(), note)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.TimeEntry{}, ErrNotFound
+	case errors.Is(err, domain.ErrDuplicate):
+		return domain.TimeEntry{}, ErrTimerRunning
+	}
+	return e, err
+}
+
+// Stop ends the user's running timer. It returns ErrNotFound if no timer
+// is running. Unlike other writes it is allowed on archived projects, so a
+// timer left running when its project was archived can still be stopped.
+func (s *TimeService) Stop(ctx context.Context, userID string) (domain.TimeEntry, error) {
+	e, err := s.repo.Stop(ctx, userID, s.now())
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TimeEntry{}, ErrNotFound
+	}
+	return e, err
+}
+
+// Running returns the user's running timer, or nil if there is none.
+func (s *TimeService) Running(ctx context.Context, userID string) (*domain.TimeEntry, error) {
+	e, err := s.repo.Running(ctx, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// Log records a finished span of work on a task after the fact.
+func (s *TimeService) Log(ctx context.Context, userID, taskID string, in domain.TimeEntryInput) (domain.TimeEntry, error) {
+	if in.EndedAt == nil {
+		return domain.TimeEntry{}, invalid("endedAt", "required")
+	}
+	note, err := validateTimeNote(in.Note)
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+	in.Note = note
+	if err := s.validateSpan(in.StartedAt, in.EndedAt); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	if err := checkTaskWritable(ctx, s.repo, userID, taskID); err != nil {
+		return domain.TimeEntry{}, err
+	}
+	e, err := s.repo.Create(ctx, userID, taskID, in)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TimeEntry{}, ErrNotFound
+	}
+	return e, err
+}
+
+func (s *TimeService) List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) (Page[domain.TimeEntry], error) {
+	if limit <= 0 {
+		limit = 20
+	}
+	if limit > 100 {
+		limit = 100
+	}
+	if cursor != nil {
+		if cursor.Sort != domain.TimeEntrySort || len(cursor.Values) != 1 {
+			return Page[domain.TimeEntry]{}, invalid("cursor", "was not issued for time entries")
+		}
+		if _, err := time.Parse(time.RFC3339Nano, cursor.Values[0]); err != nil {
+			return Page[domain.TimeEntry]{}, invalid("cursor", "was not issued for time entries")
+		}
+	}
+	items, next, err := s.repo.List(ctx, userID, taskID, limit, cursor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return Page[domain.TimeEntry]{}, ErrNotFound
+	}
+	if err != nil {
+		return Page[domain.TimeEntry]{}, err
+	}
+	return Page[domain.TimeEntry]{Items: items, NextCursor: next}, nil
+}
+
+// Update edits an entry the user logged. A running timer stays running
+// unless the patch gives it an end.
+func (s *TimeService) Update(ctx context.Context, userID, entryID string, patch domain.TimeEntryPatch) (domain.TimeEntry, error) {
+	cur, err := s.repo.Get(ctx, userID, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TimeEntry{}, ErrNotFound
+	}
+	if err != nil {
+		return domain.TimeEntry{}, err
+	}
+	if err := checkTaskWritable(ctx, s.repo, userID, cur.TaskID); err != nil {
+		return domain.TimeEntry{}, err
+	}
+
+	in := domain.TimeEntryInput{StartedAt: cur.StartedAt, EndedAt: cur.EndedAt, Note: cur.Note}
+	if patch.StartedAt != nil {
+		in.StartedAt = *patch.StartedAt
+	}
+	if patch.EndedAt != nil {
+		in.EndedAt = patch.EndedAt
+	}
+	if patch.Note != nil {
+		if in.Note, err = validateTimeNote(*patch.Note); err != nil {
+			return domain.TimeEntry{}, err
+		}
+	}
+	if err := s.validateSpan(in.StartedAt, in.EndedAt); err != nil {
+		return domain.TimeEntry{}, err
+	}
+
+	e, err := s.repo.Update(ctx, userID, entryID, in)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.TimeEntry{}, ErrNotFound
+	}
+	return e, err
+}
+
+func (s *TimeService) Delete(ctx context.Context, userID, entryID string) error {
+	cur, err := s.repo.Get(ctx, userID, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	if err != nil {
+		return err
+	}
+	if err := checkTaskWritable(ctx, s.repo, userID, cur.TaskID); err != nil {
+		return err
+	}
+	err = s.repo.Delete(ctx, userID, entryID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Totals sums logged time within [q.From, q.To) per task, project or user.
+// GroupBy defaults to task.
+func (s *TimeService) Totals(ctx context.Context, userID string, q domain.TimeTotalsQuery) ([]domain.TimeTotal, error) {
+	switch q.GroupBy {
+	case "":
+		q.GroupBy = domain.TimeByTask
+	case domain.TimeByTask, domain.TimeByProject, domain.TimeByUser:
+	default:
+		return nil, invalid("groupBy", "must be task, project or user")
+	}
+	if q.From.IsZero() {
+		return nil, invalid("from", "required")
+	}
+	if q.To.IsZero() {
+		return nil, invalid("to", "required")
+	}
+	if !q.From.Before(q.To) {
+		return nil, invalid("to", "must be after from")
+	}
+	return s.repo.Totals(ctx, userID, q)
+}
+
+// validateSpan checks that an entry does not end before it starts and that neither
+// lies in the future. A nil end is a running timer.
+func (s *TimeService) validateSpan(start time.Time, end *time.Time) error {
+	now := s.now()
+	if start.IsZero() {
+		return invalid("startedAt", "required")
+	}
+	if start.After(now) {
+		return invalid("startedAt", "cannot be in the future")
+	}
+	if end == nil {
+		return nil
+	}
+	if end.Before(start) {
+		return invalid("endedAt", "cannot be before startedAt")
+	}
+	if end.After(now) {
+		return invalid("endedAt", "cannot be in the future")
+	}
+	return nil
+}
+
+func validateTimeNote(note string) (string, error) {
+	note = strings.TrimSpace(note)
+	if utf8.RuneCountInString(note) > MaxTimeNoteLength {
+		return "", invalid("note", "must be at most 1000 characters")
+	}
+	return note, nil
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"TaskFlow/internal/domain"
+)
+
+type TrashRepo interface {
+	List(ctx context.Context, userID string) (domain.Trash, error)
+	RestoreProject(ctx context.Context, userID, projectID string) (domain.Project, error)
+	RestoreTask(ctx context.Context, userID, taskID string) (domain.Task, error)
+	Purge(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+// DefaultTrashRetention is how long deleted projects and tasks can be
+// restored before they are purged.
+const DefaultTrashRetention = 30 * 24 * time.Hour
+
+type TrashService struct {
+	repo      TrashRepo
+	retention time.Duration
+	now       func() time.Time
+}
+
+type TrashOption func(*TrashService)
+
+// WithTrashRetention sets how long deleted items stay in the trash. A
+// non-positive duration keeps the default.
+func WithTrashRetention(d time.Duration) TrashOption {
+	return func(s *TrashService) {
+		if d > 0 {
+			s.retention = d
+		}
+	}
+}
+
+// WithTrashClock replaces time.Now, which the retention is counted from.
+func WithTrashClock(now func() time.Time) TrashOption {
+	return func(s *TrashService) { s.now = now }
+}
+
+func NewTrashService(repo TrashRepo, opts ...TrashOption) *TrashService {
+	s := &TrashService{repo: repo, retention: DefaultTrashRetention, now: time.Now}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *TrashService) List(ctx context.Context, userID string) (domain.Trash, error) {
+	return s.repo.List(ctx, userID)
+}
+
+// RestoreProject brings a deleted project back with all of its tasks.
+func (s *TrashService) RestoreProject(ctx context.Context, userID, projectID string) (domain.Project, error) {
+	p, err := s.repo.RestoreProject(ctx, userID, projectID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return domain.Project{}, ErrNotFound
+	}
+	return p, err
+}
+
+// RestoreTask brings a deleted task back with the subtasks deleted along
+// with it. A task in a deleted project cannot be restored on its own; the
+// project has to be restored first. Nor can one in an archived project
+// until the project is unarchived.
+func (s *TrashService) RestoreTask(ctx context.Context, userID, taskID string) (domain.Task, error) {
+	t, err := s.repo.RestoreTask(ctx, userID, taskID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return domain.Task{}, ErrNotFound
+	case errors.Is(err, domain.ErrArchived):
+		return domain.Task{}, ErrProjectArchived
+	}
+	return t, err
+}
+
+// Purge deletes for good everything that has been in the trash for longer
+// than the retention period. It returns the number of projects and tasks
+// removed; tasks of a purged project are not counted.
+func (s *TrashService) Purge(ctx context.Context) (int64, error) {
+	return s.repo.Purge(ctx, s.now().Add(-s.retention))
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"log"
+
+	"TaskFlow/internal/domain"
+)
+
+type WatcherRepo interface {
+	WatchTask(ctx context.Context, userID, taskID string) error
+	UnwatchTask(ctx context.Context, userID, taskID string) error
+	TaskWatchers(ctx context.Context, userID, taskID string) ([]domain.Watcher, error)
+	WatchProject(ctx context.Context, userID, projectID string) error
+	UnwatchProject(ctx context.Context, userID, projectID string) error
+	ProjectWatchers(ctx context.Context, userID, projectID string) ([]domain.Watcher, error)
+	Subscribe(ctx context.Context, taskID string, userIDs []string, reason domain.WatchReason) error
+	Recipients(ctx context.Context, taskID string) ([]string, error)
+}
+
+// WatcherService lets users follow tasks and whole projects.
+type WatcherService struct {
+	repo WatcherRepo
+}
+
+func NewWatcherService(repo WatcherRepo) *WatcherService { return &WatcherService{repo: repo} }
+
+func (s *WatcherService) WatchTask(ctx context.Context, userID, taskID string) error {
+	return mapWatcherErr(s.repo.WatchTask(ctx, userID, taskID))
+}
+
+// UnwatchTask stops userID from following a task. Unwatching a task one is
+// not watching is a no-op.
+func (s *WatcherService) UnwatchTask(ctx context.Context, userID, taskID string) error {
+	return mapWatcherErr(s.repo.UnwatchTask(ctx, userID, taskID))
+}
+
+func (s *WatcherService) TaskWatchers(ctx context.Context, userID, taskID string) ([]domain.Watcher, error) {
+	out, err := s.repo.TaskWatchers(ctx, userID, taskID)
+	return out, mapWatcherErr(err)
+}
+
+// WatchProject makes userID follow every task of a project, including
+// tasks created or moved there later.
+func (s *WatcherService) WatchProject(ctx context.Context, userID, projectID string) error {
+	return mapWatcherErr(s.repo.WatchProject(ctx, userID, projectID))
+}
+
+func (s *WatcherService) UnwatchProject(ctx context.Context, userID, projectID string) error {
+	return mapWatcherErr(s.repo.UnwatchProject(ctx, userID, projectID))
+}
+
+func (s *WatcherService) ProjectWatchers(ctx context.Context, userID, projectID string) ([]domain.Watcher, error) {
+	out, err := s.repo.ProjectWatchers(ctx, userID, projectID)
+	return out, mapWatcherErr(err)
+}
+
+func mapWatcherErr(err error) error {
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// Recipients returns who should hear about a change to taskID made by
+// actorID: the watchers of the task and of its project, without the actor.
+// It returns nil when watchers are not configured.
+func (s *TaskService) Recipients(ctx context.Context, taskID, actorID string) ([]string, error) {
+	return watchersExcept(ctx, s.watchers, taskID, actorID)
+}
+
+func watchersExcept(ctx context.Context, repo WatcherRepo, taskID, actorID string) ([]string, error) {
+	if repo == nil {
+		return nil, nil
+	}
+	ids, err := repo.Recipients(ctx, taskID)
+	if err != nil {
+		return nil, err
+	}
+	return without(ids, actorID), nil
+}
+
+// without filters id out of ids in place.
+func without(ids []string, id string) []string {
+	out := ids[:0]
+	for _, v := range ids {
+		if v != id {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// followNew subscribes the creator of t and its assignees, and tells the
+// assignees about it. Like assign, it runs after t is committed and only
+// logs failures.
+func (s *TaskService) followNew(ctx context.Context, userID, projectID string, t domain.Task) {
+	if s.watchers != nil {
+		if err := s.watchers.Subscribe(ctx, t.ID, []string{userID}, domain.WatchCreator); err != nil {
+			log.Printf("task %s: subscribe creator: %v", t.ID, err)
+		}
+	}
+	s.assign(ctx, userID, projectID, t.ID, t.CustomFields)
+}
+
+// assign subscribes and notifies the users set in values, a task's
+// normalized custom field values, through fields of type user. The values
+// are already saved, so failures are logged rather than returned.
+func (s *TaskService) assign(ctx context.Context, userID, projectID, taskID string, values map[string]json.RawMessage) {
+	if (s.watchers == nil && s.notifier == nil) || len(values) == 0 || s.fields == nil {
+		return
+	}
+	defs, err := s.customFieldsByID(ctx, userID, projectID)
+	if err != nil {
+		log.Printf("task %s: assign: %v", taskID, err)
+		return
+	}
+	var users []string
+	for id, raw := range values {
+		var v string
+		if defs[id].Type != domain.FieldUser || json.Unmarshal(raw, &v) != nil || v == "" {
+			continue
+		}
+		users = append(users, v)
+	}
+	if len(users) == 0 {
+		return
+	}
+	if s.watchers != nil {
+		if err := s.watchers.Subscribe(ctx, taskID, users, domain.WatchAssignee); err != nil {
+			log.Printf("task %s: subscribe assignees: %v", taskID, err)
+		}
+	}
+	s.notify(ctx, domain.TaskEvent{
+		Type:       domain.NotifyAssigned,
+		TaskID:     taskID,
+		ActorID:    userID,
+		Recipients: without(users, userID),
+	})
+}
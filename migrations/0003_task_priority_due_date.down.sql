@@ -0,0 +1,10 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_tasks_project_due_date;
+DROP INDEX IF EXISTS idx_tasks_project_priority;
+
+ALTER TABLE tasks
+    DROP COLUMN IF EXISTS due_date,
+    DROP COLUMN IF EXISTS priority;
+
+COMMIT;
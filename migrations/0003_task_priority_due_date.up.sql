@@ -0,0 +1,16 @@
+BEGIN;
+
+ALTER TABLE tasks
+    ADD COLUMN priority SMALLINT NOT NULL DEFAULT 0 CHECK (priority BETWEEN 0 AND 4),
+    ADD COLUMN due_date TIMESTAMPTZ;
+
+-- Supporting indexes for the most common sorted listings. Every ordering ends
+-- with created_at DESC, id DESC as the keyset tie-breaker.
+CREATE INDEX idx_tasks_project_priority
+    ON tasks (project_id, priority DESC, created_at DESC, id DESC);
+
+-- Matches the expression TaskRepo.List orders on so undated tasks sort last.
+CREATE INDEX idx_tasks_project_due_date
+    ON tasks (project_id, COALESCE(due_date, 'infinity'::timestamptz), created_at DESC, id DESC);
+
+COMMIT;
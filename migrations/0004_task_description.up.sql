@@ -0,0 +1,5 @@
+BEGIN;
+
+ALTER TABLE tasks ADD COLUMN description TEXT NOT NULL DEFAULT '';
+
+COMMIT;
@@ -0,0 +1,13 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_tasks_parent;
+
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS tasks_parent_not_self,
+    DROP CONSTRAINT IF EXISTS tasks_parent_fk,
+    DROP COLUMN IF EXISTS parent_task_id;
+
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS tasks_project_id_id_key;
+
+COMMIT;
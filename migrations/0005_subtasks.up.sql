@@ -0,0 +1,20 @@
+BEGIN;
+
+-- Subtasks reference their parent through (project_id, parent_task_id) so
+-- the database guarantees a parent lives in the same project as its children.
+-- ON UPDATE CASCADE carries a whole subtree along if the parent changes project.
+ALTER TABLE tasks
+    ADD CONSTRAINT tasks_project_id_id_key UNIQUE (project_id, id);
+
+ALTER TABLE tasks
+    ADD COLUMN parent_task_id UUID,
+    ADD CONSTRAINT tasks_parent_fk
+        FOREIGN KEY (project_id, parent_task_id)
+        REFERENCES tasks (project_id, id)
+        ON DELETE CASCADE
+        ON UPDATE CASCADE,
+    ADD CONSTRAINT tasks_parent_not_self CHECK (parent_task_id <> id);
+
+CREATE INDEX idx_tasks_parent ON tasks (parent_task_id) WHERE parent_task_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- blocker_id blocks blocked_id: the blocked task should not be completed
+-- while its blocker is still open. Links may cross projects.
+CREATE TABLE task_dependencies (
+    blocker_id  UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocked_id  UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocked ON task_dependencies (blocked_id);
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS task_labels;
+DROP TABLE IF EXISTS labels;
+
+COMMIT;
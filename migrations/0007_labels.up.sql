@@ -0,0 +1,23 @@
+BEGIN;
+
+CREATE TABLE labels (
+    id          UUID PRIMARY KEY,
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    color       TEXT NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_labels_project_name ON labels (project_id, lower(name));
+
+CREATE TABLE task_labels (
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    label_id    UUID NOT NULL REFERENCES labels(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, label_id)
+);
+
+CREATE INDEX idx_task_labels_label ON task_labels (label_id);
+
+COMMIT;
@@ -0,0 +1,27 @@
+BEGIN;
+
+-- Replies reference their parent through (task_id, parent_id) so a reply
+-- always lives on the same task as the comment it answers. Threads are a
+-- single level deep; the service rejects replies to replies.
+CREATE TABLE comments (
+    id          UUID PRIMARY KEY,
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    parent_id   UUID,
+    author_id   UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    body        TEXT NOT NULL,
+    edited_at   TIMESTAMPTZ,
+    deleted_at  TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CONSTRAINT comments_task_id_id_key UNIQUE (task_id, id),
+    CONSTRAINT comments_parent_fk
+        FOREIGN KEY (task_id, parent_id)
+        REFERENCES comments (task_id, id)
+        ON DELETE CASCADE,
+    CONSTRAINT comments_parent_not_self CHECK (parent_id <> id)
+);
+
+CREATE INDEX idx_comments_task_created ON comments (task_id, created_at, id) WHERE parent_id IS NULL;
+CREATE INDEX idx_comments_parent ON comments (parent_id, created_at, id) WHERE parent_id IS NOT NULL;
+
+COMMIT;
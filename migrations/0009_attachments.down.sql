@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS attachments_queue_blob_deletion ON attachments;
+DROP FUNCTION IF EXISTS queue_attachment_blob_deletion();
+DROP TABLE IF EXISTS blob_deletions;
+DROP TABLE IF EXISTS attachments;
+
+COMMIT;
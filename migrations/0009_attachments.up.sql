@@ -0,0 +1,38 @@
+BEGIN;
+
+CREATE TABLE attachments (
+    id            UUID PRIMARY KEY,
+    task_id       UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    uploader_id   UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    filename      TEXT NOT NULL,
+    content_type  TEXT NOT NULL,
+    size_bytes    BIGINT NOT NULL CHECK (size_bytes >= 0),
+    sha256        TEXT NOT NULL,
+    storage_key   TEXT NOT NULL UNIQUE,
+    created_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_attachments_task ON attachments (task_id, created_at);
+
+-- Attachment rows disappear with their task or project through ON DELETE
+-- CASCADE, which the application never sees. This trigger queues the
+-- stored object of every deleted row so a background sweep can remove it
+-- from the blob store.
+CREATE TABLE blob_deletions (
+    storage_key  TEXT PRIMARY KEY,
+    queued_at    TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE FUNCTION queue_attachment_blob_deletion() RETURNS trigger AS $$
+BEGIN
+    INSERT INTO blob_deletions (storage_key) VALUES (OLD.storage_key)
+    ON CONFLICT (storage_key) DO NOTHING;
+    RETURN OLD;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attachments_queue_blob_deletion
+    AFTER DELETE ON attachments
+    FOR EACH ROW EXECUTE FUNCTION queue_attachment_blob_deletion();
+
+COMMIT;
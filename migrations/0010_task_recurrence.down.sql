@@ -0,0 +1,12 @@
+BEGIN;
+
+ALTER TABLE tasks
+    DROP CONSTRAINT IF EXISTS tasks_recurrence_occurrence_positive,
+    DROP CONSTRAINT IF EXISTS tasks_recurrence_complete,
+    DROP COLUMN IF EXISTS next_occurrence_id,
+    DROP COLUMN IF EXISTS recurrence_occurrence,
+    DROP COLUMN IF EXISTS recurrence_from,
+    DROP COLUMN IF EXISTS recurrence_timezone,
+    DROP COLUMN IF EXISTS recurrence_rule;
+
+COMMIT;
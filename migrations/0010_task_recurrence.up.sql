@@ -0,0 +1,20 @@
+BEGIN;
+
+-- A recurring task stores its RRULE, the timezone it is evaluated in and
+-- which date the next occurrence is computed from. Completing it creates
+-- the next occurrence, linked through next_occurrence_id so that it is
+-- generated at most once.
+ALTER TABLE tasks
+    ADD COLUMN recurrence_rule TEXT,
+    ADD COLUMN recurrence_timezone TEXT,
+    ADD COLUMN recurrence_from TEXT,
+    ADD COLUMN recurrence_occurrence INT NOT NULL DEFAULT 1,
+    ADD COLUMN next_occurrence_id UUID REFERENCES tasks(id) ON DELETE SET NULL,
+    ADD CONSTRAINT tasks_recurrence_complete CHECK (
+        (recurrence_rule IS NULL AND recurrence_timezone IS NULL AND recurrence_from IS NULL)
+        OR (recurrence_rule IS NOT NULL AND recurrence_timezone IS NOT NULL
+            AND recurrence_from IN ('scheduled', 'completion'))
+    ),
+    ADD CONSTRAINT tasks_recurrence_occurrence_positive CHECK (recurrence_occurrence >= 1);
+
+COMMIT;
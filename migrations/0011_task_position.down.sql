@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_tasks_project_position;
+ALTER TABLE tasks DROP COLUMN IF EXISTS position;
+
+COMMIT;
@@ -0,0 +1,34 @@
+BEGIN;
+
+-- position orders tasks manually within a project. Keys are fractional
+-- indexes compared byte-wise, hence the "C" collation.
+ALTER TABLE tasks ADD COLUMN position TEXT COLLATE "C";
+
+-- Give existing tasks evenly spaced keys in creation order: six base-62
+-- digits followed by 'V', so no key ends in the smallest digit '0'.
+CREATE FUNCTION pg_temp.base62(n BIGINT, width INT) RETURNS TEXT AS $$
+DECLARE
+    digits CONSTANT TEXT := '0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz';
+    out TEXT := '';
+BEGIN
+    FOR i IN 1..width LOOP
+        out := substr(digits, (n % 62)::int + 1, 1) || out;
+        n := n / 62;
+    END LOOP;
+    RETURN out;
+END;
+$$ LANGUAGE plpgsql IMMUTABLE;
+
+UPDATE tasks t
+SET position = pg_temp.base62(o.rn, 6) || 'V'
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY project_id ORDER BY created_at, id) AS rn
+    FROM tasks
+) o
+WHERE o.id = t.id;
+
+ALTER TABLE tasks ALTER COLUMN position SET NOT NULL;
+
+CREATE INDEX idx_tasks_project_position ON tasks (project_id, position);
+
+COMMIT;
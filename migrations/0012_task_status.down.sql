@@ -0,0 +1,8 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_tasks_project_status_position;
+DROP TRIGGER IF EXISTS tasks_sync_status ON tasks;
+DROP FUNCTION IF EXISTS sync_task_status();
+ALTER TABLE tasks DROP COLUMN IF EXISTS status;
+
+COMMIT;
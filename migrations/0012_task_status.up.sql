@@ -0,0 +1,35 @@
+BEGIN;
+
+-- status is the board column a task sits in. "done" and completed always
+-- agree: the trigger below keeps them in step whichever one a write sets,
+-- so existing code that only touches completed stays correct.
+ALTER TABLE tasks ADD COLUMN status TEXT NOT NULL DEFAULT 'todo'
+    CHECK (status IN ('todo', 'in_progress', 'done'));
+
+UPDATE tasks SET status = 'done' WHERE completed;
+
+CREATE FUNCTION sync_task_status() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' THEN
+        IF NEW.completed THEN
+            NEW.status := 'done';
+        ELSIF NEW.status = 'done' THEN
+            NEW.completed := TRUE;
+        END IF;
+    ELSIF NEW.status IS DISTINCT FROM OLD.status THEN
+        NEW.completed := NEW.status = 'done';
+    ELSIF NEW.completed IS DISTINCT FROM OLD.completed THEN
+        NEW.status := CASE WHEN NEW.completed THEN 'done' ELSE 'todo' END;
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_sync_status
+    BEFORE INSERT OR UPDATE OF status, completed ON tasks
+    FOR EACH ROW EXECUTE FUNCTION sync_task_status();
+
+-- Serves the board: one ordered range scan per column.
+CREATE INDEX idx_tasks_project_status_position ON tasks (project_id, status, position, id);
+
+COMMIT;
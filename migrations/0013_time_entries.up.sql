@@ -0,0 +1,22 @@
+BEGIN;
+
+-- A time entry without ended_at is a running timer.
+CREATE TABLE time_entries (
+    id          UUID PRIMARY KEY,
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    started_at  TIMESTAMPTZ NOT NULL,
+    ended_at    TIMESTAMPTZ,
+    note        TEXT NOT NULL DEFAULT '',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (ended_at IS NULL OR ended_at >= started_at)
+);
+
+-- At most one running timer per user.
+CREATE UNIQUE INDEX idx_time_entries_running ON time_entries (user_id) WHERE ended_at IS NULL;
+
+CREATE INDEX idx_time_entries_task ON time_entries (task_id, started_at DESC, id DESC);
+CREATE INDEX idx_time_entries_user ON time_entries (user_id, started_at);
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+DROP TRIGGER IF EXISTS tasks_record_completion ON tasks;
+DROP FUNCTION IF EXISTS record_task_completion();
+DROP TABLE IF EXISTS task_completion_events;
+ALTER TABLE tasks DROP COLUMN IF EXISTS estimate;
+ALTER TABLE projects DROP COLUMN IF EXISTS estimate_unit;
+
+COMMIT;
@@ -0,0 +1,39 @@
+BEGIN;
+
+-- Estimates are story points or hours, chosen per project.
+ALTER TABLE projects ADD COLUMN estimate_unit TEXT NOT NULL DEFAULT 'points'
+    CHECK (estimate_unit IN ('points', 'hours'));
+
+ALTER TABLE tasks ADD COLUMN estimate NUMERIC(8, 2) CHECK (estimate >= 0);
+
+-- Every completion and reopening of a task, so that burndown charts can
+-- tell which tasks were open on any past day.
+CREATE TABLE task_completion_events (
+    id         BIGSERIAL PRIMARY KEY,
+    task_id    UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    completed  BOOLEAN NOT NULL,
+    at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_task_completion_events_task ON task_completion_events (task_id, at);
+
+CREATE FUNCTION record_task_completion() RETURNS trigger AS $$
+BEGIN
+    IF (TG_OP = 'INSERT' AND NEW.completed)
+        OR (TG_OP = 'UPDATE' AND NEW.completed IS DISTINCT FROM OLD.completed) THEN
+        INSERT INTO task_completion_events (task_id, completed) VALUES (NEW.id, NEW.completed);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER tasks_record_completion
+    AFTER INSERT OR UPDATE OF completed ON tasks
+    FOR EACH ROW EXECUTE FUNCTION record_task_completion();
+
+-- Tasks completed before history was kept: their last update is the best
+-- available guess at when that happened.
+INSERT INTO task_completion_events (task_id, completed, at)
+SELECT id, TRUE, updated_at FROM tasks WHERE completed;
+
+COMMIT;
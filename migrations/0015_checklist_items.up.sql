@@ -0,0 +1,17 @@
+BEGIN;
+
+-- Lightweight to-dos inside a task, ordered by fractional-index keys like
+-- tasks.position.
+CREATE TABLE checklist_items (
+    id          UUID PRIMARY KEY,
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    text        TEXT NOT NULL,
+    checked     BOOLEAN NOT NULL DEFAULT FALSE,
+    position    TEXT NOT NULL COLLATE "C",
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_checklist_items_task ON checklist_items (task_id, position, id);
+
+COMMIT;
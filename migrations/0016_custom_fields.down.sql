@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS task_custom_values;
+DROP TABLE IF EXISTS custom_fields;
+
+COMMIT;
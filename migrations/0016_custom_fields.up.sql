@@ -0,0 +1,28 @@
+BEGIN;
+
+-- Project-level custom field definitions. options lists the choices of
+-- select fields and is empty for every other type.
+CREATE TABLE custom_fields (
+    id          UUID PRIMARY KEY,
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    type        TEXT NOT NULL CHECK (type IN ('text', 'number', 'date', 'single_select', 'multi_select', 'user', 'url')),
+    options     JSONB NOT NULL DEFAULT '[]',
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_custom_fields_project_name ON custom_fields (project_id, lower(name));
+
+-- One value per task and field, as validated JSON: a string, a number,
+-- a "YYYY-MM-DD" string or an array of option strings.
+CREATE TABLE task_custom_values (
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    field_id    UUID NOT NULL REFERENCES custom_fields(id) ON DELETE CASCADE,
+    value       JSONB NOT NULL,
+    PRIMARY KEY (task_id, field_id)
+);
+
+CREATE INDEX idx_task_custom_values_field ON task_custom_values (field_id);
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+-- Reusable project outlines. body holds the project name, estimate unit,
+-- labels and task tree as validated JSON.
+CREATE TABLE project_templates (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name        TEXT NOT NULL,
+    body        JSONB NOT NULL,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_project_templates_user_name ON project_templates (user_id, lower(name));
+
+COMMIT;
@@ -0,0 +1,20 @@
+BEGIN;
+
+-- Copies of large projects, made in the background. A pending row is
+-- claimed and completed within the transaction that copies the project, so
+-- a crashed worker leaves it pending for the next one.
+CREATE TABLE project_duplications (
+    id                 UUID PRIMARY KEY,
+    user_id            UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    source_project_id  UUID REFERENCES projects(id) ON DELETE SET NULL,
+    options            JSONB NOT NULL,
+    status             TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'done', 'failed')),
+    project_id         UUID REFERENCES projects(id) ON DELETE SET NULL,
+    error              TEXT NOT NULL DEFAULT '',
+    created_at         TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_project_duplications_pending ON project_duplications (created_at) WHERE status = 'pending';
+
+COMMIT;
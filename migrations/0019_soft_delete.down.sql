@@ -0,0 +1,12 @@
+BEGIN;
+
+DELETE FROM tasks WHERE deleted_at IS NOT NULL;
+DELETE FROM projects WHERE deleted_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_tasks_deleted;
+DROP INDEX IF EXISTS idx_projects_deleted;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE projects DROP COLUMN IF EXISTS deleted_at;
+
+COMMIT;
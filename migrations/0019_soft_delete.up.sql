@@ -0,0 +1,12 @@
+BEGIN;
+
+-- Deleted projects and tasks stay in the trash until a background purge
+-- removes them for good. A task deleted with its subtasks shares one
+-- deleted_at with them, which is how a restore finds the whole subtree.
+ALTER TABLE projects ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_projects_deleted ON projects (user_id, deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_tasks_deleted ON tasks (project_id, deleted_at) WHERE deleted_at IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,7 @@
+BEGIN;
+
+-- An archived project is hidden from the default project list and its
+-- tasks are read-only until it is unarchived.
+ALTER TABLE projects ADD COLUMN archived_at TIMESTAMPTZ;
+
+COMMIT;
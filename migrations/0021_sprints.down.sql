@@ -0,0 +1,6 @@
+BEGIN;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS sprint_id;
+DROP TABLE IF EXISTS sprints;
+
+COMMIT;
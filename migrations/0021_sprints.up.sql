@@ -0,0 +1,29 @@
+BEGIN;
+
+-- Time-boxed iterations of a project. start_date and end_date are
+-- inclusive calendar days. When a sprint is completed its unfinished tasks
+-- move on, and how many there were and their estimate are kept for stats.
+CREATE TABLE sprints (
+    id                      UUID PRIMARY KEY,
+    project_id              UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name                    TEXT NOT NULL,
+    goal                    TEXT NOT NULL DEFAULT '',
+    start_date              DATE NOT NULL,
+    end_date                DATE NOT NULL,
+    completed_at            TIMESTAMPTZ,
+    carried_over_tasks      INT NOT NULL DEFAULT 0,
+    carried_over_estimate   NUMERIC(10, 2) NOT NULL DEFAULT 0,
+    created_at              TIMESTAMPTZ NOT NULL DEFAULT now(),
+    updated_at              TIMESTAMPTZ NOT NULL DEFAULT now(),
+    CHECK (end_date >= start_date)
+);
+
+CREATE INDEX idx_sprints_project ON sprints (project_id, start_date);
+
+-- A task is in at most one sprint; deleting a sprint returns its tasks to
+-- the backlog.
+ALTER TABLE tasks ADD COLUMN sprint_id UUID REFERENCES sprints(id) ON DELETE SET NULL;
+
+CREATE INDEX idx_tasks_sprint ON tasks (sprint_id) WHERE sprint_id IS NOT NULL;
+
+COMMIT;
@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS project_watchers;
+DROP TABLE IF EXISTS task_watchers;
+
+COMMIT;
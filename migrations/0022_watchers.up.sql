@@ -0,0 +1,26 @@
+BEGIN;
+
+-- Users following a task. reason records how the subscription started:
+-- explicitly, or automatically for the task's creator, an assignee (a user
+-- custom field value) or a commenter.
+CREATE TABLE task_watchers (
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    reason      TEXT NOT NULL CHECK (reason IN ('manual', 'creator', 'assignee', 'commenter')),
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (task_id, user_id)
+);
+
+CREATE INDEX idx_task_watchers_user ON task_watchers (user_id);
+
+-- Users following every task of a project.
+CREATE TABLE project_watchers (
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (project_id, user_id)
+);
+
+CREATE INDEX idx_project_watchers_user ON project_watchers (user_id);
+
+COMMIT;
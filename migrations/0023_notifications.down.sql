@@ -0,0 +1,6 @@
+BEGIN;
+
+DROP TABLE IF EXISTS notification_preferences;
+DROP TABLE IF EXISTS notifications;
+
+COMMIT;
@@ -0,0 +1,32 @@
+BEGIN;
+
+-- In-app notifications about tasks a user follows. A due_soon notification
+-- is sent once per due date, so moving the due date can trigger another.
+CREATE TABLE notifications (
+    id          UUID PRIMARY KEY,
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type        TEXT NOT NULL CHECK (type IN ('assigned', 'commented', 'completed', 'due_soon')),
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    actor_id    UUID REFERENCES users(id) ON DELETE SET NULL,
+    comment_id  UUID REFERENCES comments(id) ON DELETE CASCADE,
+    due_date    TIMESTAMPTZ,
+    read_at     TIMESTAMPTZ,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notifications_user ON notifications (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications (user_id) WHERE read_at IS NULL;
+CREATE UNIQUE INDEX idx_notifications_due_soon ON notifications (user_id, task_id, due_date)
+    WHERE type = 'due_soon';
+
+-- Which events notify a user. Users without a row get every type.
+CREATE TABLE notification_preferences (
+    user_id     UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    assigned    BOOLEAN NOT NULL DEFAULT TRUE,
+    commented   BOOLEAN NOT NULL DEFAULT TRUE,
+    completed   BOOLEAN NOT NULL DEFAULT TRUE,
+    due_soon    BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at  TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMIT;
@@ -0,0 +1,19 @@
+BEGIN;
+
+-- When each user is emailed a digest of unread notifications. Users without
+-- a row get a daily digest at 08:00 UTC; a row is stored the first time a
+-- digest is sent or the settings are changed. send_weekday (0 is Sunday)
+-- only applies to weekly digests. last_sent_at bounds the next digest, so
+-- no notification is emailed twice.
+CREATE TABLE digest_settings (
+    user_id            UUID PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    frequency          TEXT NOT NULL DEFAULT 'daily' CHECK (frequency IN ('off', 'daily', 'weekly')),
+    timezone           TEXT NOT NULL DEFAULT 'UTC',
+    send_hour          SMALLINT NOT NULL DEFAULT 8 CHECK (send_hour BETWEEN 0 AND 23),
+    send_weekday       SMALLINT NOT NULL DEFAULT 1 CHECK (send_weekday BETWEEN 0 AND 6),
+    unsubscribe_token  TEXT NOT NULL UNIQUE,
+    last_sent_at       TIMESTAMPTZ,
+    updated_at         TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+COMMIT;
@@ -0,0 +1,16 @@
+BEGIN;
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_project_number_key;
+ALTER TABLE tasks DROP COLUMN IF EXISTS number;
+
+DROP TRIGGER IF EXISTS projects_record_key ON projects;
+DROP TRIGGER IF EXISTS projects_assign_key ON projects;
+DROP FUNCTION IF EXISTS record_project_key();
+DROP FUNCTION IF EXISTS assign_project_key();
+DROP FUNCTION IF EXISTS project_key_for(UUID, TEXT);
+DROP TABLE IF EXISTS project_keys;
+
+ALTER TABLE projects DROP COLUMN IF EXISTS last_task_number;
+ALTER TABLE projects DROP COLUMN IF EXISTS key;
+
+COMMIT;
@@ -0,0 +1,114 @@
+BEGIN;
+
+-- key is a project's short prefix for human-readable task keys such as
+-- WEB-123. last_task_number is the last number handed to one of its tasks;
+-- numbers are never reused, even once a task is purged.
+ALTER TABLE projects ADD COLUMN key TEXT CHECK (key ~ '^[A-Z][A-Z0-9]{1,9}$');
+ALTER TABLE projects ADD COLUMN last_task_number INTEGER NOT NULL DEFAULT 0;
+
+-- Every key a project has had, so that task keys keep resolving after the
+-- project key is renamed. A key belongs to one project of its user for
+-- good, so old links never come to point at another project's tasks.
+CREATE TABLE project_keys (
+    user_id     UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key         TEXT NOT NULL,
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    created_at  TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, key)
+);
+
+CREATE INDEX idx_project_keys_project ON project_keys (project_id);
+
+-- project_key_for derives a key from a project name: its first three
+-- letters, upper-cased, or PRJ if it has fewer than two. A number is
+-- appended if the user already has that key.
+CREATE FUNCTION project_key_for(owner UUID, project_name TEXT) RETURNS TEXT AS $$
+DECLARE
+    base TEXT := upper(left(regexp_replace(project_name, '[^A-Za-z]', '', 'g'), 3));
+    candidate TEXT;
+    n INT := 1;
+BEGIN
+    IF length(base) < 2 THEN
+        base := 'PRJ';
+    END IF;
+    candidate := base;
+    WHILE EXISTS (SELECT 1 FROM project_keys WHERE user_id = owner AND key = candidate) LOOP
+        n := n + 1;
+        candidate := base || n;
+    END LOOP;
+    RETURN candidate;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION assign_project_key() RETURNS trigger AS $$
+BEGIN
+    IF NEW.key IS NULL THEN
+        NEW.key := project_key_for(NEW.user_id, NEW.name);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER projects_assign_key
+    BEFORE INSERT ON projects
+    FOR EACH ROW EXECUTE FUNCTION assign_project_key();
+
+-- Records a project's new key, failing with a unique violation if another
+-- project of the user has, or had, the same key.
+CREATE FUNCTION record_project_key() RETURNS trigger AS $$
+DECLARE
+    holder UUID;
+BEGIN
+    IF TG_OP = 'UPDATE' AND NEW.key IS NOT DISTINCT FROM OLD.key THEN
+        RETURN NULL;
+    END IF;
+    SELECT project_id INTO holder FROM project_keys WHERE user_id = NEW.user_id AND key = NEW.key;
+    IF holder IS NULL THEN
+        INSERT INTO project_keys (user_id, key, project_id) VALUES (NEW.user_id, NEW.key, NEW.id);
+    ELSIF holder <> NEW.id THEN
+        RAISE EXCEPTION 'project key % is already in use', NEW.key
+            USING ERRCODE = 'unique_violation', CONSTRAINT = 'project_keys_pkey';
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER projects_record_key
+    AFTER INSERT OR UPDATE OF key ON projects
+    FOR EACH ROW EXECUTE FUNCTION record_project_key();
+
+-- Give existing projects keys one at a time, oldest first, so each sees
+-- the keys taken before it.
+DO $$
+DECLARE
+    p RECORD;
+BEGIN
+    FOR p IN SELECT id FROM projects ORDER BY created_at, id LOOP
+        UPDATE projects SET key = project_key_for(user_id, name) WHERE id = p.id;
+    END LOOP;
+END;
+$$;
+
+ALTER TABLE projects ALTER COLUMN key SET NOT NULL;
+
+-- number is a task's place in its project's sequence. The uniqueness check
+-- is deferred to commit because moving a subtree between projects changes
+-- project_id and number in separate statements.
+ALTER TABLE tasks ADD COLUMN number INTEGER;
+
+UPDATE tasks t
+SET number = o.rn
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY project_id ORDER BY created_at, id) AS rn
+    FROM tasks
+) o
+WHERE o.id = t.id;
+
+UPDATE projects p
+SET last_task_number = COALESCE((SELECT max(number) FROM tasks t WHERE t.project_id = p.id), 0);
+
+ALTER TABLE tasks ALTER COLUMN number SET NOT NULL;
+ALTER TABLE tasks ADD CONSTRAINT tasks_project_number_key UNIQUE (project_id, number)
+    DEFERRABLE INITIALLY DEFERRED;
+
+COMMIT;
@@ -0,0 +1,17 @@
+BEGIN;
+
+-- completed_at and completed_by record a task's last transition to
+-- completed; reopening the task clears both.
+ALTER TABLE tasks ADD COLUMN completed_at TIMESTAMPTZ;
+ALTER TABLE tasks ADD COLUMN completed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+
+-- Tasks completed before this was recorded: their last update is the best
+-- guess, and only the project's owner could have completed them.
+UPDATE tasks t
+SET completed_at = t.updated_at, completed_by = p.user_id
+FROM projects p
+WHERE p.id = t.project_id AND t.completed;
+
+CREATE INDEX idx_tasks_completed_at ON tasks (project_id, completed_at) WHERE completed_at IS NOT NULL;
+
+COMMIT;
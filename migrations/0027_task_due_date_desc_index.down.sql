@@ -0,0 +1,5 @@
+BEGIN;
+
+DROP INDEX IF EXISTS idx_tasks_project_due_date_desc;
+
+COMMIT;
@@ -0,0 +1,9 @@
+BEGIN;
+
+-- Descending due-date listings put undated tasks last by substituting
+-- -infinity, so they order on another expression than
+-- idx_tasks_project_due_date and need an index of their own.
+CREATE INDEX idx_tasks_project_due_date_desc
+    ON tasks (project_id, COALESCE(due_date, '-infinity'::timestamptz) DESC, created_at DESC, id DESC);
+
+COMMIT;
@@ -0,0 +1,15 @@
+BEGIN;
+
+-- The numbers a task had in projects it was moved out of, so that its old
+-- keys keep resolving. A project never reuses a number, so each one names
+-- at most one task for good.
+CREATE TABLE task_former_numbers (
+    project_id  UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    number      INTEGER NOT NULL,
+    task_id     UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    PRIMARY KEY (project_id, number)
+);
+
+CREATE INDEX idx_task_former_numbers_task ON task_former_numbers (task_id);
+
+COMMIT;
@@ -0,0 +1,249 @@
+package attachments
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeAttachmentRepo struct {
+	created []domain.Attachment
+	pending []string
+	cleared []string
+	missing bool
+	gone    bool
+	archive bool
+}
+
+func (f *fakeAttachmentRepo) Create(ctx context.Context, userID string, a domain.Attachment) (domain.Attachment, error) {
+	if f.missing {
+		return domain.Attachment{}, sql.ErrNoRows
+	}
+	a.UploaderID = userID
+	f.created = append(f.created, a)
+	return a, nil
+}
+
+func (f *fakeAttachmentRepo) List(ctx context.Context, userID, taskID string) ([]domain.Attachment, error) {
+	return f.created, nil
+}
+
+func (f *fakeAttachmentRepo) Get(ctx context.Context, userID, taskID, attachmentID string) (domain.Attachment, error) {
+	for _, a := range f.created {
+		if a.ID == attachmentID && a.TaskID == taskID {
+			return a, nil
+		}
+	}
+	return domain.Attachment{}, sql.ErrNoRows
+}
+
+func (f *fakeAttachmentRepo) Delete(ctx context.Context, userID, taskID, attachmentID string) error {
+	return nil
+}
+
+func (f *fakeAttachmentRepo) PendingBlobDeletions(ctx context.Context, limit int) ([]string, error) {
+	var out []string
+	for _, k := range f.pending {
+		if !contains(f.cleared, k) {
+			out = append(out, k)
+		}
+	}
+	if len(out) > limit {
+		out = out[:limit]
+	}
+	return out, nil
+}
+
+func (f *fakeAttachmentRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	if f.gone {
+		return false, sql.ErrNoRows
+	}
+	return f.archive, nil
+}
+
+func (f *fakeAttachmentRepo) ClearBlobDeletions(ctx context.Context, keys []string) error {
+	f.cleared = append(f.cleared, keys...)
+	return nil
+}
+
+type memStore struct {
+	blobs   map[string][]byte
+	failDel map[string]bool
+}
+
+func newMemStore() *memStore {
+	return &memStore{blobs: map[string][]byte{}, failDel: map[string]bool{}}
+}
+
+func (m *memStore) Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	if int64(len(b)) != size {
+		return io.ErrUnexpectedEOF
+	}
+	m.blobs[key] = b
+	return nil
+}
+
+func (m *memStore) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	b, ok := m.blobs[key]
+	if !ok {
+		return nil, errors.New("missing")
+	}
+	return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (m *memStore) Delete(ctx context.Context, key string) error {
+	if m.failDel[key] {
+		return errors.New("store unavailable")
+	}
+	delete(m.blobs, key)
+	return nil
+}
+
+func contains(s []string, v string) bool {
+	for _, x := range s {
+		if x == v {
+			return true
+		}
+	}
+	return false
+}
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestAttachmentService_Upload_SniffsHashesAndStores(t *testing.T) {
+	repo := &fakeAttachmentRepo{}
+	store := newMemStore()
+	svc := _service.NewAttachmentService(repo, store, _service.WithTempDir(t.TempDir()))
+
+	a, err := svc.Upload(context.Background(), "user-1", "task-1", `C:\shots\screen.png`, bytes.NewReader(pngHeader))
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if a.Filename != "screen.png" || a.ContentType != "image/png" || a.Size != int64(len(pngHeader)) {
+		t.Fatalf("unexpected attachment: %#v", a)
+	}
+	sum := sha256.Sum256(pngHeader)
+	if a.SHA256 != hex.EncodeToString(sum[:]) {
+		t.Fatalf("expected sha256 of contents, got %q", a.SHA256)
+	}
+	if !bytes.Equal(store.blobs[a.StorageKey], pngHeader) {
+		t.Fatalf("expected blob stored under %q", a.StorageKey)
+	}
+	if a.UploaderID != "user-1" {
+		t.Fatalf("expected uploader user-1, got %q", a.UploaderID)
+	}
+
+	_, rc, err := svc.Open(context.Background(), "user-1", "task-1", a.ID)
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if !bytes.Equal(got, pngHeader) {
+		t.Fatalf("expected stored contents back, got %q", got)
+	}
+}
+
+func TestAttachmentService_Upload_EnforcesLimits(t *testing.T) {
+	repo := &fakeAttachmentRepo{}
+	store := newMemStore()
+	svc := _service.NewAttachmentService(repo, store,
+		_service.WithTempDir(t.TempDir()),
+		_service.WithMaxAttachmentSize(16),
+		_service.WithAllowedTypes([]string{"image/*"}),
+	)
+	ctx := context.Background()
+
+	if _, err := svc.Upload(ctx, "user-1", "task-1", "big.png", bytes.NewReader(append(pngHeader, make([]byte, 16)...))); err != _service.ErrTooLarge {
+		t.Fatalf("expected ErrTooLarge, got %v", err)
+	}
+	if _, err := svc.Upload(ctx, "user-1", "task-1", "page.png", strings.NewReader("<html><script>")); err != _service.ErrUnsupportedType {
+		t.Fatalf("expected ErrUnsupportedType for sniffed HTML, got %v", err)
+	}
+	var ve *_service.ValidationError
+	if _, err := svc.Upload(ctx, "user-1", "task-1", "empty.png", strings.NewReader("")); !errors.As(err, &ve) {
+		t.Fatalf("expected validation error for empty file, got %v", err)
+	}
+	if _, err := svc.Upload(ctx, "user-1", "task-1", "  ", bytes.NewReader(pngHeader)); !errors.As(err, &ve) {
+		t.Fatalf("expected validation error for missing filename, got %v", err)
+	}
+	if len(store.blobs) != 0 || len(repo.created) != 0 {
+		t.Fatalf("rejected uploads must not be stored")
+	}
+}
+
+func TestAttachmentService_Upload_MissingTaskRemovesBlob(t *testing.T) {
+	repo := &fakeAttachmentRepo{missing: true}
+	store := newMemStore()
+	svc := _service.NewAttachmentService(repo, store, _service.WithTempDir(t.TempDir()))
+
+	if _, err := svc.Upload(context.Background(), "user-1", "task-1", "a.png", bytes.NewReader(pngHeader)); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(store.blobs) != 0 {
+		t.Fatalf("expected orphaned blob to be removed, got %d blobs", len(store.blobs))
+	}
+}
+
+// unreadable fails the test if an upload is read at all.
+type unreadable struct{ t *testing.T }
+
+func (u unreadable) Read(p []byte) (int, error) {
+	u.t.Fatalf("upload was read before the task was checked")
+	return 0, io.EOF
+}
+
+func TestAttachmentService_Upload_ChecksTaskBeforeReading(t *testing.T) {
+	cases := []struct {
+		repo *fakeAttachmentRepo
+		want error
+	}{
+		{&fakeAttachmentRepo{gone: true}, _service.ErrNotFound},
+		{&fakeAttachmentRepo{archive: true}, _service.ErrProjectArchived},
+	}
+	for _, c := range cases {
+		store := newMemStore()
+		svc := _service.NewAttachmentService(c.repo, store, _service.WithTempDir(t.TempDir()))
+
+		if _, err := svc.Upload(context.Background(), "user-1", "task-1", "a.png", unreadable{t}); err != c.want {
+			t.Fatalf("expected %v, got %v", c.want, err)
+		}
+		if len(store.blobs) != 0 {
+			t.Fatalf("expected nothing stored, got %d blobs", len(store.blobs))
+		}
+	}
+}
+
+func TestAttachmentService_SweepDeletedBlobs_KeepsFailuresQueued(t *testing.T) {
+	repo := &fakeAttachmentRepo{pending: []string{"k1", "k2", "k3"}}
+	store := newMemStore()
+	for _, k := range repo.pending {
+		store.blobs[k] = []byte("x")
+	}
+	store.failDel["k2"] = true
+	svc := _service.NewAttachmentService(repo, store)
+
+	n, err := svc.SweepDeletedBlobs(context.Background())
+	if err == nil {
+		t.Fatalf("expected the store error to be reported")
+	}
+	if n != 2 || contains(repo.cleared, "k2") || !contains(repo.cleared, "k1") || !contains(repo.cleared, "k3") {
+		t.Fatalf("expected k1 and k3 cleared, k2 queued; removed=%d cleared=%v", n, repo.cleared)
+	}
+	if _, ok := store.blobs["k2"]; !ok {
+		t.Fatalf("k2 should still be stored")
+	}
+}
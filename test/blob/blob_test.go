@@ -0,0 +1,224 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"TaskFlow/internal/blob"
+)
+
+type store interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// exerciseStore checks the behaviour every blob store must share.
+func exerciseStore(t *testing.T, s store) {
+	t.Helper()
+	ctx := context.Background()
+	data := []byte("hello, attachment")
+
+	if err := s.Put(ctx, "tasks/t1/a1", bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	rc, err := s.Get(ctx, "tasks/t1/a1")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+
+	if err := s.Delete(ctx, "tasks/t1/a1"); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := s.Delete(ctx, "tasks/t1/a1"); err != nil {
+		t.Fatalf("deleting a missing blob should succeed, got %v", err)
+	}
+	if _, err := s.Get(ctx, "tasks/t1/a1"); !errors.Is(err, blob.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+
+	for _, key := range []string{"", "../escape", "tasks//a", "/abs"} {
+		if err := s.Put(ctx, key, bytes.NewReader(data), int64(len(data)), ""); err == nil {
+			t.Fatalf("expected error for key %q", key)
+		}
+	}
+}
+
+func TestFSStore(t *testing.T) {
+	s, err := blob.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	exerciseStore(t, s)
+
+	if err := s.Put(context.Background(), "short", strings.NewReader("abc"), 10, ""); err == nil {
+		t.Fatalf("expected error when the body is shorter than size")
+	}
+	if _, err := s.Get(context.Background(), "short"); !errors.Is(err, blob.ErrNotFound) {
+		t.Fatalf("a failed put must not leave a blob behind, got %v", err)
+	}
+}
+
+// fakeS3 is a minimal S3-compatible server, in the spirit of a local MinIO,
+// that keeps objects in memory and verifies SigV4 signatures independently
+// of the client under test.
+type fakeS3 struct {
+	bucket    string
+	region    string
+	accessKey string
+	secretKey string
+
+	mu      sync.Mutex
+	objects map[string][]byte
+	types   map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !f.verify(r) {
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+	prefix := "/" + f.bucket + "/"
+	if !strings.HasPrefix(r.URL.Path, prefix) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = io.WriteString(w, "<Error><Code>NoSuchBucket</Code></Error>")
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, prefix)
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		if int64(len(body)) != r.ContentLength {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		f.objects[key] = body
+		f.types[key] = r.Header.Get("Content-Type")
+		w.WriteHeader(http.StatusOK)
+	case http.MethodGet:
+		b, ok := f.objects[key]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		_, _ = w.Write(b)
+	case http.MethodDelete:
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+func (f *fakeS3) verify(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	const algo = "AWS4-HMAC-SHA256 "
+	if !strings.HasPrefix(auth, algo) {
+		return false
+	}
+	fields := map[string]string{}
+	for _, kv := range strings.Split(strings.TrimPrefix(auth, algo), ", ") {
+		k, v, _ := strings.Cut(kv, "=")
+		fields[k] = v
+	}
+	cred := strings.Split(fields["Credential"], "/")
+	if len(cred) != 5 || cred[0] != f.accessKey || cred[2] != f.region || cred[3] != "s3" {
+		return false
+	}
+	day := cred[1]
+	amzDate := r.Header.Get("X-Amz-Date")
+
+	var canonHeaders strings.Builder
+	for _, h := range strings.Split(fields["SignedHeaders"], ";") {
+		v := r.Header.Get(h)
+		if h == "host" {
+			v = r.Host
+		}
+		canonHeaders.WriteString(h + ":" + strings.TrimSpace(v) + "\n")
+	}
+	canon := strings.Join([]string{
+		r.Method, r.URL.EscapedPath(), r.URL.RawQuery,
+		canonHeaders.String(), fields["SignedHeaders"], r.Header.Get("X-Amz-Content-Sha256"),
+	}, "\n")
+	sum := sha256.Sum256([]byte(canon))
+	scope := strings.Join(cred[1:], "/")
+	toSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+	mac := func(key []byte, s string) []byte {
+		m := hmac.New(sha256.New, key)
+		m.Write([]byte(s))
+		return m.Sum(nil)
+	}
+	k := mac([]byte("AWS4"+f.secretKey), day)
+	k = mac(k, f.region)
+	k = mac(k, "s3")
+	k = mac(k, "aws4_request")
+	return hmac.Equal([]byte(hex.EncodeToString(mac(k, toSign))), []byte(fields["Signature"]))
+}
+
+func newFakeS3(t *testing.T) (*fakeS3, *httptest.Server) {
+	f := &fakeS3{
+		bucket: "attachments", region: "eu-central-1",
+		accessKey: "minio", secretKey: "minio-secret",
+		objects: map[string][]byte{}, types: map[string]string{},
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func TestS3Store(t *testing.T) {
+	f, srv := newFakeS3(t)
+	s, err := blob.NewS3Store(blob.S3Config{
+		Endpoint: srv.URL, Region: f.region, Bucket: f.bucket,
+		AccessKey: f.accessKey, SecretKey: f.secretKey,
+	}, srv.Client())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	exerciseStore(t, s)
+
+	// Keys with characters that need escaping must sign and round-trip.
+	data := []byte("%PDF-1.4")
+	if err := s.Put(context.Background(), "tasks/t 1/spec+v2.pdf", bytes.NewReader(data), int64(len(data)), "application/pdf"); err != nil {
+		t.Fatalf("put escaped key: %v", err)
+	}
+	if got := f.types["tasks/t 1/spec+v2.pdf"]; got != "application/pdf" {
+		t.Fatalf("expected content type to be stored, got %q", got)
+	}
+}
+
+func TestS3Store_RejectsBadCredentials(t *testing.T) {
+	f, srv := newFakeS3(t)
+	s, err := blob.NewS3Store(blob.S3Config{
+		Endpoint: srv.URL, Region: f.region, Bucket: f.bucket,
+		AccessKey: f.accessKey, SecretKey: "wrong",
+	}, srv.Client())
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	err = s.Put(context.Background(), "k", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "403") {
+		t.Fatalf("expected 403 error, got %v", err)
+	}
+}
@@ -0,0 +1,305 @@
+package comments
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeCommentRepo struct {
+	createFn func(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error)
+	getFn    func(ctx context.Context, userID, commentID string) (domain.Comment, error)
+	listFn   func(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.Comment, *domain.Cursor, error)
+	updateFn func(ctx context.Context, userID, commentID, body string) (domain.Comment, error)
+	deleteFn func(ctx context.Context, userID, commentID string) error
+	archived bool
+}
+
+func (f *fakeCommentRepo) Create(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+	if f.createFn != nil {
+		return f.createFn(ctx, userID, taskID, parentID, body)
+	}
+	return domain.Comment{TaskID: taskID, ParentID: parentID, Body: body}, nil
+}
+
+func (f *fakeCommentRepo) Get(ctx context.Context, userID, commentID string) (domain.Comment, error) {
+	if f.getFn != nil {
+		return f.getFn(ctx, userID, commentID)
+	}
+	return domain.Comment{}, sql.ErrNoRows
+}
+
+func (f *fakeCommentRepo) List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.Comment, *domain.Cursor, error) {
+	if f.listFn != nil {
+		return f.listFn(ctx, userID, taskID, limit, cursor)
+	}
+	return nil, nil, nil
+}
+
+func (f *fakeCommentRepo) Update(ctx context.Context, userID, commentID, body string) (domain.Comment, error) {
+	if f.updateFn != nil {
+		return f.updateFn(ctx, userID, commentID, body)
+	}
+	return domain.Comment{ID: commentID, Body: body}, nil
+}
+
+func (f *fakeCommentRepo) Delete(ctx context.Context, userID, commentID string) error {
+	if f.deleteFn != nil {
+		return f.deleteFn(ctx, userID, commentID)
+	}
+	return nil
+}
+
+func (f *fakeCommentRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	return f.archived, nil
+}
+
+func ptr(s string) *string { return &s }
+
+func TestCommentService_RejectsWritesToArchivedProjects(t *testing.T) {
+	repo := &fakeCommentRepo{
+		archived: true,
+		getFn: func(ctx context.Context, userID, commentID string) (domain.Comment, error) {
+			return domain.Comment{ID: commentID, TaskID: "task-1"}, nil
+		},
+	}
+	svc := _service.NewCommentService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "user-1", "task-1", nil, "hi"); err != _service.ErrProjectArchived {
+		t.Fatalf("create: expected ErrProjectArchived, got %v", err)
+	}
+	if _, err := svc.Update(ctx, "user-1", "c-1", "edited"); err != _service.ErrProjectArchived {
+		t.Fatalf("update: expected ErrProjectArchived, got %v", err)
+	}
+	if err := svc.Delete(ctx, "user-1", "c-1"); err != _service.ErrProjectArchived {
+		t.Fatalf("delete: expected ErrProjectArchived, got %v", err)
+	}
+}
+
+func TestCommentService_Create_ValidatesBody(t *testing.T) {
+	svc := _service.NewCommentService(&fakeCommentRepo{})
+
+	for _, body := range []string{"", "   \n", strings.Repeat("x", _service.MaxCommentLength+1)} {
+		_, err := svc.Create(context.Background(), "user-1", "task-1", nil, body)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "body" {
+			t.Fatalf("expected body validation error for %d chars, got %v", len(body), err)
+		}
+	}
+
+	c, err := svc.Create(context.Background(), "user-1", "task-1", nil, "  **hi**  ")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if c.Body != "**hi**" {
+		t.Fatalf("expected trimmed body, got %q", c.Body)
+	}
+}
+
+func TestCommentService_Create_MapsMissingTaskToNotFound(t *testing.T) {
+	repo := &fakeCommentRepo{
+		createFn: func(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+			return domain.Comment{}, sql.ErrNoRows
+		},
+	}
+	svc := _service.NewCommentService(repo)
+
+	if _, err := svc.Create(context.Background(), "user-1", "task-1", nil, "hi"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+// subscriberRepo records who was subscribed to which task.
+type subscriberRepo struct {
+	_service.WatcherRepo
+	subs []string
+}
+
+func (f *subscriberRepo) Subscribe(ctx context.Context, taskID string, userIDs []string, reason domain.WatchReason) error {
+	for _, id := range userIDs {
+		f.subs = append(f.subs, taskID+":"+id+":"+string(reason))
+	}
+	return nil
+}
+
+func TestCommentService_Create_SubscribesCommenter(t *testing.T) {
+	watchers := &subscriberRepo{}
+	svc := _service.NewCommentService(&fakeCommentRepo{}, _service.WithCommentWatchers(watchers))
+
+	if _, err := svc.Create(context.Background(), "user-1", "task-1", nil, "hi"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(watchers.subs) != 1 || watchers.subs[0] != "task-1:user-1:commenter" {
+		t.Fatalf("expected commenter subscription, got %v", watchers.subs)
+	}
+
+	// A failed comment subscribes nobody.
+	svc = _service.NewCommentService(&fakeCommentRepo{
+		createFn: func(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+			return domain.Comment{}, sql.ErrNoRows
+		},
+	}, _service.WithCommentWatchers(watchers))
+	if _, err := svc.Create(context.Background(), "user-2", "task-1", nil, "hi"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(watchers.subs) != 1 {
+		t.Fatalf("expected no new subscription, got %v", watchers.subs)
+	}
+}
+
+type recordingNotifier struct {
+	events []domain.TaskEvent
+	err    error
+}
+
+func (f *recordingNotifier) Notify(ctx context.Context, ev domain.TaskEvent) error {
+	f.events = append(f.events, ev)
+	return f.err
+}
+
+// recipientRepo reports a fixed set of watchers.
+type recipientRepo struct {
+	subscriberRepo
+	recipients []string
+}
+
+func (f *recipientRepo) Recipients(ctx context.Context, taskID string) ([]string, error) {
+	return append([]string(nil), f.recipients...), nil
+}
+
+func TestCommentService_Create_NotifiesOtherWatchers(t *testing.T) {
+	notifier := &recordingNotifier{}
+	repo := &fakeCommentRepo{
+		createFn: func(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+			return domain.Comment{ID: "c-1", TaskID: taskID, Body: body}, nil
+		},
+	}
+	svc := _service.NewCommentService(repo,
+		_service.WithCommentWatchers(&recipientRepo{recipients: []string{"user-1", "user-2"}}),
+		_service.WithCommentNotifier(notifier),
+	)
+
+	if _, err := svc.Create(context.Background(), "user-1", "task-1", nil, "hi"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected one event, got %+v", notifier.events)
+	}
+	ev := notifier.events[0]
+	if ev.Type != domain.NotifyCommented || ev.CommentID == nil || *ev.CommentID != "c-1" ||
+		len(ev.Recipients) != 1 || ev.Recipients[0] != "user-2" {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+}
+
+func TestCommentService_Create_NotifyFailureKeepsComment(t *testing.T) {
+	notifier := &recordingNotifier{err: errors.New("notifications down")}
+	repo := &fakeCommentRepo{
+		createFn: func(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+			return domain.Comment{ID: "c-1", TaskID: taskID, Body: body}, nil
+		},
+	}
+	svc := _service.NewCommentService(repo,
+		_service.WithCommentWatchers(&recipientRepo{recipients: []string{"user-2"}}),
+		_service.WithCommentNotifier(notifier),
+	)
+
+	c, err := svc.Create(context.Background(), "user-1", "task-1", nil, "hi")
+	if err != nil || c.ID != "c-1" {
+		t.Fatalf("expected the saved comment, got %+v (%v)", c, err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected a notification attempt, got %+v", notifier.events)
+	}
+}
+
+func TestCommentService_Create_SingleLevelThreading(t *testing.T) {
+	comments := map[string]domain.Comment{
+		"top":   {ID: "top", TaskID: "task-1"},
+		"reply": {ID: "reply", TaskID: "task-1", ParentID: ptr("top")},
+		"other": {ID: "other", TaskID: "task-2"},
+	}
+	created := false
+	repo := &fakeCommentRepo{
+		getFn: func(ctx context.Context, userID, commentID string) (domain.Comment, error) {
+			c, ok := comments[commentID]
+			if !ok {
+				return domain.Comment{}, sql.ErrNoRows
+			}
+			return c, nil
+		},
+		createFn: func(ctx context.Context, userID, taskID string, parentID *string, body string) (domain.Comment, error) {
+			created = true
+			return domain.Comment{TaskID: taskID, ParentID: parentID}, nil
+		},
+	}
+	svc := _service.NewCommentService(repo)
+
+	for _, parent := range []string{"reply", "other", "missing"} {
+		_, err := svc.Create(context.Background(), "user-1", "task-1", ptr(parent), "hi")
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "parentId" {
+			t.Fatalf("parent %q: expected parentId validation error, got %v", parent, err)
+		}
+	}
+	if created {
+		t.Fatalf("repo.Create should not be called for invalid parents")
+	}
+
+	c, err := svc.Create(context.Background(), "user-1", "task-1", ptr("top"), "hi")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if c.ParentID == nil || *c.ParentID != "top" {
+		t.Fatalf("expected reply to top, got %#v", c.ParentID)
+	}
+}
+
+func TestCommentService_List_ClampsLimitAndRejectsSortedCursor(t *testing.T) {
+	var gotLimit int
+	repo := &fakeCommentRepo{
+		listFn: func(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.Comment, *domain.Cursor, error) {
+			gotLimit = limit
+			return nil, nil, nil
+		},
+	}
+	svc := _service.NewCommentService(repo)
+
+	if _, err := svc.List(context.Background(), "user-1", "task-1", 500, nil); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if gotLimit != 100 {
+		t.Fatalf("expected limit clamped to 100, got %d", gotLimit)
+	}
+
+	_, err := svc.List(context.Background(), "user-1", "task-1", 20, &domain.Cursor{ID: "x", Sort: "priority", Values: []string{"1"}})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "cursor" {
+		t.Fatalf("expected cursor validation error, got %v", err)
+	}
+}
+
+func TestCommentService_UpdateDelete_MapNotFound(t *testing.T) {
+	repo := &fakeCommentRepo{
+		updateFn: func(ctx context.Context, userID, commentID, body string) (domain.Comment, error) {
+			return domain.Comment{}, sql.ErrNoRows
+		},
+		deleteFn: func(ctx context.Context, userID, commentID string) error {
+			return sql.ErrNoRows
+		},
+	}
+	svc := _service.NewCommentService(repo)
+
+	if _, err := svc.Update(context.Background(), "user-1", "c-1", "new"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound from Update, got %v", err)
+	}
+	if err := svc.Delete(context.Background(), "user-1", "c-1"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound from Delete, got %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+package customfields
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeCustomFieldRepo struct {
+	fields map[string]domain.CustomField
+
+	created   domain.CustomField
+	lastPatch domain.CustomFieldPatch
+	createErr error
+	archived  bool
+}
+
+func (f *fakeCustomFieldRepo) Create(ctx context.Context, userID, projectID string, cf domain.CustomField) (domain.CustomField, error) {
+	if f.createErr != nil {
+		return domain.CustomField{}, f.createErr
+	}
+	cf.ID, cf.ProjectID = "field-new", projectID
+	f.created = cf
+	return cf, nil
+}
+
+func (f *fakeCustomFieldRepo) List(ctx context.Context, userID, projectID string) ([]domain.CustomField, error) {
+	out := []domain.CustomField{}
+	for _, cf := range f.fields {
+		if cf.ProjectID == projectID {
+			out = append(out, cf)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeCustomFieldRepo) Get(ctx context.Context, userID, fieldID string) (domain.CustomField, error) {
+	cf, ok := f.fields[fieldID]
+	if !ok {
+		return domain.CustomField{}, sql.ErrNoRows
+	}
+	return cf, nil
+}
+
+func (f *fakeCustomFieldRepo) Update(ctx context.Context, userID, fieldID string, patch domain.CustomFieldPatch) (domain.CustomField, error) {
+	f.lastPatch = patch
+	return f.Get(ctx, userID, fieldID)
+}
+
+func (f *fakeCustomFieldRepo) Delete(ctx context.Context, userID, fieldID string) error {
+	_, err := f.Get(ctx, userID, fieldID)
+	return err
+}
+
+func (f *fakeCustomFieldRepo) Assignable(ctx context.Context, projectID string, userIDs []string) ([]string, error) {
+	return userIDs, nil
+}
+
+func (f *fakeCustomFieldRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return f.archived, nil
+}
+
+func (f *fakeCustomFieldRepo) FieldArchived(ctx context.Context, userID, fieldID string) (bool, error) {
+	return f.archived, nil
+}
+
+func TestCustomFieldService_RejectsWritesToArchivedProjects(t *testing.T) {
+	repo := &fakeCustomFieldRepo{
+		archived: true,
+		fields: map[string]domain.CustomField{
+			"f1": {ID: "f1", ProjectID: "proj-1", Name: "Size", Type: domain.FieldSingleSelect, Options: []string{"S"}},
+		},
+	}
+	svc := _service.NewCustomFieldService(repo)
+	ctx := context.Background()
+	name := "Bigness"
+
+	checks := map[string]error{}
+	_, checks["create"] = svc.Create(ctx, "user-1", "proj-1", domain.CustomField{Name: "Env", Type: domain.FieldText})
+	_, checks["rename"] = svc.Update(ctx, "user-1", "f1", domain.CustomFieldPatch{Name: &name})
+	_, checks["options"] = svc.Update(ctx, "user-1", "f1", domain.CustomFieldPatch{Options: []string{"M"}})
+	checks["delete"] = svc.Delete(ctx, "user-1", "f1")
+	for op, err := range checks {
+		if err != _service.ErrProjectArchived {
+			t.Fatalf("%s: expected ErrProjectArchived, got %v", op, err)
+		}
+	}
+	if repo.created.ID != "" || repo.lastPatch.Name != nil || repo.lastPatch.Options != nil {
+		t.Fatalf("expected nothing written, got %+v / %+v", repo.created, repo.lastPatch)
+	}
+}
+
+func TestCustomFieldService_Create_Validates(t *testing.T) {
+	svc := _service.NewCustomFieldService(&fakeCustomFieldRepo{})
+
+	cases := []struct {
+		name  string
+		in    domain.CustomField
+		field string
+	}{
+		{"blank name", domain.CustomField{Name: "  ", Type: domain.FieldText}, "name"},
+		{"long name", domain.CustomField{Name: strings.Repeat("x", 51), Type: domain.FieldText}, "name"},
+		{"unknown type", domain.CustomField{Name: "Size", Type: "color"}, "type"},
+		{"select without options", domain.CustomField{Name: "Size", Type: domain.FieldSingleSelect}, "options"},
+		{"duplicate option", domain.CustomField{Name: "Size", Type: domain.FieldMultiSelect, Options: []string{"S", " S"}}, "options"},
+		{"empty option", domain.CustomField{Name: "Size", Type: domain.FieldSingleSelect, Options: []string{"S", ""}}, "options"},
+		{"options on text", domain.CustomField{Name: "Notes", Type: domain.FieldText, Options: []string{"a"}}, "options"},
+	}
+	for _, c := range cases {
+		_, err := svc.Create(context.Background(), "user-1", "proj-1", c.in)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected %s ValidationError, got %v", c.name, c.field, err)
+		}
+	}
+}
+
+func TestCustomFieldService_Create_NormalizesAndMapsConflict(t *testing.T) {
+	repo := &fakeCustomFieldRepo{}
+	svc := _service.NewCustomFieldService(repo)
+	ctx := context.Background()
+
+	_, err := svc.Create(ctx, "user-1", "proj-1", domain.CustomField{
+		Name: " Size ", Type: domain.FieldSingleSelect, Options: []string{" S ", "M"},
+	})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if repo.created.Name != "Size" || strings.Join(repo.created.Options, ",") != "S,M" {
+		t.Fatalf("expected trimmed name and options, got %+v", repo.created)
+	}
+
+	if _, err := svc.Create(ctx, "user-1", "proj-1", domain.CustomField{Name: "Points", Type: domain.FieldNumber}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if repo.created.Options == nil || len(repo.created.Options) != 0 {
+		t.Fatalf("expected empty options for a number field, got %#v", repo.created.Options)
+	}
+
+	repo.createErr = domain.ErrDuplicate
+	if _, err := svc.Create(ctx, "user-1", "proj-1", domain.CustomField{Name: "Points", Type: domain.FieldNumber}); !errors.Is(err, _service.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	repo.createErr = sql.ErrNoRows
+	if _, err := svc.Create(ctx, "user-1", "missing", domain.CustomField{Name: "Points", Type: domain.FieldNumber}); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestCustomFieldService_Update_ChecksOptionsAgainstType(t *testing.T) {
+	repo := &fakeCustomFieldRepo{fields: map[string]domain.CustomField{
+		"size":  {ID: "size", ProjectID: "proj-1", Name: "Size", Type: domain.FieldSingleSelect, Options: []string{"S"}},
+		"notes": {ID: "notes", ProjectID: "proj-1", Name: "Notes", Type: domain.FieldText},
+	}}
+	svc := _service.NewCustomFieldService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Update(ctx, "user-1", "size", domain.CustomFieldPatch{Options: []string{"S", " L "}}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if strings.Join(repo.lastPatch.Options, ",") != "S,L" {
+		t.Fatalf("expected trimmed options, got %v", repo.lastPatch.Options)
+	}
+
+	_, err := svc.Update(ctx, "user-1", "notes", domain.CustomFieldPatch{Options: []string{"a"}})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "options" {
+		t.Fatalf("expected options ValidationError for a text field, got %v", err)
+	}
+
+	if _, err := svc.Update(ctx, "user-1", "missing", domain.CustomFieldPatch{Options: []string{"a"}}); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := svc.Delete(ctx, "user-1", "missing"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on delete, got %v", err)
+	}
+}
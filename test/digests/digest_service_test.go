@@ -0,0 +1,351 @@
+package digests
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/mail"
+	_service "TaskFlow/internal/service"
+)
+
+type sentMark struct {
+	token string
+	at    time.Time
+}
+
+type fakeDigestRepo struct {
+	pending    []domain.DigestRecipient
+	items      map[string][]domain.DigestItem
+	total      int
+	marked     map[string]sentMark
+	tokens     map[string]bool
+	patchToken string
+	unsubbed   []string
+}
+
+func (f *fakeDigestRepo) Settings(ctx context.Context, userID string) (domain.DigestSettings, error) {
+	return domain.DefaultDigestSettings(), nil
+}
+
+func (f *fakeDigestRepo) UpdateSettings(ctx context.Context, userID string, patch domain.DigestSettingsPatch, token string) (domain.DigestSettings, error) {
+	f.patchToken = token
+	s := domain.DefaultDigestSettings()
+	if patch.Frequency != nil {
+		s.Frequency = *patch.Frequency
+	}
+	return s, nil
+}
+
+func (f *fakeDigestRepo) Unsubscribe(ctx context.Context, token string) error {
+	if !f.tokens[token] {
+		return sql.ErrNoRows
+	}
+	f.unsubbed = append(f.unsubbed, token)
+	return nil
+}
+
+func (f *fakeDigestRepo) Pending(ctx context.Context) ([]domain.DigestRecipient, error) {
+	return f.pending, nil
+}
+
+func (f *fakeDigestRepo) Activity(ctx context.Context, userID string, until time.Time, limit int) ([]domain.DigestItem, int, error) {
+	items := f.items[userID]
+	total := f.total
+	if total == 0 {
+		total = len(items)
+	}
+	if len(items) > limit {
+		items = items[:limit]
+	}
+	return items, total, nil
+}
+
+func (f *fakeDigestRepo) MarkSent(ctx context.Context, userID, token string, at time.Time) error {
+	if f.marked == nil {
+		f.marked = map[string]sentMark{}
+	}
+	f.marked[userID] = sentMark{token: token, at: at}
+	return nil
+}
+
+// failingTransport rejects mail to one address and passes the rest on.
+type failingTransport struct {
+	next _service.MailTransport
+	to   string
+}
+
+func (t failingTransport) Send(ctx context.Context, m mail.Message) error {
+	if m.To == t.to {
+		return errors.New("mailbox unavailable")
+	}
+	return t.next.Send(ctx, m)
+}
+
+func newTransport(t *testing.T) (*mail.FileTransport, string) {
+	t.Helper()
+	dir := t.TempDir()
+	tr, err := mail.NewFileTransport(dir, "TaskFlow <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("file transport: %v", err)
+	}
+	return tr, dir
+}
+
+func readMail(t *testing.T, dir string) []*netmail.Message {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if err != nil {
+		t.Fatalf("glob: %v", err)
+	}
+	var out []*netmail.Message
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			t.Fatalf("read %s: %v", f, err)
+		}
+		m, err := netmail.ReadMessage(strings.NewReader(string(b)))
+		if err != nil {
+			t.Fatalf("parse %s: %v", f, err)
+		}
+		out = append(out, m)
+	}
+	return out
+}
+
+// bodies returns the text and HTML parts of a multipart/alternative message.
+func bodies(t *testing.T, m *netmail.Message) (text, html string) {
+	t.Helper()
+	_, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("content type: %v", err)
+	}
+	r := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			return text, html
+		}
+		if err != nil {
+			t.Fatalf("next part: %v", err)
+		}
+		b, _ := io.ReadAll(p)
+		if strings.HasPrefix(p.Header.Get("Content-Type"), "text/html") {
+			html = string(b)
+		} else {
+			text = string(b)
+		}
+	}
+}
+
+func clockAt(now *time.Time) func() time.Time { return func() time.Time { return *now } }
+
+func TestDigestService_Send_WaitsForLocalSendTime(t *testing.T) {
+	tr, dir := newTransport(t)
+	repo := &fakeDigestRepo{
+		pending: []domain.DigestRecipient{{
+			UserID:   "user-1",
+			Email:    "ana@example.com",
+			Settings: domain.DigestSettings{Frequency: domain.DigestDaily, Timezone: "America/New_York", Hour: 8},
+			// 22:00 the evening before in New York.
+			Since: time.Date(2026, 5, 5, 2, 0, 0, 0, time.UTC),
+		}},
+		items: map[string][]domain.DigestItem{"user-1": {{Type: domain.NotifyCompleted, TaskTitle: "Ship", ProjectName: "Web"}}},
+	}
+	now := time.Date(2026, 5, 5, 11, 30, 0, 0, time.UTC) // 07:30 EDT
+	svc := _service.NewDigestService(repo, tr, _service.WithDigestClock(clockAt(&now)))
+	ctx := context.Background()
+
+	n, err := svc.Send(ctx)
+	if err != nil || n != 0 {
+		t.Fatalf("expected nothing sent before 08:00 local, got %d (%v)", n, err)
+	}
+	if len(readMail(t, dir)) != 0 || len(repo.marked) != 0 {
+		t.Fatalf("expected no mail and no mark")
+	}
+
+	now = time.Date(2026, 5, 5, 12, 5, 0, 0, time.UTC) // 08:05 EDT
+	n, err = svc.Send(ctx)
+	if err != nil || n != 1 {
+		t.Fatalf("expected one digest, got %d (%v)", n, err)
+	}
+	msgs := readMail(t, dir)
+	if len(msgs) != 1 || msgs[0].Header.Get("To") != "ana@example.com" {
+		t.Fatalf("expected one mail to ana, got %d", len(msgs))
+	}
+	mark, ok := repo.marked["user-1"]
+	if !ok || !mark.at.Equal(now) || len(mark.token) != 48 {
+		t.Fatalf("expected sent mark at %v with a new token, got %+v", now, mark)
+	}
+}
+
+func TestDigestService_Send_WeeklyOnWeekday(t *testing.T) {
+	tr, _ := newTransport(t)
+	repo := &fakeDigestRepo{
+		pending: []domain.DigestRecipient{{
+			UserID:   "user-1",
+			Email:    "ana@example.com",
+			Settings: domain.DigestSettings{Frequency: domain.DigestWeekly, Timezone: "UTC", Hour: 9, Weekday: 1},
+			Since:    time.Date(2026, 5, 5, 10, 0, 0, 0, time.UTC), // Tuesday
+		}},
+		items: map[string][]domain.DigestItem{"user-1": {{Type: domain.NotifyCommented, TaskTitle: "Ship", ProjectName: "Web"}}},
+	}
+	now := time.Date(2026, 5, 8, 9, 30, 0, 0, time.UTC) // Friday
+	svc := _service.NewDigestService(repo, tr, _service.WithDigestClock(clockAt(&now)))
+	ctx := context.Background()
+
+	if n, err := svc.Send(ctx); err != nil || n != 0 {
+		t.Fatalf("expected no digest before Monday, got %d (%v)", n, err)
+	}
+	now = time.Date(2026, 5, 11, 8, 59, 0, 0, time.UTC) // Monday, before 09:00
+	if n, err := svc.Send(ctx); err != nil || n != 0 {
+		t.Fatalf("expected no digest before 09:00, got %d (%v)", n, err)
+	}
+	now = time.Date(2026, 5, 11, 9, 0, 0, 0, time.UTC)
+	if n, err := svc.Send(ctx); err != nil || n != 1 {
+		t.Fatalf("expected the weekly digest, got %d (%v)", n, err)
+	}
+}
+
+func TestDigestService_Send_RendersTemplatesAndUnsubscribeLink(t *testing.T) {
+	tr, dir := newTransport(t)
+	due := time.Date(2026, 5, 6, 15, 0, 0, 0, time.UTC)
+	repo := &fakeDigestRepo{
+		pending: []domain.DigestRecipient{{
+			UserID:   "user-1",
+			Email:    "ana@example.com",
+			Settings: domain.DigestSettings{Frequency: domain.DigestDaily, Timezone: "Europe/Berlin", Hour: 8},
+			Token:    "tok-1",
+			Since:    time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC),
+		}},
+		items: map[string][]domain.DigestItem{"user-1": {
+			{Type: domain.NotifyAssigned, TaskTitle: "<b>Ship</b>", ProjectName: "Web", Actor: "bo@example.com"},
+			{Type: domain.NotifyDueSoon, TaskTitle: "Launch", ProjectName: "Web", DueDate: &due},
+		}},
+		total: 4,
+	}
+	now := time.Date(2026, 5, 5, 7, 0, 0, 0, time.UTC)
+	svc := _service.NewDigestService(repo, tr,
+		_service.WithDigestClock(clockAt(&now)),
+		_service.WithDigestBaseURL("https://tasks.example.com/"),
+	)
+
+	if n, err := svc.Send(context.Background()); err != nil || n != 1 {
+		t.Fatalf("expected one digest, got %d (%v)", n, err)
+	}
+	msgs := readMail(t, dir)
+	if len(msgs) != 1 {
+		t.Fatalf("expected one mail, got %d", len(msgs))
+	}
+	m := msgs[0]
+	if got := m.Header.Get("Subject"); got != "Your daily TaskFlow digest: 4 updates" {
+		t.Fatalf("unexpected subject %q", got)
+	}
+	link := "https://tasks.example.com/v1/digest/unsubscribe?token=tok-1"
+	if got := m.Header.Get("List-Unsubscribe"); got != "<"+link+">" {
+		t.Fatalf("unexpected List-Unsubscribe %q", got)
+	}
+
+	text, html := bodies(t, m)
+	for _, want := range []string{
+		`bo@example.com assigned you to "<b>Ship</b>" (Web)`,
+		`"Launch" is due Wed May 6 at 17:00`,
+		"...and 2 more.",
+		link,
+	} {
+		if !strings.Contains(text, want) {
+			t.Fatalf("expected text part to contain %q, got:\n%s", want, text)
+		}
+	}
+	if !strings.Contains(html, "<strong>&lt;b&gt;Ship&lt;/b&gt;</strong>") || !strings.Contains(html, `href="`+link+`"`) {
+		t.Fatalf("expected escaped title and unsubscribe link in html part, got:\n%s", html)
+	}
+	if repo.marked["user-1"].token != "tok-1" {
+		t.Fatalf("expected the existing token to be kept, got %+v", repo.marked["user-1"])
+	}
+}
+
+func TestDigestService_Send_FailureDoesNotStopOthers(t *testing.T) {
+	tr, dir := newTransport(t)
+	set := domain.DigestSettings{Frequency: domain.DigestDaily, Timezone: "UTC", Hour: 8}
+	since := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	item := []domain.DigestItem{{Type: domain.NotifyCompleted, TaskTitle: "Ship", ProjectName: "Web"}}
+	repo := &fakeDigestRepo{
+		pending: []domain.DigestRecipient{
+			{UserID: "user-1", Email: "bounce@example.com", Settings: set, Since: since},
+			{UserID: "user-2", Email: "ana@example.com", Settings: set, Since: since},
+		},
+		items: map[string][]domain.DigestItem{"user-1": item, "user-2": item},
+	}
+	now := time.Date(2026, 5, 5, 9, 0, 0, 0, time.UTC)
+	svc := _service.NewDigestService(repo, failingTransport{next: tr, to: "bounce@example.com"},
+		_service.WithDigestClock(clockAt(&now)))
+
+	n, err := svc.Send(context.Background())
+	if err == nil || n != 1 {
+		t.Fatalf("expected one digest and an error, got %d (%v)", n, err)
+	}
+	if _, ok := repo.marked["user-1"]; ok {
+		t.Fatalf("expected the failed digest not to be marked sent")
+	}
+	if _, ok := repo.marked["user-2"]; !ok || len(readMail(t, dir)) != 1 {
+		t.Fatalf("expected the other digest to be sent")
+	}
+}
+
+func TestDigestService_UpdateSettings_Validates(t *testing.T) {
+	repo := &fakeDigestRepo{}
+	svc := _service.NewDigestService(repo, nil)
+	ctx := context.Background()
+
+	hourly := domain.DigestFrequency("hourly")
+	tz := "Mars/Olympus"
+	hour := 24
+	day := 7
+	for field, patch := range map[string]domain.DigestSettingsPatch{
+		"frequency": {Frequency: &hourly},
+		"timezone":  {Timezone: &tz},
+		"hour":      {Hour: &hour},
+		"weekday":   {Weekday: &day},
+	} {
+		_, err := svc.UpdateSettings(ctx, "user-1", patch)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != field {
+			t.Fatalf("expected %s validation error, got %v", field, err)
+		}
+	}
+
+	weekly := domain.DigestWeekly
+	s, err := svc.UpdateSettings(ctx, "user-1", domain.DigestSettingsPatch{Frequency: &weekly})
+	if err != nil || s.Frequency != domain.DigestWeekly {
+		t.Fatalf("expected weekly settings, got %+v (%v)", s, err)
+	}
+	if len(repo.patchToken) != 48 {
+		t.Fatalf("expected a token for new settings, got %q", repo.patchToken)
+	}
+}
+
+func TestDigestService_Unsubscribe_MapsNotFound(t *testing.T) {
+	repo := &fakeDigestRepo{tokens: map[string]bool{"tok-1": true}}
+	svc := _service.NewDigestService(repo, nil)
+	ctx := context.Background()
+
+	if err := svc.Unsubscribe(ctx, "tok-1"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	for _, tok := range []string{"", "tok-2"} {
+		if err := svc.Unsubscribe(ctx, tok); err != _service.ErrNotFound {
+			t.Fatalf("expected ErrNotFound for %q, got %v", tok, err)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package digests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	httpx "TaskFlow/internal/http"
+	_service "TaskFlow/internal/service"
+)
+
+func TestUnsubscribe_GetOnlyConfirms(t *testing.T) {
+	repo := &fakeDigestRepo{tokens: map[string]bool{"tok-1": true}}
+	router := httpx.NewRouter(httpx.Deps{DigestSvc: _service.NewDigestService(repo, nil)})
+
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/digest/unsubscribe?token=tok-1", nil))
+	if rec.Code != 200 || !strings.Contains(rec.Body.String(), `method="post"`) {
+		t.Fatalf("expected a confirmation form, got %d %q", rec.Code, rec.Body.String())
+	}
+	if len(repo.unsubbed) != 0 {
+		t.Fatalf("expected GET to change nothing, got %v", repo.unsubbed)
+	}
+
+	rec = httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/v1/digest/unsubscribe?token=tok-1", strings.NewReader("List-Unsubscribe=One-Click"))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	router.ServeHTTP(rec, req)
+	if rec.Code != 200 || len(repo.unsubbed) != 1 || repo.unsubbed[0] != "tok-1" {
+		t.Fatalf("expected POST to unsubscribe, got %d (%v)", rec.Code, repo.unsubbed)
+	}
+
+	rec = httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/v1/digest/unsubscribe?token=nope", nil))
+	if rec.Code != 404 {
+		t.Fatalf("expected 404 for an unknown token, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,114 @@
+package fracindex
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"TaskFlow/internal/fracindex"
+)
+
+func mustBetween(t *testing.T, a, b string) string {
+	t.Helper()
+	k, err := fracindex.Between(a, b)
+	if err != nil {
+		t.Fatalf("Between(%q, %q): %v", a, b, err)
+	}
+	if !fracindex.Valid(k) {
+		t.Fatalf("Between(%q, %q) = %q, not a valid key", a, b, k)
+	}
+	if (a != "" && k <= a) || (b != "" && k >= b) {
+		t.Fatalf("Between(%q, %q) = %q, not strictly between", a, b, k)
+	}
+	return k
+}
+
+func TestBetween_OpenEndsAndAdjacentKeys(t *testing.T) {
+	first := mustBetween(t, "", "")
+	mustBetween(t, "", first)
+	mustBetween(t, first, "")
+
+	for _, c := range [][2]string{
+		{"1", "2"},
+		{"V", "W"},
+		{"z", ""},
+		{"", "1"},
+		{"01", "1"},
+		{"zzz", ""},
+		{"A", "A1"},
+		{"Az", "B"},
+	} {
+		mustBetween(t, c[0], c[1])
+	}
+}
+
+func TestBetween_RejectsBadInput(t *testing.T) {
+	if _, err := fracindex.Between("B", "A"); !errors.Is(err, fracindex.ErrOutOfOrder) {
+		t.Fatalf("expected ErrOutOfOrder, got %v", err)
+	}
+	if _, err := fracindex.Between("A", "A"); !errors.Is(err, fracindex.ErrOutOfOrder) {
+		t.Fatalf("expected ErrOutOfOrder for equal keys, got %v", err)
+	}
+	for _, k := range []string{"A0", "a-b", "é"} {
+		if _, err := fracindex.Between(k, ""); !errors.Is(err, fracindex.ErrInvalidKey) {
+			t.Fatalf("%q: expected ErrInvalidKey, got %v", k, err)
+		}
+	}
+}
+
+func TestBetween_RepeatedInsertsKeepOrder(t *testing.T) {
+	// Keep inserting at random gaps and check the list stays sorted and
+	// free of duplicates, including the worst case of always inserting at
+	// the front.
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{mustBetween(t, "", "")}
+	for i := 0; i < 2000; i++ {
+		j := rng.Intn(len(keys) + 1)
+		if i%4 == 0 {
+			j = 0
+		}
+		var lo, hi string
+		if j > 0 {
+			lo = keys[j-1]
+		}
+		if j < len(keys) {
+			hi = keys[j]
+		}
+		k := mustBetween(t, lo, hi)
+		keys = append(keys[:j], append([]string{k}, keys[j:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Fatal("keys out of order")
+	}
+	for i := 1; i < len(keys); i++ {
+		if keys[i] == keys[i-1] {
+			t.Fatalf("duplicate key %q", keys[i])
+		}
+	}
+}
+
+func TestSpread_ShortSortedKeys(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 61, 62, 1000, 5000} {
+		keys := fracindex.Spread(n)
+		if len(keys) != n {
+			t.Fatalf("Spread(%d) returned %d keys", n, len(keys))
+		}
+		for i, k := range keys {
+			if !fracindex.Valid(k) {
+				t.Fatalf("Spread(%d)[%d] = %q is invalid", n, i, k)
+			}
+			if len(k) > 3 {
+				t.Fatalf("Spread(%d)[%d] = %q is longer than needed", n, i, k)
+			}
+			if i > 0 && keys[i-1] >= k {
+				t.Fatalf("Spread(%d) not strictly ascending at %d: %q >= %q", n, i, keys[i-1], k)
+			}
+		}
+		if n > 0 {
+			// Leave room at both ends for later inserts.
+			mustBetween(t, "", keys[0])
+			mustBetween(t, keys[n-1], "")
+		}
+	}
+}
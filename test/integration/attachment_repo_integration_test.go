@@ -0,0 +1,152 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"io"
+	"os"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/blob"
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestAttachmentRepo_Ownership_QueuesBlobsOnCascade(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+	attachmentRepo := postgres.NewAttachmentRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	owner := uuid.NewString()
+	stranger := uuid.NewString()
+	insertUser(t, db, owner, "a-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, stranger, "a-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, owner, "Attached")
+	t.Cleanup(func() { deleteUser(t, db, owner) })
+	t.Cleanup(func() { deleteUser(t, db, stranger) })
+
+	task, err := taskRepo.Create(ctx, owner, project, domain.TaskInput{Title: "with files"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	newAttachment := func() domain.Attachment {
+		id := uuid.NewString()
+		return domain.Attachment{
+			ID: id, TaskID: task.ID, Filename: "spec.pdf", ContentType: "application/pdf",
+			Size: 3, SHA256: "abc", StorageKey: "tasks/" + task.ID + "/" + id,
+		}
+	}
+
+	if _, err := attachmentRepo.Create(ctx, stranger, newAttachment()); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows attaching to another user's task, got %v", err)
+	}
+	if _, err := attachmentRepo.TaskArchived(ctx, stranger, task.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows checking another user's task, got %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE projects SET archived_at = now() WHERE id = $1`, project); err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if archived, err := attachmentRepo.TaskArchived(ctx, owner, task.ID); err != nil || !archived {
+		t.Fatalf("expected the task's project archived, got %v (%v)", archived, err)
+	}
+	if _, err := attachmentRepo.Create(ctx, owner, newAttachment()); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows attaching to an archived project, got %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `UPDATE projects SET archived_at = NULL WHERE id = $1`, project); err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+
+	kept, err := attachmentRepo.Create(ctx, owner, newAttachment())
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	removed, err := attachmentRepo.Create(ctx, owner, newAttachment())
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if kept.UploaderID != owner {
+		t.Fatalf("expected uploader %s, got %s", owner, kept.UploaderID)
+	}
+
+	if _, err := attachmentRepo.Get(ctx, stranger, task.ID, kept.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for stranger get, got %v", err)
+	}
+	if err := attachmentRepo.Delete(ctx, owner, task.ID, removed.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	items, err := attachmentRepo.List(ctx, owner, task.ID)
+	if err != nil || len(items) != 1 || items[0].ID != kept.ID {
+		t.Fatalf("expected only the kept attachment, got %#v err=%v", items, err)
+	}
+
+	deleteProject(t, db, project)
+
+	pending, err := attachmentRepo.PendingBlobDeletions(ctx, 1000)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	queued := map[string]bool{}
+	for _, k := range pending {
+		queued[k] = true
+	}
+	if !queued[kept.StorageKey] || !queued[removed.StorageKey] {
+		t.Fatalf("expected both blobs queued for deletion, got %v", pending)
+	}
+	if err := attachmentRepo.ClearBlobDeletions(ctx, []string{kept.StorageKey, removed.StorageKey}); err != nil {
+		t.Fatalf("clear: %v", err)
+	}
+}
+
+// TestS3Store_AgainstServer runs the S3 store against a real S3-compatible
+// server such as MinIO when S3_TEST_ENDPOINT is set. The bucket must exist.
+func TestS3Store_AgainstServer(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT not set; skipping S3 store test")
+	}
+	s, err := blob.NewS3Store(blob.S3Config{
+		Endpoint:  endpoint,
+		Region:    os.Getenv("S3_TEST_REGION"),
+		Bucket:    os.Getenv("S3_TEST_BUCKET"),
+		AccessKey: os.Getenv("S3_TEST_ACCESS_KEY"),
+		SecretKey: os.Getenv("S3_TEST_SECRET_KEY"),
+	}, nil)
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	ctx := context.Background()
+	key := "it/" + uuid.NewString()
+	data := []byte("integration payload")
+	if err := s.Put(ctx, key, bytes.NewReader(data), int64(len(data)), "text/plain"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	rc, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	got, _ := io.ReadAll(rc)
+	_ = rc.Close()
+	if !bytes.Equal(got, data) {
+		t.Fatalf("expected %q, got %q", data, got)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, blob.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound after delete, got %v", err)
+	}
+}
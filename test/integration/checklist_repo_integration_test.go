@@ -0,0 +1,110 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestChecklistRepo_Order_Progress_Convert_Ownership(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	repo := postgres.NewChecklistRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "c-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Checklists")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+
+	task, err := tasks.Create(ctx, user, project, domain.TaskInput{Title: "Release", Priority: domain.PriorityMedium})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if task.ChecklistProgress != "0/0" {
+		t.Fatalf("expected empty progress, got %q", task.ChecklistProgress)
+	}
+
+	var items []domain.ChecklistItem
+	for _, text := range []string{"tag", "build", "announce"} {
+		it, err := repo.Create(ctx, user, task.ID, text, false)
+		if err != nil {
+			t.Fatalf("create item: %v", err)
+		}
+		items = append(items, it)
+	}
+	if _, err := repo.Create(ctx, other, task.ID, "nope", false); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user's task, got %v", err)
+	}
+
+	yes := true
+	if _, err := repo.Update(ctx, user, items[0].ID, domain.ChecklistItemPatch{Checked: &yes}); err != nil {
+		t.Fatalf("check: %v", err)
+	}
+	if _, err := repo.Update(ctx, other, items[1].ID, domain.ChecklistItemPatch{Checked: &yes}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user's item, got %v", err)
+	}
+	got, err := tasks.Get(ctx, user, task.ID)
+	if err != nil || got.ChecklistTotal != 3 || got.ChecklistChecked != 1 || got.ChecklistProgress != "1/3" {
+		t.Fatalf("expected progress 1/3, got %d/%d %q %v", got.ChecklistChecked, got.ChecklistTotal, got.ChecklistProgress, err)
+	}
+
+	// Move "announce" to the top.
+	if _, err := repo.Move(ctx, user, items[2].ID, nil, &items[0].ID); err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if _, err := repo.Move(ctx, user, items[2].ID, &items[0].ID, &items[0].ID); !errors.Is(err, domain.ErrInvalidNeighbor) {
+		t.Fatalf("expected ErrInvalidNeighbor for out-of-order neighbors, got %v", err)
+	}
+	list, err := repo.List(ctx, user, task.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 3 || list[0].Text != "announce" || list[1].Text != "tag" || list[2].Text != "build" {
+		t.Fatalf("unexpected order: %+v", list)
+	}
+	if _, err := repo.List(ctx, other, task.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows listing another user's task, got %v", err)
+	}
+
+	// Converting the checked item yields a completed subtask and drops the item.
+	sub, err := repo.ConvertToTask(ctx, user, items[0].ID)
+	if err != nil {
+		t.Fatalf("convert: %v", err)
+	}
+	if sub.Title != "tag" || !sub.Completed || sub.Status != domain.StatusDone ||
+		sub.ParentTaskID == nil || *sub.ParentTaskID != task.ID || sub.ProjectID != project {
+		t.Fatalf("unexpected converted task: %+v", sub)
+	}
+	if _, err := repo.ConvertToTask(ctx, user, items[0].ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows converting twice, got %v", err)
+	}
+
+	if err := repo.Delete(ctx, other, items[1].ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows deleting another user's item, got %v", err)
+	}
+	if err := repo.Delete(ctx, user, items[1].ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	got, err = tasks.Get(ctx, user, task.ID)
+	if err != nil || got.ChecklistProgress != "0/1" || got.SubtaskCount != 1 {
+		t.Fatalf("expected progress 0/1 with one subtask, got %q/%d %v", got.ChecklistProgress, got.SubtaskCount, err)
+	}
+}
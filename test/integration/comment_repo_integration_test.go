@@ -0,0 +1,105 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestCommentRepo_Threads_Pagination_SoftDelete_Ownership(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+	commentRepo := postgres.NewCommentRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	owner := uuid.NewString()
+	stranger := uuid.NewString()
+	insertUser(t, db, owner, "c-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, stranger, "c-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, owner, "Discussed")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, owner) })
+	t.Cleanup(func() { deleteUser(t, db, stranger) })
+
+	task, err := taskRepo.Create(ctx, owner, project, domain.TaskInput{Title: "talk about me"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	if _, err := commentRepo.Create(ctx, stranger, task.ID, nil, "sneaky"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows commenting on another user's task, got %v", err)
+	}
+	if _, _, err := commentRepo.List(ctx, stranger, task.ID, 10, nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows listing another user's task, got %v", err)
+	}
+
+	var top []domain.Comment
+	for _, body := range []string{"first", "second", "third"} {
+		c, err := commentRepo.Create(ctx, owner, task.ID, nil, body)
+		if err != nil {
+			t.Fatalf("create %s: %v", body, err)
+		}
+		top = append(top, c)
+	}
+	reply, err := commentRepo.Create(ctx, owner, task.ID, &top[0].ID, "a reply")
+	if err != nil {
+		t.Fatalf("create reply: %v", err)
+	}
+
+	page1, next, err := commentRepo.List(ctx, owner, task.ID, 2, nil)
+	if err != nil {
+		t.Fatalf("list page 1: %v", err)
+	}
+	if len(page1) != 2 || next == nil || page1[0].ID != top[0].ID {
+		t.Fatalf("unexpected first page: %#v next=%v", page1, next)
+	}
+	if len(page1[0].Replies) != 1 || page1[0].Replies[0].ID != reply.ID {
+		t.Fatalf("expected reply nested under first comment, got %#v", page1[0].Replies)
+	}
+	page2, next, err := commentRepo.List(ctx, owner, task.ID, 2, next)
+	if err != nil {
+		t.Fatalf("list page 2: %v", err)
+	}
+	if len(page2) != 1 || next != nil || page2[0].ID != top[2].ID {
+		t.Fatalf("unexpected second page: %#v next=%v", page2, next)
+	}
+
+	edited, err := commentRepo.Update(ctx, owner, top[1].ID, "second, revised")
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if !edited.Edited || edited.EditedAt == nil || edited.Body != "second, revised" {
+		t.Fatalf("expected edited comment, got %#v", edited)
+	}
+
+	if err := commentRepo.Delete(ctx, owner, top[0].ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := commentRepo.Delete(ctx, owner, top[0].ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows deleting twice, got %v", err)
+	}
+	if _, err := commentRepo.Update(ctx, owner, top[0].ID, "resurrect"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows editing a deleted comment, got %v", err)
+	}
+
+	all, _, err := commentRepo.List(ctx, owner, task.ID, 10, nil)
+	if err != nil {
+		t.Fatalf("list after delete: %v", err)
+	}
+	if !all[0].Deleted || all[0].Body != "" || len(all[0].Replies) != 1 {
+		t.Fatalf("expected tombstone keeping its reply, got %#v", all[0])
+	}
+}
@@ -0,0 +1,233 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestCustomFieldRepo_Values_Filters_Sorts(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	repo := postgres.NewCustomFieldRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "cf-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Custom fields")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+
+	points, err := repo.Create(ctx, user, project, domain.CustomField{Name: "Points", Type: domain.FieldNumber})
+	if err != nil {
+		t.Fatalf("create number field: %v", err)
+	}
+	size, err := repo.Create(ctx, user, project, domain.CustomField{Name: "Size", Type: domain.FieldSingleSelect, Options: []string{"S", "M", "L"}})
+	if err != nil {
+		t.Fatalf("create select field: %v", err)
+	}
+	tags, err := repo.Create(ctx, user, project, domain.CustomField{Name: "Tags", Type: domain.FieldMultiSelect, Options: []string{"ui", "api"}})
+	if err != nil {
+		t.Fatalf("create multi-select field: %v", err)
+	}
+	if _, err := repo.Create(ctx, user, project, domain.CustomField{Name: "points", Type: domain.FieldText}); !errors.Is(err, domain.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate for a case-insensitive name clash, got %v", err)
+	}
+	if _, err := repo.Create(ctx, other, project, domain.CustomField{Name: "Sneaky", Type: domain.FieldText}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user's project, got %v", err)
+	}
+	if list, err := repo.List(ctx, other, project); err != nil || len(list) != 0 {
+		t.Fatalf("expected no fields for another user, got %v %v", list, err)
+	}
+
+	values := func(kv ...string) map[string]json.RawMessage {
+		out := map[string]json.RawMessage{}
+		for i := 0; i < len(kv); i += 2 {
+			out[kv[i]] = json.RawMessage(kv[i+1])
+		}
+		return out
+	}
+	create := func(title string, cf map[string]json.RawMessage) domain.Task {
+		t.Helper()
+		task, err := tasks.Create(ctx, user, project, domain.TaskInput{Title: title, Priority: domain.PriorityMedium, CustomFields: cf})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		return task
+	}
+	small := create("small", values(points.ID, `1`, size.ID, `"S"`, tags.ID, `["ui"]`))
+	big := create("big", values(points.ID, `8`, size.ID, `"L"`, tags.ID, `["ui","api"]`))
+	mid := create("mid", values(points.ID, `3.5`))
+	none := create("none", nil)
+
+	if string(big.CustomFields[size.ID]) != `"L"` || string(big.CustomFields[tags.ID]) != `["ui", "api"]` {
+		t.Fatalf("expected values on the created task, got %v", big.CustomFields)
+	}
+	if none.CustomFields == nil || len(none.CustomFields) != 0 {
+		t.Fatalf("expected an empty customFields object, got %#v", none.CustomFields)
+	}
+
+	titles := func(f domain.TaskFilter, sort []domain.SortKey) []string {
+		t.Helper()
+		f.ProjectID = project
+		f.LabelMatch = domain.LabelMatchAny
+		got, _, err := tasks.List(ctx, user, f, sort, 10, nil)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		var out []string
+		for _, task := range got {
+			out = append(out, task.Title)
+		}
+		return out
+	}
+	expect := func(name string, got []string, want ...string) {
+		t.Helper()
+		if len(got) != len(want) {
+			t.Fatalf("%s: expected %v, got %v", name, want, got)
+		}
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("%s: expected %v, got %v", name, want, got)
+			}
+		}
+	}
+	filter := func(field domain.CustomField, op domain.CustomFieldOp, v string) domain.TaskFilter {
+		return domain.TaskFilter{CustomFields: []domain.CustomFieldFilter{{FieldID: field.ID, Type: field.Type, Op: op, Value: v}}}
+	}
+	byPoints := []domain.SortKey{{Field: domain.CustomFieldSortPrefix + points.ID, FieldType: domain.FieldNumber}}
+
+	expect("points >= 3", titles(filter(points, domain.OpGte, "3"), byPoints), "mid", "big")
+	expect("size = L", titles(filter(size, domain.OpEq, "L"), nil), "big")
+	expect("tags has api", titles(filter(tags, domain.OpEq, "api"), nil), "big")
+	expect("points empty", titles(filter(points, domain.OpEmpty, "true"), nil), "none")
+	// Injection attempts stay plain values.
+	expect("quoted value", titles(filter(size, domain.OpEq, `L' OR '1'='1`), nil))
+
+	expect("sort by points", titles(domain.TaskFilter{}, byPoints), "small", "mid", "big", "none")
+	desc := []domain.SortKey{{Field: domain.CustomFieldSortPrefix + points.ID, Desc: true, FieldType: domain.FieldNumber}}
+	expect("sort by points desc", titles(domain.TaskFilter{}, desc), "big", "mid", "small", "none")
+
+	// Keyset pagination walks the custom field ordering.
+	var walked []string
+	var cursor *domain.Cursor
+	for {
+		page, next, err := tasks.List(ctx, user, domain.TaskFilter{ProjectID: project, LabelMatch: domain.LabelMatchAny}, byPoints, 1, cursor)
+		if err != nil {
+			t.Fatalf("page: %v", err)
+		}
+		for _, task := range page {
+			walked = append(walked, task.Title)
+		}
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+	expect("paged sort", walked, "small", "mid", "big", "none")
+
+	// Clearing a value and writing a field of another project.
+	if _, err := tasks.Update(ctx, user, mid.ID, domain.TaskPatch{CustomFields: values(points.ID, `null`)}); err != nil {
+		t.Fatalf("clear value: %v", err)
+	}
+	got, err := tasks.Get(ctx, user, mid.ID)
+	if err != nil || len(got.CustomFields) != 0 {
+		t.Fatalf("expected the value cleared, got %v %v", got.CustomFields, err)
+	}
+	foreign := uuid.NewString()
+	insertProject(t, db, foreign, user, "Elsewhere")
+	t.Cleanup(func() { deleteProject(t, db, foreign) })
+	elsewhere, err := repo.Create(ctx, user, foreign, domain.CustomField{Name: "Points", Type: domain.FieldNumber})
+	if err != nil {
+		t.Fatalf("create field in second project: %v", err)
+	}
+	if _, err := tasks.Update(ctx, user, mid.ID, domain.TaskPatch{CustomFields: values(elsewhere.ID, `2`)}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for a field of another project, got %v", err)
+	}
+	if _, err := tasks.Update(ctx, other, big.ID, domain.TaskPatch{CustomFields: values(points.ID, `2`)}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user's task, got %v", err)
+	}
+
+	// Removing options drops the values that used them.
+	if _, err := repo.Update(ctx, user, size.ID, domain.CustomFieldPatch{Options: []string{"S", "M"}}); err != nil {
+		t.Fatalf("update options: %v", err)
+	}
+	if _, err := repo.Update(ctx, user, tags.ID, domain.CustomFieldPatch{Options: []string{"api"}}); err != nil {
+		t.Fatalf("update options: %v", err)
+	}
+	got, err = tasks.Get(ctx, user, big.ID)
+	if err != nil {
+		t.Fatalf("get big: %v", err)
+	}
+	if _, ok := got.CustomFields[size.ID]; ok || string(got.CustomFields[tags.ID]) != `["api"]` {
+		t.Fatalf("expected pruned values on big, got %v", got.CustomFields)
+	}
+	got, err = tasks.Get(ctx, user, small.ID)
+	if err != nil {
+		t.Fatalf("get small: %v", err)
+	}
+	if string(got.CustomFields[size.ID]) != `"S"` {
+		t.Fatalf("expected a kept option on small, got %v", got.CustomFields)
+	}
+	if _, ok := got.CustomFields[tags.ID]; ok {
+		t.Fatalf("expected an emptied multi-select to be removed, got %v", got.CustomFields)
+	}
+
+	if err := repo.Delete(ctx, other, points.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows deleting another user's field, got %v", err)
+	}
+	if err := repo.Delete(ctx, user, points.ID); err != nil {
+		t.Fatalf("delete field: %v", err)
+	}
+	got, err = tasks.Get(ctx, user, big.ID)
+	if err != nil {
+		t.Fatalf("get big: %v", err)
+	}
+	if _, ok := got.CustomFields[points.ID]; ok {
+		t.Fatalf("expected values removed with the field, got %v", got.CustomFields)
+	}
+}
+
+func TestCustomFieldRepo_Assignable(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewCustomFieldRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	owner := uuid.NewString()
+	outsider := uuid.NewString()
+	for _, id := range []string{owner, outsider} {
+		id := id
+		insertUser(t, db, id, "assign-"+uuid.NewString()+"@example.com")
+		t.Cleanup(func() { deleteUser(t, db, id) })
+	}
+	project := uuid.NewString()
+	insertProject(t, db, project, owner, "Assignable")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+
+	got, err := repo.Assignable(ctx, project, []string{owner, outsider, uuid.NewString()})
+	if err != nil {
+		t.Fatalf("assignable: %v", err)
+	}
+	if len(got) != 1 || got[0] != owner {
+		t.Fatalf("assignable = %v, want only the owner", got)
+	}
+}
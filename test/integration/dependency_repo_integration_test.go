@@ -0,0 +1,142 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestDependencyRepo_CrossProject_IsBlocked_Ownership(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+	depRepo := postgres.NewDependencyRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	userA := uuid.NewString()
+	userB := uuid.NewString()
+	insertUser(t, db, userA, "a-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, userB, "b-"+uuid.NewString()+"@example.com")
+
+	project1 := uuid.NewString()
+	project2 := uuid.NewString()
+	insertProject(t, db, project1, userA, "One")
+	insertProject(t, db, project2, userA, "Two")
+
+	t.Cleanup(func() { deleteProject(t, db, project1) })
+	t.Cleanup(func() { deleteProject(t, db, project2) })
+	t.Cleanup(func() { deleteUser(t, db, userA) })
+	t.Cleanup(func() { deleteUser(t, db, userB) })
+
+	blocker, err := taskRepo.Create(ctx, userA, project1, domain.TaskInput{Title: "blocker"})
+	if err != nil {
+		t.Fatalf("create blocker: %v", err)
+	}
+	blocked, err := taskRepo.Create(ctx, userA, project2, domain.TaskInput{Title: "blocked"})
+	if err != nil {
+		t.Fatalf("create blocked: %v", err)
+	}
+
+	// userB cannot link userA's tasks.
+	if err := depRepo.Add(ctx, userB, blocker.ID, blocked.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner add, got %v", err)
+	}
+	if got, _ := taskRepo.Get(ctx, userA, blocked.ID); got.IsBlocked {
+		t.Fatal("non-owner link should not have been stored")
+	}
+
+	if err := depRepo.Add(ctx, userA, blocker.ID, blocked.ID); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if err := depRepo.Add(ctx, userA, blocker.ID, blocked.ID); err != nil {
+		t.Fatalf("add again: %v", err)
+	}
+	got, err := taskRepo.Get(ctx, userA, blocked.ID)
+	if err != nil {
+		t.Fatalf("get blocked: %v", err)
+	}
+	if !got.IsBlocked {
+		t.Fatal("expected task to be blocked")
+	}
+
+	deps, err := depRepo.List(ctx, userA, blocked.ID)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(deps.BlockedBy) != 1 || deps.BlockedBy[0].ID != blocker.ID || len(deps.Blocks) != 0 {
+		t.Fatalf("unexpected dependencies: %#v", deps)
+	}
+
+	// Completing the blocker unblocks the task.
+	if _, err := taskRepo.Update(ctx, userA, blocker.ID, domain.TaskPatch{Completed: ptrBool(true)}); err != nil {
+		t.Fatalf("complete blocker: %v", err)
+	}
+	if got, _ := taskRepo.Get(ctx, userA, blocked.ID); got.IsBlocked {
+		t.Fatal("expected task to be unblocked once blocker completed")
+	}
+
+	if err := depRepo.Remove(ctx, userB, blocker.ID, blocked.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner remove, got %v", err)
+	}
+	if err := depRepo.Remove(ctx, userA, blocker.ID, blocked.ID); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+}
+
+func TestDependencyRepo_Add_RejectsConcurrentCycle(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+	depRepo := postgres.NewDependencyRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	userID := uuid.NewString()
+	insertUser(t, db, userID, "cycle-"+uuid.NewString()+"@example.com")
+	project1 := uuid.NewString()
+	project2 := uuid.NewString()
+	insertProject(t, db, project1, userID, "One")
+	insertProject(t, db, project2, userID, "Two")
+	t.Cleanup(func() { deleteProject(t, db, project1) })
+	t.Cleanup(func() { deleteProject(t, db, project2) })
+	t.Cleanup(func() { deleteUser(t, db, userID) })
+
+	a, err := taskRepo.Create(ctx, userID, project1, domain.TaskInput{Title: "a"})
+	if err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	b, err := taskRepo.Create(ctx, userID, project2, domain.TaskInput{Title: "b"})
+	if err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+
+	// a blocks b and b blocks a, requested at the same time: exactly one wins.
+	errs := make(chan error, 2)
+	go func() { errs <- depRepo.Add(ctx, userID, a.ID, b.ID) }()
+	go func() { errs <- depRepo.Add(ctx, userID, b.ID, a.ID) }()
+
+	var cycles int
+	for i := 0; i < 2; i++ {
+		err := <-errs
+		switch {
+		case errors.Is(err, domain.ErrDependencyCycle):
+			cycles++
+		case err != nil:
+			t.Fatalf("add: %v", err)
+		}
+	}
+	if cycles != 1 {
+		t.Fatalf("expected exactly one link to be rejected as a cycle, got %d", cycles)
+	}
+}
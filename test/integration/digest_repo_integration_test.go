@@ -0,0 +1,165 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func pendingDigest(t *testing.T, ctx context.Context, digests *postgres.DigestRepo, userID string) (domain.DigestRecipient, bool) {
+	t.Helper()
+	pending, err := digests.Pending(ctx)
+	if err != nil {
+		t.Fatalf("pending: %v", err)
+	}
+	for _, p := range pending {
+		if p.UserID == userID {
+			return p, true
+		}
+	}
+	return domain.DigestRecipient{}, false
+}
+
+func TestDigestRepo_PendingActivityAndMarkSent(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	notifications := postgres.NewNotificationRepo(db)
+	digests := postgres.NewDigestRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	actor := uuid.NewString()
+	reader := uuid.NewString()
+	readerEmail := "digest-" + uuid.NewString() + "@example.com"
+	actorEmail := "digest-" + uuid.NewString() + "@example.com"
+	insertUser(t, db, actor, actorEmail)
+	t.Cleanup(func() { deleteUser(t, db, actor) })
+	insertUser(t, db, reader, readerEmail)
+	t.Cleanup(func() { deleteUser(t, db, reader) })
+	proj := uuid.NewString()
+	insertProject(t, db, proj, reader, "Digested")
+	t.Cleanup(func() { deleteProject(t, db, proj) })
+
+	task, err := tasks.Create(ctx, reader, proj, domain.TaskInput{Title: "ship it"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	for _, typ := range []domain.NotificationType{domain.NotifyAssigned, domain.NotifyCompleted} {
+		if _, err := notifications.Create(ctx, domain.TaskEvent{
+			Type: typ, TaskID: task.ID, ActorID: actor, Recipients: []string{reader},
+		}); err != nil {
+			t.Fatalf("create notification: %v", err)
+		}
+	}
+	if _, err := db.ExecContext(ctx, `
+		UPDATE notifications SET created_at = now() - interval '1 hour' WHERE user_id = $1
+	`, reader); err != nil {
+		t.Fatalf("backdate: %v", err)
+	}
+
+	p, ok := pendingDigest(t, ctx, digests, reader)
+	if !ok {
+		t.Fatalf("expected the reader to have a pending digest")
+	}
+	if p.Email != readerEmail || p.Token != "" || p.Settings != domain.DefaultDigestSettings() {
+		t.Fatalf("expected default settings and no token, got %+v", p)
+	}
+	if _, ok := pendingDigest(t, ctx, digests, actor); ok {
+		t.Fatalf("expected no digest for a user without notifications")
+	}
+
+	items, total, err := digests.Activity(ctx, reader, time.Now().Add(time.Minute), 1)
+	if err != nil {
+		t.Fatalf("activity: %v", err)
+	}
+	if total != 2 || len(items) != 1 || items[0].TaskTitle != "ship it" || items[0].ProjectName != "Digested" || items[0].Actor != actorEmail {
+		t.Fatalf("unexpected activity: %+v (total %d)", items, total)
+	}
+
+	sentAt := time.Now().Add(-30 * time.Minute)
+	if err := digests.MarkSent(ctx, reader, "tok-"+reader, sentAt); err != nil {
+		t.Fatalf("mark sent: %v", err)
+	}
+	if _, ok := pendingDigest(t, ctx, digests, reader); ok {
+		t.Fatalf("expected nothing pending after the digest")
+	}
+
+	if _, err := notifications.Create(ctx, domain.TaskEvent{
+		Type: domain.NotifyCommented, TaskID: task.ID, ActorID: actor, Recipients: []string{reader},
+	}); err != nil {
+		t.Fatalf("create notification: %v", err)
+	}
+	p, ok = pendingDigest(t, ctx, digests, reader)
+	if !ok || p.Token != "tok-"+reader || p.Settings.LastSentAt == nil {
+		t.Fatalf("expected a pending digest with the stored token, got %+v", p)
+	}
+	items, total, err = digests.Activity(ctx, reader, time.Now().Add(time.Minute), 10)
+	if err != nil || total != 1 || items[0].Type != domain.NotifyCommented {
+		t.Fatalf("expected only the new notification, got %+v (%v)", items, err)
+	}
+
+	// Tasks the reader may no longer read stay out of their digests.
+	if _, err := db.ExecContext(ctx, `UPDATE projects SET user_id = $2 WHERE id = $1`, proj, actor); err != nil {
+		t.Fatalf("hand over project: %v", err)
+	}
+	if _, ok := pendingDigest(t, ctx, digests, reader); ok {
+		t.Fatalf("expected no digest after losing access")
+	}
+	items, total, err = digests.Activity(ctx, reader, time.Now().Add(time.Minute), 10)
+	if err != nil || total != 0 || len(items) != 0 {
+		t.Fatalf("expected no activity after losing access, got %+v (%v)", items, err)
+	}
+}
+
+func TestDigestRepo_SettingsAndUnsubscribe(t *testing.T) {
+	db := openTestDB(t)
+	digests := postgres.NewDigestRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "digest-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	s, err := digests.Settings(ctx, user)
+	if err != nil || s != domain.DefaultDigestSettings() {
+		t.Fatalf("expected defaults, got %+v (%v)", s, err)
+	}
+
+	weekly := domain.DigestWeekly
+	tz := "Europe/Berlin"
+	hour := 18
+	s, err = digests.UpdateSettings(ctx, user, domain.DigestSettingsPatch{Frequency: &weekly, Timezone: &tz}, "tok-"+user)
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if s.Frequency != domain.DigestWeekly || s.Timezone != tz || s.Hour != 8 || s.Weekday != 1 {
+		t.Fatalf("unexpected settings: %+v", s)
+	}
+	s, err = digests.UpdateSettings(ctx, user, domain.DigestSettingsPatch{Hour: &hour}, "ignored")
+	if err != nil || s.Hour != 18 || s.Frequency != domain.DigestWeekly {
+		t.Fatalf("unexpected settings: %+v (%v)", s, err)
+	}
+
+	if err := digests.Unsubscribe(ctx, "ignored"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for a token never stored, got %v", err)
+	}
+	if err := digests.Unsubscribe(ctx, "tok-"+user); err != nil {
+		t.Fatalf("unsubscribe: %v", err)
+	}
+	if s, _ := digests.Settings(ctx, user); s.Frequency != domain.DigestOff {
+		t.Fatalf("expected digests off, got %+v", s)
+	}
+}
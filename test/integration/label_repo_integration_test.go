@@ -0,0 +1,91 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestLabelRepo_AttachAndFilter(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+	labelRepo := postgres.NewLabelRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "l-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	other := uuid.NewString()
+	insertProject(t, db, project, user, "Labelled")
+	insertProject(t, db, other, user, "Other")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteProject(t, db, other) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	bug, err := labelRepo.Create(ctx, user, project, "Bug", "#d73a4a")
+	if err != nil {
+		t.Fatalf("create bug: %v", err)
+	}
+	frontend, err := labelRepo.Create(ctx, user, project, "frontend", "#1d76db")
+	if err != nil {
+		t.Fatalf("create frontend: %v", err)
+	}
+	if _, err := labelRepo.Create(ctx, user, project, "bug", "#000000"); !errors.Is(err, domain.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate for case-insensitive duplicate, got %v", err)
+	}
+	foreign, err := labelRepo.Create(ctx, user, other, "bug", "#000000")
+	if err != nil {
+		t.Fatalf("same name in another project should be allowed: %v", err)
+	}
+
+	both, _ := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "both"})
+	onlyBug, _ := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "only bug"})
+	_, _ = taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "none"})
+
+	for _, link := range [][2]string{{both.ID, bug.ID}, {both.ID, frontend.ID}, {onlyBug.ID, bug.ID}, {onlyBug.ID, bug.ID}} {
+		if err := labelRepo.Attach(ctx, user, link[0], link[1]); err != nil {
+			t.Fatalf("attach: %v", err)
+		}
+	}
+	if err := labelRepo.Attach(ctx, user, both.ID, foreign.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows attaching a label from another project, got %v", err)
+	}
+
+	anyItems, _, err := taskRepo.List(ctx, user, domain.TaskFilter{ProjectID: project, Labels: []string{"BUG", "frontend"}, LabelMatch: domain.LabelMatchAny}, nil, 50, nil)
+	if err != nil {
+		t.Fatalf("list any: %v", err)
+	}
+	if len(anyItems) != 2 {
+		t.Fatalf("expected 2 tasks with any label, got %d", len(anyItems))
+	}
+
+	allItems, _, err := taskRepo.List(ctx, user, domain.TaskFilter{ProjectID: project, Labels: []string{"bug", "frontend"}, LabelMatch: domain.LabelMatchAll}, nil, 50, nil)
+	if err != nil {
+		t.Fatalf("list all: %v", err)
+	}
+	if len(allItems) != 1 || allItems[0].ID != both.ID {
+		t.Fatalf("expected only %s with all labels, got %#v", both.ID, allItems)
+	}
+	if len(allItems[0].Labels) != 2 || allItems[0].Labels[0].Name != "Bug" {
+		t.Fatalf("expected task labels in response, got %#v", allItems[0].Labels)
+	}
+
+	if err := labelRepo.Detach(ctx, user, onlyBug.ID, bug.ID); err != nil {
+		t.Fatalf("detach: %v", err)
+	}
+	if err := labelRepo.Detach(ctx, user, onlyBug.ID, bug.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows detaching twice, got %v", err)
+	}
+}
@@ -0,0 +1,169 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestNotificationRepo_CreateListAndRead(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	notifications := postgres.NewNotificationRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	owner := uuid.NewString()
+	actor := uuid.NewString()
+	outsider := uuid.NewString()
+	for _, id := range []string{owner, actor, outsider} {
+		id := id
+		insertUser(t, db, id, "notify-"+uuid.NewString()+"@example.com")
+		t.Cleanup(func() { deleteUser(t, db, id) })
+	}
+	proj := uuid.NewString()
+	insertProject(t, db, proj, owner, "Notified")
+	t.Cleanup(func() { deleteProject(t, db, proj) })
+
+	task, err := tasks.Create(ctx, owner, proj, domain.TaskInput{Title: "ship it"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	off := false
+	prefs, err := notifications.UpdatePrefs(ctx, owner, domain.NotificationPrefsPatch{Commented: &off})
+	if err != nil {
+		t.Fatalf("update prefs: %v", err)
+	}
+	if prefs.Commented || !prefs.Completed || !prefs.Assigned || !prefs.DueSoon {
+		t.Fatalf("unexpected prefs: %+v", prefs)
+	}
+
+	n, err := notifications.Create(ctx, domain.TaskEvent{
+		Type:       domain.NotifyCompleted,
+		TaskID:     task.ID,
+		ActorID:    actor,
+		Recipients: []string{owner, outsider, uuid.NewString()},
+	})
+	if err != nil {
+		t.Fatalf("create notifications: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected one notification past the access check, got %d", n)
+	}
+	if n, err := notifications.Create(ctx, domain.TaskEvent{
+		Type: domain.NotifyCommented, TaskID: task.ID, ActorID: actor, Recipients: []string{owner},
+	}); err != nil || n != 0 {
+		t.Fatalf("expected the preferences to drop the comment notification, got %d (%v)", n, err)
+	}
+	if _, err := notifications.Create(ctx, domain.TaskEvent{
+		Type: domain.NotifyAssigned, TaskID: task.ID, ActorID: actor, Recipients: []string{owner},
+	}); err != nil {
+		t.Fatalf("create notifications: %v", err)
+	}
+
+	page, next, err := notifications.List(ctx, owner, false, 1, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(page) != 1 || next == nil || page[0].Type != domain.NotifyAssigned || page[0].TaskTitle != "ship it" || page[0].ProjectID != proj {
+		t.Fatalf("unexpected first page: %+v (next %v)", page, next)
+	}
+	rest, _, err := notifications.List(ctx, owner, false, 10, next)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(rest) != 1 || rest[0].Type != domain.NotifyCompleted || rest[0].ActorID == nil || *rest[0].ActorID != actor {
+		t.Fatalf("unexpected second page: %+v", rest)
+	}
+
+	if c, err := notifications.UnreadCount(ctx, owner); err != nil || c != 2 {
+		t.Fatalf("expected 2 unread, got %d (%v)", c, err)
+	}
+	if err := notifications.MarkRead(ctx, owner, page[0].ID); err != nil {
+		t.Fatalf("mark read: %v", err)
+	}
+	if err := notifications.MarkRead(ctx, actor, page[0].ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for someone else's notification, got %v", err)
+	}
+	unread, _, err := notifications.List(ctx, owner, true, 10, nil)
+	if err != nil || len(unread) != 1 || unread[0].ID != rest[0].ID {
+		t.Fatalf("unexpected unread list: %+v (%v)", unread, err)
+	}
+	if n, err := notifications.MarkAllRead(ctx, owner); err != nil || n != 1 {
+		t.Fatalf("expected one marked read, got %d (%v)", n, err)
+	}
+	if c, _ := notifications.UnreadCount(ctx, owner); c != 0 {
+		t.Fatalf("expected no unread, got %d", c)
+	}
+
+	// Notifications about tasks a user may no longer read are hidden.
+	if _, err := db.ExecContext(ctx, `UPDATE projects SET user_id = $2 WHERE id = $1`, proj, outsider); err != nil {
+		t.Fatalf("hand over project: %v", err)
+	}
+	if list, _, err := notifications.List(ctx, owner, false, 10, nil); err != nil || len(list) != 0 {
+		t.Fatalf("expected no notifications after losing access, got %+v (%v)", list, err)
+	}
+}
+
+func TestNotificationRepo_CreateDueSoonOncePerDueDate(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	watchers := postgres.NewWatcherRepo(db)
+	notifications := postgres.NewNotificationRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "due-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	proj := uuid.NewString()
+	insertProject(t, db, proj, user, "Due")
+	t.Cleanup(func() { deleteProject(t, db, proj) })
+
+	now := time.Now().UTC().Truncate(time.Second)
+	soon := now.Add(2 * time.Hour)
+	later := now.Add(72 * time.Hour)
+	dueSoon, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "soon", DueDate: &soon})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if _, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "later", DueDate: &later}); err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	if err := watchers.WatchProject(ctx, user, proj); err != nil {
+		t.Fatalf("watch project: %v", err)
+	}
+	if err := watchers.Subscribe(ctx, dueSoon.ID, []string{user}, domain.WatchCreator); err != nil {
+		t.Fatalf("subscribe: %v", err)
+	}
+
+	for i, want := range []int{1, 0} {
+		n, err := notifications.CreateDueSoon(ctx, now, now.Add(24*time.Hour))
+		if err != nil {
+			t.Fatalf("run %d: %v", i, err)
+		}
+		if n != want {
+			t.Fatalf("run %d: expected %d notifications, got %d", i, want, n)
+		}
+	}
+	list, _, err := notifications.List(ctx, user, true, 10, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 1 || list[0].Type != domain.NotifyDueSoon || list[0].DueDate == nil || !list[0].DueDate.Equal(soon) {
+		t.Fatalf("unexpected notifications: %+v", list)
+	}
+}
@@ -0,0 +1,182 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestProjectRepo_Duplicate(t *testing.T) {
+	db := openTestDB(t)
+	projects := postgres.NewProjectRepo(db)
+	tasks := postgres.NewTaskRepo(db)
+	labels := postgres.NewLabelRepo(db)
+	fields := postgres.NewCustomFieldRepo(db)
+	checklists := postgres.NewChecklistRepo(db)
+	deps := postgres.NewDependencyRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "dup-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+	src := uuid.NewString()
+	insertProject(t, db, src, user, "Launch")
+	t.Cleanup(func() { deleteProject(t, db, src) })
+
+	bug, err := labels.Create(ctx, user, src, "Bug", "#ff0000")
+	if err != nil {
+		t.Fatalf("label: %v", err)
+	}
+	size, err := fields.Create(ctx, user, src, domain.CustomField{Name: "Size", Type: domain.FieldSingleSelect, Options: []string{"S", "L"}})
+	if err != nil {
+		t.Fatalf("field: %v", err)
+	}
+	due := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	parent, err := tasks.Create(ctx, user, src, domain.TaskInput{
+		Title: "Plan", DueDate: &due, CustomFields: map[string]json.RawMessage{size.ID: json.RawMessage(`"L"`)},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	child, err := tasks.Create(ctx, user, src, domain.TaskInput{Title: "Draft", ParentTaskID: &parent.ID})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := tasks.Update(ctx, user, child.ID, domain.TaskPatch{Completed: ptrBool(true)}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if err := labels.Attach(ctx, user, parent.ID, bug.ID); err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if _, err := checklists.Create(ctx, user, parent.ID, "agenda", true); err != nil {
+		t.Fatalf("checklist: %v", err)
+	}
+	if err := deps.Add(ctx, user, child.ID, parent.ID); err != nil {
+		t.Fatalf("dependency: %v", err)
+	}
+
+	if _, err := projects.Duplicate(ctx, other, src, domain.DuplicateOptions{}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user's project, got %v", err)
+	}
+	if n, err := projects.TaskCount(ctx, user, src); err != nil || n != 2 {
+		t.Fatalf("expected 2 tasks, got %d %v", n, err)
+	}
+
+	cp, err := projects.Duplicate(ctx, user, src, domain.DuplicateOptions{ResetCompletion: true, ShiftDays: 14})
+	if err != nil {
+		t.Fatalf("duplicate: %v", err)
+	}
+	t.Cleanup(func() { deleteProject(t, db, cp.ID) })
+	if cp.Name != "Launch (copy)" || cp.ID == src {
+		t.Fatalf("unexpected copy %+v", cp)
+	}
+
+	list, _, err := tasks.List(ctx, user, domain.TaskFilter{ProjectID: cp.ID, LabelMatch: domain.LabelMatchAny},
+		[]domain.SortKey{{Field: "position"}}, 10, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 2 || list[0].Title != "Plan" || list[1].Title != "Draft" {
+		t.Fatalf("expected both tasks copied in order, got %+v", list)
+	}
+	plan, draft := list[0], list[1]
+	if plan.ID == parent.ID || draft.ParentTaskID == nil || *draft.ParentTaskID != plan.ID {
+		t.Fatalf("expected the hierarchy rebuilt among the copies, got %+v", draft)
+	}
+	if draft.Completed || draft.Status != domain.StatusTodo {
+		t.Fatalf("expected completion reset, got %+v", draft)
+	}
+	if plan.DueDate == nil || !plan.DueDate.Equal(due.AddDate(0, 0, 14)) {
+		t.Fatalf("expected the due date shifted, got %v", plan.DueDate)
+	}
+	if len(plan.Labels) != 1 || plan.Labels[0].Name != "Bug" || plan.Labels[0].ID == bug.ID {
+		t.Fatalf("expected a copied label, got %+v", plan.Labels)
+	}
+	if plan.ChecklistTotal != 1 || plan.ChecklistChecked != 0 || !plan.IsBlocked {
+		t.Fatalf("expected an unticked checklist and the copied blocker, got %+v", plan)
+	}
+	copiedFields, err := fields.List(ctx, user, cp.ID)
+	if err != nil || len(copiedFields) != 1 || string(plan.CustomFields[copiedFields[0].ID]) != `"L"` {
+		t.Fatalf("expected the custom value on the copied field, got %v %v", plan.CustomFields, err)
+	}
+
+	// The original is untouched.
+	if orig, err := tasks.Get(ctx, user, child.ID); err != nil || !orig.Completed {
+		t.Fatalf("expected the original still completed, got %+v %v", orig, err)
+	}
+}
+
+func TestDuplicationRepo_Queue(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewDuplicationRepo(db)
+	tasks := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "dq-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+	src := uuid.NewString()
+	gone := uuid.NewString()
+	insertProject(t, db, src, user, "Big")
+	insertProject(t, db, gone, user, "Gone")
+	t.Cleanup(func() { deleteProject(t, db, src) })
+	if _, err := tasks.Create(ctx, user, src, domain.TaskInput{Title: "one"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := repo.Create(ctx, other, src, domain.DuplicateOptions{}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user's project, got %v", err)
+	}
+	d, err := repo.Create(ctx, user, src, domain.DuplicateOptions{Name: "Big v2"})
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	doomed, err := repo.Create(ctx, user, gone, domain.DuplicateOptions{})
+	if err != nil {
+		t.Fatalf("queue: %v", err)
+	}
+	deleteProject(t, db, gone)
+	if _, err := repo.Get(ctx, other, d.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user, got %v", err)
+	}
+
+	// Other tests may have queued copies too; run until ours are settled.
+	for i := 0; i < 20; i++ {
+		if _, ok, _ := repo.RunNext(ctx); !ok {
+			break
+		}
+	}
+	done, err := repo.Get(ctx, user, d.ID)
+	if err != nil || done.Status != domain.DuplicationDone || done.ProjectID == nil {
+		t.Fatalf("expected the copy done, got %+v %v", done, err)
+	}
+	t.Cleanup(func() { deleteProject(t, db, *done.ProjectID) })
+	if n, err := postgres.NewProjectRepo(db).TaskCount(ctx, user, *done.ProjectID); err != nil || n != 1 {
+		t.Fatalf("expected the task copied, got %d %v", n, err)
+	}
+	failed, err := repo.Get(ctx, user, doomed.ID)
+	if err != nil || failed.Status != domain.DuplicationFailed || failed.Error != "source project not found" {
+		t.Fatalf("expected the copy of a deleted project failed, got %+v %v", failed, err)
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 	"time"
 
+	"TaskFlow/internal/domain"
 	"TaskFlow/internal/repo/postgres"
 
 	"github.com/google/uuid"
@@ -32,7 +33,7 @@ func TestProjectRepo_CRUD_Ownership(t *testing.T) {
 	t.Cleanup(func() { deleteUser(t, db, userB) })
 
 	// Create a project for userA
-	p, err := repo.Create(ctx, userA, "Project A")
+	p, err := repo.Create(ctx, userA, "Project A", "")
 	if err != nil {
 		t.Fatalf("create: %v", err)
 	}
@@ -50,7 +51,8 @@ func TestProjectRepo_CRUD_Ownership(t *testing.T) {
 	}
 
 	// update as non-owner should fail (sql.ErrNoRows)
-	if _, err := repo.UpdateName(ctx, userB, p.ID, "Hacked"); err == nil {
+	hacked := "Hacked"
+	if _, err := repo.Update(ctx, userB, p.ID, domain.ProjectPatch{Name: &hacked}); err == nil {
 		t.Fatalf("expected error for non-owner update")
 	} else if !errors.Is(err, sql.ErrNoRows) {
 		t.Fatalf("expected sql.ErrNoRows for non-owner update, got %v", err)
@@ -74,3 +76,79 @@ func TestProjectRepo_CRUD_Ownership(t *testing.T) {
 		t.Fatalf("expected sql.ErrNoRows after delete, got %v", err)
 	}
 }
+
+func TestProjectRepo_Archive(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewProjectRepo(db)
+	tasks := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	userA := uuid.NewString()
+	userB := uuid.NewString()
+	insertUser(t, db, userA, "a-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, userB, "b-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, userA) })
+	t.Cleanup(func() { deleteUser(t, db, userB) })
+
+	live, err := repo.Create(ctx, userA, "Live", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	old, err := repo.Create(ctx, userA, "Old", "")
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if _, err := repo.SetArchived(ctx, userB, old.ID, true); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner archive, got %v", err)
+	}
+	p, err := repo.SetArchived(ctx, userA, old.ID, true)
+	if err != nil {
+		t.Fatalf("archive: %v", err)
+	}
+	if p.ArchivedAt == nil {
+		t.Fatal("expected archivedAt to be set")
+	}
+	again, err := repo.SetArchived(ctx, userA, old.ID, true)
+	if err != nil {
+		t.Fatalf("archive again: %v", err)
+	}
+	if again.ArchivedAt == nil || !again.ArchivedAt.Equal(*p.ArchivedAt) {
+		t.Fatalf("expected archiving twice to keep archivedAt, got %v", again.ArchivedAt)
+	}
+
+	active, _, err := repo.List(ctx, userA, false, 10, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(active) != 1 || active[0].ID != live.ID {
+		t.Fatalf("expected only the live project by default, got %+v", active)
+	}
+	archived, _, err := repo.List(ctx, userA, true, 10, nil)
+	if err != nil {
+		t.Fatalf("list archived: %v", err)
+	}
+	if len(archived) != 1 || archived[0].ID != old.ID {
+		t.Fatalf("expected only the archived project, got %+v", archived)
+	}
+
+	if ok, err := tasks.ProjectArchived(ctx, userA, old.ID); err != nil || !ok {
+		t.Fatalf("expected archived project, got %v, %v", ok, err)
+	}
+	if ok, err := tasks.ProjectArchived(ctx, userA, live.ID); err != nil || ok {
+		t.Fatalf("expected live project, got %v, %v", ok, err)
+	}
+	if _, err := tasks.ProjectArchived(ctx, userB, old.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner, got %v", err)
+	}
+
+	p, err = repo.SetArchived(ctx, userA, old.ID, false)
+	if err != nil {
+		t.Fatalf("unarchive: %v", err)
+	}
+	if p.ArchivedAt != nil {
+		t.Fatalf("expected archivedAt to be cleared, got %v", p.ArchivedAt)
+	}
+}
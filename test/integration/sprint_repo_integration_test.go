@@ -0,0 +1,123 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestSprintRepo_CompleteCarriesOverAndStats(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	sprints := postgres.NewSprintRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "sprint-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+	proj := uuid.NewString()
+	insertProject(t, db, proj, user, "Sprints")
+	t.Cleanup(func() { deleteProject(t, db, proj) })
+
+	s1, err := sprints.Create(ctx, user, proj, domain.Sprint{Name: "Sprint 1", Goal: "Search", StartDate: "2026-03-02", EndDate: "2026-03-13"})
+	if err != nil {
+		t.Fatalf("create sprint: %v", err)
+	}
+	if s1.StartDate != "2026-03-02" || s1.EndDate != "2026-03-13" || s1.CompletedAt != nil {
+		t.Fatalf("unexpected sprint: %+v", s1)
+	}
+	s2, err := sprints.Create(ctx, user, proj, domain.Sprint{Name: "Sprint 2", StartDate: "2026-03-16", EndDate: "2026-03-27"})
+	if err != nil {
+		t.Fatalf("create sprint: %v", err)
+	}
+	if _, err := sprints.Create(ctx, other, proj, domain.Sprint{Name: "x", StartDate: "2026-03-16", EndDate: "2026-03-27"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner create, got %v", err)
+	}
+
+	three, two := 3.0, 2.0
+	done, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "done", SprintID: &s1.ID, Estimate: &three})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	open, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "open", SprintID: &s1.ID, Estimate: &two})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	backlog, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "backlog"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	completed := true
+	if _, err := tasks.Update(ctx, user, done.ID, domain.TaskPatch{Completed: &completed}); err != nil {
+		t.Fatalf("complete task: %v", err)
+	}
+
+	inSprint, _, err := tasks.List(ctx, user, domain.TaskFilter{ProjectID: proj, SprintID: s1.ID}, nil, 10, nil)
+	if err != nil || len(inSprint) != 2 {
+		t.Fatalf("expected 2 tasks in sprint 1, got %d %v", len(inSprint), err)
+	}
+	inBacklog, _, err := tasks.List(ctx, user, domain.TaskFilter{ProjectID: proj, Backlog: true}, nil, 10, nil)
+	if err != nil || len(inBacklog) != 1 || inBacklog[0].ID != backlog.ID {
+		t.Fatalf("expected only the backlog task, got %+v %v", inBacklog, err)
+	}
+
+	res, err := sprints.Complete(ctx, user, s1.ID, nil)
+	if err != nil {
+		t.Fatalf("complete sprint: %v", err)
+	}
+	if res.Sprint.CompletedAt == nil || res.CarriedOver != 1 || res.NextSprintID == nil || *res.NextSprintID != s2.ID {
+		t.Fatalf("expected one task carried to sprint 2, got %+v", res)
+	}
+	got, err := tasks.Get(ctx, user, open.ID)
+	if err != nil || got.SprintID == nil || *got.SprintID != s2.ID {
+		t.Fatalf("expected open task in sprint 2, got %v %v", got.SprintID, err)
+	}
+	if _, err := sprints.Complete(ctx, user, s1.ID, nil); !errors.Is(err, domain.ErrSprintCompleted) {
+		t.Fatalf("expected ErrSprintCompleted, got %v", err)
+	}
+
+	st, err := sprints.Stats(ctx, user, s1.ID)
+	if err != nil {
+		t.Fatalf("stats: %v", err)
+	}
+	if st.TotalTasks != 2 || st.CompletedTasks != 1 || st.CarriedOverTasks != 1 || st.TotalEstimate != 5 || st.CompletedEstimate != 3 {
+		t.Fatalf("unexpected stats: %+v", st)
+	}
+
+	// The last sprint has nowhere to carry work to, so it goes back to the
+	// backlog; a completed sprint cannot receive it either.
+	if _, err := sprints.Complete(ctx, user, s2.ID, &s1.ID); !errors.Is(err, domain.ErrInvalidSprint) {
+		t.Fatalf("expected ErrInvalidSprint, got %v", err)
+	}
+	res, err = sprints.Complete(ctx, user, s2.ID, nil)
+	if err != nil || res.NextSprintID != nil || res.CarriedOver != 1 {
+		t.Fatalf("expected one task back in the backlog, got %+v %v", res, err)
+	}
+	got, err = tasks.Get(ctx, user, open.ID)
+	if err != nil || got.SprintID != nil {
+		t.Fatalf("expected open task in the backlog, got %v %v", got.SprintID, err)
+	}
+
+	if err := sprints.Delete(ctx, user, s1.ID); err != nil {
+		t.Fatalf("delete sprint: %v", err)
+	}
+	got, err = tasks.Get(ctx, user, done.ID)
+	if err != nil || got.SprintID != nil {
+		t.Fatalf("expected deleting the sprint to clear it from tasks, got %v %v", got.SprintID, err)
+	}
+}
@@ -0,0 +1,170 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestTaskRepo_Create_NumbersConcurrentTasks(t *testing.T) {
+	db := openTestDB(t)
+	projects := postgres.NewProjectRepo(db)
+	tasks := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "keys-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	p, err := projects.Create(ctx, user, "Numbers", "NUM")
+	if err != nil {
+		t.Fatalf("create project: %v", err)
+	}
+
+	const n = 20
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		numbers = map[int]string{}
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			task, err := tasks.Create(ctx, user, p.ID, domain.TaskInput{Title: fmt.Sprintf("task %d", i)})
+			if err != nil {
+				t.Errorf("create task: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if prev, ok := numbers[task.Number]; ok {
+				t.Errorf("number %d handed to both %s and %s", task.Number, prev, task.ID)
+			}
+			numbers[task.Number] = task.ID
+			if task.Key != fmt.Sprintf("NUM-%d", task.Number) {
+				t.Errorf("unexpected key %q for number %d", task.Key, task.Number)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 1; i <= n; i++ {
+		if _, ok := numbers[i]; !ok {
+			t.Fatalf("expected numbers 1 to %d, got %v", n, numbers)
+		}
+	}
+}
+
+func TestProjectRepo_Keys_DerivedRenamedAndReserved(t *testing.T) {
+	db := openTestDB(t)
+	projects := postgres.NewProjectRepo(db)
+	tasks := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "keys-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	insertUser(t, db, other, "keys-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, other) })
+
+	web, err := projects.Create(ctx, user, "Website", "")
+	if err != nil || web.Key != "WEB" {
+		t.Fatalf("expected derived key WEB, got %q (%v)", web.Key, err)
+	}
+	app, err := projects.Create(ctx, user, "Web app", "")
+	if err != nil || app.Key != "WEB2" {
+		t.Fatalf("expected derived key WEB2, got %q (%v)", app.Key, err)
+	}
+	if _, err := projects.Create(ctx, user, "Another", "WEB"); !errors.Is(err, domain.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate for a taken key, got %v", err)
+	}
+	if p, err := projects.Create(ctx, other, "Website", ""); err != nil || p.Key != "WEB" {
+		t.Fatalf("expected keys to be per user, got %q (%v)", p.Key, err)
+	}
+
+	task, err := tasks.Create(ctx, user, web.ID, domain.TaskInput{Title: "first"})
+	if err != nil || task.Key != "WEB-1" {
+		t.Fatalf("expected WEB-1, got %q (%v)", task.Key, err)
+	}
+
+	site := "SITE"
+	if web, err = projects.Update(ctx, user, web.ID, domain.ProjectPatch{Key: &site}); err != nil || web.Key != "SITE" {
+		t.Fatalf("rename key: %q (%v)", web.Key, err)
+	}
+	for _, key := range []string{"SITE", "WEB"} {
+		got, err := tasks.GetByKey(ctx, user, key, 1)
+		if err != nil || got.ID != task.ID || got.Key != "SITE-1" {
+			t.Fatalf("%s-1: expected the renamed task, got %+v (%v)", key, got, err)
+		}
+	}
+	if _, err := tasks.GetByKey(ctx, other, "SITE", 1); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user, got %v", err)
+	}
+
+	old := "WEB"
+	if _, err := projects.Update(ctx, user, app.ID, domain.ProjectPatch{Key: &old}); !errors.Is(err, domain.ErrDuplicate) {
+		t.Fatalf("expected an old key to stay reserved, got %v", err)
+	}
+	if web, err = projects.Update(ctx, user, web.ID, domain.ProjectPatch{Key: &old}); err != nil || web.Key != "WEB" {
+		t.Fatalf("expected a project to get its old key back, got %q (%v)", web.Key, err)
+	}
+}
+
+func TestProjectRepo_Create_DerivesDistinctKeysConcurrently(t *testing.T) {
+	db := openTestDB(t)
+	projects := postgres.NewProjectRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "keys-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	const n = 8
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		keys = map[string]bool{}
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			p, err := projects.Create(ctx, user, "Website", "")
+			if err != nil {
+				t.Errorf("create project: %v", err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if keys[p.Key] {
+				t.Errorf("key %s derived twice", p.Key)
+			}
+			keys[p.Key] = true
+		}()
+	}
+	wg.Wait()
+
+	if len(keys) != n {
+		t.Fatalf("expected %d distinct keys, got %v", n, keys)
+	}
+}
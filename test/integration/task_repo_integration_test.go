@@ -6,10 +6,14 @@ package integration
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"TaskFlow/internal/domain"
 	"TaskFlow/internal/repo/postgres"
 
 	"github.com/google/uuid"
@@ -39,15 +43,15 @@ func TestTaskRepo_Ownership_Filter_Pagination(t *testing.T) {
 	t.Cleanup(func() { deleteUser(t, db, userA) })
 	t.Cleanup(func() { deleteUser(t, db, userB) })
 
-	t1, err := taskRepo.Create(ctx, userA, projectA, "Task 1")
+	t1, err := taskRepo.Create(ctx, userA, projectA, domain.TaskInput{Title: "Task 1"})
 	if err != nil {
 		t.Fatalf("create t1: %v", err)
 	}
-	t2, err := taskRepo.Create(ctx, userA, projectA, "Task 2")
+	t2, err := taskRepo.Create(ctx, userA, projectA, domain.TaskInput{Title: "Task 2"})
 	if err != nil {
 		t.Fatalf("create t2: %v", err)
 	}
-	t3, err := taskRepo.Create(ctx, userA, projectA, "Task 3")
+	t3, err := taskRepo.Create(ctx, userA, projectA, domain.TaskInput{Title: "Task 3"})
 	if err != nil {
 		t.Fatalf("create t3: %v", err)
 	}
@@ -66,21 +70,21 @@ func TestTaskRepo_Ownership_Filter_Pagination(t *testing.T) {
 
 	// update as non-owner should fail
 	newTitle := "hacked"
-	if _, err := taskRepo.Update(ctx, userB, t1.ID, &newTitle, nil); err == nil {
+	if _, err := taskRepo.Update(ctx, userB, t1.ID, domain.TaskPatch{Title: &newTitle}); err == nil {
 		t.Fatalf("expected error for non-owner update")
 	} else if !errors.Is(err, sql.ErrNoRows) {
 		t.Fatalf("expected sql.ErrNoRows for non-owner update, got %v", err)
 	}
 
 	// delete as non-owner should fail
-	if err := taskRepo.Delete(ctx, userB, t1.ID); err == nil {
+	if err := taskRepo.Delete(ctx, userB, t1.ID, domain.ChildrenCascade); err == nil {
 		t.Fatalf("expected error for non-owner delete")
 	} else if !errors.Is(err, sql.ErrNoRows) {
 		t.Fatalf("expected sql.ErrNoRows for non-owner delete, got %v", err)
 	}
 
 	// Pagination: limit 2 should return 2 + nextCursor
-	items, next, err := taskRepo.List(ctx, userA, projectA, nil, 2, nil)
+	items, next, err := taskRepo.List(ctx, userA, domain.TaskFilter{ProjectID: projectA}, nil, 2, nil)
 	if err != nil {
 		t.Fatalf("list page1: %v", err)
 	}
@@ -92,7 +96,7 @@ func TestTaskRepo_Ownership_Filter_Pagination(t *testing.T) {
 	}
 
 	// Next page should return remaining 1
-	items2, next2, err := taskRepo.List(ctx, userA, projectA, nil, 2, next)
+	items2, next2, err := taskRepo.List(ctx, userA, domain.TaskFilter{ProjectID: projectA}, nil, 2, next)
 	if err != nil {
 		t.Fatalf("list page2: %v", err)
 	}
@@ -104,13 +108,13 @@ func TestTaskRepo_Ownership_Filter_Pagination(t *testing.T) {
 	}
 
 	// Mark one completed
-	_, err = taskRepo.Update(ctx, userA, t2.ID, nil, ptrBool(true))
+	_, err = taskRepo.Update(ctx, userA, t2.ID, domain.TaskPatch{Completed: ptrBool(true)})
 	if err != nil {
 		t.Fatalf("update completed: %v", err)
 	}
 
 	// Filter completed=true should return exactly that one
-	itemsC, _, err := taskRepo.List(ctx, userA, projectA, ptrBool(true), 50, nil)
+	itemsC, _, err := taskRepo.List(ctx, userA, domain.TaskFilter{ProjectID: projectA, Completed: ptrBool(true)}, nil, 50, nil)
 	if err != nil {
 		t.Fatalf("list completed=true: %v", err)
 	}
@@ -122,7 +126,7 @@ func TestTaskRepo_Ownership_Filter_Pagination(t *testing.T) {
 	}
 
 	// Delete as owner succeeds
-	if err := taskRepo.Delete(ctx, userA, t3.ID); err != nil {
+	if err := taskRepo.Delete(ctx, userA, t3.ID, domain.ChildrenCascade); err != nil {
 		t.Fatalf("delete as owner: %v", err)
 	}
 	if _, err := taskRepo.Get(ctx, userA, t3.ID); err == nil {
@@ -139,7 +143,7 @@ func TestTaskRepo_Ownership_Filter_Pagination(t *testing.T) {
 	}
 
 	// Ensure non-owner cannot list tasks of another user's project (by passing projectA with userB)
-	_, _, err = taskRepo.List(ctx, userB, projectA, nil, 10, nil)
+	_, _, err = taskRepo.List(ctx, userB, domain.TaskFilter{ProjectID: projectA}, nil, 10, nil)
 	if err != nil {
 		// List should typically return empty + nil cursor, not error.
 		// But if your repo chooses to enforce "project must be owned", sql.ErrNoRows is acceptable too.
@@ -152,4 +156,845 @@ func TestTaskRepo_Ownership_Filter_Pagination(t *testing.T) {
 	_ = t2
 }
 
+func TestTaskRepo_List_SortByPriorityThenDueDate_Paginates(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "s-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Sorted")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	soon := time.Now().Add(24 * time.Hour).UTC()
+	later := soon.Add(24 * time.Hour)
+
+	inputs := []domain.TaskInput{
+		{Title: "low", Priority: domain.PriorityLow},
+		{Title: "urgent-undated", Priority: domain.PriorityUrgent},
+		{Title: "urgent-later", Priority: domain.PriorityUrgent, DueDate: &later},
+		{Title: "urgent-soon", Priority: domain.PriorityUrgent, DueDate: &soon},
+		{Title: "none"},
+	}
+	for _, in := range inputs {
+		if _, err := taskRepo.Create(ctx, user, project, in); err != nil {
+			t.Fatalf("create %s: %v", in.Title, err)
+		}
+	}
+
+	sort, err := domain.ParseTaskSort("-priority,dueDate")
+	if err != nil {
+		t.Fatalf("parse sort: %v", err)
+	}
+
+	var got []string
+	var cursor *domain.Cursor
+	for {
+		items, next, err := taskRepo.List(ctx, user, domain.TaskFilter{ProjectID: project}, sort, 2, cursor)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		for _, it := range items {
+			got = append(got, it.Title)
+		}
+		if next == nil {
+			break
+		}
+		cursor = next
+	}
+
+	want := []string{"urgent-soon", "urgent-later", "urgent-undated", "low", "none"}
+	if len(got) != len(want) {
+		t.Fatalf("expected %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("expected %v, got %v", want, got)
+		}
+	}
+}
+
 func ptrBool(b bool) *bool { return &b }
+
+func TestTaskRepo_Subtasks_RollUp_CascadeComplete_Reparent(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "h-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Hierarchy")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	root, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "root"})
+	if err != nil {
+		t.Fatalf("create root: %v", err)
+	}
+	mid, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "mid", ParentTaskID: &root.ID})
+	if err != nil {
+		t.Fatalf("create mid: %v", err)
+	}
+	leaf, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "leaf", ParentTaskID: &mid.ID})
+	if err != nil {
+		t.Fatalf("create leaf: %v", err)
+	}
+
+	ancestors, err := taskRepo.Ancestors(ctx, user, leaf.ID)
+	if err != nil {
+		t.Fatalf("ancestors: %v", err)
+	}
+	if len(ancestors) != 2 || ancestors[0] != mid.ID || ancestors[1] != root.ID {
+		t.Fatalf("unexpected ancestors: %v", ancestors)
+	}
+
+	sub, err := taskRepo.Subtree(ctx, user, root.ID)
+	if err != nil {
+		t.Fatalf("subtree: %v", err)
+	}
+	if len(sub) != 3 || sub[0].ID != root.ID || sub[0].SubtaskCount != 1 {
+		t.Fatalf("unexpected subtree: %#v", sub)
+	}
+
+	// Completing mid with CompleteSubtasks also completes leaf.
+	got, err := taskRepo.Update(ctx, user, mid.ID, domain.TaskPatch{Completed: ptrBool(true), CompleteSubtasks: true})
+	if err != nil {
+		t.Fatalf("complete mid: %v", err)
+	}
+	if got.SubtaskCount != 1 || got.CompletedSubtaskCount != 1 {
+		t.Fatalf("expected 1/1 subtasks completed, got %d/%d", got.CompletedSubtaskCount, got.SubtaskCount)
+	}
+
+	// Reparenting is checked against the tree and the depth limit.
+	if _, err := taskRepo.Update(ctx, user, root.ID, domain.TaskPatch{ParentTaskID: &leaf.ID}); !errors.Is(err, domain.ErrParentCycle) {
+		t.Fatalf("expected ErrParentCycle moving root under its leaf, got %v", err)
+	}
+	other, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "other"})
+	if err != nil {
+		t.Fatalf("create other: %v", err)
+	}
+	if _, err := taskRepo.Update(ctx, user, other.ID, domain.TaskPatch{ParentTaskID: &leaf.ID, MaxDepth: 2}); !errors.Is(err, domain.ErrTooDeep) {
+		t.Fatalf("expected ErrTooDeep nesting under leaf, got %v", err)
+	}
+	if _, err := taskRepo.Update(ctx, user, root.ID, domain.TaskPatch{ParentTaskID: &other.ID, MaxDepth: 2}); !errors.Is(err, domain.ErrTooDeep) {
+		t.Fatalf("expected ErrTooDeep moving a two-level subtree under other, got %v", err)
+	}
+	missing := uuid.NewString()
+	if _, err := taskRepo.Update(ctx, user, other.ID, domain.TaskPatch{ParentTaskID: &missing}); !errors.Is(err, domain.ErrInvalidParent) {
+		t.Fatalf("expected ErrInvalidParent for a missing parent, got %v", err)
+	}
+	if _, err := taskRepo.Update(ctx, user, other.ID, domain.TaskPatch{ParentTaskID: &mid.ID, MaxDepth: 3}); err != nil {
+		t.Fatalf("reparent other under mid: %v", err)
+	}
+	if err := taskRepo.Delete(ctx, user, other.ID, domain.ChildrenCascade); err != nil {
+		t.Fatalf("delete other: %v", err)
+	}
+
+	// Deleting mid with reparent keeps leaf under root.
+	if err := taskRepo.Delete(ctx, user, mid.ID, domain.ChildrenReparent); err != nil {
+		t.Fatalf("delete mid: %v", err)
+	}
+	leafAfter, err := taskRepo.Get(ctx, user, leaf.ID)
+	if err != nil {
+		t.Fatalf("get leaf: %v", err)
+	}
+	if leafAfter.ParentTaskID == nil || *leafAfter.ParentTaskID != root.ID {
+		t.Fatalf("expected leaf to be reparented under root, got %v", leafAfter.ParentTaskID)
+	}
+
+	// Deleting root with cascade removes leaf too.
+	if err := taskRepo.Delete(ctx, user, root.ID, domain.ChildrenCascade); err != nil {
+		t.Fatalf("delete root: %v", err)
+	}
+	if _, err := taskRepo.Get(ctx, user, leaf.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected leaf to be deleted with root, got %v", err)
+	}
+}
+
+func TestTaskRepo_Update_ConcurrentReparentsCannotCloseCycle(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "rp-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Reparent")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	a, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "a"})
+	if err != nil {
+		t.Fatalf("create a: %v", err)
+	}
+	b, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "b"})
+	if err != nil {
+		t.Fatalf("create b: %v", err)
+	}
+
+	// Each goes under the other; the project lock lets only one through.
+	var (
+		wg   sync.WaitGroup
+		errs = make([]error, 2)
+	)
+	for i, pair := range [][2]string{{a.ID, b.ID}, {b.ID, a.ID}} {
+		wg.Add(1)
+		go func(i int, taskID, parentID string) {
+			defer wg.Done()
+			_, errs[i] = taskRepo.Update(ctx, user, taskID, domain.TaskPatch{ParentTaskID: &parentID, MaxDepth: 5})
+		}(i, pair[0], pair[1])
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, err := range errs {
+		switch {
+		case errors.Is(err, domain.ErrParentCycle):
+			failed++
+		case err != nil:
+			t.Fatalf("reparent: %v", err)
+		}
+	}
+	if failed != 1 {
+		t.Fatalf("expected exactly one reparent refused, got %v", errs)
+	}
+}
+
+func TestTaskRepo_CreateNextOccurrence_CopiesTaskOnce(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+	labelRepo := postgres.NewLabelRepo(db)
+	checklistRepo := postgres.NewChecklistRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "r-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Recurring")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	weekly, err := taskRepo.Create(ctx, user, project, domain.TaskInput{
+		Title: "Weekly report", Priority: domain.PriorityHigh, DueDate: &due,
+		Recurrence: &domain.Recurrence{Rule: "FREQ=WEEKLY", Timezone: "UTC", RegenerateFrom: domain.FromScheduled},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if weekly.Recurrence == nil || weekly.Recurrence.Occurrence != 1 || weekly.Recurrence.NextTaskID != nil {
+		t.Fatalf("unexpected recurrence on create: %#v", weekly.Recurrence)
+	}
+	label, err := labelRepo.Create(ctx, user, project, "reports", "#0e8a16")
+	if err != nil {
+		t.Fatalf("create label: %v", err)
+	}
+	if err := labelRepo.Attach(ctx, user, weekly.ID, label.ID); err != nil {
+		t.Fatalf("attach: %v", err)
+	}
+	if _, err := checklistRepo.Create(ctx, user, weekly.ID, "send to team", true); err != nil {
+		t.Fatalf("create checklist item: %v", err)
+	}
+
+	nextDue := due.AddDate(0, 0, 7)
+	next, err := taskRepo.CreateNextOccurrence(ctx, user, weekly.ID, &nextDue, 2)
+	if err != nil {
+		t.Fatalf("next occurrence: %v", err)
+	}
+	if next.Title != weekly.Title || next.Priority != domain.PriorityHigh || next.Completed ||
+		next.DueDate == nil || !next.DueDate.Equal(nextDue) || next.Recurrence == nil || next.Recurrence.Occurrence != 2 {
+		t.Fatalf("unexpected next occurrence: %#v", next)
+	}
+	if len(next.Labels) != 1 || next.Labels[0].ID != label.ID {
+		t.Fatalf("expected labels copied, got %#v", next.Labels)
+	}
+	if next.ChecklistProgress != "0/1" {
+		t.Fatalf("expected checklist copied unticked, got %q", next.ChecklistProgress)
+	}
+
+	if _, err := taskRepo.CreateNextOccurrence(ctx, user, weekly.ID, &nextDue, 2); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows generating twice, got %v", err)
+	}
+	prev, err := taskRepo.Get(ctx, user, weekly.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if prev.Recurrence.NextTaskID == nil || *prev.Recurrence.NextTaskID != next.ID {
+		t.Fatalf("expected previous occurrence linked to %s, got %#v", next.ID, prev.Recurrence)
+	}
+
+	cleared, err := taskRepo.Update(ctx, user, next.ID, domain.TaskPatch{ClearRecurrence: true})
+	if err != nil {
+		t.Fatalf("clear recurrence: %v", err)
+	}
+	if cleared.Recurrence != nil {
+		t.Fatalf("expected recurrence cleared, got %#v", cleared.Recurrence)
+	}
+}
+
+func TestTaskRepo_Move_Rebalance(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "o-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Ordering")
+	other := uuid.NewString()
+	insertProject(t, db, other, user, "Other")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteProject(t, db, other) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	var ids []string
+	for _, title := range []string{"a", "b", "c", "d"} {
+		task, err := repo.Create(ctx, user, project, domain.TaskInput{Title: title, Priority: domain.PriorityMedium})
+		if err != nil {
+			t.Fatalf("create %s: %v", title, err)
+		}
+		ids = append(ids, task.ID)
+	}
+	foreign, err := repo.Create(ctx, user, other, domain.TaskInput{Title: "x", Priority: domain.PriorityMedium})
+	if err != nil {
+		t.Fatalf("create foreign: %v", err)
+	}
+
+	order := func() string {
+		t.Helper()
+		got, _, err := repo.List(ctx, user, domain.TaskFilter{ProjectID: project},
+			[]domain.SortKey{{Field: "position"}}, 100, nil)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		out := make([]string, len(got))
+		for i, task := range got {
+			out[i] = task.Title
+		}
+		return strings.Join(out, "")
+	}
+	if got := order(); got != "abcd" {
+		t.Fatalf("expected creation order abcd, got %s", got)
+	}
+
+	// d between a and b, then a to the end, then c to the front.
+	if _, err := repo.Move(ctx, user, ids[3], &ids[0], &ids[1]); err != nil {
+		t.Fatalf("move d: %v", err)
+	}
+	if _, err := repo.Move(ctx, user, ids[0], &ids[2], nil); err != nil {
+		t.Fatalf("move a: %v", err)
+	}
+	if _, err := repo.Move(ctx, user, ids[2], nil, &ids[3]); err != nil {
+		t.Fatalf("move c: %v", err)
+	}
+	if got := order(); got != "cdba" {
+		t.Fatalf("expected cdba after moves, got %s", got)
+	}
+
+	if _, err := repo.Move(ctx, user, ids[0], &foreign.ID, nil); !errors.Is(err, domain.ErrInvalidNeighbor) {
+		t.Fatalf("expected ErrInvalidNeighbor for a task in another project, got %v", err)
+	}
+	if _, err := repo.Move(ctx, user, ids[0], &ids[1], &ids[3]); !errors.Is(err, domain.ErrInvalidNeighbor) {
+		t.Fatalf("expected ErrInvalidNeighbor for reversed neighbors, got %v", err)
+	}
+	if _, err := repo.Move(ctx, user, ids[0], &ids[2], &ids[1]); !errors.Is(err, domain.ErrInvalidNeighbor) {
+		t.Fatalf("expected ErrInvalidNeighbor for neighbors with d between them, got %v", err)
+	}
+	// Leaving b out, d and a are adjacent.
+	if _, err := repo.Move(ctx, user, ids[1], &ids[3], &ids[0]); err != nil {
+		t.Fatalf("move b between its own neighbors: %v", err)
+	}
+	if _, err := repo.Move(ctx, uuid.NewString(), ids[0], &ids[1], nil); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user, got %v", err)
+	}
+
+	// Force a collision and check the rebalance picks the project up and
+	// keeps the order.
+	if _, err := db.ExecContext(ctx, `UPDATE tasks SET position = 'zz' WHERE id = ANY($1::uuid[])`,
+		[]string{ids[1], ids[0]}); err != nil {
+		t.Fatalf("collide: %v", err)
+	}
+	projects, err := repo.ProjectsToRebalance(ctx, 24, 1000)
+	if err != nil {
+		t.Fatalf("projects to rebalance: %v", err)
+	}
+	found := false
+	for _, p := range projects {
+		found = found || p == project
+	}
+	if !found {
+		t.Fatalf("expected %s among %v", project, projects)
+	}
+	if err := repo.RebalancePositions(ctx, project); err != nil {
+		t.Fatalf("rebalance: %v", err)
+	}
+	var distinct int
+	if err := db.QueryRowContext(ctx, `SELECT count(DISTINCT position) FROM tasks WHERE project_id = $1`, project).Scan(&distinct); err != nil {
+		t.Fatalf("count: %v", err)
+	}
+	if distinct != 4 {
+		t.Fatalf("expected 4 distinct positions after rebalance, got %d", distinct)
+	}
+	if got := order(); got[:2] != "cd" {
+		t.Fatalf("expected cd to stay first, got %s", got)
+	}
+}
+
+func TestTaskRepo_Board_ColumnsCountsCursors(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "b-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Board")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	for i, st := range []domain.TaskStatus{"todo", "todo", "todo", "in_progress", "done"} {
+		task, err := repo.Create(ctx, user, project, domain.TaskInput{
+			Title: string(rune('a' + i)), Priority: domain.PriorityMedium, Status: st,
+		})
+		if err != nil {
+			t.Fatalf("create: %v", err)
+		}
+		if task.Status != st || task.Completed != (st == domain.StatusDone) {
+			t.Fatalf("expected status %s in step with completed, got %s/%v", st, task.Status, task.Completed)
+		}
+	}
+
+	// Completing and reopening through completed alone keeps status in step.
+	reopened, err := repo.Create(ctx, user, project, domain.TaskInput{Title: "f", Priority: domain.PriorityMedium, Status: "in_progress"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	yes, no := true, false
+	if got, err := repo.Update(ctx, user, reopened.ID, domain.TaskPatch{Completed: &yes}); err != nil || got.Status != domain.StatusDone {
+		t.Fatalf("expected done after completing, got %v %v", got.Status, err)
+	}
+	if got, err := repo.Update(ctx, user, reopened.ID, domain.TaskPatch{Completed: &no}); err != nil || got.Status != domain.StatusTodo {
+		t.Fatalf("expected todo after reopening, got %v %v", got.Status, err)
+	}
+	// That leaves todo with a, b, c, f.
+
+	cols, err := repo.Board(ctx, user, project, domain.BoardQuery{
+		Statuses: domain.TaskStatuses,
+		Limit:    2,
+	})
+	if err != nil {
+		t.Fatalf("board: %v", err)
+	}
+	if len(cols) != 3 {
+		t.Fatalf("expected 3 columns, got %d", len(cols))
+	}
+	todo, inProgress, done := cols[0], cols[1], cols[2]
+	if todo.Status != "todo" || todo.Count != 4 || len(todo.Tasks) != 2 || todo.NextCursor == nil {
+		t.Fatalf("unexpected todo column: %+v", todo)
+	}
+	if todo.Tasks[0].Title != "a" || todo.Tasks[1].Title != "b" {
+		t.Fatalf("expected manual order a, b; got %s, %s", todo.Tasks[0].Title, todo.Tasks[1].Title)
+	}
+	if inProgress.Count != 1 || len(inProgress.Tasks) != 1 || inProgress.NextCursor != nil {
+		t.Fatalf("unexpected in_progress column: %+v", inProgress)
+	}
+	if done.Count != 1 || len(done.Tasks) != 1 {
+		t.Fatalf("unexpected done column: %+v", done)
+	}
+
+	// Page only the todo column; its count still covers the whole column.
+	cols, err = repo.Board(ctx, user, project, domain.BoardQuery{
+		Statuses: []domain.TaskStatus{"todo"},
+		Limit:    2,
+		Cursors:  map[domain.TaskStatus]domain.Cursor{"todo": *todo.NextCursor},
+	})
+	if err != nil {
+		t.Fatalf("board page 2: %v", err)
+	}
+	if len(cols) != 1 || cols[0].Count != 4 || len(cols[0].Tasks) != 2 || cols[0].NextCursor != nil ||
+		cols[0].Tasks[0].Title != "c" || cols[0].Tasks[1].Title != "f" {
+		t.Fatalf("unexpected todo page 2: %+v", cols)
+	}
+
+	// An empty page past the end still reports the column.
+	last := cols[0].Tasks[1]
+	cols, err = repo.Board(ctx, user, project, domain.BoardQuery{
+		Statuses: []domain.TaskStatus{"todo"},
+		Limit:    2,
+		Cursors: map[domain.TaskStatus]domain.Cursor{"todo": {
+			ID: last.ID, Sort: domain.BoardSort, Values: []string{"todo", last.Position},
+		}},
+	})
+	if err != nil || len(cols) != 1 || cols[0].Count != 4 || len(cols[0].Tasks) != 0 {
+		t.Fatalf("expected an empty todo column, got %+v, %v", cols, err)
+	}
+
+	if _, err := repo.Board(ctx, uuid.NewString(), project, domain.BoardQuery{Statuses: domain.TaskStatuses, Limit: 2}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user, got %v", err)
+	}
+}
+
+func TestTaskRepo_Estimates_Burndown(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewTaskRepo(db)
+	projects := postgres.NewProjectRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "e-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Burndown")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	hours := domain.EstimateHours
+	p, err := projects.Update(ctx, user, project, domain.ProjectPatch{EstimateUnit: &hours})
+	if err != nil || p.EstimateUnit != domain.EstimateHours || p.Name != "Burndown" {
+		t.Fatalf("expected estimate unit hours with name kept, got %+v %v", p, err)
+	}
+
+	three, five := 3.0, 5.0
+	a, err := repo.Create(ctx, user, project, domain.TaskInput{Title: "a", Priority: domain.PriorityMedium, Estimate: &three})
+	if err != nil || a.Estimate == nil || *a.Estimate != 3 {
+		t.Fatalf("expected estimate 3, got %v %v", a.Estimate, err)
+	}
+	b, err := repo.Create(ctx, user, project, domain.TaskInput{Title: "b", Priority: domain.PriorityMedium})
+	if err != nil || b.Estimate != nil {
+		t.Fatalf("expected no estimate, got %v %v", b.Estimate, err)
+	}
+	if b, err = repo.Update(ctx, user, b.ID, domain.TaskPatch{Estimate: &five}); err != nil || b.Estimate == nil || *b.Estimate != 5 {
+		t.Fatalf("expected estimate 5, got %v %v", b.Estimate, err)
+	}
+	if got, err := repo.Update(ctx, user, b.ID, domain.TaskPatch{ClearEstimate: true}); err != nil || got.Estimate != nil {
+		t.Fatalf("expected estimate cleared, got %v %v", got.Estimate, err)
+	}
+	if _, err := repo.Update(ctx, user, b.ID, domain.TaskPatch{Estimate: &five}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	// Completing a task records an event through the trigger.
+	yes := true
+	if _, err := repo.Update(ctx, user, a.ID, domain.TaskPatch{Completed: &yes}); err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT count(*) FROM task_completion_events WHERE task_id = $1 AND completed`, a.ID).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("expected one completion event, got %d %v", n, err)
+	}
+
+	// Replace the history with a fixed one: a is completed, reopened and
+	// completed again on consecutive days.
+	if _, err := db.ExecContext(ctx, `UPDATE tasks SET created_at = '2024-01-01T10:00:00Z' WHERE project_id = $1`, project); err != nil {
+		t.Fatalf("backdate tasks: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `DELETE FROM task_completion_events WHERE task_id = $1`, a.ID); err != nil {
+		t.Fatalf("clear events: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `
+		INSERT INTO task_completion_events (task_id, completed, at) VALUES
+			($1, TRUE, '2024-01-02T03:00:00Z'),
+			($1, FALSE, '2024-01-03T03:00:00Z'),
+			($1, TRUE, '2024-01-04T03:00:00Z')
+	`, a.ID); err != nil {
+		t.Fatalf("insert events: %v", err)
+	}
+
+	day := func(d int) time.Time { return time.Date(2023, 12, 31+d, 0, 0, 0, 0, time.UTC) }
+	bd, err := repo.Burndown(ctx, user, project, domain.BurndownQuery{From: day(0), To: day(4), Location: time.UTC})
+	if err != nil {
+		t.Fatalf("burndown: %v", err)
+	}
+	if bd.EstimateUnit != domain.EstimateHours || len(bd.Days) != 5 {
+		t.Fatalf("unexpected burndown: %+v", bd)
+	}
+	want := []domain.BurndownDay{
+		{Date: "2023-12-31"},
+		{Date: "2024-01-01", RemainingTasks: 2, RemainingEstimate: 8},
+		{Date: "2024-01-02", RemainingTasks: 1, CompletedTasks: 1, RemainingEstimate: 5, CompletedEstimate: 3},
+		{Date: "2024-01-03", RemainingTasks: 2, RemainingEstimate: 8},
+		{Date: "2024-01-04", RemainingTasks: 1, CompletedTasks: 1, RemainingEstimate: 5, CompletedEstimate: 3},
+	}
+	for i, w := range want {
+		if bd.Days[i] != w {
+			t.Fatalf("day %d: expected %+v, got %+v", i, w, bd.Days[i])
+		}
+	}
+
+	// January 2nd in New York ends at 05:00 UTC, after the reopening.
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	bd, err = repo.Burndown(ctx, user, project, domain.BurndownQuery{From: day(2), To: day(2), Location: ny})
+	if err != nil || len(bd.Days) != 1 || bd.Days[0].CompletedTasks != 0 || bd.Timezone != "America/New_York" {
+		t.Fatalf("expected a reopened by the end of 2024-01-02 in New York, got %+v %v", bd, err)
+	}
+
+	if _, err := repo.Burndown(ctx, uuid.NewString(), project, domain.BurndownQuery{From: day(0), To: day(1), Location: time.UTC}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user, got %v", err)
+	}
+}
+
+func TestTaskRepo_Update_MoveToProject(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewTaskRepo(db)
+	labels := postgres.NewLabelRepo(db)
+	fields := postgres.NewCustomFieldRepo(db)
+	checklists := postgres.NewChecklistRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "mv-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	src := uuid.NewString()
+	dst := uuid.NewString()
+	foreign := uuid.NewString()
+	insertProject(t, db, src, user, "Source")
+	insertProject(t, db, dst, user, "Target")
+	insertProject(t, db, foreign, other, "Foreign")
+	t.Cleanup(func() { deleteProject(t, db, src) })
+	t.Cleanup(func() { deleteProject(t, db, dst) })
+	t.Cleanup(func() { deleteProject(t, db, foreign) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+
+	bug, err := labels.Create(ctx, user, src, "Bug", "#ff0000")
+	if err != nil {
+		t.Fatalf("create label: %v", err)
+	}
+	ui, err := labels.Create(ctx, user, src, "ui", "#00ff00")
+	if err != nil {
+		t.Fatalf("create label: %v", err)
+	}
+	existingUI, err := labels.Create(ctx, user, dst, "UI", "#0000ff")
+	if err != nil {
+		t.Fatalf("create label: %v", err)
+	}
+	size, err := fields.Create(ctx, user, src, domain.CustomField{Name: "Size", Type: domain.FieldSingleSelect, Options: []string{"S", "XL"}})
+	if err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+	dstSize, err := fields.Create(ctx, user, dst, domain.CustomField{Name: "size", Type: domain.FieldSingleSelect, Options: []string{"S"}})
+	if err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+	points, err := fields.Create(ctx, user, src, domain.CustomField{Name: "Points", Type: domain.FieldNumber})
+	if err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+	assignee, err := fields.Create(ctx, user, src, domain.CustomField{Name: "Assignee", Type: domain.FieldUser})
+	if err != nil {
+		t.Fatalf("create field: %v", err)
+	}
+
+	if _, err := repo.Create(ctx, user, dst, domain.TaskInput{Title: "already there"}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	parent, err := repo.Create(ctx, user, src, domain.TaskInput{Title: "parent"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	task, err := repo.Create(ctx, user, src, domain.TaskInput{
+		Title: "misfiled", ParentTaskID: &parent.ID,
+		CustomFields: map[string]json.RawMessage{
+			size.ID: json.RawMessage(`"XL"`), points.ID: json.RawMessage(`5`), assignee.ID: json.RawMessage(`"` + user + `"`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	child, err := repo.Create(ctx, user, src, domain.TaskInput{
+		Title: "child", ParentTaskID: &task.ID,
+		CustomFields: map[string]json.RawMessage{assignee.ID: json.RawMessage(`"` + other + `"`)},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	for _, a := range [][2]string{{task.ID, bug.ID}, {child.ID, ui.ID}} {
+		if err := labels.Attach(ctx, user, a[0], a[1]); err != nil {
+			t.Fatalf("attach: %v", err)
+		}
+	}
+	if _, err := checklists.Create(ctx, user, task.ID, "step", false); err != nil {
+		t.Fatalf("checklist: %v", err)
+	}
+	trashed, err := repo.Create(ctx, user, src, domain.TaskInput{Title: "trashed", ParentTaskID: &task.ID})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if err := repo.Delete(ctx, user, trashed.ID, domain.ChildrenCascade); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	if _, err := repo.Update(ctx, user, task.ID, domain.TaskPatch{ProjectID: &foreign}); !errors.Is(err, domain.ErrInvalidProject) {
+		t.Fatalf("expected ErrInvalidProject for another user's project, got %v", err)
+	}
+	if _, err := repo.Update(ctx, other, task.ID, domain.TaskPatch{ProjectID: &foreign}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows moving another user's task, got %v", err)
+	}
+
+	// A new parent in the same patch must belong to the target project.
+	if _, err := repo.Update(ctx, user, task.ID, domain.TaskPatch{ProjectID: &dst, ParentTaskID: &parent.ID}); !errors.Is(err, domain.ErrInvalidParent) {
+		t.Fatalf("expected ErrInvalidParent for a parent left in the source, got %v", err)
+	}
+
+	moved, err := repo.Update(ctx, user, task.ID, domain.TaskPatch{ProjectID: &dst})
+	if err != nil {
+		t.Fatalf("move: %v", err)
+	}
+	if moved.ID != task.ID || moved.ProjectID != dst || moved.ParentTaskID != nil || moved.ChecklistTotal != 1 || moved.SubtaskCount != 1 {
+		t.Fatalf("unexpected moved task %+v", moved)
+	}
+	if len(moved.Labels) != 1 || moved.Labels[0].Name != "Bug" || moved.Labels[0].ID == bug.ID {
+		t.Fatalf("expected Bug recreated in the target, got %+v", moved.Labels)
+	}
+	if string(moved.CustomFields[dstSize.ID]) != `"XL"` || len(moved.CustomFields) != 3 {
+		t.Fatalf("expected values carried to target fields, got %v", moved.CustomFields)
+	}
+	if f, err := fields.Get(ctx, user, dstSize.ID); err != nil || len(f.Options) != 2 || f.Options[1] != "XL" {
+		t.Fatalf("expected XL added to the target field, got %+v %v", f, err)
+	}
+
+	movedChild, err := repo.Get(ctx, user, child.ID)
+	if err != nil {
+		t.Fatalf("get child: %v", err)
+	}
+	if movedChild.ProjectID != dst || movedChild.ParentTaskID == nil || *movedChild.ParentTaskID != task.ID {
+		t.Fatalf("expected the subtask to follow, got %+v", movedChild)
+	}
+	if len(movedChild.Labels) != 1 || movedChild.Labels[0].ID != existingUI.ID {
+		t.Fatalf("expected the existing UI label reused, got %+v", movedChild.Labels)
+	}
+	if len(movedChild.CustomFields) != 0 {
+		t.Fatalf("expected a user value without access to the target dropped, got %v", movedChild.CustomFields)
+	}
+
+	for _, old := range []domain.Task{task, child} {
+		key, number, _ := domain.ParseTaskKey(old.Key)
+		got, err := repo.GetByKey(ctx, user, key, number)
+		if err != nil || got.ID != old.ID || got.Key == old.Key {
+			t.Fatalf("expected old key %s to resolve to the moved task, got %+v %v", old.Key, got, err)
+		}
+	}
+
+	var (
+		trashedProject string
+		trashedParent  sql.NullString
+	)
+	if err := db.QueryRowContext(ctx, `SELECT project_id, parent_task_id FROM tasks WHERE id = $1`, trashed.ID).
+		Scan(&trashedProject, &trashedParent); err != nil {
+		t.Fatalf("get trashed: %v", err)
+	}
+	if trashedProject != src || trashedParent.Valid {
+		t.Fatalf("expected the trashed subtask left behind as a top-level task, got %s %v", trashedProject, trashedParent)
+	}
+
+	list, _, err := repo.List(ctx, user, domain.TaskFilter{ProjectID: dst, LabelMatch: domain.LabelMatchAny},
+		[]domain.SortKey{{Field: "position"}}, 10, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 3 || list[0].Title != "already there" || list[1].ID != task.ID || list[2].ID != child.ID {
+		t.Fatalf("expected moved tasks appended in order, got %+v", list)
+	}
+	if p, err := repo.Get(ctx, user, parent.ID); err != nil || p.SubtaskCount != 0 {
+		t.Fatalf("expected the old parent to lose its subtask, got %+v %v", p, err)
+	}
+}
+
+func TestTaskRepo_Completion_StampedFilteredAndCleared(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "done-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, user, "Done")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	parent, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "parent"})
+	if err != nil {
+		t.Fatalf("create parent: %v", err)
+	}
+	child, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "child", ParentTaskID: &parent.ID})
+	if err != nil {
+		t.Fatalf("create child: %v", err)
+	}
+	if parent.CompletedAt != nil || parent.CompletedBy != nil {
+		t.Fatalf("expected an open task to have no completion, got %v by %v", parent.CompletedAt, parent.CompletedBy)
+	}
+
+	at := time.Date(2026, 10, 12, 9, 30, 0, 0, time.UTC)
+	got, err := taskRepo.Update(ctx, user, parent.ID, domain.TaskPatch{
+		Completed: ptrBool(true), CompleteSubtasks: true, CompletedAt: &at, CompletedBy: &user,
+	})
+	if err != nil {
+		t.Fatalf("complete: %v", err)
+	}
+	if got.CompletedAt == nil || !got.CompletedAt.Equal(at) || got.CompletedBy == nil || *got.CompletedBy != user {
+		t.Fatalf("expected the completion to be stamped, got %v by %v", got.CompletedAt, got.CompletedBy)
+	}
+	if c, _ := taskRepo.Get(ctx, user, child.ID); c.CompletedAt == nil || !c.CompletedAt.Equal(at) {
+		t.Fatalf("expected the subtask to be stamped too, got %v", c.CompletedAt)
+	}
+
+	// Completing again keeps the original stamp.
+	later := at.Add(time.Hour)
+	if got, err = taskRepo.Update(ctx, user, parent.ID, domain.TaskPatch{Completed: ptrBool(true), CompletedAt: &later, CompletedBy: &user}); err != nil || !got.CompletedAt.Equal(at) {
+		t.Fatalf("expected the first completion to stick, got %v (%v)", got.CompletedAt, err)
+	}
+
+	list := func(after, before time.Time) []domain.Task {
+		t.Helper()
+		items, _, err := taskRepo.List(ctx, user, domain.TaskFilter{ProjectID: project, CompletedAfter: &after, CompletedBefore: &before}, nil, 10, nil)
+		if err != nil {
+			t.Fatalf("list: %v", err)
+		}
+		return items
+	}
+	if items := list(at, at.Add(time.Minute)); len(items) != 2 {
+		t.Fatalf("expected both tasks in the range, got %d", len(items))
+	}
+	if items := list(at.Add(-time.Minute), at); len(items) != 0 {
+		t.Fatalf("expected completedBefore to be exclusive, got %d", len(items))
+	}
+
+	if got, err = taskRepo.Update(ctx, user, parent.ID, domain.TaskPatch{Completed: ptrBool(false)}); err != nil {
+		t.Fatalf("reopen: %v", err)
+	}
+	if got.CompletedAt != nil || got.CompletedBy != nil {
+		t.Fatalf("expected reopening to clear the completion, got %v by %v", got.CompletedAt, got.CompletedBy)
+	}
+
+	done, err := taskRepo.Create(ctx, user, project, domain.TaskInput{Title: "already done", Status: domain.StatusDone})
+	if err != nil {
+		t.Fatalf("create done: %v", err)
+	}
+	if done.CompletedAt == nil || done.CompletedBy == nil || *done.CompletedBy != user {
+		t.Fatalf("expected a task created done to be stamped, got %v by %v", done.CompletedAt, done.CompletedBy)
+	}
+}
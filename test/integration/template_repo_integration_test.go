@@ -0,0 +1,148 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestTemplateRepo_CRUD_Ownership(t *testing.T) {
+	db := openTestDB(t)
+	repo := postgres.NewTemplateRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "tm-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+
+	due := 3
+	in := domain.Template{
+		Name:         "Onboarding",
+		ProjectName:  "Onboarding {{client}}",
+		EstimateUnit: domain.EstimateHours,
+		Labels:       []domain.TemplateLabel{{Name: "legal", Color: "#112233"}},
+		Tasks: []domain.TemplateTask{{
+			Title: "Contract", Priority: domain.PriorityHigh, DueInDays: &due, Labels: []string{"legal"},
+			Subtasks: []domain.TemplateTask{{Title: "Send"}},
+		}},
+	}
+	created, err := repo.Create(ctx, user, in)
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := repo.Create(ctx, user, domain.Template{Name: "onboarding", ProjectName: "x"}); !errors.Is(err, domain.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate for a case-insensitive name clash, got %v", err)
+	}
+	if _, err := repo.Create(ctx, other, in); err != nil {
+		t.Fatalf("expected another user to reuse the name, got %v", err)
+	}
+
+	got, err := repo.Get(ctx, user, created.ID)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.ProjectName != in.ProjectName || got.EstimateUnit != domain.EstimateHours || len(got.Labels) != 1 {
+		t.Fatalf("unexpected template %+v", got)
+	}
+	task := got.Tasks[0]
+	if task.Priority != domain.PriorityHigh || *task.DueInDays != 3 || task.Labels[0] != "legal" || task.Subtasks[0].Title != "Send" {
+		t.Fatalf("expected the task tree to round-trip, got %+v", task)
+	}
+
+	if _, err := repo.Get(ctx, other, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user, got %v", err)
+	}
+	list, err := repo.List(ctx, user)
+	if err != nil || len(list) != 1 {
+		t.Fatalf("expected one template, got %d %v", len(list), err)
+	}
+	if err := repo.Delete(ctx, other, created.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows deleting another user's template, got %v", err)
+	}
+	if err := repo.Delete(ctx, user, created.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
+
+func TestProjectRepo_CreateFromBlueprint(t *testing.T) {
+	db := openTestDB(t)
+	projects := postgres.NewProjectRepo(db)
+	tasks := postgres.NewTaskRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "bp-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+
+	due := time.Date(2026, 11, 5, 9, 0, 0, 0, time.UTC)
+	bp := domain.ProjectBlueprint{
+		Name:         "Onboarding Acme",
+		EstimateUnit: domain.EstimateHours,
+		Labels:       []domain.TemplateLabel{{Name: "Legal", Color: "#112233"}, {Name: "ops", Color: "#445566"}},
+		Tasks: []domain.BlueprintTask{
+			{
+				TaskInput: domain.TaskInput{Title: "Contract", Priority: domain.PriorityHigh, DueDate: &due},
+				Labels:    []string{"legal"},
+				Subtasks:  []domain.BlueprintTask{{TaskInput: domain.TaskInput{Title: "Send"}, Labels: []string{"ops", "Legal"}}},
+			},
+			{TaskInput: domain.TaskInput{Title: "Retro"}},
+		},
+	}
+	p, err := projects.CreateFromBlueprint(ctx, user, bp)
+	if err != nil {
+		t.Fatalf("create from blueprint: %v", err)
+	}
+	t.Cleanup(func() { deleteProject(t, db, p.ID) })
+	if p.Name != "Onboarding Acme" || p.EstimateUnit != domain.EstimateHours {
+		t.Fatalf("unexpected project %+v", p)
+	}
+
+	list, _, err := tasks.List(ctx, user, domain.TaskFilter{ProjectID: p.ID, LabelMatch: domain.LabelMatchAny},
+		[]domain.SortKey{{Field: "position"}}, 10, nil)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(list) != 3 || list[0].Title != "Contract" || list[1].Title != "Send" || list[2].Title != "Retro" {
+		t.Fatalf("expected depth-first order, got %+v", list)
+	}
+	if list[1].ParentTaskID == nil || *list[1].ParentTaskID != list[0].ID || list[0].SubtaskCount != 1 {
+		t.Fatalf("expected Send under Contract, got %+v", list[1])
+	}
+	if list[0].Priority != domain.PriorityHigh || list[0].DueDate == nil || !list[0].DueDate.Equal(due) || list[0].Completed {
+		t.Fatalf("unexpected first task %+v", list[0])
+	}
+	if len(list[0].Labels) != 1 || list[0].Labels[0].Name != "Legal" || len(list[1].Labels) != 2 {
+		t.Fatalf("expected labels attached, got %+v / %+v", list[0].Labels, list[1].Labels)
+	}
+
+	// A failing blueprint leaves nothing behind.
+	bad := domain.ProjectBlueprint{
+		Name:         "Broken",
+		EstimateUnit: domain.EstimatePoints,
+		Labels:       []domain.TemplateLabel{{Name: "dup", Color: "#000000"}, {Name: "DUP", Color: "#000000"}},
+	}
+	if _, err := projects.CreateFromBlueprint(ctx, user, bad); !errors.Is(err, domain.ErrDuplicate) {
+		t.Fatalf("expected ErrDuplicate, got %v", err)
+	}
+	var n int
+	if err := db.QueryRowContext(ctx, `SELECT count(*) FROM projects WHERE user_id = $1`, user).Scan(&n); err != nil || n != 1 {
+		t.Fatalf("expected the failed project rolled back, got %d projects (%v)", n, err)
+	}
+}
@@ -0,0 +1,119 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestTimeEntryRepo_Timers_Entries_Totals(t *testing.T) {
+	db := openTestDB(t)
+	taskRepo := postgres.NewTaskRepo(db)
+	timeRepo := postgres.NewTimeEntryRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	owner := uuid.NewString()
+	stranger := uuid.NewString()
+	insertUser(t, db, owner, "t-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, stranger, "t-"+uuid.NewString()+"@example.com")
+	project := uuid.NewString()
+	insertProject(t, db, project, owner, "Billable")
+	t.Cleanup(func() { deleteProject(t, db, project) })
+	t.Cleanup(func() { deleteUser(t, db, owner) })
+	t.Cleanup(func() { deleteUser(t, db, stranger) })
+
+	design, err := taskRepo.Create(ctx, owner, project, domain.TaskInput{Title: "Design"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+	build, err := taskRepo.Create(ctx, owner, project, domain.TaskInput{Title: "Build"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if _, err := timeRepo.Start(ctx, stranger, design.ID, start, ""); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows timing another user's task, got %v", err)
+	}
+	timer, err := timeRepo.Start(ctx, owner, design.ID, start, "sketching")
+	if err != nil {
+		t.Fatalf("start: %v", err)
+	}
+	if !timer.Running || timer.EndedAt != nil || timer.DurationSeconds < 3599 {
+		t.Fatalf("unexpected running timer: %#v", timer)
+	}
+	if _, err := timeRepo.Start(ctx, owner, build.ID, start, ""); !errors.Is(err, domain.ErrDuplicate) {
+		t.Fatalf("expected domain.ErrDuplicate for a second timer, got %v", err)
+	}
+	if got, err := timeRepo.Running(ctx, owner); err != nil || got.ID != timer.ID {
+		t.Fatalf("expected the running timer, got %#v, %v", got, err)
+	}
+
+	stopped, err := timeRepo.Stop(ctx, owner, start.Add(30*time.Minute))
+	if err != nil {
+		t.Fatalf("stop: %v", err)
+	}
+	if stopped.Running || stopped.DurationSeconds != 1800 {
+		t.Fatalf("unexpected stopped entry: %#v", stopped)
+	}
+	if _, err := timeRepo.Stop(ctx, owner, time.Now()); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows with no timer running, got %v", err)
+	}
+
+	// A manual entry that straddles the start of the totals range.
+	day := time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+	manualEnd := day.Add(2 * time.Hour)
+	manual, err := timeRepo.Create(ctx, owner, build.ID, domain.TimeEntryInput{
+		StartedAt: day.Add(-time.Hour), EndedAt: &manualEnd, Note: "late night",
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if manual.DurationSeconds != 3*3600 {
+		t.Fatalf("expected a 3h entry, got %d", manual.DurationSeconds)
+	}
+
+	page, next, err := timeRepo.List(ctx, owner, build.ID, 10, nil)
+	if err != nil || len(page) != 1 || next != nil {
+		t.Fatalf("unexpected list: %v, %v, %v", page, next, err)
+	}
+
+	totals, err := timeRepo.Totals(ctx, owner, domain.TimeTotalsQuery{
+		GroupBy: domain.TimeByTask, From: day, To: day.AddDate(0, 0, 1),
+	})
+	if err != nil {
+		t.Fatalf("totals: %v", err)
+	}
+	if len(totals) != 1 || totals[0].ID != build.ID || totals[0].Name != "Build" || totals[0].TotalSeconds != 2*3600 {
+		t.Fatalf("expected only the in-range 2h of Build, got %#v", totals)
+	}
+
+	totals, err = timeRepo.Totals(ctx, owner, domain.TimeTotalsQuery{
+		GroupBy: domain.TimeByProject, From: day.AddDate(0, 0, -1), To: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("totals: %v", err)
+	}
+	if len(totals) != 1 || totals[0].ID != project || totals[0].TotalSeconds != 3*3600+1800 {
+		t.Fatalf("expected 3.5h on the project, got %#v", totals)
+	}
+
+	if err := timeRepo.Delete(ctx, stranger, manual.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows deleting another user's entry, got %v", err)
+	}
+	if err := timeRepo.Delete(ctx, owner, manual.ID); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+}
@@ -0,0 +1,177 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestTrashRepo_TaskDeleteListAndRestore(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	trash := postgres.NewTrashRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	insertUser(t, db, user, "trash-"+uuid.NewString()+"@example.com")
+	insertUser(t, db, other, "o-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	t.Cleanup(func() { deleteUser(t, db, other) })
+	proj := uuid.NewString()
+	insertProject(t, db, proj, user, "Trash")
+	t.Cleanup(func() { deleteProject(t, db, proj) })
+
+	root, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "root"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	mid, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "mid", ParentTaskID: &root.ID})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	leaf, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "leaf", ParentTaskID: &mid.ID})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if err := tasks.Delete(ctx, user, mid.ID, domain.ChildrenCascade); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	for _, id := range []string{mid.ID, leaf.ID} {
+		if _, err := tasks.Get(ctx, user, id); !errors.Is(err, sql.ErrNoRows) {
+			t.Fatalf("expected %s to be hidden, got %v", id, err)
+		}
+	}
+	got, err := tasks.Get(ctx, user, root.ID)
+	if err != nil || got.SubtaskCount != 0 {
+		t.Fatalf("expected root without live subtasks, got %d %v", got.SubtaskCount, err)
+	}
+	items, _, err := tasks.List(ctx, user, domain.TaskFilter{ProjectID: proj}, nil, 50, nil)
+	if err != nil || len(items) != 1 {
+		t.Fatalf("expected only root to be listed, got %d %v", len(items), err)
+	}
+
+	// The leaf went with mid, so only mid is listed.
+	bin, err := trash.List(ctx, user)
+	if err != nil {
+		t.Fatalf("list trash: %v", err)
+	}
+	if len(bin.Tasks) != 1 || bin.Tasks[0].ID != mid.ID || bin.Tasks[0].DeletedAt.IsZero() {
+		t.Fatalf("expected mid in the trash, got %+v", bin.Tasks)
+	}
+
+	if _, err := trash.RestoreTask(ctx, other, mid.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for another user, got %v", err)
+	}
+	restored, err := trash.RestoreTask(ctx, user, mid.ID)
+	if err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if restored.ParentTaskID == nil || *restored.ParentTaskID != root.ID || restored.SubtaskCount != 1 {
+		t.Fatalf("expected mid back under root with its leaf, got %+v", restored)
+	}
+	if _, err := trash.RestoreTask(ctx, user, mid.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for a live task, got %v", err)
+	}
+
+	// A task whose parent stays in the trash comes back at the top level.
+	if err := tasks.Delete(ctx, user, leaf.ID, domain.ChildrenCascade); err != nil {
+		t.Fatalf("delete leaf: %v", err)
+	}
+	if err := tasks.Delete(ctx, user, mid.ID, domain.ChildrenCascade); err != nil {
+		t.Fatalf("delete mid: %v", err)
+	}
+	bin, err = trash.List(ctx, user)
+	if err != nil || len(bin.Tasks) != 2 {
+		t.Fatalf("expected leaf and mid in the trash, got %+v %v", bin.Tasks, err)
+	}
+	restored, err = trash.RestoreTask(ctx, user, leaf.ID)
+	if err != nil {
+		t.Fatalf("restore leaf: %v", err)
+	}
+	if restored.ParentTaskID != nil {
+		t.Fatalf("expected leaf to become top-level, got parent %v", *restored.ParentTaskID)
+	}
+}
+
+func TestTrashRepo_ProjectRestoreAndPurge(t *testing.T) {
+	db := openTestDB(t)
+	projects := postgres.NewProjectRepo(db)
+	tasks := postgres.NewTaskRepo(db)
+	trash := postgres.NewTrashRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	insertUser(t, db, user, "trash-"+uuid.NewString()+"@example.com")
+	t.Cleanup(func() { deleteUser(t, db, user) })
+	proj := uuid.NewString()
+	insertProject(t, db, proj, user, "Old")
+	t.Cleanup(func() { deleteProject(t, db, proj) })
+
+	task, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "inside"})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	if err := projects.Delete(ctx, user, proj); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if err := projects.Delete(ctx, user, proj); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows deleting twice, got %v", err)
+	}
+	if _, err := tasks.Get(ctx, user, task.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected the project's task to be hidden, got %v", err)
+	}
+	if _, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "late"}); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows creating in a deleted project, got %v", err)
+	}
+	bin, err := trash.List(ctx, user)
+	if err != nil || len(bin.Projects) != 1 || bin.Projects[0].ID != proj {
+		t.Fatalf("expected the project in the trash, got %+v %v", bin.Projects, err)
+	}
+
+	if _, err := trash.RestoreProject(ctx, user, proj); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	if _, err := tasks.Get(ctx, user, task.ID); err != nil {
+		t.Fatalf("expected the task back with its project, got %v", err)
+	}
+
+	// Nothing is purged inside the retention window.
+	if err := projects.Delete(ctx, user, proj); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := trash.Purge(ctx, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if _, err := trash.RestoreProject(ctx, user, proj); err != nil {
+		t.Fatalf("expected a recent deletion to survive the purge, got %v", err)
+	}
+
+	if err := projects.Delete(ctx, user, proj); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	n, err := trash.Purge(ctx, time.Now().Add(time.Hour))
+	if err != nil || n < 1 {
+		t.Fatalf("expected the project to be purged, got %d %v", n, err)
+	}
+	var exists bool
+	if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1)`, task.ID).Scan(&exists); err != nil || exists {
+		t.Fatalf("expected the task row to be gone, got %v %v", exists, err)
+	}
+}
@@ -0,0 +1,108 @@
+//go:build integration
+// +build integration
+
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	"TaskFlow/internal/repo/postgres"
+
+	"github.com/google/uuid"
+)
+
+func TestWatcherRepo_WatchSubscribeAndRecipients(t *testing.T) {
+	db := openTestDB(t)
+	tasks := postgres.NewTaskRepo(db)
+	watchers := postgres.NewWatcherRepo(db)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user := uuid.NewString()
+	other := uuid.NewString()
+	for _, id := range []string{user, other} {
+		id := id
+		insertUser(t, db, id, "watch-"+uuid.NewString()+"@example.com")
+		t.Cleanup(func() { deleteUser(t, db, id) })
+	}
+	proj := uuid.NewString()
+	insertProject(t, db, proj, user, "Watched")
+	t.Cleanup(func() { deleteProject(t, db, proj) })
+
+	task, err := tasks.Create(ctx, user, proj, domain.TaskInput{Title: "watch me"})
+	if err != nil {
+		t.Fatalf("create task: %v", err)
+	}
+
+	if err := watchers.Subscribe(ctx, task.ID, []string{user}, domain.WatchCreator); err != nil {
+		t.Fatalf("subscribe creator: %v", err)
+	}
+	// Unknown users and users who do not own the task are skipped, and
+	// existing watchers keep their reason.
+	if err := watchers.Subscribe(ctx, task.ID, []string{uuid.NewString(), other, user}, domain.WatchAssignee); err != nil {
+		t.Fatalf("subscribe assignees: %v", err)
+	}
+	if err := watchers.WatchTask(ctx, user, task.ID); err != nil {
+		t.Fatalf("watch task: %v", err)
+	}
+	if err := watchers.WatchTask(ctx, other, task.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner watch, got %v", err)
+	}
+	if err := watchers.WatchProject(ctx, other, proj); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner project watch, got %v", err)
+	}
+	if _, err := watchers.TaskWatchers(ctx, other, task.ID); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for non-owner watcher list, got %v", err)
+	}
+
+	list, err := watchers.TaskWatchers(ctx, user, task.ID)
+	if err != nil {
+		t.Fatalf("task watchers: %v", err)
+	}
+	if len(list) != 1 || list[0].UserID != user || list[0].Reason != domain.WatchCreator {
+		t.Fatalf("unexpected watchers: %+v", list)
+	}
+
+	// Project watchers hear about every task of the project; a stray
+	// subscription of someone who does not own it is never a recipient.
+	if err := watchers.WatchProject(ctx, user, proj); err != nil {
+		t.Fatalf("watch project: %v", err)
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO project_watchers (project_id, user_id) VALUES ($1, $2)`, proj, other); err != nil {
+		t.Fatalf("insert project watcher: %v", err)
+	}
+	got, err := watchers.Recipients(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("recipients: %v", err)
+	}
+	if !reflect.DeepEqual(got, []string{user}) {
+		t.Fatalf("recipients = %v, want [%s]", got, user)
+	}
+
+	if err := watchers.UnwatchTask(ctx, user, task.ID); err != nil {
+		t.Fatalf("unwatch task: %v", err)
+	}
+	if err := watchers.UnwatchTask(ctx, user, task.ID); err != nil {
+		t.Fatalf("unwatch task again: %v", err)
+	}
+	if err := watchers.UnwatchTask(ctx, user, uuid.NewString()); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("expected sql.ErrNoRows for missing task, got %v", err)
+	}
+	if err := watchers.UnwatchProject(ctx, user, proj); err != nil {
+		t.Fatalf("unwatch project: %v", err)
+	}
+	got, err = watchers.Recipients(ctx, task.ID)
+	if err != nil {
+		t.Fatalf("recipients: %v", err)
+	}
+	if len(got) != 0 {
+		t.Fatalf("expected no recipients after unwatching, got %v", got)
+	}
+}
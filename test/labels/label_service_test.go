@@ -0,0 +1,143 @@
+package labels
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeLabelRepo struct {
+	createFn func(ctx context.Context, userID, projectID, name, color string) (domain.Label, error)
+	updateFn func(ctx context.Context, userID, labelID string, name, color *string) (domain.Label, error)
+	attachFn func(ctx context.Context, userID, taskID, labelID string) error
+	archived bool
+}
+
+func (f *fakeLabelRepo) Create(ctx context.Context, userID, projectID, name, color string) (domain.Label, error) {
+	if f.createFn != nil {
+		return f.createFn(ctx, userID, projectID, name, color)
+	}
+	return domain.Label{}, nil
+}
+
+func (f *fakeLabelRepo) List(ctx context.Context, userID, projectID string) ([]domain.Label, error) {
+	return nil, nil
+}
+
+func (f *fakeLabelRepo) Update(ctx context.Context, userID, labelID string, name, color *string) (domain.Label, error) {
+	if f.updateFn != nil {
+		return f.updateFn(ctx, userID, labelID, name, color)
+	}
+	return domain.Label{}, nil
+}
+
+func (f *fakeLabelRepo) Delete(ctx context.Context, userID, labelID string) error { return nil }
+
+func (f *fakeLabelRepo) Attach(ctx context.Context, userID, taskID, labelID string) error {
+	if f.attachFn != nil {
+		return f.attachFn(ctx, userID, taskID, labelID)
+	}
+	return nil
+}
+
+func (f *fakeLabelRepo) Detach(ctx context.Context, userID, taskID, labelID string) error { return nil }
+
+func (f *fakeLabelRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return f.archived, nil
+}
+
+func (f *fakeLabelRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	return f.archived, nil
+}
+
+func (f *fakeLabelRepo) LabelArchived(ctx context.Context, userID, labelID string) (bool, error) {
+	return f.archived, nil
+}
+
+func TestLabelService_RejectsWritesToArchivedProjects(t *testing.T) {
+	svc := _service.NewLabelService(&fakeLabelRepo{archived: true})
+	ctx := context.Background()
+	name := "bug"
+
+	checks := map[string]error{}
+	_, checks["create"] = svc.Create(ctx, "user-1", "proj-1", name, "#ff0000")
+	_, checks["update"] = svc.Update(ctx, "user-1", "label-1", &name, nil)
+	checks["delete"] = svc.Delete(ctx, "user-1", "label-1")
+	checks["attach"] = svc.Attach(ctx, "user-1", "task-1", "label-1")
+	checks["detach"] = svc.Detach(ctx, "user-1", "task-1", "label-1")
+	for op, err := range checks {
+		if err != _service.ErrProjectArchived {
+			t.Fatalf("%s: expected ErrProjectArchived, got %v", op, err)
+		}
+	}
+}
+
+func TestLabelService_Create_ValidatesNameAndColor(t *testing.T) {
+	svc := _service.NewLabelService(&fakeLabelRepo{})
+
+	cases := []struct {
+		name, color, field string
+	}{
+		{"   ", "#ff0000", "name"},
+		{"a,b", "#ff0000", "name"},
+		{"bug", "red", "color"},
+		{"bug", "#ff00", "color"},
+	}
+	for _, c := range cases {
+		_, err := svc.Create(context.Background(), "user-1", "proj-1", c.name, c.color)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%q/%q: expected %s validation error, got %v", c.name, c.color, c.field, err)
+		}
+	}
+}
+
+func TestLabelService_Create_NormalizesInput(t *testing.T) {
+	var gotName, gotColor string
+	repo := &fakeLabelRepo{
+		createFn: func(ctx context.Context, userID, projectID, name, color string) (domain.Label, error) {
+			gotName, gotColor = name, color
+			return domain.Label{}, nil
+		},
+	}
+	svc := _service.NewLabelService(repo)
+
+	if _, err := svc.Create(context.Background(), "user-1", "proj-1", "  Bug ", "#FF00AA"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if gotName != "Bug" || gotColor != "#ff00aa" {
+		t.Fatalf("expected trimmed name and lowercase color, got %q %q", gotName, gotColor)
+	}
+}
+
+func TestLabelService_Create_MapsDuplicate_ToErrConflict(t *testing.T) {
+	repo := &fakeLabelRepo{
+		createFn: func(ctx context.Context, userID, projectID, name, color string) (domain.Label, error) {
+			return domain.Label{}, domain.ErrDuplicate
+		},
+	}
+	svc := _service.NewLabelService(repo)
+
+	_, err := svc.Create(context.Background(), "user-1", "proj-1", "bug", "#ff0000")
+	if !errors.Is(err, _service.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
+
+func TestLabelService_Attach_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
+	repo := &fakeLabelRepo{
+		attachFn: func(ctx context.Context, userID, taskID, labelID string) error {
+			return sql.ErrNoRows
+		},
+	}
+	svc := _service.NewLabelService(repo)
+
+	err := svc.Attach(context.Background(), "user-1", "task-1", "label-1")
+	if !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,116 @@
+package mail
+
+import (
+	"context"
+	"io"
+	"mime"
+	"mime/multipart"
+	netmail "net/mail"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"TaskFlow/internal/mail"
+)
+
+func TestFileTransport_WritesMultipartMessage(t *testing.T) {
+	dir := t.TempDir()
+	tr, err := mail.NewFileTransport(dir, "TaskFlow <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+
+	err = tr.Send(context.Background(), mail.Message{
+		To:      "ana@example.com",
+		Subject: "Grüße",
+		Text:    "plain body",
+		HTML:    "<p>html body</p>",
+		Headers: map[string]string{"list-unsubscribe": "<https://example.com/u>"},
+	})
+	if err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(dir, "*"))
+	if len(files) != 1 || filepath.Ext(files[0]) != ".eml" {
+		t.Fatalf("expected one .eml file, got %v", files)
+	}
+	f, err := os.Open(files[0])
+	if err != nil {
+		t.Fatalf("open: %v", err)
+	}
+	defer func() { _ = f.Close() }()
+	m, err := netmail.ReadMessage(f)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil || subject != "Grüße" {
+		t.Fatalf("unexpected subject %q (%v)", subject, err)
+	}
+	if m.Header.Get("From") != "TaskFlow <no-reply@example.com>" || m.Header.Get("To") != "ana@example.com" {
+		t.Fatalf("unexpected addresses: %v", m.Header)
+	}
+	if m.Header.Get("List-Unsubscribe") != "<https://example.com/u>" {
+		t.Fatalf("expected extra header, got %v", m.Header)
+	}
+	if !strings.HasSuffix(m.Header.Get("Message-Id"), "@example.com>") {
+		t.Fatalf("unexpected Message-Id %q", m.Header.Get("Message-Id"))
+	}
+
+	typ, params, _ := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if typ != "multipart/alternative" {
+		t.Fatalf("unexpected content type %q", typ)
+	}
+	r := multipart.NewReader(m.Body, params["boundary"])
+	var parts []string
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("part: %v", err)
+		}
+		b, _ := io.ReadAll(p)
+		parts = append(parts, string(b))
+	}
+	if len(parts) != 2 || parts[0] != "plain body" || parts[1] != "<p>html body</p>" {
+		t.Fatalf("unexpected parts %q", parts)
+	}
+}
+
+func TestFileTransport_RejectsHeaderInjection(t *testing.T) {
+	dir := t.TempDir()
+	tr, err := mail.NewFileTransport(dir, "no-reply@example.com")
+	if err != nil {
+		t.Fatalf("new: %v", err)
+	}
+	err = tr.Send(context.Background(), mail.Message{To: "ana@example.com\r\nBcc: eve@example.com", Text: "hi"})
+	if err == nil {
+		t.Fatalf("expected an error for a recipient with a line break")
+	}
+	if files, _ := filepath.Glob(filepath.Join(dir, "*")); len(files) != 0 {
+		t.Fatalf("expected nothing written, got %v", files)
+	}
+}
+
+func TestNewSMTPTransport_ValidatesConfig(t *testing.T) {
+	if _, err := mail.NewSMTPTransport(mail.SMTPConfig{Addr: "smtp.example.com", From: "a@example.com"}); err == nil {
+		t.Fatalf("expected an error for an address without a port")
+	}
+	if _, err := mail.NewSMTPTransport(mail.SMTPConfig{Addr: "smtp.example.com:587", From: "not an address"}); err == nil {
+		t.Fatalf("expected an error for an invalid sender")
+	}
+	if _, err := mail.NewSMTPTransport(mail.SMTPConfig{Addr: "smtp.example.com:587", From: "TaskFlow <a@example.com>"}); err != nil {
+		t.Fatalf("expected a valid config, got %v", err)
+	}
+}
+
+func TestRender_UnknownTemplate(t *testing.T) {
+	if _, _, err := mail.Render("missing", nil); err == nil {
+		t.Fatalf("expected an error for an unknown template")
+	}
+}
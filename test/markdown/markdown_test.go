@@ -0,0 +1,40 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+
+	"TaskFlow/internal/markdown"
+)
+
+func TestToHTML_RendersMarkdown(t *testing.T) {
+	out, err := markdown.ToHTML("# Title\n\nSome **bold** text and a [link](https://example.com).")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	for _, want := range []string{"<h1>Title</h1>", "<strong>bold</strong>", `href="https://example.com"`} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected %q in output, got %q", want, out)
+		}
+	}
+}
+
+func TestToHTML_StripsUnsafeContent(t *testing.T) {
+	src := "<script>alert(1)</script>\n\n[click](javascript:alert(1))\n\n<img src=x onerror=alert(1)>"
+	out, err := markdown.ToHTML(src)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	for _, bad := range []string{"<script", "javascript:", "onerror"} {
+		if strings.Contains(out, bad) {
+			t.Fatalf("expected %q to be stripped, got %q", bad, out)
+		}
+	}
+}
+
+func TestToHTML_EmptyInput(t *testing.T) {
+	out, err := markdown.ToHTML("")
+	if err != nil || out != "" {
+		t.Fatalf("expected empty output, got %q, %v", out, err)
+	}
+}
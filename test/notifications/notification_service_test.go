@@ -0,0 +1,142 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeNotificationRepo struct {
+	created   []domain.TaskEvent
+	dueFrom   time.Time
+	dueTo     time.Time
+	listLimit int
+	read      map[string]bool
+}
+
+func (f *fakeNotificationRepo) Create(ctx context.Context, ev domain.TaskEvent) (int, error) {
+	f.created = append(f.created, ev)
+	return len(ev.Recipients), nil
+}
+
+func (f *fakeNotificationRepo) CreateDueSoon(ctx context.Context, from, to time.Time) (int, error) {
+	f.dueFrom, f.dueTo = from, to
+	return 2, nil
+}
+
+func (f *fakeNotificationRepo) List(ctx context.Context, userID string, unreadOnly bool, limit int, cursor *domain.Cursor) ([]domain.Notification, *domain.Cursor, error) {
+	f.listLimit = limit
+	return []domain.Notification{}, nil, nil
+}
+
+func (f *fakeNotificationRepo) UnreadCount(ctx context.Context, userID string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeNotificationRepo) MarkRead(ctx context.Context, userID, notificationID string) error {
+	if _, ok := f.read[notificationID]; !ok {
+		return sql.ErrNoRows
+	}
+	f.read[notificationID] = true
+	return nil
+}
+
+func (f *fakeNotificationRepo) MarkAllRead(ctx context.Context, userID string) (int, error) {
+	return 0, nil
+}
+
+func (f *fakeNotificationRepo) Prefs(ctx context.Context, userID string) (domain.NotificationPrefs, error) {
+	return domain.NotificationPrefs{}, nil
+}
+
+func (f *fakeNotificationRepo) UpdatePrefs(ctx context.Context, userID string, patch domain.NotificationPrefsPatch) (domain.NotificationPrefs, error) {
+	return domain.NotificationPrefs{}, nil
+}
+
+func TestNotificationService_Notify_SkipsEventsWithoutRecipients(t *testing.T) {
+	repo := &fakeNotificationRepo{}
+	svc := _service.NewNotificationService(repo)
+	ctx := context.Background()
+
+	if err := svc.Notify(ctx, domain.TaskEvent{Type: domain.NotifyCompleted, TaskID: "task-1"}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(repo.created) != 0 {
+		t.Fatalf("expected nothing stored, got %+v", repo.created)
+	}
+
+	ev := domain.TaskEvent{Type: domain.NotifyCompleted, TaskID: "task-1", ActorID: "user-1", Recipients: []string{"user-2"}}
+	if err := svc.Notify(ctx, ev); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(repo.created) != 1 || repo.created[0].Recipients[0] != "user-2" {
+		t.Fatalf("expected the event to be stored, got %+v", repo.created)
+	}
+}
+
+func TestNotificationService_NotifyDueSoon_UsesWindow(t *testing.T) {
+	now := time.Date(2026, 5, 4, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	ctx := context.Background()
+
+	repo := &fakeNotificationRepo{}
+	svc := _service.NewNotificationService(repo, _service.WithNotificationClock(clock))
+	if _, err := svc.NotifyDueSoon(ctx); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if !repo.dueFrom.Equal(now) || !repo.dueTo.Equal(now.Add(_service.DefaultDueSoonWindow)) {
+		t.Fatalf("unexpected window %v - %v", repo.dueFrom, repo.dueTo)
+	}
+
+	repo = &fakeNotificationRepo{}
+	svc = _service.NewNotificationService(repo,
+		_service.WithNotificationClock(clock),
+		_service.WithDueSoonWindow(2*time.Hour),
+	)
+	if _, err := svc.NotifyDueSoon(ctx); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if !repo.dueTo.Equal(now.Add(2 * time.Hour)) {
+		t.Fatalf("expected a two hour window, got %v", repo.dueTo)
+	}
+}
+
+func TestNotificationService_List_ClampsLimitAndRejectsSortedCursor(t *testing.T) {
+	repo := &fakeNotificationRepo{}
+	svc := _service.NewNotificationService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.List(ctx, "user-1", false, 500, nil); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if repo.listLimit != 100 {
+		t.Fatalf("expected limit clamped to 100, got %d", repo.listLimit)
+	}
+	if _, err := svc.List(ctx, "user-1", true, 0, nil); err != nil || repo.listLimit != 20 {
+		t.Fatalf("expected default limit 20, got %d (%v)", repo.listLimit, err)
+	}
+
+	_, err := svc.List(ctx, "user-1", false, 20, &domain.Cursor{ID: "x", Sort: "priority", Values: []string{"1"}})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "cursor" {
+		t.Fatalf("expected cursor validation error, got %v", err)
+	}
+}
+
+func TestNotificationService_MarkRead_MapsNotFound(t *testing.T) {
+	repo := &fakeNotificationRepo{read: map[string]bool{"n-1": false}}
+	svc := _service.NewNotificationService(repo)
+	ctx := context.Background()
+
+	if err := svc.MarkRead(ctx, "user-1", "n-1"); err != nil || !repo.read["n-1"] {
+		t.Fatalf("expected n-1 marked read, got %v", err)
+	}
+	if err := svc.MarkRead(ctx, "user-1", "missing"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
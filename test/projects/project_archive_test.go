@@ -0,0 +1,82 @@
+package projects
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func TestProjectService_Archive_PassesFlag(t *testing.T) {
+	var got []bool
+	repo := &fakeProjectRepo{
+		archiveFn: func(ctx context.Context, userID, projectID string, archived bool) (domain.Project, error) {
+			got = append(got, archived)
+			return domain.Project{ID: projectID}, nil
+		},
+	}
+	svc := _service.NewProjectService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Archive(ctx, "user-1", "proj-1"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if _, err := svc.Unarchive(ctx, "user-1", "proj-1"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(got) != 2 || !got[0] || got[1] {
+		t.Fatalf("expected archive then unarchive, got %v", got)
+	}
+}
+
+func TestProjectService_Archive_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
+	repo := &fakeProjectRepo{
+		archiveFn: func(ctx context.Context, userID, projectID string, archived bool) (domain.Project, error) {
+			return domain.Project{}, sql.ErrNoRows
+		},
+	}
+	svc := _service.NewProjectService(repo)
+
+	if _, err := svc.Archive(context.Background(), "user-1", "proj-1"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.Unarchive(context.Background(), "user-1", "proj-1"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestProjectService_List_PassesArchived(t *testing.T) {
+	repo := &fakeProjectRepo{}
+	svc := _service.NewProjectService(repo)
+
+	if _, err := svc.List(context.Background(), "user-1", true, 20, nil); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if !repo.lastListArchived {
+		t.Fatal("expected archived=true to reach the repo")
+	}
+}
+
+func TestProjectService_Update_RejectsArchivedProject(t *testing.T) {
+	archivedAt := time.Now()
+	repo := &fakeProjectRepo{
+		getFn: func(ctx context.Context, userID, projectID string) (domain.Project, error) {
+			return domain.Project{ID: projectID, ArchivedAt: &archivedAt}, nil
+		},
+		updateFn: func(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
+			t.Fatal("repo should not be called")
+			return domain.Project{}, nil
+		},
+	}
+	svc := _service.NewProjectService(repo)
+
+	name := "Website"
+	_, err := svc.Update(context.Background(), "user-1", "proj-1", domain.ProjectPatch{Name: &name})
+	if !errors.Is(err, _service.ErrProjectArchived) {
+		t.Fatalf("expected ErrProjectArchived, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package projects
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeDuplicationRepo struct {
+	created []domain.DuplicateOptions
+	pending int
+	runErr  error
+	ran     int
+}
+
+func (f *fakeDuplicationRepo) Create(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Duplication, error) {
+	f.created = append(f.created, opts)
+	return domain.Duplication{ID: "dup-1", SourceProjectID: &projectID, Options: opts, Status: domain.DuplicationPending}, nil
+}
+
+func (f *fakeDuplicationRepo) Get(ctx context.Context, userID, id string) (domain.Duplication, error) {
+	if id != "dup-1" {
+		return domain.Duplication{}, sql.ErrNoRows
+	}
+	return domain.Duplication{ID: id, Status: domain.DuplicationDone}, nil
+}
+
+func (f *fakeDuplicationRepo) RunNext(ctx context.Context) (domain.Duplication, bool, error) {
+	if f.pending == 0 {
+		return domain.Duplication{}, false, nil
+	}
+	f.pending--
+	f.ran++
+	if f.ran == 2 && f.runErr != nil {
+		return domain.Duplication{Status: domain.DuplicationFailed}, true, f.runErr
+	}
+	return domain.Duplication{Status: domain.DuplicationDone}, true, nil
+}
+
+func TestProjectService_Duplicate_SmallProjectCopiedNow(t *testing.T) {
+	var got domain.DuplicateOptions
+	repo := &fakeProjectRepo{
+		taskCountFn: func(ctx context.Context, userID, projectID string) (int, error) { return 10, nil },
+		duplicateFn: func(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error) {
+			got = opts
+			return domain.Project{ID: "copy"}, nil
+		},
+	}
+	dups := &fakeDuplicationRepo{}
+	svc := _service.NewProjectService(repo, _service.WithDuplications(dups, 10))
+
+	res, err := svc.Duplicate(context.Background(), "user-1", "proj-1", domain.DuplicateOptions{Name: "  Q3  ", ShiftDays: 7})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if res.Project == nil || res.Project.ID != "copy" || res.Duplication != nil {
+		t.Fatalf("expected the copy, got %+v", res)
+	}
+	if got.Name != "Q3" || got.ShiftDays != 7 {
+		t.Fatalf("expected trimmed options, got %+v", got)
+	}
+	if len(dups.created) != 0 {
+		t.Fatalf("expected nothing queued, got %v", dups.created)
+	}
+}
+
+func TestProjectService_Duplicate_LargeProjectQueued(t *testing.T) {
+	repo := &fakeProjectRepo{
+		taskCountFn: func(ctx context.Context, userID, projectID string) (int, error) { return 11, nil },
+		duplicateFn: func(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error) {
+			t.Fatal("expected no copy during the request")
+			return domain.Project{}, nil
+		},
+	}
+	dups := &fakeDuplicationRepo{}
+	svc := _service.NewProjectService(repo, _service.WithDuplications(dups, 10))
+
+	res, err := svc.Duplicate(context.Background(), "user-1", "proj-1", domain.DuplicateOptions{ResetCompletion: true})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if res.Duplication == nil || res.Duplication.Status != domain.DuplicationPending || res.Project != nil {
+		t.Fatalf("expected a queued copy, got %+v", res)
+	}
+	if len(dups.created) != 1 || !dups.created[0].ResetCompletion {
+		t.Fatalf("expected options queued, got %v", dups.created)
+	}
+}
+
+func TestProjectService_Duplicate_ValidatesAndMapsNotFound(t *testing.T) {
+	repo := &fakeProjectRepo{
+		taskCountFn: func(ctx context.Context, userID, projectID string) (int, error) { return 0, sql.ErrNoRows },
+		duplicateFn: func(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error) {
+			return domain.Project{}, sql.ErrNoRows
+		},
+	}
+	ctx := context.Background()
+
+	_, err := _service.NewProjectService(repo).Duplicate(ctx, "user-1", "proj-1", domain.DuplicateOptions{ShiftDays: 4000})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "shiftDays" {
+		t.Fatalf("expected shiftDays validation error, got %v", err)
+	}
+
+	// Without background copies every project is copied during the request.
+	if _, err := _service.NewProjectService(repo).Duplicate(ctx, "user-1", "proj-1", domain.DuplicateOptions{}); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	svc := _service.NewProjectService(repo, _service.WithDuplications(&fakeDuplicationRepo{}, 10))
+	if _, err := svc.Duplicate(ctx, "user-1", "proj-1", domain.DuplicateOptions{}); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.Duplication(ctx, "user-1", "dup-2"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for an unknown duplication, got %v", err)
+	}
+}
+
+func TestProjectService_RunDuplications_DrainsQueueAndStopsOnError(t *testing.T) {
+	dups := &fakeDuplicationRepo{pending: 3}
+	svc := _service.NewProjectService(&fakeProjectRepo{}, _service.WithDuplications(dups, 10))
+	n, err := svc.RunDuplications(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 copies, got %d %v", n, err)
+	}
+
+	dups = &fakeDuplicationRepo{pending: 3, runErr: errors.New("boom")}
+	svc = _service.NewProjectService(&fakeProjectRepo{}, _service.WithDuplications(dups, 10))
+	n, err = svc.RunDuplications(context.Background())
+	if err == nil || n != 2 || dups.pending != 1 {
+		t.Fatalf("expected a stop after the failed copy, got %d %v (pending %d)", n, err, dups.pending)
+	}
+}
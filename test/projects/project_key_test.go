@@ -0,0 +1,73 @@
+package projects
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func TestProjectService_Create_NormalizesKey(t *testing.T) {
+	var got []string
+	repo := &fakeProjectRepo{
+		createFn: func(ctx context.Context, userID, name, key string) (domain.Project, error) {
+			got = append(got, key)
+			return domain.Project{Name: name, Key: key}, nil
+		},
+	}
+	svc := _service.NewProjectService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Create(ctx, "user-1", "Website", " web2 "); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if _, err := svc.Create(ctx, "user-1", "Website", ""); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(got) != 2 || got[0] != "WEB2" || got[1] != "" {
+		t.Fatalf("expected WEB2 then a derived key, got %q", got)
+	}
+}
+
+func TestProjectService_Create_RejectsInvalidKey(t *testing.T) {
+	repo := &fakeProjectRepo{
+		createFn: func(ctx context.Context, userID, name, key string) (domain.Project, error) {
+			t.Fatal("repo should not be called")
+			return domain.Project{}, nil
+		},
+	}
+	svc := _service.NewProjectService(repo)
+
+	for _, key := range []string{"W", "2WEB", "WEB-1", "ABCDEFGHIJK"} {
+		_, err := svc.Create(context.Background(), "user-1", "Website", key)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "key" {
+			t.Fatalf("key %q: expected a key validation error, got %v", key, err)
+		}
+	}
+}
+
+func TestProjectService_KeyConflict_MapsToErrConflict(t *testing.T) {
+	repo := &fakeProjectRepo{
+		createFn: func(ctx context.Context, userID, name, key string) (domain.Project, error) {
+			return domain.Project{}, domain.ErrDuplicate
+		},
+		updateFn: func(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
+			if patch.Key == nil || *patch.Key != "WEB" {
+				t.Fatalf("expected the upper-cased key, got %v", patch.Key)
+			}
+			return domain.Project{}, domain.ErrDuplicate
+		},
+	}
+	svc := _service.NewProjectService(repo)
+
+	if _, err := svc.Create(context.Background(), "user-1", "Website", "WEB"); !errors.Is(err, _service.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+	key := "web"
+	if _, err := svc.Update(context.Background(), "user-1", "proj-1", domain.ProjectPatch{Key: &key}); !errors.Is(err, _service.ErrConflict) {
+		t.Fatalf("expected ErrConflict, got %v", err)
+	}
+}
@@ -11,26 +11,32 @@ import (
 )
 
 type fakeProjectRepo struct {
-	createFn     func(ctx context.Context, userID, name string) (domain.Project, error)
-	listFn       func(ctx context.Context, userID string, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error)
-	getFn        func(ctx context.Context, userID, projectID string) (domain.Project, error)
-	updateNameFn func(ctx context.Context, userID, projectID, name string) (domain.Project, error)
-	deleteFn     func(ctx context.Context, userID, projectID string) error
-
-	lastListLimit  int
-	lastListCursor *domain.Cursor
+	createFn    func(ctx context.Context, userID, name, key string) (domain.Project, error)
+	listFn      func(ctx context.Context, userID string, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error)
+	getFn       func(ctx context.Context, userID, projectID string) (domain.Project, error)
+	updateFn    func(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error)
+	deleteFn    func(ctx context.Context, userID, projectID string) error
+	blueprintFn func(ctx context.Context, userID string, bp domain.ProjectBlueprint) (domain.Project, error)
+	taskCountFn func(ctx context.Context, userID, projectID string) (int, error)
+	duplicateFn func(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error)
+	archiveFn   func(ctx context.Context, userID, projectID string, archived bool) (domain.Project, error)
+
+	lastListLimit    int
+	lastListCursor   *domain.Cursor
+	lastListArchived bool
 }
 
-func (f *fakeProjectRepo) Create(ctx context.Context, userID, name string) (domain.Project, error) {
+func (f *fakeProjectRepo) Create(ctx context.Context, userID, name, key string) (domain.Project, error) {
 	if f.createFn != nil {
-		return f.createFn(ctx, userID, name)
+		return f.createFn(ctx, userID, name, key)
 	}
 	return domain.Project{}, nil
 }
 
-func (f *fakeProjectRepo) List(ctx context.Context, userID string, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error) {
+func (f *fakeProjectRepo) List(ctx context.Context, userID string, archived bool, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error) {
 	f.lastListLimit = limit
 	f.lastListCursor = cursor
+	f.lastListArchived = archived
 	if f.listFn != nil {
 		return f.listFn(ctx, userID, limit, cursor)
 	}
@@ -44,9 +50,9 @@ func (f *fakeProjectRepo) Get(ctx context.Context, userID, projectID string) (do
 	return domain.Project{}, nil
 }
 
-func (f *fakeProjectRepo) UpdateName(ctx context.Context, userID, projectID, name string) (domain.Project, error) {
-	if f.updateNameFn != nil {
-		return f.updateNameFn(ctx, userID, projectID, name)
+func (f *fakeProjectRepo) Update(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
+	if f.updateFn != nil {
+		return f.updateFn(ctx, userID, projectID, patch)
 	}
 	return domain.Project{}, nil
 }
@@ -58,6 +64,34 @@ func (f *fakeProjectRepo) Delete(ctx context.Context, userID, projectID string)
 	return nil
 }
 
+func (f *fakeProjectRepo) CreateFromBlueprint(ctx context.Context, userID string, bp domain.ProjectBlueprint) (domain.Project, error) {
+	if f.blueprintFn != nil {
+		return f.blueprintFn(ctx, userID, bp)
+	}
+	return domain.Project{}, nil
+}
+
+func (f *fakeProjectRepo) TaskCount(ctx context.Context, userID, projectID string) (int, error) {
+	if f.taskCountFn != nil {
+		return f.taskCountFn(ctx, userID, projectID)
+	}
+	return 0, nil
+}
+
+func (f *fakeProjectRepo) Duplicate(ctx context.Context, userID, projectID string, opts domain.DuplicateOptions) (domain.Project, error) {
+	if f.duplicateFn != nil {
+		return f.duplicateFn(ctx, userID, projectID, opts)
+	}
+	return domain.Project{}, nil
+}
+
+func (f *fakeProjectRepo) SetArchived(ctx context.Context, userID, projectID string, archived bool) (domain.Project, error) {
+	if f.archiveFn != nil {
+		return f.archiveFn(ctx, userID, projectID, archived)
+	}
+	return domain.Project{ID: projectID}, nil
+}
+
 func TestProjectService_List_ClampsLimit_DefaultsTo20(t *testing.T) {
 	repo := &fakeProjectRepo{
 		listFn: func(ctx context.Context, userID string, limit int, cursor *domain.Cursor) ([]domain.Project, *domain.Cursor, error) {
@@ -66,7 +100,7 @@ func TestProjectService_List_ClampsLimit_DefaultsTo20(t *testing.T) {
 	}
 	svc := _service.NewProjectService(repo)
 
-	_, err := svc.List(context.Background(), "user-1", 0, nil) // <=0 => default 20
+	_, err := svc.List(context.Background(), "user-1", false, 0, nil) // <=0 => default 20
 	if err != nil {
 		t.Fatalf("expected nil err, got %v", err)
 	}
@@ -83,7 +117,7 @@ func TestProjectService_List_ClampsLimit_Max100(t *testing.T) {
 	}
 	svc := _service.NewProjectService(repo)
 
-	_, err := svc.List(context.Background(), "user-1", 999, nil) // >100 => 100
+	_, err := svc.List(context.Background(), "user-1", false, 999, nil) // >100 => 100
 	if err != nil {
 		t.Fatalf("expected nil err, got %v", err)
 	}
@@ -106,15 +140,59 @@ func TestProjectService_Get_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
 	}
 }
 
-func TestProjectService_UpdateName_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
+func TestProjectService_Update_TrimsNameAndPassesEstimateUnit(t *testing.T) {
+	var got domain.ProjectPatch
+	repo := &fakeProjectRepo{
+		updateFn: func(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
+			got = patch
+			return domain.Project{ID: projectID}, nil
+		},
+	}
+	svc := _service.NewProjectService(repo)
+
+	name := "  Website  "
+	unit := domain.EstimateHours
+	if _, err := svc.Update(context.Background(), "user-1", "proj-1", domain.ProjectPatch{Name: &name, EstimateUnit: &unit}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.Name == nil || *got.Name != "Website" {
+		t.Fatalf("expected trimmed name, got %v", got.Name)
+	}
+	if got.EstimateUnit == nil || *got.EstimateUnit != domain.EstimateHours {
+		t.Fatalf("expected estimate unit hours, got %v", got.EstimateUnit)
+	}
+}
+
+func TestProjectService_Update_RejectsInvalidInput(t *testing.T) {
+	repo := &fakeProjectRepo{
+		updateFn: func(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
+			t.Fatal("repo should not be called")
+			return domain.Project{}, nil
+		},
+	}
+	svc := _service.NewProjectService(repo)
+
+	blank := "   "
+	bad := domain.EstimateUnit("days")
+	for _, patch := range []domain.ProjectPatch{{Name: &blank}, {EstimateUnit: &bad}} {
+		_, err := svc.Update(context.Background(), "user-1", "proj-1", patch)
+		var verr *_service.ValidationError
+		if !errors.As(err, &verr) {
+			t.Fatalf("expected ValidationError, got %v", err)
+		}
+	}
+}
+
+func TestProjectService_Update_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
 	repo := &fakeProjectRepo{
-		updateNameFn: func(ctx context.Context, userID, projectID, name string) (domain.Project, error) {
+		updateFn: func(ctx context.Context, userID, projectID string, patch domain.ProjectPatch) (domain.Project, error) {
 			return domain.Project{}, sql.ErrNoRows
 		},
 	}
 	svc := _service.NewProjectService(repo)
 
-	_, err := svc.UpdateName(context.Background(), "user-1", "proj-1", "new")
+	unit := domain.EstimatePoints
+	_, err := svc.Update(context.Background(), "user-1", "proj-1", domain.ProjectPatch{EstimateUnit: &unit})
 	if !errors.Is(err, _service.ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -0,0 +1,91 @@
+package recurrence
+
+import (
+	"testing"
+	"time"
+
+	"TaskFlow/internal/recurrence"
+)
+
+func mustLoad(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func TestParse_CanonicalizesAndRejectsUnsupported(t *testing.T) {
+	r, err := recurrence.Parse("RRULE:freq=weekly;byday=mo,we;interval=1", time.UTC)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if got := r.String(); got != "FREQ=WEEKLY;BYDAY=MO,WE" {
+		t.Fatalf("unexpected canonical form %q", got)
+	}
+
+	for _, bad := range []string{
+		"",
+		"INTERVAL=2",
+		"FREQ=HOURLY",
+		"FREQ=DAILY;INTERVAL=0",
+		"FREQ=DAILY;COUNT=3;UNTIL=20260101",
+		"FREQ=WEEKLY;BYDAY=1MO",
+		"FREQ=MONTHLY;BYDAY=XX",
+		"FREQ=MONTHLY;BYMONTHDAY=1",
+		"FREQ=DAILY;FREQ=WEEKLY",
+	} {
+		if _, err := recurrence.Parse(bad, time.UTC); err == nil {
+			t.Fatalf("expected %q to be rejected", bad)
+		}
+	}
+}
+
+func TestNext(t *testing.T) {
+	berlin := mustLoad(t, "Europe/Berlin")
+	at := func(s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04", s, berlin)
+		if err != nil {
+			t.Fatalf("parse %q: %v", s, err)
+		}
+		return tm
+	}
+
+	cases := []struct {
+		rule  string
+		start string
+		want  string // empty means no further occurrence
+	}{
+		{"FREQ=DAILY", "2026-03-10 09:00", "2026-03-11 09:00"},
+		{"FREQ=DAILY;INTERVAL=3", "2026-03-10 09:00", "2026-03-13 09:00"},
+		{"FREQ=DAILY;BYDAY=MO,TU,WE,TH,FR", "2026-03-13 09:00", "2026-03-16 09:00"},
+		// Keeps local time across the DST change on 2026-03-29.
+		{"FREQ=WEEKLY", "2026-03-23 09:00", "2026-03-30 09:00"},
+		{"FREQ=WEEKLY;BYDAY=MO,TH", "2026-03-10 09:00", "2026-03-12 09:00"},
+		{"FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE", "2026-03-11 09:00", "2026-03-23 09:00"},
+		{"FREQ=MONTHLY", "2026-01-31 10:00", "2026-03-31 10:00"},
+		{"FREQ=MONTHLY;BYDAY=-1FR", "2026-03-27 10:00", "2026-04-24 10:00"},
+		{"FREQ=MONTHLY;BYDAY=1MO", "2026-03-10 10:00", "2026-04-06 10:00"},
+		{"FREQ=YEARLY", "2024-02-29 08:00", "2028-02-29 08:00"},
+		{"FREQ=YEARLY;INTERVAL=2", "2026-06-01 08:00", "2028-06-01 08:00"},
+		{"FREQ=DAILY;UNTIL=20260311", "2026-03-10 09:00", "2026-03-11 09:00"},
+		{"FREQ=DAILY;UNTIL=20260311", "2026-03-11 09:00", ""},
+	}
+	for _, c := range cases {
+		r, err := recurrence.Parse(c.rule, berlin)
+		if err != nil {
+			t.Fatalf("%s: parse: %v", c.rule, err)
+		}
+		got, ok := r.Next(at(c.start))
+		if c.want == "" {
+			if ok {
+				t.Fatalf("%s from %s: expected no occurrence, got %s", c.rule, c.start, got)
+			}
+			continue
+		}
+		if !ok || !got.Equal(at(c.want)) {
+			t.Fatalf("%s from %s: expected %s, got %s (ok=%v)", c.rule, c.start, c.want, got.In(berlin).Format("2006-01-02 15:04"), ok)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+package sprints
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeSprintRepo struct {
+	sprints  map[string]domain.Sprint
+	archived map[string]bool
+
+	createFn   func(ctx context.Context, userID, projectID string, sp domain.Sprint) (domain.Sprint, error)
+	updateFn   func(ctx context.Context, userID, sprintID string, patch domain.SprintPatch) (domain.Sprint, error)
+	completeFn func(ctx context.Context, userID, sprintID string, nextID *string) (domain.SprintCompletion, error)
+	statsFn    func(ctx context.Context, userID, sprintID string) (domain.SprintStats, error)
+
+	deleted []string
+}
+
+func (f *fakeSprintRepo) Create(ctx context.Context, userID, projectID string, sp domain.Sprint) (domain.Sprint, error) {
+	if f.createFn != nil {
+		return f.createFn(ctx, userID, projectID, sp)
+	}
+	sp.ID, sp.ProjectID = "sprint-new", projectID
+	return sp, nil
+}
+
+func (f *fakeSprintRepo) List(ctx context.Context, userID, projectID string) ([]domain.Sprint, error) {
+	return []domain.Sprint{}, nil
+}
+
+func (f *fakeSprintRepo) Get(ctx context.Context, userID, sprintID string) (domain.Sprint, error) {
+	sp, ok := f.sprints[sprintID]
+	if !ok {
+		return domain.Sprint{}, sql.ErrNoRows
+	}
+	return sp, nil
+}
+
+func (f *fakeSprintRepo) Update(ctx context.Context, userID, sprintID string, patch domain.SprintPatch) (domain.Sprint, error) {
+	if f.updateFn != nil {
+		return f.updateFn(ctx, userID, sprintID, patch)
+	}
+	return f.Get(ctx, userID, sprintID)
+}
+
+func (f *fakeSprintRepo) Delete(ctx context.Context, userID, sprintID string) error {
+	f.deleted = append(f.deleted, sprintID)
+	return nil
+}
+
+func (f *fakeSprintRepo) Complete(ctx context.Context, userID, sprintID string, nextID *string) (domain.SprintCompletion, error) {
+	if f.completeFn != nil {
+		return f.completeFn(ctx, userID, sprintID, nextID)
+	}
+	return domain.SprintCompletion{Sprint: f.sprints[sprintID], NextSprintID: nextID}, nil
+}
+
+func (f *fakeSprintRepo) Stats(ctx context.Context, userID, sprintID string) (domain.SprintStats, error) {
+	if f.statsFn != nil {
+		return f.statsFn(ctx, userID, sprintID)
+	}
+	return domain.SprintStats{SprintID: sprintID}, nil
+}
+
+func (f *fakeSprintRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return f.archived[projectID], nil
+}
+
+func newRepo() *fakeSprintRepo {
+	done := time.Date(2026, 3, 13, 17, 0, 0, 0, time.UTC)
+	return &fakeSprintRepo{
+		sprints: map[string]domain.Sprint{
+			"s1":   {ID: "s1", ProjectID: "proj-1", Name: "Sprint 1", StartDate: "2026-03-02", EndDate: "2026-03-13"},
+			"done": {ID: "done", ProjectID: "proj-1", Name: "Sprint 0", StartDate: "2026-02-16", EndDate: "2026-02-27", CompletedAt: &done},
+			"old":  {ID: "old", ProjectID: "proj-old", Name: "Archived", StartDate: "2025-01-06", EndDate: "2025-01-17"},
+		},
+		archived: map[string]bool{"proj-old": true},
+	}
+}
+
+func TestSprintService_Create_Validates(t *testing.T) {
+	svc := _service.NewSprintService(newRepo())
+	ctx := context.Background()
+
+	cases := []struct {
+		name  string
+		in    domain.Sprint
+		field string
+	}{
+		{"blank name", domain.Sprint{Name: "  ", StartDate: "2026-03-02", EndDate: "2026-03-13"}, "name"},
+		{"bad start", domain.Sprint{Name: "S", StartDate: "03/02/2026", EndDate: "2026-03-13"}, "startDate"},
+		{"missing end", domain.Sprint{Name: "S", StartDate: "2026-03-02"}, "endDate"},
+		{"end before start", domain.Sprint{Name: "S", StartDate: "2026-03-13", EndDate: "2026-03-02"}, "endDate"},
+	}
+	for _, c := range cases {
+		_, err := svc.Create(ctx, "user-1", "proj-1", c.in)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected ValidationError on %q, got %v", c.name, c.field, err)
+		}
+	}
+
+	sp, err := svc.Create(ctx, "user-1", "proj-1", domain.Sprint{Name: " Sprint 2 ", Goal: "Ship search", StartDate: "2026-03-16", EndDate: "2026-03-16"})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if sp.Name != "Sprint 2" {
+		t.Fatalf("expected trimmed name, got %q", sp.Name)
+	}
+
+	_, err = svc.Create(ctx, "user-1", "proj-old", domain.Sprint{Name: "S", StartDate: "2026-03-16", EndDate: "2026-03-27"})
+	if !errors.Is(err, _service.ErrProjectArchived) {
+		t.Fatalf("expected ErrProjectArchived, got %v", err)
+	}
+}
+
+func TestSprintService_Update_ChecksDatesAgainstCurrent(t *testing.T) {
+	repo := newRepo()
+	var got domain.SprintPatch
+	repo.updateFn = func(ctx context.Context, userID, sprintID string, patch domain.SprintPatch) (domain.Sprint, error) {
+		got = patch
+		return repo.sprints[sprintID], nil
+	}
+	svc := _service.NewSprintService(repo)
+	ctx := context.Background()
+
+	early := "2026-03-01"
+	var ve *_service.ValidationError
+	if _, err := svc.Update(ctx, "user-1", "s1", domain.SprintPatch{EndDate: &early}); !errors.As(err, &ve) || ve.Field != "endDate" {
+		t.Fatalf("expected endDate before the current start to be rejected, got %v", err)
+	}
+
+	later := "2026-03-20"
+	if _, err := svc.Update(ctx, "user-1", "s1", domain.SprintPatch{EndDate: &later}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.EndDate == nil || *got.EndDate != later {
+		t.Fatalf("expected end date to reach the repo, got %v", got.EndDate)
+	}
+
+	name := "Renamed"
+	if _, err := svc.Update(ctx, "user-1", "missing", domain.SprintPatch{Name: &name}); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.Update(ctx, "user-1", "old", domain.SprintPatch{Name: &name}); !errors.Is(err, _service.ErrProjectArchived) {
+		t.Fatalf("expected ErrProjectArchived, got %v", err)
+	}
+}
+
+func TestSprintService_Complete(t *testing.T) {
+	repo := newRepo()
+	svc := _service.NewSprintService(repo)
+	ctx := context.Background()
+
+	if _, err := svc.Complete(ctx, "user-1", "done", nil); !errors.Is(err, _service.ErrSprintCompleted) {
+		t.Fatalf("expected ErrSprintCompleted, got %v", err)
+	}
+	self := "s1"
+	var ve *_service.ValidationError
+	if _, err := svc.Complete(ctx, "user-1", "s1", &self); !errors.As(err, &ve) || ve.Field != "nextSprintId" {
+		t.Fatalf("expected nextSprintId validation error, got %v", err)
+	}
+	if _, err := svc.Complete(ctx, "user-1", "old", nil); !errors.Is(err, _service.ErrProjectArchived) {
+		t.Fatalf("expected ErrProjectArchived, got %v", err)
+	}
+
+	repo.completeFn = func(ctx context.Context, userID, sprintID string, nextID *string) (domain.SprintCompletion, error) {
+		return domain.SprintCompletion{}, domain.ErrInvalidSprint
+	}
+	other := "s9"
+	if _, err := svc.Complete(ctx, "user-1", "s1", &other); !errors.As(err, &ve) || ve.Field != "nextSprintId" {
+		t.Fatalf("expected nextSprintId validation error, got %v", err)
+	}
+
+	repo.completeFn = func(ctx context.Context, userID, sprintID string, nextID *string) (domain.SprintCompletion, error) {
+		return domain.SprintCompletion{}, domain.ErrSprintCompleted
+	}
+	if _, err := svc.Complete(ctx, "user-1", "s1", nil); !errors.Is(err, _service.ErrSprintCompleted) {
+		t.Fatalf("expected a concurrent completion to map to ErrSprintCompleted, got %v", err)
+	}
+}
+
+func TestSprintService_Stats_CompletionRate(t *testing.T) {
+	repo := newRepo()
+	repo.statsFn = func(ctx context.Context, userID, sprintID string) (domain.SprintStats, error) {
+		if sprintID == "missing" {
+			return domain.SprintStats{}, sql.ErrNoRows
+		}
+		if sprintID == "empty" {
+			return domain.SprintStats{SprintID: sprintID}, nil
+		}
+		return domain.SprintStats{SprintID: sprintID, TotalTasks: 8, CompletedTasks: 6, CarriedOverTasks: 2}, nil
+	}
+	svc := _service.NewSprintService(repo)
+	ctx := context.Background()
+
+	st, err := svc.Stats(ctx, "user-1", "s1")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if st.CompletionRate != 0.75 {
+		t.Fatalf("expected completion rate 0.75, got %v", st.CompletionRate)
+	}
+	if st, err := svc.Stats(ctx, "user-1", "empty"); err != nil || st.CompletionRate != 0 {
+		t.Fatalf("expected rate 0 for an empty sprint, got %v, %v", st.CompletionRate, err)
+	}
+	if _, err := svc.Stats(ctx, "user-1", "missing"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestSprintService_Delete(t *testing.T) {
+	repo := newRepo()
+	svc := _service.NewSprintService(repo)
+	ctx := context.Background()
+
+	if err := svc.Delete(ctx, "user-1", "s1"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if err := svc.Delete(ctx, "user-1", "missing"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if len(repo.deleted) != 1 || repo.deleted[0] != "s1" {
+		t.Fatalf("expected only s1 to be deleted, got %v", repo.deleted)
+	}
+}
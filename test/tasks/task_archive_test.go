@@ -0,0 +1,122 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func archivedProjects(ids ...string) func(ctx context.Context, userID, projectID string) (bool, error) {
+	return func(ctx context.Context, userID, projectID string) (bool, error) {
+		for _, id := range ids {
+			if id == projectID {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+func TestTaskService_ArchivedProject_RejectsWrites(t *testing.T) {
+	repo := &fakeTaskRepo{
+		archivedFn: archivedProjects("proj-1"),
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1"}, nil
+		},
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+			t.Fatal("create should not reach the repo")
+			return domain.Task{}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			t.Fatal("update should not reach the repo")
+			return domain.Task{}, nil
+		},
+		deleteFn: func(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error {
+			t.Fatal("delete should not reach the repo")
+			return nil
+		},
+		moveFn: func(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error) {
+			t.Fatal("move should not reach the repo")
+			return domain.Task{}, nil
+		},
+	}
+	checklists := &fakeChecklistRepo{items: map[string]domain.ChecklistItem{
+		"item-1": {ID: "item-1", TaskID: "task-1", Text: "Write tests"},
+	}}
+	svc := _service.NewTaskService(repo, _service.WithChecklists(checklists))
+	ctx := context.Background()
+	title := "renamed"
+
+	writes := map[string]func() error{
+		"create": func() error {
+			_, err := svc.Create(ctx, "user-1", "proj-1", domain.TaskInput{Title: "hello"})
+			return err
+		},
+		"update": func() error {
+			_, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{Title: &title})
+			return err
+		},
+		"delete": func() error {
+			return svc.Delete(ctx, "user-1", "task-1", "")
+		},
+		"move": func() error {
+			_, err := svc.Move(ctx, "user-1", "task-1", strPtr("task-2"), nil)
+			return err
+		},
+		"add checklist item": func() error {
+			_, err := svc.AddChecklistItem(ctx, "user-1", "task-1", "more", false)
+			return err
+		},
+		"update checklist item": func() error {
+			_, err := svc.UpdateChecklistItem(ctx, "user-1", "item-1", domain.ChecklistItemPatch{Text: &title})
+			return err
+		},
+		"delete checklist item": func() error {
+			return svc.DeleteChecklistItem(ctx, "user-1", "item-1")
+		},
+	}
+	for name, write := range writes {
+		if err := write(); !errors.Is(err, _service.ErrProjectArchived) {
+			t.Fatalf("%s: expected ErrProjectArchived, got %v", name, err)
+		}
+	}
+
+	if _, err := svc.Get(ctx, "user-1", "task-1"); err != nil {
+		t.Fatalf("expected reads to keep working, got %v", err)
+	}
+}
+
+func TestTaskService_Update_RejectsMoveIntoArchivedProject(t *testing.T) {
+	repo := &fakeTaskRepo{
+		archivedFn: func(ctx context.Context, userID, projectID string) (bool, error) {
+			if projectID == "missing" {
+				return false, sql.ErrNoRows
+			}
+			return projectID == "proj-2", nil
+		},
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1"}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			t.Fatal("update should not reach the repo")
+			return domain.Task{}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+	ctx := context.Background()
+
+	_, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{ProjectID: strPtr("proj-2")})
+	if !errors.Is(err, _service.ErrProjectArchived) {
+		t.Fatalf("expected ErrProjectArchived, got %v", err)
+	}
+
+	_, err = svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{ProjectID: strPtr("missing")})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "projectId" {
+		t.Fatalf("expected projectId validation error, got %v", err)
+	}
+}
@@ -0,0 +1,117 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func TestTaskService_Board_DefaultsAllColumns(t *testing.T) {
+	var got domain.BoardQuery
+	repo := &fakeTaskRepo{
+		boardFn: func(ctx context.Context, userID, projectID string, q domain.BoardQuery) ([]domain.BoardColumn, error) {
+			got = q
+			return nil, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	if _, err := svc.Board(context.Background(), "user-1", "proj-1", domain.BoardQuery{Limit: 500}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.Limit != _service.MaxBoardLimit {
+		t.Fatalf("expected limit clamped to %d, got %d", _service.MaxBoardLimit, got.Limit)
+	}
+	if len(got.Statuses) != 3 || got.Statuses[0] != domain.StatusTodo || got.Statuses[2] != domain.StatusDone {
+		t.Fatalf("expected every column in board order, got %v", got.Statuses)
+	}
+}
+
+func TestTaskService_Board_ValidatesColumnsAndCursors(t *testing.T) {
+	repo := &fakeTaskRepo{}
+	svc := _service.NewTaskService(repo)
+
+	todoCursor := domain.Cursor{ID: "t-1", Sort: domain.BoardSort, Values: []string{"todo", "V"}}
+	cases := []struct {
+		name  string
+		q     domain.BoardQuery
+		field string
+	}{
+		{"unknown column", domain.BoardQuery{Statuses: []domain.TaskStatus{"blocked"}}, "columns"},
+		{"repeated column", domain.BoardQuery{Statuses: []domain.TaskStatus{"todo", "todo"}}, "columns"},
+		{"list cursor", domain.BoardQuery{Cursors: map[domain.TaskStatus]domain.Cursor{
+			"todo": {ID: "t-1", Sort: "priority", Values: []string{"3"}},
+		}}, "cursor"},
+		{"cursor for other column", domain.BoardQuery{Cursors: map[domain.TaskStatus]domain.Cursor{
+			"done": todoCursor,
+		}}, "cursor"},
+		{"cursor for unrequested column", domain.BoardQuery{
+			Statuses: []domain.TaskStatus{"done"},
+			Cursors:  map[domain.TaskStatus]domain.Cursor{"todo": todoCursor},
+		}, "cursor"},
+	}
+	for _, c := range cases {
+		_, err := svc.Board(context.Background(), "user-1", "proj-1", c.q)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected validation error on %s, got %v", c.name, c.field, err)
+		}
+	}
+}
+
+func TestTaskService_Board_NotFound(t *testing.T) {
+	repo := &fakeTaskRepo{
+		boardFn: func(ctx context.Context, userID, projectID string, q domain.BoardQuery) ([]domain.BoardColumn, error) {
+			return nil, sql.ErrNoRows
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	if _, err := svc.Board(context.Background(), "user-1", "proj-1", domain.BoardQuery{}); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTaskService_Update_StatusDrivesCompletion(t *testing.T) {
+	var got domain.TaskPatch
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, IsBlocked: true}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			got = patch
+			return domain.Task{ID: taskID}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithBlockerEnforcement(true))
+
+	inProgress := domain.StatusInProgress
+	if _, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Status: &inProgress}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.Completed == nil || *got.Completed {
+		t.Fatalf("expected in_progress to reopen, got completed=%v", got.Completed)
+	}
+
+	// Moving a blocked task to done counts as completing it.
+	done := domain.StatusDone
+	if _, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Status: &done}); err != _service.ErrBlocked {
+		t.Fatalf("expected ErrBlocked, got %v", err)
+	}
+
+	no := false
+	_, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Status: &done, Completed: &no})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "status" {
+		t.Fatalf("expected validation error on status, got %v", err)
+	}
+
+	bogus := domain.TaskStatus("later")
+	if _, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Status: &bogus}); !errors.As(err, &ve) {
+		t.Fatalf("expected validation error for unknown status, got %v", err)
+	}
+}
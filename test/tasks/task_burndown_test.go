@@ -0,0 +1,109 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func TestTaskService_Estimate_Validated(t *testing.T) {
+	repo := &fakeTaskRepo{
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+			return domain.Task{Estimate: in.Estimate}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	for _, e := range []float64{-1, _service.MaxEstimate + 1, math.NaN()} {
+		e := e
+		_, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "t", Estimate: &e})
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "estimate" {
+			t.Fatalf("create with estimate %v: expected estimate ValidationError, got %v", e, err)
+		}
+		_, err = svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Estimate: &e})
+		if !errors.As(err, &ve) || ve.Field != "estimate" {
+			t.Fatalf("update with estimate %v: expected estimate ValidationError, got %v", e, err)
+		}
+	}
+
+	five := 5.5
+	got, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "t", Estimate: &five})
+	if err != nil || got.Estimate == nil || *got.Estimate != 5.5 {
+		t.Fatalf("expected estimate 5.5, got %v (err %v)", got.Estimate, err)
+	}
+}
+
+func TestTaskService_Burndown_NormalizesQuery(t *testing.T) {
+	var got domain.BurndownQuery
+	repo := &fakeTaskRepo{
+		burndownFn: func(ctx context.Context, userID, projectID string, q domain.BurndownQuery) (domain.Burndown, error) {
+			got = q
+			return domain.Burndown{ProjectID: projectID}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	from := time.Date(2024, 3, 1, 15, 30, 0, 0, time.FixedZone("x", 3600))
+	to := time.Date(2024, 3, 14, 0, 0, 0, 0, time.UTC)
+	if _, err := svc.Burndown(context.Background(), "user-1", "proj-1", domain.BurndownQuery{From: from, To: to}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.Location != time.UTC {
+		t.Fatalf("expected location to default to UTC, got %v", got.Location)
+	}
+	if !got.From.Equal(time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)) || !got.To.Equal(to) {
+		t.Fatalf("expected dates truncated to days, got %v..%v", got.From, got.To)
+	}
+}
+
+func TestTaskService_Burndown_ValidatesRange(t *testing.T) {
+	repo := &fakeTaskRepo{}
+	svc := _service.NewTaskService(repo)
+
+	day := func(y int, m time.Month, d int) time.Time { return time.Date(y, m, d, 0, 0, 0, 0, time.UTC) }
+	cases := []struct {
+		name  string
+		q     domain.BurndownQuery
+		field string
+	}{
+		{"missing from", domain.BurndownQuery{To: day(2024, 1, 1)}, "from"},
+		{"missing to", domain.BurndownQuery{From: day(2024, 1, 1)}, "to"},
+		{"reversed", domain.BurndownQuery{From: day(2024, 1, 2), To: day(2024, 1, 1)}, "to"},
+		{"too long", domain.BurndownQuery{From: day(2024, 1, 1), To: day(2025, 1, 1)}, "to"},
+	}
+	for _, c := range cases {
+		_, err := svc.Burndown(context.Background(), "user-1", "proj-1", c.q)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected ValidationError on %q, got %v", c.name, c.field, err)
+		}
+	}
+
+	// 2024 is a leap year: Jan 1 to Dec 31 is exactly 366 days.
+	if _, err := svc.Burndown(context.Background(), "user-1", "proj-1",
+		domain.BurndownQuery{From: day(2024, 1, 1), To: day(2024, 12, 31)}); err != nil {
+		t.Fatalf("expected a 366-day range to be accepted, got %v", err)
+	}
+}
+
+func TestTaskService_Burndown_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
+	repo := &fakeTaskRepo{
+		burndownFn: func(ctx context.Context, userID, projectID string, q domain.BurndownQuery) (domain.Burndown, error) {
+			return domain.Burndown{}, sql.ErrNoRows
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	day := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	_, err := svc.Burndown(context.Background(), "user-1", "proj-1", domain.BurndownQuery{From: day, To: day})
+	if !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
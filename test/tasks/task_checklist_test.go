@@ -0,0 +1,186 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeChecklistRepo struct {
+	items     map[string]domain.ChecklistItem
+	moveFn    func(ctx context.Context, userID, itemID string, afterID, beforeID *string) (domain.ChecklistItem, error)
+	converted []string
+
+	lastText  string
+	lastPatch domain.ChecklistItemPatch
+}
+
+func (f *fakeChecklistRepo) Create(ctx context.Context, userID, taskID, text string, checked bool) (domain.ChecklistItem, error) {
+	f.lastText = text
+	return domain.ChecklistItem{ID: "item-new", TaskID: taskID, Text: text, Checked: checked}, nil
+}
+
+func (f *fakeChecklistRepo) List(ctx context.Context, userID, taskID string) ([]domain.ChecklistItem, error) {
+	return nil, sql.ErrNoRows
+}
+
+func (f *fakeChecklistRepo) Get(ctx context.Context, userID, itemID string) (domain.ChecklistItem, error) {
+	it, ok := f.items[itemID]
+	if !ok {
+		return domain.ChecklistItem{}, sql.ErrNoRows
+	}
+	return it, nil
+}
+
+func (f *fakeChecklistRepo) Update(ctx context.Context, userID, itemID string, patch domain.ChecklistItemPatch) (domain.ChecklistItem, error) {
+	f.lastPatch = patch
+	return f.Get(ctx, userID, itemID)
+}
+
+func (f *fakeChecklistRepo) Delete(ctx context.Context, userID, itemID string) error {
+	_, err := f.Get(ctx, userID, itemID)
+	return err
+}
+
+func (f *fakeChecklistRepo) Move(ctx context.Context, userID, itemID string, afterID, beforeID *string) (domain.ChecklistItem, error) {
+	if f.moveFn != nil {
+		return f.moveFn(ctx, userID, itemID, afterID, beforeID)
+	}
+	return domain.ChecklistItem{ID: itemID}, nil
+}
+
+func (f *fakeChecklistRepo) ConvertToTask(ctx context.Context, userID, itemID string) (domain.Task, error) {
+	it, err := f.Get(ctx, userID, itemID)
+	if err != nil {
+		return domain.Task{}, err
+	}
+	f.converted = append(f.converted, itemID)
+	return domain.Task{ID: "task-new", ParentTaskID: &it.TaskID, Title: it.Text}, nil
+}
+
+func TestTaskService_Checklist_RequiresRepo(t *testing.T) {
+	svc := _service.NewTaskService(&fakeTaskRepo{})
+
+	if _, err := svc.AddChecklistItem(context.Background(), "user-1", "task-1", "x", false); err == nil {
+		t.Fatal("expected an error without a checklist repo")
+	}
+}
+
+func TestTaskService_AddChecklistItem_ValidatesText(t *testing.T) {
+	repo := &fakeChecklistRepo{}
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithChecklists(repo))
+	ctx := context.Background()
+
+	for _, text := range []string{"", "   ", strings.Repeat("x", _service.MaxChecklistItemLength+1), "nul\x00byte"} {
+		_, err := svc.AddChecklistItem(ctx, "user-1", "task-1", text, false)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "text" {
+			t.Fatalf("text %q: expected text ValidationError, got %v", text, err)
+		}
+	}
+
+	it, err := svc.AddChecklistItem(ctx, "user-1", "task-1", "  Buy milk ", true)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if repo.lastText != "Buy milk" || !it.Checked {
+		t.Fatalf("expected trimmed checked item, got %q %+v", repo.lastText, it)
+	}
+}
+
+func TestTaskService_UpdateChecklistItem_TrimsAndMapsNotFound(t *testing.T) {
+	repo := &fakeChecklistRepo{items: map[string]domain.ChecklistItem{"item-1": {ID: "item-1", TaskID: "task-1"}}}
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithChecklists(repo))
+	ctx := context.Background()
+
+	text := " done "
+	if _, err := svc.UpdateChecklistItem(ctx, "user-1", "item-1", domain.ChecklistItemPatch{Text: &text}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if repo.lastPatch.Text == nil || *repo.lastPatch.Text != "done" {
+		t.Fatalf("expected trimmed text, got %v", repo.lastPatch.Text)
+	}
+
+	checked := true
+	if _, err := svc.UpdateChecklistItem(ctx, "user-1", "missing", domain.ChecklistItemPatch{Checked: &checked}); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	if err := svc.DeleteChecklistItem(ctx, "user-1", "missing"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on delete, got %v", err)
+	}
+	if _, err := svc.Checklist(ctx, "user-1", "missing"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound on list, got %v", err)
+	}
+}
+
+func TestTaskService_MoveChecklistItem_Validates(t *testing.T) {
+	repo := &fakeChecklistRepo{
+		items: map[string]domain.ChecklistItem{"item-1": {ID: "item-1", TaskID: "task-1"}},
+		moveFn: func(ctx context.Context, userID, itemID string, afterID, beforeID *string) (domain.ChecklistItem, error) {
+			return domain.ChecklistItem{}, domain.ErrInvalidNeighbor
+		},
+	}
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithChecklists(repo))
+	ctx := context.Background()
+
+	cases := []struct {
+		name          string
+		after, before *string
+		field         string
+	}{
+		{"no neighbors", nil, nil, "afterId"},
+		{"after itself", strPtr("item-1"), nil, "afterId"},
+		{"before itself", nil, strPtr("item-1"), "beforeId"},
+		{"neighbor elsewhere", nil, strPtr("item-9"), "beforeId"},
+	}
+	for _, c := range cases {
+		_, err := svc.MoveChecklistItem(ctx, "user-1", "item-1", c.after, c.before)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected ValidationError on %q, got %v", c.name, c.field, err)
+		}
+	}
+}
+
+func TestTaskService_ConvertChecklistItem_RespectsMaxDepth(t *testing.T) {
+	repo := &fakeChecklistRepo{items: map[string]domain.ChecklistItem{
+		"shallow": {ID: "shallow", TaskID: "top", Text: "Write tests"},
+		"deep":    {ID: "deep", TaskID: "leaf", Text: "Too deep"},
+	}}
+	tasks := &fakeTaskRepo{
+		ancestorsFn: func(ctx context.Context, userID, taskID string) ([]string, error) {
+			if taskID == "leaf" {
+				return []string{"mid", "top"}, nil
+			}
+			return nil, nil
+		},
+	}
+	svc := _service.NewTaskService(tasks, _service.WithChecklists(repo), _service.WithMaxDepth(2))
+	ctx := context.Background()
+
+	got, err := svc.ConvertChecklistItem(ctx, "user-1", "shallow")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.ParentTaskID == nil || *got.ParentTaskID != "top" || got.Title != "Write tests" {
+		t.Fatalf("expected a subtask of top titled from the item, got %+v", got)
+	}
+
+	_, err = svc.ConvertChecklistItem(ctx, "user-1", "deep")
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("expected ValidationError past the depth limit, got %v", err)
+	}
+	if len(repo.converted) != 1 {
+		t.Fatalf("expected only the shallow item converted, got %v", repo.converted)
+	}
+
+	if _, err := svc.ConvertChecklistItem(ctx, "user-1", "missing"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func TestTaskService_Update_RecordsCompletion(t *testing.T) {
+	now := time.Date(2026, 10, 12, 9, 30, 0, 0, time.UTC)
+	var patches []domain.TaskPatch
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1"}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			patches = append(patches, patch)
+			return domain.Task{ID: taskID}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithClock(func() time.Time { return now }))
+	ctx := context.Background()
+
+	done := domain.StatusDone
+	complete, reopen := true, false
+	for _, p := range []domain.TaskPatch{
+		{Completed: &complete},
+		{Status: &done},
+		{Completed: &reopen},
+	} {
+		if _, err := svc.Update(ctx, "user-1", "task-1", p); err != nil {
+			t.Fatalf("update: %v", err)
+		}
+	}
+
+	for i, p := range patches[:2] {
+		if p.CompletedAt == nil || !p.CompletedAt.Equal(now) || p.CompletedBy == nil || *p.CompletedBy != "user-1" {
+			t.Fatalf("patch %d: expected the completion to be stamped, got %v by %v", i, p.CompletedAt, p.CompletedBy)
+		}
+	}
+	if patches[2].CompletedAt != nil || patches[2].CompletedBy != nil {
+		t.Fatalf("expected no stamp on reopen, got %v by %v", patches[2].CompletedAt, patches[2].CompletedBy)
+	}
+}
+
+func TestTaskService_Update_IgnoresCallerCompletionStamp(t *testing.T) {
+	var got domain.TaskPatch
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1"}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			got = patch
+			return domain.Task{ID: taskID}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	at := time.Now()
+	other := "user-2"
+	title := "renamed"
+	if _, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Title: &title, CompletedAt: &at, CompletedBy: &other}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if got.CompletedAt != nil || got.CompletedBy != nil {
+		t.Fatalf("expected the stamp to be dropped, got %v by %v", got.CompletedAt, got.CompletedBy)
+	}
+}
+
+func TestTaskService_List_RejectsEmptyCompletionRange(t *testing.T) {
+	repo := &fakeTaskRepo{
+		listFn: func(ctx context.Context, userID string, f domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
+			t.Fatal("repo should not be called")
+			return nil, nil, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	after := time.Date(2026, 10, 12, 0, 0, 0, 0, time.UTC)
+	before := after.AddDate(0, 0, -7)
+	_, err := svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "proj-1", CompletedAfter: &after, CompletedBefore: &before}, nil, 20, nil)
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "completedBefore" {
+		t.Fatalf("expected a completedBefore validation error, got %v", err)
+	}
+}
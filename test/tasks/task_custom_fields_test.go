@@ -0,0 +1,244 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+const userFieldValue = "6f1c2a3e-0b4d-4e5f-8a9b-1c2d3e4f5a6b"
+
+type fakeFieldRepo struct {
+	fields    []domain.CustomField
+	outsiders map[string]bool
+}
+
+func (f *fakeFieldRepo) Create(ctx context.Context, userID, projectID string, cf domain.CustomField) (domain.CustomField, error) {
+	return cf, nil
+}
+
+func (f *fakeFieldRepo) List(ctx context.Context, userID, projectID string) ([]domain.CustomField, error) {
+	out := []domain.CustomField{}
+	for _, cf := range f.fields {
+		if cf.ProjectID == projectID {
+			out = append(out, cf)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeFieldRepo) Get(ctx context.Context, userID, fieldID string) (domain.CustomField, error) {
+	return domain.CustomField{}, nil
+}
+
+func (f *fakeFieldRepo) Update(ctx context.Context, userID, fieldID string, patch domain.CustomFieldPatch) (domain.CustomField, error) {
+	return domain.CustomField{}, nil
+}
+
+func (f *fakeFieldRepo) Delete(ctx context.Context, userID, fieldID string) error { return nil }
+
+func (f *fakeFieldRepo) Assignable(ctx context.Context, projectID string, userIDs []string) ([]string, error) {
+	out := []string{}
+	for _, id := range userIDs {
+		if !f.outsiders[id] {
+			out = append(out, id)
+		}
+	}
+	return out, nil
+}
+
+func (f *fakeFieldRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	return false, nil
+}
+
+func (f *fakeFieldRepo) FieldArchived(ctx context.Context, userID, fieldID string) (bool, error) {
+	return false, nil
+}
+
+func projectFields() *fakeFieldRepo {
+	return &fakeFieldRepo{fields: []domain.CustomField{
+		{ID: "text", ProjectID: "proj-1", Type: domain.FieldText},
+		{ID: "num", ProjectID: "proj-1", Type: domain.FieldNumber},
+		{ID: "date", ProjectID: "proj-1", Type: domain.FieldDate},
+		{ID: "size", ProjectID: "proj-1", Type: domain.FieldSingleSelect, Options: []string{"S", "M"}},
+		{ID: "tags", ProjectID: "proj-1", Type: domain.FieldMultiSelect, Options: []string{"a", "b"}},
+		{ID: "owner", ProjectID: "proj-1", Type: domain.FieldUser},
+		{ID: "link", ProjectID: "proj-1", Type: domain.FieldURL},
+		{ID: "other", ProjectID: "proj-2", Type: domain.FieldText},
+	}}
+}
+
+func TestTaskService_Create_ValidatesCustomFieldValues(t *testing.T) {
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithCustomFields(projectFields()))
+	ctx := context.Background()
+
+	cases := []struct {
+		field, value string
+	}{
+		{"text", `""`},
+		{"text", `12`},
+		{"num", `"12"`},
+		{"date", `"2026-02-30"`},
+		{"size", `"XL"`},
+		{"tags", `["a","c"]`},
+		{"tags", `"a"`},
+		{"owner", `"not-a-uuid"`},
+		{"link", `"javascript:alert(1)"`},
+		{"link", `"/relative"`},
+		{"other", `"x"`},
+		{"missing", `"x"`},
+	}
+	for _, c := range cases {
+		in := domain.TaskInput{Title: "t", CustomFields: map[string]json.RawMessage{c.field: json.RawMessage(c.value)}}
+		_, err := svc.Create(ctx, "user-1", "proj-1", in)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "customFields."+c.field {
+			t.Fatalf("%s=%s: expected customFields.%s ValidationError, got %v", c.field, c.value, c.field, err)
+		}
+	}
+}
+
+func TestTaskService_Create_RejectsUsersWithoutAccess(t *testing.T) {
+	fields := projectFields()
+	fields.outsiders = map[string]bool{userFieldValue: true}
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithCustomFields(fields))
+
+	in := domain.TaskInput{Title: "t", CustomFields: map[string]json.RawMessage{"owner": json.RawMessage(`"` + userFieldValue + `"`)}}
+	_, err := svc.Create(context.Background(), "user-1", "proj-1", in)
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "customFields.owner" {
+		t.Fatalf("expected customFields.owner ValidationError, got %v", err)
+	}
+}
+
+func TestTaskService_Create_NormalizesCustomFieldValues(t *testing.T) {
+	var got domain.TaskInput
+	repo := &fakeTaskRepo{
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+			got = in
+			return domain.Task{}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithCustomFields(projectFields()))
+
+	in := domain.TaskInput{Title: "t", CustomFields: map[string]json.RawMessage{
+		"text":  json.RawMessage(`" hello "`),
+		"num":   json.RawMessage(`1.50`),
+		"date":  json.RawMessage(`"2026-03-01"`),
+		"size":  json.RawMessage(`"M"`),
+		"tags":  json.RawMessage(`["b","a","b"]`),
+		"owner": json.RawMessage(`"` + userFieldValue + `"`),
+		"link":  json.RawMessage(`"https://example.com/x"`),
+	}}
+	if _, err := svc.Create(context.Background(), "user-1", "proj-1", in); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+
+	want := map[string]string{
+		"text":  `"hello"`,
+		"num":   `1.5`,
+		"date":  `"2026-03-01"`,
+		"size":  `"M"`,
+		"tags":  `["b","a"]`,
+		"owner": `"` + userFieldValue + `"`,
+		"link":  `"https://example.com/x"`,
+	}
+	for id, w := range want {
+		if string(got.CustomFields[id]) != w {
+			t.Fatalf("%s: expected %s, got %s", id, w, got.CustomFields[id])
+		}
+	}
+}
+
+func TestTaskService_Update_ChecksCustomFieldsAgainstTaskProject(t *testing.T) {
+	var got domain.TaskPatch
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-2"}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			got = patch
+			return domain.Task{}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithCustomFields(projectFields()))
+	ctx := context.Background()
+
+	_, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{CustomFields: map[string]json.RawMessage{"text": json.RawMessage(`"x"`)}})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "customFields.text" {
+		t.Fatalf("expected a field of another project to be rejected, got %v", err)
+	}
+
+	patch := domain.TaskPatch{CustomFields: map[string]json.RawMessage{"other": json.RawMessage(`null`)}}
+	if _, err := svc.Update(ctx, "user-1", "task-1", patch); err != nil {
+		t.Fatalf("expected nil err clearing a value, got %v", err)
+	}
+	if string(got.CustomFields["other"]) != "null" {
+		t.Fatalf("expected null to pass through, got %s", got.CustomFields["other"])
+	}
+}
+
+func TestTaskService_List_ResolvesCustomFieldQuery(t *testing.T) {
+	var gotSort []domain.SortKey
+	repo := &fakeTaskRepo{
+		listFn: func(ctx context.Context, userID string, f domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
+			gotSort = sort
+			return nil, nil, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithCustomFields(projectFields()))
+	ctx := context.Background()
+
+	f := domain.TaskFilter{ProjectID: "proj-1", CustomFields: []domain.CustomFieldFilter{
+		{FieldID: "num", Op: domain.OpGte, Value: "2.50"},
+		{FieldID: "tags", Op: domain.OpEq, Value: "a"},
+		{FieldID: "link", Op: domain.OpEmpty, Value: "true"},
+	}}
+	sort := []domain.SortKey{{Field: "cf.date", Desc: true}}
+	if _, err := svc.List(ctx, "user-1", f, sort, 10, nil); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	cf := repo.lastListFilter.CustomFields
+	if cf[0].Type != domain.FieldNumber || cf[0].Value != "2.5" || cf[1].Type != domain.FieldMultiSelect {
+		t.Fatalf("expected resolved filters, got %+v", cf)
+	}
+	if gotSort[0].FieldType != domain.FieldDate {
+		t.Fatalf("expected date sort type, got %+v", gotSort)
+	}
+
+	bad := []struct {
+		name   string
+		filter domain.CustomFieldFilter
+		sort   string
+		field  string
+	}{
+		{"unknown field", domain.CustomFieldFilter{FieldID: "nope", Op: domain.OpEq, Value: "x"}, "", "cf.nope"},
+		{"range on text", domain.CustomFieldFilter{FieldID: "text", Op: domain.OpGt, Value: "x"}, "", "cf.text"},
+		{"unknown op", domain.CustomFieldFilter{FieldID: "num", Op: "like", Value: "1"}, "", "cf.num"},
+		{"bad number", domain.CustomFieldFilter{FieldID: "num", Op: domain.OpLt, Value: "ten"}, "", "cf.num.lt"},
+		{"bad empty", domain.CustomFieldFilter{FieldID: "num", Op: domain.OpEmpty, Value: "yes"}, "", "cf.num.empty"},
+		{"bad option", domain.CustomFieldFilter{FieldID: "size", Op: domain.OpEq, Value: "XL"}, "", "cf.size"},
+		{"sort unknown", domain.CustomFieldFilter{}, "cf.nope", "sort"},
+		{"sort multi", domain.CustomFieldFilter{}, "cf.tags", "sort"},
+	}
+	for _, c := range bad {
+		f := domain.TaskFilter{ProjectID: "proj-1"}
+		if c.filter.FieldID != "" {
+			f.CustomFields = []domain.CustomFieldFilter{c.filter}
+		}
+		var sort []domain.SortKey
+		if c.sort != "" {
+			sort = []domain.SortKey{{Field: c.sort}}
+		}
+		_, err := svc.List(ctx, "user-1", f, sort, 10, nil)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected %s ValidationError, got %v", c.name, c.field, err)
+		}
+	}
+}
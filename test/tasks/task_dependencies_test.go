@@ -0,0 +1,137 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+// fakeDependencyLink is a link in fakeDependencyRepo.
+type fakeDependencyLink struct{ blocker, blocked string }
+
+// fakeDependencyRepo keeps links in memory as blocker -> blocked and
+// rejects cycles like the Postgres repository does.
+type fakeDependencyRepo struct {
+	links []fakeDependencyLink
+	addFn func(ctx context.Context, userID, blockerID, blockedID string) error
+}
+
+func (f *fakeDependencyRepo) Add(ctx context.Context, userID, blockerID, blockedID string) error {
+	if f.addFn != nil {
+		return f.addFn(ctx, userID, blockerID, blockedID)
+	}
+	if f.reaches(blockedID, blockerID) {
+		return domain.ErrDependencyCycle
+	}
+	f.links = append(f.links, fakeDependencyLink{blocker: blockerID, blocked: blockedID})
+	return nil
+}
+
+func (f *fakeDependencyRepo) reaches(from, to string) bool {
+	if from == to {
+		return true
+	}
+	for _, l := range f.links {
+		if l.blocker == from && f.reaches(l.blocked, to) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f *fakeDependencyRepo) Remove(ctx context.Context, userID, blockerID, blockedID string) error {
+	for i, l := range f.links {
+		if l.blocker == blockerID && l.blocked == blockedID {
+			f.links = append(f.links[:i], f.links[i+1:]...)
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+func (f *fakeDependencyRepo) List(ctx context.Context, userID, taskID string) (domain.TaskDependencies, error) {
+	return domain.TaskDependencies{}, nil
+}
+
+func TestTaskService_AddBlocker_RejectsCycles(t *testing.T) {
+	deps := &fakeDependencyRepo{}
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithDependencies(deps))
+	ctx := context.Background()
+
+	// a blocks b, b blocks c
+	if err := svc.AddBlocker(ctx, "user-1", "b", "a"); err != nil {
+		t.Fatalf("add a->b: %v", err)
+	}
+	if err := svc.AddBlocker(ctx, "user-1", "c", "b"); err != nil {
+		t.Fatalf("add b->c: %v", err)
+	}
+
+	// c blocks a would close a -> b -> c -> a
+	err := svc.AddBlocker(ctx, "user-1", "a", "c")
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "blockerId" {
+		t.Fatalf("expected cycle validation error, got %v", err)
+	}
+	if len(deps.links) != 2 {
+		t.Fatalf("expected cyclic link not to be stored, got %v", deps.links)
+	}
+
+	// a blocks c is a shortcut, not a cycle
+	if err := svc.AddBlocker(ctx, "user-1", "c", "a"); err != nil {
+		t.Fatalf("add a->c: %v", err)
+	}
+}
+
+func TestTaskService_AddBlocker_MapsVanishedTask(t *testing.T) {
+	deps := &fakeDependencyRepo{
+		addFn: func(ctx context.Context, userID, blockerID, blockedID string) error {
+			return sql.ErrNoRows
+		},
+	}
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithDependencies(deps))
+
+	if err := svc.AddBlocker(context.Background(), "user-1", "b", "a"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTaskService_AddBlocker_RejectsSelf(t *testing.T) {
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithDependencies(&fakeDependencyRepo{}))
+
+	err := svc.AddBlocker(context.Background(), "user-1", "a", "a")
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "blockerId" {
+		t.Fatalf("expected validation error, got %v", err)
+	}
+}
+
+func TestTaskService_Update_EnforcesOpenBlockers(t *testing.T) {
+	updated := false
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, IsBlocked: true}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			updated = true
+			return domain.Task{}, nil
+		},
+	}
+	done := true
+
+	enforced := _service.NewTaskService(repo, _service.WithBlockerEnforcement(true))
+	if _, err := enforced.Update(context.Background(), "user-1", "t1", domain.TaskPatch{Completed: &done}); !errors.Is(err, _service.ErrBlocked) {
+		t.Fatalf("expected ErrBlocked, got %v", err)
+	}
+	if updated {
+		t.Fatal("repo should not be updated when blocked")
+	}
+
+	relaxed := _service.NewTaskService(repo)
+	if _, err := relaxed.Update(context.Background(), "user-1", "t1", domain.TaskPatch{Completed: &done}); err != nil {
+		t.Fatalf("expected nil err without enforcement, got %v", err)
+	}
+}
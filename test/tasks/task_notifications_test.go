@@ -0,0 +1,119 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeNotifier struct {
+	events []domain.TaskEvent
+	err    error
+}
+
+func (f *fakeNotifier) Notify(ctx context.Context, ev domain.TaskEvent) error {
+	f.events = append(f.events, ev)
+	return f.err
+}
+
+func TestTaskService_Update_NotifiesWatchersOnCompletion(t *testing.T) {
+	completed := false
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1", Completed: completed}, nil
+		},
+	}
+	notifier := &fakeNotifier{}
+	svc := _service.NewTaskService(repo,
+		_service.WithWatchers(&fakeWatcherRepo{recipients: []string{"user-1", "user-2"}}),
+		_service.WithNotifier(notifier),
+	)
+	ctx := context.Background()
+	done := true
+
+	if _, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{Completed: &done}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected one event, got %+v", notifier.events)
+	}
+	ev := notifier.events[0]
+	if ev.Type != domain.NotifyCompleted || ev.TaskID != "task-1" || ev.ActorID != "user-1" ||
+		!reflect.DeepEqual(ev.Recipients, []string{"user-2"}) {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	// Completing an already completed task is not news.
+	completed = true
+	if _, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{Completed: &done}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected no new event, got %+v", notifier.events)
+	}
+}
+
+func TestTaskService_Create_NotifiesAssigneesButNotTheActor(t *testing.T) {
+	repo := &fakeTaskRepo{
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+			return domain.Task{ID: "task-1", ProjectID: projectID, CustomFields: in.CustomFields}, nil
+		},
+	}
+	notifier := &fakeNotifier{}
+	svc := _service.NewTaskService(repo,
+		_service.WithCustomFields(projectFields()),
+		_service.WithNotifier(notifier),
+	)
+	ctx := context.Background()
+	assign := map[string]json.RawMessage{"owner": json.RawMessage(`"` + userFieldValue + `"`)}
+
+	if _, err := svc.Create(ctx, "user-1", "proj-1", domain.TaskInput{Title: "t", CustomFields: assign}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected one event, got %+v", notifier.events)
+	}
+	ev := notifier.events[0]
+	if ev.Type != domain.NotifyAssigned || !reflect.DeepEqual(ev.Recipients, []string{userFieldValue}) {
+		t.Fatalf("unexpected event %+v", ev)
+	}
+
+	// Assigning yourself notifies nobody.
+	if _, err := svc.Create(ctx, userFieldValue, "proj-1", domain.TaskInput{Title: "t", CustomFields: assign}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected no new event, got %+v", notifier.events)
+	}
+}
+
+func TestTaskService_Update_NotifyFailureKeepsCompletion(t *testing.T) {
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	task := domain.Task{ID: "task-1", DueDate: &due, Recurrence: &domain.Recurrence{
+		Rule: "FREQ=DAILY", Timezone: "UTC", RegenerateFrom: domain.FromScheduled, Occurrence: 1,
+	}}
+	repo, created := recurringRepo(task)
+	notifier := &fakeNotifier{err: errors.New("notifications down")}
+	svc := _service.NewTaskService(repo,
+		_service.WithWatchers(&fakeWatcherRepo{recipients: []string{"user-2"}}),
+		_service.WithNotifier(notifier),
+	)
+	done := true
+
+	got, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Completed: &done})
+	if err != nil {
+		t.Fatalf("expected the committed update to succeed, got %v", err)
+	}
+	if len(notifier.events) != 1 {
+		t.Fatalf("expected a notification attempt, got %+v", notifier.events)
+	}
+	if len(*created) != 1 || got.Recurrence.NextTaskID == nil {
+		t.Fatalf("expected the next occurrence to be scheduled, got %v (%#v)", *created, got.Recurrence)
+	}
+}
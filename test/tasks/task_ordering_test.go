@@ -0,0 +1,93 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func strPtr(s string) *string { return &s }
+
+func TestTaskService_Move_ValidatesNeighbors(t *testing.T) {
+	called := false
+	repo := &fakeTaskRepo{
+		moveFn: func(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error) {
+			called = true
+			return domain.Task{}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	cases := []struct {
+		name          string
+		after, before *string
+		field         string
+	}{
+		{"no neighbors", nil, nil, "afterId"},
+		{"after itself", strPtr("task-1"), nil, "afterId"},
+		{"before itself", nil, strPtr("task-1"), "beforeId"},
+	}
+	for _, c := range cases {
+		_, err := svc.Move(context.Background(), "user-1", "task-1", c.after, c.before)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected validation error on %s, got %v", c.name, c.field, err)
+		}
+	}
+	if called {
+		t.Fatal("repo should not be called for invalid input")
+	}
+}
+
+func TestTaskService_Move_MapsRepoErrors(t *testing.T) {
+	var repoErr error
+	repo := &fakeTaskRepo{
+		moveFn: func(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error) {
+			return domain.Task{}, repoErr
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	repoErr = sql.ErrNoRows
+	if _, err := svc.Move(context.Background(), "user-1", "task-1", strPtr("task-2"), nil); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	repoErr = domain.ErrInvalidNeighbor
+	_, err := svc.Move(context.Background(), "user-1", "task-1", nil, strPtr("task-2"))
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "beforeId" {
+		t.Fatalf("expected validation error on beforeId, got %v", err)
+	}
+}
+
+func TestTaskService_RebalancePositions_SkipsFailuresAndDeletedProjects(t *testing.T) {
+	boom := errors.New("boom")
+	var seen []string
+	repo := &fakeTaskRepo{
+		toRebalance: []string{"p1", "p2", "p3", "p4"},
+		rebalanceFn: func(ctx context.Context, projectID string) error {
+			seen = append(seen, projectID)
+			switch projectID {
+			case "p2":
+				return boom
+			case "p3":
+				return sql.ErrNoRows
+			}
+			return nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	n, err := svc.RebalancePositions(context.Background())
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected first error to be reported, got %v", err)
+	}
+	if n != 2 || len(seen) != 4 {
+		t.Fatalf("expected 2 fixed of 4 tried, got %d of %v", n, seen)
+	}
+}
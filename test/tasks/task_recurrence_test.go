@@ -0,0 +1,133 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func TestTaskService_Create_NormalizesRecurrence(t *testing.T) {
+	var got *domain.Recurrence
+	repo := &fakeTaskRepo{
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+			got = in.Recurrence
+			return domain.Task{}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	rec := &domain.Recurrence{Rule: "freq=weekly;byday=fr"}
+	if _, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "Report", Recurrence: rec}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.Rule != "FREQ=WEEKLY;BYDAY=FR" || got.Timezone != "UTC" || got.RegenerateFrom != domain.FromScheduled || got.Occurrence != 1 {
+		t.Fatalf("unexpected normalized recurrence: %#v", got)
+	}
+
+	for _, bad := range []domain.Recurrence{
+		{Rule: "FREQ=HOURLY"},
+		{Rule: "FREQ=DAILY", Timezone: "Mars/Olympus"},
+		{Rule: "FREQ=DAILY", RegenerateFrom: "whenever"},
+	} {
+		bad := bad
+		_, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "x", Recurrence: &bad})
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) {
+			t.Fatalf("%#v: expected validation error, got %v", bad, err)
+		}
+	}
+}
+
+// recurringRepo serves a single recurring task and records the next
+// occurrence requested for it.
+func recurringRepo(task domain.Task) (*fakeTaskRepo, *[]time.Time) {
+	var created []time.Time
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return task, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			out := task
+			rec := *task.Recurrence
+			out.Recurrence = &rec
+			out.Completed = *patch.Completed
+			return out, nil
+		},
+		nextFn: func(ctx context.Context, userID, prevID string, due *time.Time, occurrence int) (domain.Task, error) {
+			created = append(created, *due)
+			return domain.Task{ID: "next-1"}, nil
+		},
+	}
+	return repo, &created
+}
+
+func TestTaskService_Update_CompletingRecurringTaskSchedulesNext(t *testing.T) {
+	berlin, _ := time.LoadLocation("Europe/Berlin")
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, berlin) // a Monday
+	completedAt := time.Date(2026, 3, 5, 16, 30, 0, 0, berlin)
+	done := true
+
+	cases := []struct {
+		from domain.RegenerateFrom
+		want time.Time
+	}{
+		{domain.FromScheduled, time.Date(2026, 3, 9, 9, 0, 0, 0, berlin)},
+		{domain.FromCompletion, time.Date(2026, 3, 12, 9, 0, 0, 0, berlin)},
+	}
+	for _, c := range cases {
+		task := domain.Task{ID: "task-1", DueDate: &due, Recurrence: &domain.Recurrence{
+			Rule: "FREQ=WEEKLY", Timezone: "Europe/Berlin", RegenerateFrom: c.from, Occurrence: 1,
+		}}
+		repo, created := recurringRepo(task)
+		svc := _service.NewTaskService(repo, _service.WithClock(func() time.Time { return completedAt }))
+
+		got, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Completed: &done})
+		if err != nil {
+			t.Fatalf("%s: expected nil err, got %v", c.from, err)
+		}
+		if len(*created) != 1 || !(*created)[0].Equal(c.want) {
+			t.Fatalf("%s: expected next occurrence at %s, got %v", c.from, c.want, *created)
+		}
+		if got.Recurrence.NextTaskID == nil || *got.Recurrence.NextTaskID != "next-1" {
+			t.Fatalf("%s: expected nextTaskId in response, got %#v", c.from, got.Recurrence)
+		}
+	}
+}
+
+func TestTaskService_Update_RecurrenceStopsAtCount(t *testing.T) {
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	done := true
+	task := domain.Task{ID: "task-1", DueDate: &due, Recurrence: &domain.Recurrence{
+		Rule: "FREQ=DAILY;COUNT=3", Timezone: "UTC", RegenerateFrom: domain.FromScheduled, Occurrence: 3,
+	}}
+	repo, created := recurringRepo(task)
+	svc := _service.NewTaskService(repo)
+
+	if _, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Completed: &done}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(*created) != 0 {
+		t.Fatalf("expected no occurrence after the last one, got %v", *created)
+	}
+}
+
+func TestTaskService_Update_AlreadyCompletedDoesNotRegenerate(t *testing.T) {
+	due := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	done := true
+	task := domain.Task{ID: "task-1", Completed: true, DueDate: &due, Recurrence: &domain.Recurrence{
+		Rule: "FREQ=DAILY", Timezone: "UTC", RegenerateFrom: domain.FromScheduled, Occurrence: 1,
+	}}
+	repo, created := recurringRepo(task)
+	svc := _service.NewTaskService(repo)
+
+	if _, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Completed: &done}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(*created) != 0 {
+		t.Fatalf("re-completing a completed task must not create an occurrence, got %v", *created)
+	}
+}
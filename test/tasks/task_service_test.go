@@ -4,6 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
@@ -12,26 +14,39 @@ import (
 )
 
 type fakeTaskRepo struct {
-	createFn func(ctx context.Context, userID, projectID, title string) (domain.Task, error)
-	listFn   func(ctx context.Context, userID, projectID string, completed *bool, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error)
+	createFn func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error)
+	listFn   func(ctx context.Context, userID string, f domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error)
 	getFn    func(ctx context.Context, userID, taskID string) (domain.Task, error)
-	updateFn func(ctx context.Context, userID, taskID string, title *string, completed *bool) (domain.Task, error)
-	deleteFn func(ctx context.Context, userID, taskID string) error
+	keyFn    func(ctx context.Context, userID, projectKey string, number int) (domain.Task, error)
+	updateFn func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error)
+	deleteFn func(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error
 
-	lastListLimit int
+	ancestorsFn func(ctx context.Context, userID, taskID string) ([]string, error)
+	subtreeFn   func(ctx context.Context, userID, taskID string) ([]domain.Task, error)
+	nextFn      func(ctx context.Context, userID, prevID string, due *time.Time, occurrence int) (domain.Task, error)
+	moveFn      func(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error)
+	toRebalance []string
+	rebalanceFn func(ctx context.Context, projectID string) error
+	boardFn     func(ctx context.Context, userID, projectID string, q domain.BoardQuery) ([]domain.BoardColumn, error)
+	burndownFn  func(ctx context.Context, userID, projectID string, q domain.BurndownQuery) (domain.Burndown, error)
+	archivedFn  func(ctx context.Context, userID, projectID string) (bool, error)
+
+	lastListLimit  int
+	lastListFilter domain.TaskFilter
 }
 
-func (f *fakeTaskRepo) Create(ctx context.Context, userID, projectID, title string) (domain.Task, error) {
+func (f *fakeTaskRepo) Create(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
 	if f.createFn != nil {
-		return f.createFn(ctx, userID, projectID, title)
+		return f.createFn(ctx, userID, projectID, in)
 	}
 	return domain.Task{}, nil
 }
 
-func (f *fakeTaskRepo) List(ctx context.Context, userID, projectID string, completed *bool, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
+func (f *fakeTaskRepo) List(ctx context.Context, userID string, filter domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
 	f.lastListLimit = limit
+	f.lastListFilter = filter
 	if f.listFn != nil {
-		return f.listFn(ctx, userID, projectID, completed, limit, cursor)
+		return f.listFn(ctx, userID, filter, sort, limit, cursor)
 	}
 	return nil, nil, nil
 }
@@ -43,25 +58,93 @@ func (f *fakeTaskRepo) Get(ctx context.Context, userID, taskID string) (domain.T
 	return domain.Task{}, nil
 }
 
-func (f *fakeTaskRepo) Update(ctx context.Context, userID, taskID string, title *string, completed *bool) (domain.Task, error) {
+// GetByKey falls back to getFn, since IDs such as task-1 parse as keys.
+func (f *fakeTaskRepo) GetByKey(ctx context.Context, userID, projectKey string, number int) (domain.Task, error) {
+	if f.keyFn != nil {
+		return f.keyFn(ctx, userID, projectKey, number)
+	}
+	return f.Get(ctx, userID, fmt.Sprintf("%s-%d", projectKey, number))
+}
+
+func (f *fakeTaskRepo) Update(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
 	if f.updateFn != nil {
-		return f.updateFn(ctx, userID, taskID, title, completed)
+		return f.updateFn(ctx, userID, taskID, patch)
 	}
 	return domain.Task{}, nil
 }
 
-func (f *fakeTaskRepo) Delete(ctx context.Context, userID, taskID string) error {
+func (f *fakeTaskRepo) Delete(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error {
 	if f.deleteFn != nil {
-		return f.deleteFn(ctx, userID, taskID)
+		return f.deleteFn(ctx, userID, taskID, children)
+	}
+	return nil
+}
+
+func (f *fakeTaskRepo) Ancestors(ctx context.Context, userID, taskID string) ([]string, error) {
+	if f.ancestorsFn != nil {
+		return f.ancestorsFn(ctx, userID, taskID)
+	}
+	return nil, nil
+}
+
+func (f *fakeTaskRepo) Subtree(ctx context.Context, userID, taskID string) ([]domain.Task, error) {
+	if f.subtreeFn != nil {
+		return f.subtreeFn(ctx, userID, taskID)
+	}
+	return []domain.Task{{ID: taskID}}, nil
+}
+
+func (f *fakeTaskRepo) CreateNextOccurrence(ctx context.Context, userID, prevID string, due *time.Time, occurrence int) (domain.Task, error) {
+	if f.nextFn != nil {
+		return f.nextFn(ctx, userID, prevID, due, occurrence)
+	}
+	return domain.Task{}, sql.ErrNoRows
+}
+
+func (f *fakeTaskRepo) Move(ctx context.Context, userID, taskID string, afterID, beforeID *string) (domain.Task, error) {
+	if f.moveFn != nil {
+		return f.moveFn(ctx, userID, taskID, afterID, beforeID)
+	}
+	return domain.Task{ID: taskID}, nil
+}
+
+func (f *fakeTaskRepo) ProjectsToRebalance(ctx context.Context, maxLen, limit int) ([]string, error) {
+	return f.toRebalance, nil
+}
+
+func (f *fakeTaskRepo) RebalancePositions(ctx context.Context, projectID string) error {
+	if f.rebalanceFn != nil {
+		return f.rebalanceFn(ctx, projectID)
 	}
 	return nil
 }
 
+func (f *fakeTaskRepo) Board(ctx context.Context, userID, projectID string, q domain.BoardQuery) ([]domain.BoardColumn, error) {
+	if f.boardFn != nil {
+		return f.boardFn(ctx, userID, projectID, q)
+	}
+	return nil, nil
+}
+
+func (f *fakeTaskRepo) Burndown(ctx context.Context, userID, projectID string, q domain.BurndownQuery) (domain.Burndown, error) {
+	if f.burndownFn != nil {
+		return f.burndownFn(ctx, userID, projectID, q)
+	}
+	return domain.Burndown{}, nil
+}
+
+func (f *fakeTaskRepo) ProjectArchived(ctx context.Context, userID, projectID string) (bool, error) {
+	if f.archivedFn != nil {
+		return f.archivedFn(ctx, userID, projectID)
+	}
+	return false, nil
+}
+
 func TestTaskService_Create_RejectsEmptyTitle(t *testing.T) {
 	repo := &fakeTaskRepo{}
 	svc := _service.NewTaskService(repo)
 
-	_, err := svc.Create(context.Background(), "user-1", "proj-1", "   ")
+	_, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "   "})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -72,13 +155,13 @@ func TestTaskService_Create_RejectsEmptyTitle(t *testing.T) {
 
 func TestTaskService_Create_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
 	repo := &fakeTaskRepo{
-		createFn: func(ctx context.Context, userID, projectID, title string) (domain.Task, error) {
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
 			return domain.Task{}, sql.ErrNoRows
 		},
 	}
 	svc := _service.NewTaskService(repo)
 
-	_, err := svc.Create(context.Background(), "user-1", "proj-1", "hello")
+	_, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "hello"})
 	if !errors.Is(err, _service.ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -86,13 +169,13 @@ func TestTaskService_Create_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
 
 func TestTaskService_List_ClampsLimit_Defaults20_AndMax100(t *testing.T) {
 	repo := &fakeTaskRepo{
-		listFn: func(ctx context.Context, userID, projectID string, completed *bool, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
+		listFn: func(ctx context.Context, userID string, f domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
 			return []domain.Task{}, nil, nil
 		},
 	}
 	svc := _service.NewTaskService(repo)
 
-	_, err := svc.List(context.Background(), "user-1", "proj-1", nil, 0, nil)
+	_, err := svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "proj-1"}, nil, 0, nil)
 	if err != nil {
 		t.Fatalf("expected nil err, got %v", err)
 	}
@@ -100,7 +183,7 @@ func TestTaskService_List_ClampsLimit_Defaults20_AndMax100(t *testing.T) {
 		t.Fatalf("expected limit=20, got %d", repo.lastListLimit)
 	}
 
-	_, err = svc.List(context.Background(), "user-1", "proj-1", nil, 999, nil)
+	_, err = svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "proj-1"}, nil, 999, nil)
 	if err != nil {
 		t.Fatalf("expected nil err, got %v", err)
 	}
@@ -114,7 +197,7 @@ func TestTaskService_Update_RejectsEmptyTitleWhenProvided(t *testing.T) {
 	svc := _service.NewTaskService(repo)
 
 	empty := "   "
-	_, err := svc.Update(context.Background(), "user-1", "task-1", &empty, nil)
+	_, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Title: &empty})
 	if err == nil {
 		t.Fatal("expected error, got nil")
 	}
@@ -137,15 +220,71 @@ func TestTaskService_Get_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
 	}
 }
 
+func TestTaskService_Get_ResolvesTaskKey(t *testing.T) {
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID}, nil
+		},
+		keyFn: func(ctx context.Context, userID, projectKey string, number int) (domain.Task, error) {
+			if projectKey != "WEB" || number != 123 {
+				t.Fatalf("unexpected key %s-%d", projectKey, number)
+			}
+			return domain.Task{ID: "task-1"}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+	ctx := context.Background()
+
+	if got, err := svc.Get(ctx, "user-1", "web-123"); err != nil || got.ID != "task-1" {
+		t.Fatalf("expected the keyed task, got %+v (%v)", got, err)
+	}
+	id := "6f1c2a4e-8d3b-4c7e-9a1f-2b3c4d5e6f70"
+	if got, err := svc.Get(ctx, "user-1", id); err != nil || got.ID != id {
+		t.Fatalf("expected a UUID lookup, got %+v (%v)", got, err)
+	}
+	repo.keyFn = func(ctx context.Context, userID, projectKey string, number int) (domain.Task, error) {
+		return domain.Task{}, sql.ErrNoRows
+	}
+	if _, err := svc.Get(ctx, "user-1", "WEB-999"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestParseTaskKey(t *testing.T) {
+	tests := []struct {
+		in     string
+		key    string
+		number int
+		ok     bool
+	}{
+		{"WEB-123", "WEB", 123, true},
+		{"web2-7", "WEB2", 7, true},
+		{"WEB-0", "", 0, false},
+		{"WEB-012", "", 0, false},
+		{"WEB-", "", 0, false},
+		{"W-1", "", 0, false},
+		{"2WEB-1", "", 0, false},
+		{"WEB-1x", "", 0, false},
+		{"WEB-1234567890", "", 0, false},
+		{"6f1c2a4e-8d3b-4c7e-9a1f-2b3c4d5e6f70", "", 0, false},
+	}
+	for _, tt := range tests {
+		key, number, ok := domain.ParseTaskKey(tt.in)
+		if key != tt.key || number != tt.number || ok != tt.ok {
+			t.Errorf("ParseTaskKey(%q) = %q, %d, %v; want %q, %d, %v", tt.in, key, number, ok, tt.key, tt.number, tt.ok)
+		}
+	}
+}
+
 func TestTaskService_Delete_MapsSqlNoRows_ToErrNotFound(t *testing.T) {
 	repo := &fakeTaskRepo{
-		deleteFn: func(ctx context.Context, userID, taskID string) error {
+		deleteFn: func(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error {
 			return sql.ErrNoRows
 		},
 	}
 	svc := _service.NewTaskService(repo)
 
-	err := svc.Delete(context.Background(), "user-1", "task-1")
+	err := svc.Delete(context.Background(), "user-1", "task-1", "")
 	if !errors.Is(err, _service.ErrNotFound) {
 		t.Fatalf("expected ErrNotFound, got %v", err)
 	}
@@ -157,13 +296,13 @@ func TestTaskService_List_ReturnsNextCursor(t *testing.T) {
 	next := &domain.Cursor{CreatedAt: now, ID: "x"}
 
 	repo := &fakeTaskRepo{
-		listFn: func(ctx context.Context, userID, projectID string, completed *bool, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
+		listFn: func(ctx context.Context, userID string, f domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
 			return []domain.Task{{ID: "t1", ProjectID: "p1", Title: "a"}}, next, nil
 		},
 	}
 	svc := _service.NewTaskService(repo)
 
-	page, err := svc.List(context.Background(), "user-1", "p1", nil, 10, nil)
+	page, err := svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "p1"}, nil, 10, nil)
 	if err != nil {
 		t.Fatalf("expected nil err, got %v", err)
 	}
@@ -174,3 +313,332 @@ func TestTaskService_List_ReturnsNextCursor(t *testing.T) {
 		t.Fatalf("expected next cursor, got %#v", page.NextCursor)
 	}
 }
+
+func TestTaskService_Create_RejectsUnknownPriority(t *testing.T) {
+	repo := &fakeTaskRepo{}
+	svc := _service.NewTaskService(repo)
+
+	_, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "a", Priority: domain.Priority(9)})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "priority" {
+		t.Fatalf("expected priority validation error, got %v", err)
+	}
+}
+
+func TestTaskService_List_RejectsCursorFromDifferentSort(t *testing.T) {
+	repo := &fakeTaskRepo{}
+	svc := _service.NewTaskService(repo)
+
+	sort, err := domain.ParseTaskSort("-priority,dueDate")
+	if err != nil {
+		t.Fatalf("parse sort: %v", err)
+	}
+	cursor := &domain.Cursor{CreatedAt: time.Now(), ID: "x", Sort: "title", Values: []string{"a"}}
+
+	_, err = svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "p1"}, sort, 10, cursor)
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "cursor" {
+		t.Fatalf("expected cursor validation error, got %v", err)
+	}
+	if repo.lastListLimit != 0 {
+		t.Fatalf("repo should not be called with a mismatched cursor")
+	}
+}
+
+func TestTaskService_List_RejectsCursorValuesOfWrongType(t *testing.T) {
+	const id = "6f1c2a0e-1b1e-4c3f-9a57-0c4a7f5e2d11"
+	cases := []struct {
+		name, sort string
+		id         string
+		values     []string
+		ok         bool
+	}{
+		{"valid", "-priority,dueDate", id, []string{"3", "2024-05-01T10:00:00Z"}, true},
+		{"missing due date", "-priority,dueDate", id, []string{"3", "infinity"}, true},
+		{"any title", "title", id, []string{"anything at all"}, true},
+		{"priority not a number", "-priority,dueDate", id, []string{"high", "infinity"}, false},
+		{"due date not a time", "-priority,dueDate", id, []string{"3", "tomorrow"}, false},
+		{"bad updatedAt", "updatedAt", id, []string{"2024-05-01"}, false},
+		{"title with NUL", "title", id, []string{"a\x00b"}, false},
+		{"id not a uuid", "title", "x", []string{"a"}, false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := &fakeTaskRepo{}
+			svc := _service.NewTaskService(repo)
+			sort, err := domain.ParseTaskSort(tc.sort)
+			if err != nil {
+				t.Fatalf("parse sort: %v", err)
+			}
+			cursor := &domain.Cursor{CreatedAt: time.Now(), ID: tc.id, Sort: domain.FormatSort(sort), Values: tc.values}
+
+			_, err = svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "p1"}, sort, 10, cursor)
+			if tc.ok {
+				if err != nil {
+					t.Fatalf("expected nil err, got %v", err)
+				}
+				return
+			}
+			var ve *_service.ValidationError
+			if !errors.As(err, &ve) || ve.Field != "cursor" {
+				t.Fatalf("expected cursor validation error, got %v", err)
+			}
+			if repo.lastListLimit != 0 {
+				t.Fatalf("repo should not be called with a malformed cursor")
+			}
+		})
+	}
+}
+
+func TestParseTaskSort(t *testing.T) {
+	keys, err := domain.ParseTaskSort("-priority, dueDate")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	want := []domain.SortKey{{Field: "priority", Desc: true}, {Field: "dueDate"}}
+	if len(keys) != len(want) || keys[0] != want[0] || keys[1] != want[1] {
+		t.Fatalf("unexpected keys: %#v", keys)
+	}
+	if got := domain.FormatSort(keys); got != "-priority,dueDate" {
+		t.Fatalf("expected canonical form, got %q", got)
+	}
+
+	if _, err := domain.ParseTaskSort("-owner"); err == nil {
+		t.Fatal("expected error for unknown field")
+	}
+	if _, err := domain.ParseTaskSort("title,-title"); err == nil {
+		t.Fatal("expected error for duplicate field")
+	}
+}
+
+func TestCursorToken_RoundTrip(t *testing.T) {
+	c := domain.Cursor{CreatedAt: time.Now().UTC(), ID: "x", Sort: "-priority", Values: []string{"4"}}
+
+	got, err := domain.ParseCursorToken(c.Token())
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if !got.CreatedAt.Equal(c.CreatedAt) || got.ID != c.ID || got.Sort != c.Sort || len(got.Values) != 1 || got.Values[0] != "4" {
+		t.Fatalf("round trip mismatch: %#v", got)
+	}
+
+	if _, err := domain.ParseCursorToken("not a token"); err == nil {
+		t.Fatal("expected error for garbage token")
+	}
+}
+
+func TestTaskService_Create_RejectsOversizedDescription(t *testing.T) {
+	repo := &fakeTaskRepo{}
+	svc := _service.NewTaskService(repo)
+
+	long := strings.Repeat("a", _service.MaxDescriptionLength+1)
+	_, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "a", Description: long})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "description" {
+		t.Fatalf("expected description validation error, got %v", err)
+	}
+}
+
+func TestTaskService_Update_ValidatesDescription(t *testing.T) {
+	var got domain.TaskPatch
+	repo := &fakeTaskRepo{
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			got = patch
+			return domain.Task{}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	bad := "nul\x00byte"
+	_, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Description: &bad})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "description" {
+		t.Fatalf("expected description validation error, got %v", err)
+	}
+
+	// An empty description is allowed and clears it.
+	empty := ""
+	if _, err := svc.Update(context.Background(), "user-1", "task-1", domain.TaskPatch{Description: &empty}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.Description == nil || *got.Description != "" {
+		t.Fatalf("expected empty description to reach repo, got %#v", got.Description)
+	}
+}
+
+// hierarchy is a fake tree: parent links by task id, all in project p1.
+func hierarchyRepo(parents map[string]string) *fakeTaskRepo {
+	return &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			if _, ok := parents[taskID]; !ok {
+				return domain.Task{}, sql.ErrNoRows
+			}
+			return domain.Task{ID: taskID, ProjectID: "p1"}, nil
+		},
+		ancestorsFn: func(ctx context.Context, userID, taskID string) ([]string, error) {
+			var out []string
+			for p := parents[taskID]; p != ""; p = parents[p] {
+				out = append(out, p)
+			}
+			return out, nil
+		},
+		subtreeFn: func(ctx context.Context, userID, taskID string) ([]domain.Task, error) {
+			out := []domain.Task{{ID: taskID}}
+			for i := 0; i < len(out); i++ {
+				for id, p := range parents {
+					if p == out[i].ID {
+						parent := p
+						out = append(out, domain.Task{ID: id, ParentTaskID: &parent})
+					}
+				}
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestTaskService_Create_RejectsNestingBeyondMaxDepth(t *testing.T) {
+	// root <- a <- b
+	repo := hierarchyRepo(map[string]string{"root": "", "a": "root", "b": "a"})
+	svc := _service.NewTaskService(repo, _service.WithMaxDepth(2))
+
+	parent := "a"
+	if _, err := svc.Create(context.Background(), "user-1", "p1", domain.TaskInput{Title: "ok", ParentTaskID: &parent}); err != nil {
+		t.Fatalf("expected depth 2 to be allowed, got %v", err)
+	}
+
+	parent = "b"
+	_, err := svc.Create(context.Background(), "user-1", "p1", domain.TaskInput{Title: "too deep", ParentTaskID: &parent})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "parentTaskId" {
+		t.Fatalf("expected parentTaskId validation error, got %v", err)
+	}
+}
+
+func TestTaskService_Create_RejectsParentInOtherProject(t *testing.T) {
+	repo := hierarchyRepo(map[string]string{"root": ""})
+	svc := _service.NewTaskService(repo)
+
+	parent := "root"
+	_, err := svc.Create(context.Background(), "user-1", "other-project", domain.TaskInput{Title: "x", ParentTaskID: &parent})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "parentTaskId" {
+		t.Fatalf("expected parentTaskId validation error, got %v", err)
+	}
+}
+
+func TestTaskService_Update_ChecksReparentInRepo(t *testing.T) {
+	var got domain.TaskPatch
+	repo := &fakeTaskRepo{
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			got = patch
+			return domain.Task{}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithMaxDepth(3))
+
+	parent := "y"
+	if _, err := svc.Update(context.Background(), "user-1", "a", domain.TaskPatch{ParentTaskID: &parent}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.MaxDepth != 3 {
+		t.Fatalf("expected the depth limit passed to the repo, got %d", got.MaxDepth)
+	}
+
+	// A task cannot be its own parent, which needs no lookup.
+	got = domain.TaskPatch{}
+	self := "a"
+	_, err := svc.Update(context.Background(), "user-1", "a", domain.TaskPatch{ParentTaskID: &self})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "parentTaskId" {
+		t.Fatalf("expected parentTaskId validation error, got %v", err)
+	}
+	if got.ParentTaskID != nil {
+		t.Fatalf("repo should not be called for a self-parent")
+	}
+}
+
+func TestTaskService_Update_MapsReparentErrors(t *testing.T) {
+	for _, repoErr := range []error{domain.ErrInvalidParent, domain.ErrParentCycle, domain.ErrTooDeep} {
+		t.Run(repoErr.Error(), func(t *testing.T) {
+			repo := &fakeTaskRepo{
+				updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+					return domain.Task{}, repoErr
+				},
+			}
+			svc := _service.NewTaskService(repo)
+
+			parent := "b"
+			_, err := svc.Update(context.Background(), "user-1", "a", domain.TaskPatch{ParentTaskID: &parent})
+			var ve *_service.ValidationError
+			if !errors.As(err, &ve) || ve.Field != "parentTaskId" {
+				t.Fatalf("expected parentTaskId validation error, got %v", err)
+			}
+		})
+	}
+}
+
+func TestTaskService_Subtree_NestsChildren(t *testing.T) {
+	repo := hierarchyRepo(map[string]string{"root": "", "a": "root", "b": "a", "c": "root"})
+	svc := _service.NewTaskService(repo)
+
+	tree, err := svc.Subtree(context.Background(), "user-1", "root")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if tree.ID != "root" || len(tree.Subtasks) != 2 {
+		t.Fatalf("unexpected root: %#v", tree)
+	}
+	var a domain.TaskNode
+	for _, n := range tree.Subtasks {
+		if n.ID == "a" {
+			a = n
+		}
+	}
+	if len(a.Subtasks) != 1 || a.Subtasks[0].ID != "b" {
+		t.Fatalf("expected b under a, got %#v", a)
+	}
+}
+
+func TestTaskService_Delete_ValidatesChildPolicy(t *testing.T) {
+	var got domain.ChildPolicy
+	repo := &fakeTaskRepo{
+		deleteFn: func(ctx context.Context, userID, taskID string, children domain.ChildPolicy) error {
+			got = children
+			return nil
+		},
+	}
+	svc := _service.NewTaskService(repo)
+
+	if err := svc.Delete(context.Background(), "user-1", "task-1", ""); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got != domain.ChildrenCascade {
+		t.Fatalf("expected cascade by default, got %q", got)
+	}
+
+	err := svc.Delete(context.Background(), "user-1", "task-1", "orphan")
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "children" {
+		t.Fatalf("expected children validation error, got %v", err)
+	}
+}
+
+func TestTaskService_List_DefaultsLabelMatchToAny(t *testing.T) {
+	repo := &fakeTaskRepo{}
+	svc := _service.NewTaskService(repo)
+
+	_, err := svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "p1", Labels: []string{"bug"}}, nil, 10, nil)
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if repo.lastListFilter.LabelMatch != domain.LabelMatchAny {
+		t.Fatalf("expected labelMatch=any, got %q", repo.lastListFilter.LabelMatch)
+	}
+
+	_, err = svc.List(context.Background(), "user-1", domain.TaskFilter{ProjectID: "p1", LabelMatch: "some"}, nil, 10, nil)
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "labelMatch" {
+		t.Fatalf("expected labelMatch validation error, got %v", err)
+	}
+}
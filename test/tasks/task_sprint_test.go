@@ -0,0 +1,95 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+// fakeSprintRepo only serves Get; the task service looks nothing else up.
+type fakeSprintRepo struct {
+	_service.SprintRepo
+	sprints map[string]domain.Sprint
+}
+
+func (f *fakeSprintRepo) Get(ctx context.Context, userID, sprintID string) (domain.Sprint, error) {
+	sp, ok := f.sprints[sprintID]
+	if !ok {
+		return domain.Sprint{}, sql.ErrNoRows
+	}
+	return sp, nil
+}
+
+func sprintFixtures() *fakeSprintRepo {
+	done := time.Date(2026, 2, 27, 17, 0, 0, 0, time.UTC)
+	return &fakeSprintRepo{sprints: map[string]domain.Sprint{
+		"s1":    {ID: "s1", ProjectID: "proj-1"},
+		"s2":    {ID: "s2", ProjectID: "proj-2"},
+		"done":  {ID: "done", ProjectID: "proj-1", CompletedAt: &done},
+		"other": {ID: "other", ProjectID: "proj-9"},
+	}}
+}
+
+func TestTaskService_Create_ChecksSprint(t *testing.T) {
+	var got domain.TaskInput
+	repo := &fakeTaskRepo{
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+			got = in
+			return domain.Task{ID: "task-1", SprintID: in.SprintID}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithSprints(sprintFixtures()))
+	ctx := context.Background()
+
+	for _, id := range []string{"missing", "other", "done", ""} {
+		_, err := svc.Create(ctx, "user-1", "proj-1", domain.TaskInput{Title: "t", SprintID: strPtr(id)})
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != "sprintId" {
+			t.Fatalf("sprint %q: expected sprintId validation error, got %v", id, err)
+		}
+	}
+
+	if _, err := svc.Create(ctx, "user-1", "proj-1", domain.TaskInput{Title: "t", SprintID: strPtr("s1")}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.SprintID == nil || *got.SprintID != "s1" {
+		t.Fatalf("expected sprint to reach the repo, got %v", got.SprintID)
+	}
+}
+
+func TestTaskService_Update_ChecksSprintOfTargetProject(t *testing.T) {
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1"}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithSprints(sprintFixtures()))
+	ctx := context.Background()
+
+	if _, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{SprintID: strPtr("s1")}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+
+	// A sprint of the source project cannot come along on a move.
+	_, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{ProjectID: strPtr("proj-2"), SprintID: strPtr("s1")})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "sprintId" {
+		t.Fatalf("expected sprintId validation error, got %v", err)
+	}
+	if _, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{ProjectID: strPtr("proj-2"), SprintID: strPtr("s2")}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+}
+
+func TestTaskService_Sprint_RequiresRepo(t *testing.T) {
+	svc := _service.NewTaskService(&fakeTaskRepo{})
+
+	if _, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{Title: "t", SprintID: strPtr("s1")}); err == nil {
+		t.Fatal("expected an error without a sprint repo")
+	}
+}
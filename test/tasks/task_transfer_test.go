@@ -0,0 +1,63 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+func TestTaskService_Update_MoveToProject(t *testing.T) {
+	var got *domain.TaskPatch
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1"}, nil
+		},
+		updateFn: func(ctx context.Context, userID, taskID string, patch domain.TaskPatch) (domain.Task, error) {
+			got = &patch
+			if patch.ProjectID != nil && *patch.ProjectID == "missing" {
+				return domain.Task{}, domain.ErrInvalidProject
+			}
+			return domain.Task{ID: taskID}, nil
+		},
+	}
+	svc := _service.NewTaskService(repo, _service.WithCustomFields(projectFields()))
+	ctx := context.Background()
+
+	if _, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{ProjectID: strPtr("proj-1")}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.ProjectID != nil {
+		t.Fatalf("expected a move to the task's own project to be dropped, got %q", *got.ProjectID)
+	}
+
+	_, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{ProjectID: strPtr("")})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "projectId" {
+		t.Fatalf("expected projectId validation error, got %v", err)
+	}
+
+	_, err = svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{ProjectID: strPtr("missing")})
+	if !errors.As(err, &ve) || ve.Field != "projectId" || ve.Message != "not found" {
+		t.Fatalf("expected projectId not found, got %v", err)
+	}
+
+	// Custom field values in the same patch are checked against the target.
+	patch := domain.TaskPatch{
+		ProjectID:    strPtr("proj-2"),
+		CustomFields: map[string]json.RawMessage{"text": json.RawMessage(`"x"`)},
+	}
+	if _, err := svc.Update(ctx, "user-1", "task-1", patch); !errors.As(err, &ve) || ve.Field != "customFields.text" {
+		t.Fatalf("expected a field of the source project to be rejected, got %v", err)
+	}
+	patch.CustomFields = map[string]json.RawMessage{"other": json.RawMessage(`"x"`)}
+	if _, err := svc.Update(ctx, "user-1", "task-1", patch); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.ProjectID == nil || *got.ProjectID != "proj-2" {
+		t.Fatalf("expected the move to reach the repo, got %+v", got)
+	}
+}
@@ -0,0 +1,120 @@
+package tasks
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+// fakeWatcherRepo records subscriptions; the task service only subscribes
+// and asks for recipients.
+type fakeWatcherRepo struct {
+	_service.WatcherRepo
+	subs       map[domain.WatchReason][]string
+	recipients []string
+}
+
+func (f *fakeWatcherRepo) Subscribe(ctx context.Context, taskID string, userIDs []string, reason domain.WatchReason) error {
+	if f.subs == nil {
+		f.subs = map[domain.WatchReason][]string{}
+	}
+	f.subs[reason] = append(f.subs[reason], userIDs...)
+	return nil
+}
+
+func (f *fakeWatcherRepo) Recipients(ctx context.Context, taskID string) ([]string, error) {
+	return append([]string(nil), f.recipients...), nil
+}
+
+func TestTaskService_Create_SubscribesCreatorAndAssignees(t *testing.T) {
+	repo := &fakeTaskRepo{
+		createFn: func(ctx context.Context, userID, projectID string, in domain.TaskInput) (domain.Task, error) {
+			return domain.Task{ID: "task-1", ProjectID: projectID, CustomFields: in.CustomFields}, nil
+		},
+	}
+	watchers := &fakeWatcherRepo{}
+	svc := _service.NewTaskService(repo,
+		_service.WithCustomFields(projectFields()),
+		_service.WithWatchers(watchers),
+	)
+
+	_, err := svc.Create(context.Background(), "user-1", "proj-1", domain.TaskInput{
+		Title: "t",
+		CustomFields: map[string]json.RawMessage{
+			"owner": json.RawMessage(`"` + userFieldValue + `"`),
+			"text":  json.RawMessage(`"not a user"`),
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got := watchers.subs[domain.WatchCreator]; !reflect.DeepEqual(got, []string{"user-1"}) {
+		t.Fatalf("expected creator to be subscribed, got %v", got)
+	}
+	if got := watchers.subs[domain.WatchAssignee]; !reflect.DeepEqual(got, []string{userFieldValue}) {
+		t.Fatalf("expected assignee to be subscribed, got %v", got)
+	}
+}
+
+func TestTaskService_Update_SubscribesNewAssignees(t *testing.T) {
+	repo := &fakeTaskRepo{
+		getFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{ID: taskID, ProjectID: "proj-1"}, nil
+		},
+	}
+	watchers := &fakeWatcherRepo{}
+	svc := _service.NewTaskService(repo,
+		_service.WithCustomFields(projectFields()),
+		_service.WithWatchers(watchers),
+	)
+	ctx := context.Background()
+
+	// Clearing the field subscribes nobody.
+	_, err := svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{
+		CustomFields: map[string]json.RawMessage{"owner": json.RawMessage(`null`)},
+	})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(watchers.subs[domain.WatchAssignee]) != 0 {
+		t.Fatalf("expected no assignee, got %v", watchers.subs)
+	}
+
+	_, err = svc.Update(ctx, "user-1", "task-1", domain.TaskPatch{
+		CustomFields: map[string]json.RawMessage{"owner": json.RawMessage(`"` + userFieldValue + `"`)},
+	})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got := watchers.subs[domain.WatchAssignee]; !reflect.DeepEqual(got, []string{userFieldValue}) {
+		t.Fatalf("expected assignee to be subscribed, got %v", got)
+	}
+	if len(watchers.subs[domain.WatchCreator]) != 0 {
+		t.Fatalf("expected no creator subscription on update, got %v", watchers.subs)
+	}
+}
+
+func TestTaskService_Recipients_SkipsActor(t *testing.T) {
+	watchers := &fakeWatcherRepo{recipients: []string{"user-1", "user-2", "user-3"}}
+	svc := _service.NewTaskService(&fakeTaskRepo{}, _service.WithWatchers(watchers))
+
+	got, err := svc.Recipients(context.Background(), "task-1", "user-2")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"user-1", "user-3"}) {
+		t.Fatalf("unexpected recipients %v", got)
+	}
+
+	// Without watchers nobody is notified.
+	got, err = _service.NewTaskService(&fakeTaskRepo{}).Recipients(context.Background(), "task-1", "user-2")
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected no recipients, got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,280 @@
+package templates
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeTemplateRepo struct {
+	templates map[string]domain.Template
+	created   domain.Template
+}
+
+func (f *fakeTemplateRepo) Create(ctx context.Context, userID string, t domain.Template) (domain.Template, error) {
+	t.ID = "tmpl-new"
+	f.created = t
+	return t, nil
+}
+
+func (f *fakeTemplateRepo) List(ctx context.Context, userID string) ([]domain.Template, error) {
+	return nil, nil
+}
+
+func (f *fakeTemplateRepo) Get(ctx context.Context, userID, templateID string) (domain.Template, error) {
+	t, ok := f.templates[templateID]
+	if !ok {
+		return domain.Template{}, sql.ErrNoRows
+	}
+	return t, nil
+}
+
+func (f *fakeTemplateRepo) Delete(ctx context.Context, userID, templateID string) error { return nil }
+
+// The project, label and task fakes embed the repo interfaces and only
+// implement what templates use.
+type fakeProjectRepo struct {
+	_service.ProjectRepo
+	project   domain.Project
+	blueprint domain.ProjectBlueprint
+}
+
+func (f *fakeProjectRepo) Get(ctx context.Context, userID, projectID string) (domain.Project, error) {
+	if projectID != f.project.ID {
+		return domain.Project{}, sql.ErrNoRows
+	}
+	return f.project, nil
+}
+
+func (f *fakeProjectRepo) CreateFromBlueprint(ctx context.Context, userID string, bp domain.ProjectBlueprint) (domain.Project, error) {
+	f.blueprint = bp
+	return domain.Project{ID: "proj-new", Name: bp.Name}, nil
+}
+
+type fakeLabelRepo struct {
+	_service.LabelRepo
+	labels []domain.Label
+}
+
+func (f *fakeLabelRepo) List(ctx context.Context, userID, projectID string) ([]domain.Label, error) {
+	return f.labels, nil
+}
+
+type fakeTaskRepo struct {
+	_service.TaskRepo
+	tasks []domain.Task
+	pages int
+}
+
+// List serves tasks two at a time to exercise paging.
+func (f *fakeTaskRepo) List(ctx context.Context, userID string, filter domain.TaskFilter, sort []domain.SortKey, limit int, cursor *domain.Cursor) ([]domain.Task, *domain.Cursor, error) {
+	f.pages++
+	start := 0
+	if cursor != nil {
+		start = int(cursor.CreatedAt.Unix())
+	}
+	end := min(start+2, len(f.tasks))
+	var next *domain.Cursor
+	if end < len(f.tasks) {
+		next = &domain.Cursor{CreatedAt: time.Unix(int64(end), 0)}
+	}
+	return f.tasks[start:end], next, nil
+}
+
+func newService(repo *fakeTemplateRepo, projects *fakeProjectRepo, labels *fakeLabelRepo, tasks *fakeTaskRepo, opts ..._service.TemplateOption) *_service.TemplateService {
+	return _service.NewTemplateService(repo, projects, labels, tasks, opts...)
+}
+
+func intPtr(n int) *int { return &n }
+
+func TestTemplateService_Create_Validates(t *testing.T) {
+	svc := newService(&fakeTemplateRepo{}, &fakeProjectRepo{}, &fakeLabelRepo{}, &fakeTaskRepo{}, _service.WithTemplateMaxDepth(1))
+
+	base := func() domain.Template {
+		return domain.Template{
+			Name:        "Onboarding",
+			ProjectName: "Onboarding {{client}}",
+			Labels:      []domain.TemplateLabel{{Name: "legal", Color: "#112233"}},
+			Tasks:       []domain.TemplateTask{{Title: "Kickoff", Subtasks: []domain.TemplateTask{{Title: "Agenda"}}}},
+		}
+	}
+	cases := []struct {
+		name   string
+		mutate func(*domain.Template)
+		field  string
+	}{
+		{"blank name", func(t *domain.Template) { t.Name = " " }, "name"},
+		{"blank project name", func(t *domain.Template) { t.ProjectName = "" }, "projectName"},
+		{"bad unit", func(t *domain.Template) { t.EstimateUnit = "days" }, "estimateUnit"},
+		{"bad color", func(t *domain.Template) { t.Labels[0].Color = "red" }, "labels[0].color"},
+		{"duplicate label", func(t *domain.Template) {
+			t.Labels = append(t.Labels, domain.TemplateLabel{Name: "Legal", Color: "#000000"})
+		}, "labels[1].name"},
+		{"blank subtask title", func(t *domain.Template) { t.Tasks[0].Subtasks[0].Title = " " }, "tasks[0].subtasks[0].title"},
+		{"unknown label", func(t *domain.Template) { t.Tasks[0].Labels = []string{"finance"} }, "tasks[0].labels"},
+		{"due offset", func(t *domain.Template) { t.Tasks[0].DueInDays = intPtr(_service.MaxDueInDays + 1) }, "tasks[0].dueInDays"},
+		{"negative estimate", func(t *domain.Template) { e := -1.0; t.Tasks[0].Estimate = &e }, "tasks[0].estimate"},
+		{"too deep", func(t *domain.Template) {
+			t.Tasks[0].Subtasks[0].Subtasks = []domain.TemplateTask{{Title: "Deeper"}}
+		}, "tasks[0].subtasks[0].subtasks[0]"},
+		{"too many", func(t *domain.Template) {
+			t.Tasks = make([]domain.TemplateTask, _service.MaxTemplateTasks+1)
+			for i := range t.Tasks {
+				t.Tasks[i].Title = "x"
+			}
+		}, "tasks"},
+	}
+	for _, c := range cases {
+		tmpl := base()
+		c.mutate(&tmpl)
+		_, err := svc.Create(context.Background(), "user-1", tmpl)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected %s ValidationError, got %v", c.name, c.field, err)
+		}
+	}
+}
+
+func TestTemplateService_Create_NormalizesAndListsVariables(t *testing.T) {
+	repo := &fakeTemplateRepo{}
+	svc := newService(repo, &fakeProjectRepo{}, &fakeLabelRepo{}, &fakeTaskRepo{})
+
+	got, err := svc.Create(context.Background(), "user-1", domain.Template{
+		Name:        " Onboarding ",
+		ProjectName: "Onboarding {{ client }}",
+		Labels:      []domain.TemplateLabel{{Name: " Legal ", Color: "#AABBCC"}},
+		Tasks: []domain.TemplateTask{
+			{Title: "Contract for {{client}}", Labels: []string{"legal", "LEGAL"}},
+			{Title: "Intro call", Description: "Ask {{owner}} to join", Subtasks: []domain.TemplateTask{{Title: "Book {{room}}"}}},
+		},
+	})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	c := repo.created
+	if c.Name != "Onboarding" || c.EstimateUnit != domain.EstimatePoints || c.Labels[0].Name != "Legal" || c.Labels[0].Color != "#aabbcc" {
+		t.Fatalf("expected normalized template, got %+v", c)
+	}
+	if strings.Join(c.Tasks[0].Labels, ",") != "Legal" {
+		t.Fatalf("expected label references resolved and deduplicated, got %v", c.Tasks[0].Labels)
+	}
+	if strings.Join(got.Variables, ",") != "client,owner,room" {
+		t.Fatalf("expected variables in order of use, got %v", got.Variables)
+	}
+}
+
+func TestTemplateService_Instantiate_SubstitutesAndSchedules(t *testing.T) {
+	repo := &fakeTemplateRepo{templates: map[string]domain.Template{"tmpl-1": {
+		ID:           "tmpl-1",
+		ProjectName:  "Onboarding {{client}}",
+		EstimateUnit: domain.EstimateHours,
+		Labels:       []domain.TemplateLabel{{Name: "legal", Color: "#112233"}},
+		Tasks: []domain.TemplateTask{
+			{Title: "Contract for {{client}}", Priority: domain.PriorityHigh, DueInDays: intPtr(3), Labels: []string{"legal"},
+				Subtasks: []domain.TemplateTask{{Title: "Send to {{ client }}", DueInDays: intPtr(-1)}}},
+			{Title: "Retro"},
+		},
+	}}}
+	projects := &fakeProjectRepo{}
+	svc := newService(repo, projects, &fakeLabelRepo{}, &fakeTaskRepo{})
+	ctx := context.Background()
+
+	start := time.Date(2026, 11, 2, 9, 0, 0, 0, time.UTC)
+	p, err := svc.Instantiate(ctx, "user-1", "tmpl-1", domain.TemplateInstance{
+		Variables: map[string]string{"client": " Acme "},
+		StartDate: &start,
+	})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	bp := projects.blueprint
+	if p.Name != "Onboarding Acme" || bp.EstimateUnit != domain.EstimateHours || len(bp.Labels) != 1 {
+		t.Fatalf("expected substituted project, got %+v / %+v", p, bp)
+	}
+	contract := bp.Tasks[0]
+	if contract.Title != "Contract for Acme" || contract.Priority != domain.PriorityHigh || contract.Labels[0] != "legal" {
+		t.Fatalf("unexpected first task %+v", contract)
+	}
+	if !contract.DueDate.Equal(start.AddDate(0, 0, 3)) {
+		t.Fatalf("expected due in 3 days, got %v", contract.DueDate)
+	}
+	sub := contract.Subtasks[0]
+	if sub.Title != "Send to Acme" || !sub.DueDate.Equal(start.AddDate(0, 0, -1)) {
+		t.Fatalf("unexpected subtask %+v", sub)
+	}
+	if bp.Tasks[1].DueDate != nil {
+		t.Fatalf("expected no due date without an offset, got %v", bp.Tasks[1].DueDate)
+	}
+
+	cases := []struct {
+		vars  map[string]string
+		field string
+	}{
+		{nil, "variables.client"},
+		{map[string]string{"client": "  "}, "variables.client"},
+		{map[string]string{"client": "Acme", "clinet": "typo"}, "variables.clinet"},
+		{map[string]string{"client": "two\nlines"}, "variables.client"},
+	}
+	for _, c := range cases {
+		_, err := svc.Instantiate(ctx, "user-1", "tmpl-1", domain.TemplateInstance{Variables: c.vars})
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%v: expected %s ValidationError, got %v", c.vars, c.field, err)
+		}
+	}
+
+	if _, err := svc.Instantiate(ctx, "user-1", "missing", domain.TemplateInstance{}); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTemplateService_Capture_BuildsTreeWithOffsets(t *testing.T) {
+	day := func(d int) *time.Time {
+		v := time.Date(2026, 5, d, 15, 0, 0, 0, time.UTC)
+		return &v
+	}
+	parent := "t-1"
+	tasks := &fakeTaskRepo{tasks: []domain.Task{
+		{ID: "t-1", Title: "Kickoff", DueDate: day(10), Completed: true, Labels: []domain.TaskLabel{{Name: "Legal"}}},
+		{ID: "t-2", Title: "Agenda", ParentTaskID: &parent, DueDate: day(8)},
+		{ID: "t-3", Title: "Wrap up", Priority: domain.PriorityLow, DueDate: day(20)},
+		{ID: "t-4", Title: "Notes"},
+	}}
+	repo := &fakeTemplateRepo{}
+	projects := &fakeProjectRepo{project: domain.Project{ID: "proj-1", Name: "Acme onboarding", EstimateUnit: domain.EstimateHours}}
+	labels := &fakeLabelRepo{labels: []domain.Label{{Name: "Legal", Color: "#112233"}}}
+	svc := newService(repo, projects, labels, tasks)
+	ctx := context.Background()
+
+	got, err := svc.Capture(ctx, "user-1", "proj-1", "Onboarding")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if tasks.pages != 2 {
+		t.Fatalf("expected every page to be read, got %d pages", tasks.pages)
+	}
+	if got.Name != "Onboarding" || got.ProjectName != "Acme onboarding" || got.EstimateUnit != domain.EstimateHours || len(got.Labels) != 1 {
+		t.Fatalf("unexpected template %+v", got)
+	}
+	if len(got.Tasks) != 3 || got.Tasks[0].Title != "Kickoff" || got.Tasks[1].Title != "Wrap up" || got.Tasks[2].Title != "Notes" {
+		t.Fatalf("expected three top-level tasks in order, got %+v", got.Tasks)
+	}
+	kickoff := got.Tasks[0]
+	if len(kickoff.Subtasks) != 1 || kickoff.Subtasks[0].Title != "Agenda" || kickoff.Labels[0] != "Legal" {
+		t.Fatalf("expected the subtask and label kept, got %+v", kickoff)
+	}
+	// Offsets count from the earliest due date, May 8.
+	if *kickoff.DueInDays != 2 || *kickoff.Subtasks[0].DueInDays != 0 || *got.Tasks[1].DueInDays != 12 || got.Tasks[2].DueInDays != nil {
+		t.Fatalf("unexpected offsets %+v", got.Tasks)
+	}
+
+	if _, err := svc.Capture(ctx, "user-1", "missing", "x"); !errors.Is(err, _service.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
@@ -0,0 +1,260 @@
+package timetracking
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeTimeEntryRepo struct {
+	startFn  func(ctx context.Context, userID, taskID string, at time.Time, note string) (domain.TimeEntry, error)
+	stopFn   func(ctx context.Context, userID string, at time.Time) (domain.TimeEntry, error)
+	createFn func(ctx context.Context, userID, taskID string, in domain.TimeEntryInput) (domain.TimeEntry, error)
+	getFn    func(ctx context.Context, userID, entryID string) (domain.TimeEntry, error)
+	updateFn func(ctx context.Context, userID, entryID string, in domain.TimeEntryInput) (domain.TimeEntry, error)
+	totalsFn func(ctx context.Context, userID string, q domain.TimeTotalsQuery) ([]domain.TimeTotal, error)
+	archived bool
+}
+
+func (f *fakeTimeEntryRepo) Start(ctx context.Context, userID, taskID string, at time.Time, note string) (domain.TimeEntry, error) {
+	if f.startFn != nil {
+		return f.startFn(ctx, userID, taskID, at, note)
+	}
+	return domain.TimeEntry{TaskID: taskID, StartedAt: at, Note: note, Running: true}, nil
+}
+
+func (f *fakeTimeEntryRepo) Stop(ctx context.Context, userID string, at time.Time) (domain.TimeEntry, error) {
+	if f.stopFn != nil {
+		return f.stopFn(ctx, userID, at)
+	}
+	return domain.TimeEntry{}, sql.ErrNoRows
+}
+
+func (f *fakeTimeEntryRepo) Running(ctx context.Context, userID string) (domain.TimeEntry, error) {
+	return domain.TimeEntry{}, sql.ErrNoRows
+}
+
+func (f *fakeTimeEntryRepo) Create(ctx context.Context, userID, taskID string, in domain.TimeEntryInput) (domain.TimeEntry, error) {
+	if f.createFn != nil {
+		return f.createFn(ctx, userID, taskID, in)
+	}
+	return domain.TimeEntry{TaskID: taskID, StartedAt: in.StartedAt, EndedAt: in.EndedAt, Note: in.Note}, nil
+}
+
+func (f *fakeTimeEntryRepo) Get(ctx context.Context, userID, entryID string) (domain.TimeEntry, error) {
+	if f.getFn != nil {
+		return f.getFn(ctx, userID, entryID)
+	}
+	return domain.TimeEntry{}, sql.ErrNoRows
+}
+
+func (f *fakeTimeEntryRepo) List(ctx context.Context, userID, taskID string, limit int, cursor *domain.Cursor) ([]domain.TimeEntry, *domain.Cursor, error) {
+	return nil, nil, nil
+}
+
+func (f *fakeTimeEntryRepo) Update(ctx context.Context, userID, entryID string, in domain.TimeEntryInput) (domain.TimeEntry, error) {
+	if f.updateFn != nil {
+		return f.updateFn(ctx, userID, entryID, in)
+	}
+	return domain.TimeEntry{ID: entryID, StartedAt: in.StartedAt, EndedAt: in.EndedAt, Note: in.Note}, nil
+}
+
+func (f *fakeTimeEntryRepo) Delete(ctx context.Context, userID, entryID string) error {
+	return nil
+}
+
+func (f *fakeTimeEntryRepo) Totals(ctx context.Context, userID string, q domain.TimeTotalsQuery) ([]domain.TimeTotal, error) {
+	if f.totalsFn != nil {
+		return f.totalsFn(ctx, userID, q)
+	}
+	return nil, nil
+}
+
+func (f *fakeTimeEntryRepo) TaskArchived(ctx context.Context, userID, taskID string) (bool, error) {
+	return f.archived, nil
+}
+
+var now = time.Date(2026, 5, 4, 15, 0, 0, 0, time.UTC)
+
+func clock() time.Time { return now }
+
+func at(h, m int) *time.Time {
+	t := time.Date(2026, 5, 4, h, m, 0, 0, time.UTC)
+	return &t
+}
+
+func TestTimeService_Start_UsesClockAndMapsRunningTimer(t *testing.T) {
+	var gotAt time.Time
+	repo := &fakeTimeEntryRepo{
+		startFn: func(ctx context.Context, userID, taskID string, at time.Time, note string) (domain.TimeEntry, error) {
+			gotAt = at
+			return domain.TimeEntry{}, nil
+		},
+	}
+	svc := _service.NewTimeService(repo, _service.WithTimeClock(clock))
+
+	if _, err := svc.Start(context.Background(), "user-1", "task-1", "  "); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if !gotAt.Equal(now) {
+		t.Fatalf("expected timer to start at %v, got %v", now, gotAt)
+	}
+
+	repo.startFn = func(ctx context.Context, userID, taskID string, at time.Time, note string) (domain.TimeEntry, error) {
+		return domain.TimeEntry{}, domain.ErrDuplicate
+	}
+	if _, err := svc.Start(context.Background(), "user-1", "task-1", ""); err != _service.ErrTimerRunning {
+		t.Fatalf("expected ErrTimerRunning, got %v", err)
+	}
+
+	repo.startFn = func(ctx context.Context, userID, taskID string, at time.Time, note string) (domain.TimeEntry, error) {
+		return domain.TimeEntry{}, sql.ErrNoRows
+	}
+	if _, err := svc.Start(context.Background(), "user-1", "task-1", ""); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestTimeService_Stop_WithoutTimerIsNotFound(t *testing.T) {
+	svc := _service.NewTimeService(&fakeTimeEntryRepo{})
+	if _, err := svc.Stop(context.Background(), "user-1"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+	e, err := svc.Running(context.Background(), "user-1")
+	if err != nil || e != nil {
+		t.Fatalf("expected no running timer, got %v, %v", e, err)
+	}
+}
+
+func TestTimeService_Log_ValidatesSpanAndNote(t *testing.T) {
+	svc := _service.NewTimeService(&fakeTimeEntryRepo{}, _service.WithTimeClock(clock))
+
+	cases := []struct {
+		name  string
+		in    domain.TimeEntryInput
+		field string
+	}{
+		{"no end", domain.TimeEntryInput{StartedAt: *at(9, 0)}, "endedAt"},
+		{"no start", domain.TimeEntryInput{EndedAt: at(10, 0)}, "startedAt"},
+		{"ends before start", domain.TimeEntryInput{StartedAt: *at(10, 0), EndedAt: at(9, 0)}, "endedAt"},
+		{"ends in future", domain.TimeEntryInput{StartedAt: *at(14, 0), EndedAt: at(16, 0)}, "endedAt"},
+		{"long note", domain.TimeEntryInput{StartedAt: *at(9, 0), EndedAt: at(10, 0), Note: strings.Repeat("x", 1001)}, "note"},
+	}
+	for _, c := range cases {
+		_, err := svc.Log(context.Background(), "user-1", "task-1", c.in)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%s: expected validation error on %s, got %v", c.name, c.field, err)
+		}
+	}
+
+	e, err := svc.Log(context.Background(), "user-1", "task-1", domain.TimeEntryInput{
+		StartedAt: *at(9, 0), EndedAt: at(10, 30), Note: " invoiced ",
+	})
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if e.Note != "invoiced" {
+		t.Fatalf("expected trimmed note, got %q", e.Note)
+	}
+}
+
+func TestTimeService_Update_MergesWithCurrentEntry(t *testing.T) {
+	var got domain.TimeEntryInput
+	repo := &fakeTimeEntryRepo{
+		getFn: func(ctx context.Context, userID, entryID string) (domain.TimeEntry, error) {
+			return domain.TimeEntry{ID: entryID, StartedAt: *at(13, 0), Note: "running", Running: true}, nil
+		},
+		updateFn: func(ctx context.Context, userID, entryID string, in domain.TimeEntryInput) (domain.TimeEntry, error) {
+			got = in
+			return domain.TimeEntry{}, nil
+		},
+	}
+	svc := _service.NewTimeService(repo, _service.WithTimeClock(clock))
+
+	note := "design review"
+	if _, err := svc.Update(context.Background(), "user-1", "e-1", domain.TimeEntryPatch{Note: &note}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.EndedAt != nil || !got.StartedAt.Equal(*at(13, 0)) || got.Note != note {
+		t.Fatalf("expected a running entry with a new note, got %#v", got)
+	}
+
+	_, err := svc.Update(context.Background(), "user-1", "e-1", domain.TimeEntryPatch{EndedAt: at(12, 0)})
+	var ve *_service.ValidationError
+	if !errors.As(err, &ve) || ve.Field != "endedAt" {
+		t.Fatalf("expected validation error on endedAt, got %v", err)
+	}
+}
+
+func TestTimeService_Totals_ValidatesRange(t *testing.T) {
+	var got domain.TimeTotalsQuery
+	repo := &fakeTimeEntryRepo{
+		totalsFn: func(ctx context.Context, userID string, q domain.TimeTotalsQuery) ([]domain.TimeTotal, error) {
+			got = q
+			return nil, nil
+		},
+	}
+	svc := _service.NewTimeService(repo)
+
+	from, to := *at(0, 0), *at(23, 0)
+	for _, c := range []struct {
+		q     domain.TimeTotalsQuery
+		field string
+	}{
+		{domain.TimeTotalsQuery{To: to}, "from"},
+		{domain.TimeTotalsQuery{From: from}, "to"},
+		{domain.TimeTotalsQuery{From: to, To: from}, "to"},
+		{domain.TimeTotalsQuery{From: from, To: to, GroupBy: "client"}, "groupBy"},
+	} {
+		_, err := svc.Totals(context.Background(), "user-1", c.q)
+		var ve *_service.ValidationError
+		if !errors.As(err, &ve) || ve.Field != c.field {
+			t.Fatalf("%#v: expected validation error on %s, got %v", c.q, c.field, err)
+		}
+	}
+
+	if _, err := svc.Totals(context.Background(), "user-1", domain.TimeTotalsQuery{From: from, To: to}); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if got.GroupBy != domain.TimeByTask {
+		t.Fatalf("expected totals grouped by task by default, got %q", got.GroupBy)
+	}
+}
+
+func TestTimeService_ArchivedProjectsOnlyStopTimers(t *testing.T) {
+	stopped := false
+	repo := &fakeTimeEntryRepo{
+		archived: true,
+		getFn: func(ctx context.Context, userID, entryID string) (domain.TimeEntry, error) {
+			return domain.TimeEntry{ID: entryID, TaskID: "task-1", StartedAt: *at(9, 0)}, nil
+		},
+		stopFn: func(ctx context.Context, userID string, at time.Time) (domain.TimeEntry, error) {
+			stopped = true
+			return domain.TimeEntry{}, nil
+		},
+	}
+	svc := _service.NewTimeService(repo, _service.WithTimeClock(clock))
+	ctx := context.Background()
+	note := "edited"
+
+	checks := map[string]error{}
+	_, checks["start"] = svc.Start(ctx, "user-1", "task-1", "")
+	_, checks["log"] = svc.Log(ctx, "user-1", "task-1", domain.TimeEntryInput{StartedAt: *at(9, 0), EndedAt: at(10, 0)})
+	_, checks["update"] = svc.Update(ctx, "user-1", "entry-1", domain.TimeEntryPatch{Note: &note})
+	checks["delete"] = svc.Delete(ctx, "user-1", "entry-1")
+	for op, err := range checks {
+		if err != _service.ErrProjectArchived {
+			t.Fatalf("%s: expected ErrProjectArchived, got %v", op, err)
+		}
+	}
+	if _, err := svc.Stop(ctx, "user-1"); err != nil || !stopped {
+		t.Fatalf("expected the running timer to stop, got %v", err)
+	}
+}
@@ -0,0 +1,105 @@
+package trash
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeTrashRepo struct {
+	restoreProjectFn func(ctx context.Context, userID, projectID string) (domain.Project, error)
+	restoreTaskFn    func(ctx context.Context, userID, taskID string) (domain.Task, error)
+	purgeFn          func(ctx context.Context, cutoff time.Time) (int64, error)
+}
+
+func (f *fakeTrashRepo) List(ctx context.Context, userID string) (domain.Trash, error) {
+	return domain.Trash{}, nil
+}
+
+func (f *fakeTrashRepo) RestoreProject(ctx context.Context, userID, projectID string) (domain.Project, error) {
+	if f.restoreProjectFn != nil {
+		return f.restoreProjectFn(ctx, userID, projectID)
+	}
+	return domain.Project{ID: projectID}, nil
+}
+
+func (f *fakeTrashRepo) RestoreTask(ctx context.Context, userID, taskID string) (domain.Task, error) {
+	if f.restoreTaskFn != nil {
+		return f.restoreTaskFn(ctx, userID, taskID)
+	}
+	return domain.Task{ID: taskID}, nil
+}
+
+func (f *fakeTrashRepo) Purge(ctx context.Context, cutoff time.Time) (int64, error) {
+	if f.purgeFn != nil {
+		return f.purgeFn(ctx, cutoff)
+	}
+	return 0, nil
+}
+
+func TestTrashService_Restore_MapsNotFound(t *testing.T) {
+	repo := &fakeTrashRepo{
+		restoreProjectFn: func(ctx context.Context, userID, projectID string) (domain.Project, error) {
+			return domain.Project{}, sql.ErrNoRows
+		},
+		restoreTaskFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{}, sql.ErrNoRows
+		},
+	}
+	svc := _service.NewTrashService(repo)
+
+	if _, err := svc.RestoreProject(context.Background(), "user-1", "proj-1"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for project, got %v", err)
+	}
+	if _, err := svc.RestoreTask(context.Background(), "user-1", "task-1"); err != _service.ErrNotFound {
+		t.Fatalf("expected ErrNotFound for task, got %v", err)
+	}
+}
+
+func TestTrashService_Purge_UsesRetention(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	var got time.Time
+	repo := &fakeTrashRepo{
+		purgeFn: func(ctx context.Context, cutoff time.Time) (int64, error) {
+			got = cutoff
+			return 3, nil
+		},
+	}
+
+	svc := _service.NewTrashService(repo, _service.WithTrashClock(func() time.Time { return now }))
+	n, err := svc.Purge(context.Background())
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 purged, got %d, %v", n, err)
+	}
+	if want := now.Add(-_service.DefaultTrashRetention); !got.Equal(want) {
+		t.Fatalf("expected default cutoff %v, got %v", want, got)
+	}
+
+	svc = _service.NewTrashService(repo,
+		_service.WithTrashClock(func() time.Time { return now }),
+		_service.WithTrashRetention(7*24*time.Hour),
+	)
+	if _, err := svc.Purge(context.Background()); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if want := time.Date(2024, 3, 24, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Fatalf("expected cutoff %v, got %v", want, got)
+	}
+}
+
+func TestTrashService_RestoreTask_MapsArchivedProject(t *testing.T) {
+	repo := &fakeTrashRepo{
+		restoreTaskFn: func(ctx context.Context, userID, taskID string) (domain.Task, error) {
+			return domain.Task{}, domain.ErrArchived
+		},
+	}
+	svc := _service.NewTrashService(repo)
+
+	if _, err := svc.RestoreTask(context.Background(), "user-1", "task-1"); err != _service.ErrProjectArchived {
+		t.Fatalf("expected ErrProjectArchived, got %v", err)
+	}
+}
@@ -0,0 +1,158 @@
+package watchers
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"TaskFlow/internal/domain"
+	_service "TaskFlow/internal/service"
+)
+
+type fakeWatcherRepo struct {
+	tasks    map[string][]domain.Watcher
+	projects map[string][]domain.Watcher
+}
+
+func (f *fakeWatcherRepo) WatchTask(ctx context.Context, userID, taskID string) error {
+	if _, ok := f.tasks[taskID]; !ok {
+		return sql.ErrNoRows
+	}
+	for _, w := range f.tasks[taskID] {
+		if w.UserID == userID {
+			return nil
+		}
+	}
+	f.tasks[taskID] = append(f.tasks[taskID], domain.Watcher{UserID: userID, Reason: domain.WatchManual})
+	return nil
+}
+
+func (f *fakeWatcherRepo) UnwatchTask(ctx context.Context, userID, taskID string) error {
+	list, ok := f.tasks[taskID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	out := []domain.Watcher{}
+	for _, w := range list {
+		if w.UserID != userID {
+			out = append(out, w)
+		}
+	}
+	f.tasks[taskID] = out
+	return nil
+}
+
+func (f *fakeWatcherRepo) TaskWatchers(ctx context.Context, userID, taskID string) ([]domain.Watcher, error) {
+	list, ok := f.tasks[taskID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return list, nil
+}
+
+func (f *fakeWatcherRepo) WatchProject(ctx context.Context, userID, projectID string) error {
+	if _, ok := f.projects[projectID]; !ok {
+		return sql.ErrNoRows
+	}
+	f.projects[projectID] = append(f.projects[projectID], domain.Watcher{UserID: userID})
+	return nil
+}
+
+func (f *fakeWatcherRepo) UnwatchProject(ctx context.Context, userID, projectID string) error {
+	if _, ok := f.projects[projectID]; !ok {
+		return sql.ErrNoRows
+	}
+	f.projects[projectID] = []domain.Watcher{}
+	return nil
+}
+
+func (f *fakeWatcherRepo) ProjectWatchers(ctx context.Context, userID, projectID string) ([]domain.Watcher, error) {
+	list, ok := f.projects[projectID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return list, nil
+}
+
+func (f *fakeWatcherRepo) Subscribe(ctx context.Context, taskID string, userIDs []string, reason domain.WatchReason) error {
+	return nil
+}
+
+func (f *fakeWatcherRepo) Recipients(ctx context.Context, taskID string) ([]string, error) {
+	return nil, nil
+}
+
+func newRepo() *fakeWatcherRepo {
+	return &fakeWatcherRepo{
+		tasks:    map[string][]domain.Watcher{"task-1": {}},
+		projects: map[string][]domain.Watcher{"proj-1": {}},
+	}
+}
+
+func TestWatcherService_WatchAndUnwatchTask(t *testing.T) {
+	svc := _service.NewWatcherService(newRepo())
+	ctx := context.Background()
+
+	for i := 0; i < 2; i++ {
+		if err := svc.WatchTask(ctx, "user-1", "task-1"); err != nil {
+			t.Fatalf("expected nil err, got %v", err)
+		}
+	}
+	got, err := svc.TaskWatchers(ctx, "user-1", "task-1")
+	if err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	if len(got) != 1 || got[0].UserID != "user-1" || got[0].Reason != domain.WatchManual {
+		t.Fatalf("expected a single manual watcher, got %+v", got)
+	}
+
+	// Unwatching is idempotent.
+	for i := 0; i < 2; i++ {
+		if err := svc.UnwatchTask(ctx, "user-1", "task-1"); err != nil {
+			t.Fatalf("expected nil err, got %v", err)
+		}
+	}
+	if got, _ := svc.TaskWatchers(ctx, "user-1", "task-1"); len(got) != 0 {
+		t.Fatalf("expected no watchers, got %+v", got)
+	}
+}
+
+func TestWatcherService_MapsNotFound(t *testing.T) {
+	svc := _service.NewWatcherService(newRepo())
+	ctx := context.Background()
+
+	if err := svc.WatchTask(ctx, "user-1", "missing"); err != _service.ErrNotFound {
+		t.Fatalf("WatchTask: expected ErrNotFound, got %v", err)
+	}
+	if err := svc.UnwatchTask(ctx, "user-1", "missing"); err != _service.ErrNotFound {
+		t.Fatalf("UnwatchTask: expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.TaskWatchers(ctx, "user-1", "missing"); err != _service.ErrNotFound {
+		t.Fatalf("TaskWatchers: expected ErrNotFound, got %v", err)
+	}
+	if err := svc.WatchProject(ctx, "user-1", "missing"); err != _service.ErrNotFound {
+		t.Fatalf("WatchProject: expected ErrNotFound, got %v", err)
+	}
+	if err := svc.UnwatchProject(ctx, "user-1", "missing"); err != _service.ErrNotFound {
+		t.Fatalf("UnwatchProject: expected ErrNotFound, got %v", err)
+	}
+	if _, err := svc.ProjectWatchers(ctx, "user-1", "missing"); err != _service.ErrNotFound {
+		t.Fatalf("ProjectWatchers: expected ErrNotFound, got %v", err)
+	}
+}
+
+func TestWatcherService_WatchProject(t *testing.T) {
+	svc := _service.NewWatcherService(newRepo())
+	ctx := context.Background()
+
+	if err := svc.WatchProject(ctx, "user-1", "proj-1"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+	got, err := svc.ProjectWatchers(ctx, "user-1", "proj-1")
+	if err != nil || len(got) != 1 || got[0].UserID != "user-1" {
+		t.Fatalf("expected one project watcher, got %+v, %v", got, err)
+	}
+	if err := svc.UnwatchProject(ctx, "user-1", "proj-1"); err != nil {
+		t.Fatalf("expected nil err, got %v", err)
+	}
+}